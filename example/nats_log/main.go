@@ -20,7 +20,7 @@ func main() {
 
 	// 2. Инициализация NATS клиента
 	natsURL := "nats://localhost:4222" // Из конфига
-	natsClient, err := tnats.NewClient(natsURL, appLogger)
+	natsClient, err := tnats.NewClient(tnats.DefaultConfig(natsURL), appLogger)
 	if err != nil {
 		appLogger.WithError(err).Fatal("Failed to initialize NATS client")
 	}
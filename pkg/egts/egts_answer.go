@@ -0,0 +1,181 @@
+package egts
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// newPackage создает пакет транспортного уровня с флагами по умолчанию
+// (без маршрутизации, шифрования и сжатия)
+func newPackage(pid uint16, packetType byte, sfrd BinaryData) *Package {
+	return &Package{
+		ProtocolVersion:   1,
+		SecurityKeyID:     0,
+		Prefix:            "00",
+		Route:             "0",
+		EncryptionAlg:     "00",
+		Compression:       "0",
+		Priority:          "00",
+		HeaderEncoding:    0,
+		PacketIdentifier:  pid,
+		PacketType:        packetType,
+		ServicesFrameData: sfrd,
+	}
+}
+
+// newServiceRecord создает запись уровня поддержки услуг без OID, EVID и TM
+func newServiceRecord(rn uint16, service byte, rds RecordDataSet) ServiceDataRecord {
+	return ServiceDataRecord{
+		RecordNumber:             rn,
+		SourceServiceOnDevice:    "0",
+		RecipientServiceOnDevice: "0",
+		Group:                    "0",
+		RecordProcessingPriority: "00",
+		TimeFieldExists:          "0",
+		EventIDFieldExists:       "0",
+		ObjectIDFieldExists:      "0",
+		SourceServiceType:        service,
+		RecipientServiceType:     service,
+		RecordDataSet:            rds,
+	}
+}
+
+// RecordStatus результат обработки одной записи ППУ для подтверждения EGTS_SR_RECORD_RESPONSE
+type RecordStatus struct {
+	RecordNumber uint16
+	Service      byte
+	Status       uint8
+}
+
+// EncodePtResponse формирует пакет EGTS_PT_RESPONSE на принятый пакет pid.
+// Для каждой записи из records добавляется подзапись EGTS_SR_RECORD_RESPONSE.
+func EncodePtResponse(pid uint16, rpid uint16, result uint8, rn uint16, records []RecordStatus) ([]byte, error) {
+	resp := &PtResponse{
+		ResponsePacketID: rpid,
+		ProcessingResult: result,
+	}
+
+	if len(records) > 0 {
+		sdr := ServiceDataSet{}
+		for i, rec := range records {
+			sdr = append(sdr, newServiceRecord(rn+uint16(i), rec.Service, RecordDataSet{
+				RecordData{
+					SubrecordType: EGTS_SR_RECORD_RESPONSE,
+					SubrecordData: &SrResponse{
+						ConfirmedRecordNumber: rec.RecordNumber,
+						RecordStatus:          rec.Status,
+					},
+				},
+			}))
+		}
+		resp.SDR = &sdr
+	}
+
+	return newPackage(pid, EGTS_PT_RESPONSE, resp).Encode()
+}
+
+// EncodeResultCode формирует пакет EGTS_PT_APPDATA с подзаписью EGTS_SR_RESULT_CODE,
+// которым ТП сообщает АСН результат авторизации
+func EncodeResultCode(pid uint16, rn uint16, code uint8) ([]byte, error) {
+	sdr := ServiceDataSet{
+		newServiceRecord(rn, SERVICE_AUTH, RecordDataSet{
+			RecordData{
+				SubrecordType: EGTS_SR_RESULT_CODE,
+				SubrecordData: &SrResultCode{ResultCode: code},
+			},
+		}),
+	}
+
+	return newPackage(pid, EGTS_PT_APPDATA, &sdr).Encode()
+}
+
+// EncodeCommand формирует пакет EGTS_PT_APPDATA сервиса EGTS_COMMANDS_SERVICE с командой
+// CT_COM для АСН. АСН подтверждает пакет, а результат выполнения присылает подзаписью
+// EGTS_SR_COMMAND_DATA типа CT_COMCONF с тем же cid.
+func EncodeCommand(pid uint16, rn uint16, cid uint32, code uint16, act uint8, data []byte) ([]byte, error) {
+	sdr := ServiceDataSet{
+		newServiceRecord(rn, SERVICE_COMMANDS, RecordDataSet{
+			RecordData{
+				SubrecordType: EGTS_SR_COMMAND_DATA,
+				SubrecordData: &SrCommandData{
+					CommandType:         CT_COM,
+					CommandID:           cid,
+					AuthCodeFieldExists: "0",
+					CharsetFieldExists:  "0",
+					Action:              act,
+					CommandCode:         code,
+					Data:                data,
+				},
+			},
+		}),
+	}
+
+	return newPackage(pid, EGTS_PT_APPDATA, &sdr).Encode()
+}
+
+// FindCommandConfirms возвращает подзаписи CT_COMCONF из записей сервиса команд
+func FindCommandConfirms(sdrs *ServiceDataSet) []*SrCommandData {
+	var result []*SrCommandData
+	for _, sdr := range *sdrs {
+		if sdr.SourceServiceType != SERVICE_COMMANDS {
+			continue
+		}
+		for _, rd := range sdr.RecordDataSet {
+			if cmd, ok := rd.SubrecordData.(*SrCommandData); ok && cmd.CommandType == CT_COMCONF {
+				result = append(result, cmd)
+			}
+		}
+	}
+	return result
+}
+
+// FindTermIdentity ищет подзапись EGTS_SR_TERM_IDENTITY в записях сервиса авторизации
+func FindTermIdentity(sdrs *ServiceDataSet) *SrTermIdentity {
+	for _, sdr := range *sdrs {
+		if sdr.SourceServiceType != SERVICE_AUTH {
+			continue
+		}
+		for _, rd := range sdr.RecordDataSet {
+			if ident, ok := rd.SubrecordData.(*SrTermIdentity); ok {
+				return ident
+			}
+		}
+	}
+	return nil
+}
+
+// ReadPackage читает из потока ровно один пакет транспортного уровня,
+// используя длину заголовка (HL) и длину данных (FDL)
+func ReadPackage(r io.Reader) ([]byte, error) {
+	head := make([]byte, 4)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return nil, err
+	}
+	if head[0] != 1 {
+		return nil, fmt.Errorf("неподдерживаемая версия протокола: %d", head[0])
+	}
+
+	headerLength := int(head[3])
+	if headerLength != DEFAULT_HEADER_LEN && headerLength != DEFAULT_HEADER_LEN+5 {
+		return nil, fmt.Errorf("некорректная длина заголовка: %d", headerLength)
+	}
+
+	packet := make([]byte, headerLength)
+	copy(packet, head)
+	if _, err := io.ReadFull(r, packet[4:]); err != nil {
+		return nil, err
+	}
+
+	frameDataLength := int(binary.LittleEndian.Uint16(packet[5:7]))
+	if frameDataLength == 0 {
+		return packet, nil
+	}
+
+	body := make([]byte, frameDataLength+2)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+
+	return append(packet, body...), nil
+}
@@ -0,0 +1,190 @@
+package egts
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testTermIdentityPkgBin = []byte{0x01, 0x00, 0x03, 0x0B, 0x00, 0x13, 0x00, 0x86, 0x00, 0x01, 0xB6, 0x08, 0x00,
+	0x5F, 0x00, 0x99, 0x02, 0x00, 0x00, 0x00, 0x01, 0x01, 0x01, 0x05, 0x00, 0xB0, 0x09, 0x02, 0x00, 0x10, 0x0D, 0xCE}
+
+func TestReadPackage(t *testing.T) {
+	// два пакета подряд в одном потоке
+	stream := bytes.NewReader(append(append([]byte{}, testTermIdentityPkgBin...), testTermIdentityPkgBin...))
+
+	for i := 0; i < 2; i++ {
+		raw, err := ReadPackage(stream)
+		if assert.NoError(t, err) {
+			assert.Equal(t, testTermIdentityPkgBin, raw)
+		}
+	}
+
+	_, err := ReadPackage(stream)
+	assert.Error(t, err)
+}
+
+func TestReadPackage_BadVersion(t *testing.T) {
+	bad := append([]byte{}, testTermIdentityPkgBin...)
+	bad[0] = 2
+
+	_, err := ReadPackage(bytes.NewReader(bad))
+	assert.Error(t, err)
+}
+
+func TestFindTermIdentity(t *testing.T) {
+	pkg := Package{}
+	_, err := pkg.Decode(testTermIdentityPkgBin)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ident := FindTermIdentity(pkg.ServicesFrameData.(*ServiceDataSet))
+	if assert.NotNil(t, ident) {
+		assert.Equal(t, uint32(133552), ident.TerminalIdentifier)
+	}
+}
+
+func TestEncodePtResponse(t *testing.T) {
+	raw, err := EncodePtResponse(10, 134, EGTS_PC_OK, 5, []RecordStatus{
+		{RecordNumber: 95, Service: SERVICE_AUTH, Status: EGTS_PC_OK},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	pkg := Package{}
+	_, err = pkg.Decode(raw)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, uint16(10), pkg.PacketIdentifier)
+	assert.Equal(t, byte(EGTS_PT_RESPONSE), pkg.PacketType)
+
+	resp, ok := pkg.ServicesFrameData.(*PtResponse)
+	if assert.True(t, ok) {
+		assert.Equal(t, uint16(134), resp.ResponsePacketID)
+		assert.Equal(t, uint8(EGTS_PC_OK), resp.ProcessingResult)
+		if assert.NotNil(t, resp.SDR) {
+			sdr := (*resp.SDR.(*ServiceDataSet))[0]
+			assert.Equal(t, uint16(5), sdr.RecordNumber)
+			assert.Equal(t, &SrResponse{ConfirmedRecordNumber: 95, RecordStatus: EGTS_PC_OK}, sdr.RecordDataSet[0].SubrecordData)
+		}
+	}
+}
+
+func TestEncodeResultCode(t *testing.T) {
+	raw, err := EncodeResultCode(1, 2, EGTS_PC_AUTH_DENIED)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	pkg := Package{}
+	_, err = pkg.Decode(raw)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	sdr := (*pkg.ServicesFrameData.(*ServiceDataSet))[0]
+	assert.Equal(t, byte(SERVICE_AUTH), sdr.SourceServiceType)
+	assert.Equal(t, &SrResultCode{ResultCode: EGTS_PC_AUTH_DENIED}, sdr.RecordDataSet[0].SubrecordData)
+}
+
+func TestEncodeTermIdentity(t *testing.T) {
+	raw, err := EncodeTermIdentity(0, 0, 133552, "356307042441013")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	pkg := Package{}
+	_, err = pkg.Decode(raw)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ident := FindTermIdentity(pkg.ServicesFrameData.(*ServiceDataSet))
+	if assert.NotNil(t, ident) {
+		assert.Equal(t, uint32(133552), ident.TerminalIdentifier)
+		assert.Equal(t, "1", ident.IMEIE)
+		assert.Equal(t, "356307042441013", ident.IMEI)
+	}
+}
+
+func TestEncodeDispatcherIdentity(t *testing.T) {
+	raw, err := EncodeDispatcherIdentity(3, 1, 4100, "НКС")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	pkg := Package{}
+	_, err = pkg.Decode(raw)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	sdr := (*pkg.ServicesFrameData.(*ServiceDataSet))[0]
+	assert.Equal(t, byte(SERVICE_AUTH), sdr.SourceServiceType)
+	assert.Equal(t, &SrDispatcherIdentity{DispatcherID: 4100, Description: "НКС"}, sdr.RecordDataSet[0].SubrecordData)
+}
+
+func TestFindResultCode(t *testing.T) {
+	raw, err := EncodeResultCode(1, 2, EGTS_PC_OK)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	pkg := Package{}
+	_, err = pkg.Decode(raw)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	code := FindResultCode(pkg.ServicesFrameData.(*ServiceDataSet))
+	if assert.NotNil(t, code) {
+		assert.Equal(t, uint8(EGTS_PC_OK), code.ResultCode)
+	}
+}
+
+func TestEncodeCommand(t *testing.T) {
+	raw, err := EncodeCommand(5, 9, 42, EGTS_RAW_DATA, CA_PARAMS, []byte("OUT1 1"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	pkg := Package{}
+	_, err = pkg.Decode(raw)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	sdr := (*pkg.ServicesFrameData.(*ServiceDataSet))[0]
+	assert.Equal(t, byte(SERVICE_COMMANDS), sdr.SourceServiceType)
+	assert.Equal(t, uint16(9), sdr.RecordNumber)
+	cmd, ok := sdr.RecordDataSet[0].SubrecordData.(*SrCommandData)
+	if assert.True(t, ok) {
+		assert.Equal(t, uint8(CT_COM), cmd.CommandType)
+		assert.Equal(t, uint32(42), cmd.CommandID)
+		assert.Equal(t, []byte("OUT1 1"), cmd.Data)
+	}
+
+	// команда от ТП не является подтверждением
+	assert.Empty(t, FindCommandConfirms(pkg.ServicesFrameData.(*ServiceDataSet)))
+}
+
+func TestFindCommandConfirms(t *testing.T) {
+	sdrs := &ServiceDataSet{
+		newServiceRecord(1, SERVICE_DATA, RecordDataSet{
+			RecordData{SubrecordData: &SrPosData{}},
+		}),
+		newServiceRecord(2, SERVICE_COMMANDS, RecordDataSet{
+			RecordData{SubrecordData: &SrCommandData{CommandType: CT_COMCONF, CommandConfirmType: CC_OK, CommandID: 42}},
+		}),
+	}
+
+	confirms := FindCommandConfirms(sdrs)
+	if assert.Len(t, confirms, 1) {
+		assert.Equal(t, uint32(42), confirms[0].CommandID)
+	}
+}
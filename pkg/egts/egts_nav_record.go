@@ -0,0 +1,309 @@
+package egts
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/models"
+)
+
+// Источники (SRC) навигационных данных EGTS_SR_POS_DATA, ГОСТ 33472-2015
+const (
+	srcTimerIgnitionOn  = 0  // таймер при включенном зажигании
+	srcDistance         = 1  // пробег заданной дистанции
+	srcAngle            = 2  // превышение установленного значения угла поворота
+	srcResponse         = 3  // ответ на запрос
+	srcTimerIgnitionOff = 5  // таймер при выключенном зажигании
+	srcCaseOpened       = 10 // вскрытие корпуса
+	srcAlarmButton      = 13 // нажатие тревожной кнопки
+	srcEmergencyCall    = 15 // экстренный вызов
+	srcTimerEmergency   = 29 // таймер в экстренном режиме
+)
+
+// applySource отмечает запись как тревогу или событие по источнику навигационных данных.
+// Периодические источники (таймеры, пробег, угол, ответ на запрос) событием не считаются.
+func applySource(rec *models.NavRecord, src byte) {
+	switch src {
+	case srcTimerIgnitionOn, srcDistance, srcAngle, srcResponse, srcTimerIgnitionOff, srcTimerEmergency:
+		return
+	case srcCaseOpened, srcAlarmButton, srcEmergencyCall:
+		rec.Alarm = true
+	}
+	rec.Event = "src" + strconv.Itoa(int(src))
+}
+
+// флаги FlagPos подзаписи EGTS_SR_POS_DATA
+const (
+	flagPosValid    = 0x01 // VLD - координаты валидны
+	flagPosBlackBox = 0x08 // BB - данные из памяти
+	flagPosMoving   = 0x10 // MV - признак движения
+	flagPosLAHS     = 0x20 // LAHS - южная широта
+	flagPosLOHS     = 0x40 // LOHS - западная долгота
+	flagPosALTE     = 0x80 // ALTE - передана высота
+)
+
+// ApplyTo переносит основные навигационные данные в запись.
+// Координаты при разборе уже приведены к градусам*10^7 по модулю (см. Pos2int),
+// здесь они переводятся в градусы со знаком полушария.
+func (e *SrPosData) ApplyTo(rec *models.NavRecord) {
+	rec.NavigationTime = time.Unix(int64(e.NavigationTime), 0).UTC()
+	rec.Latitude = float64(e.Latitude) / 1e7
+	if e.FlagPos&flagPosLAHS != 0 {
+		rec.Latitude = -rec.Latitude
+	}
+	rec.Longitude = float64(e.Longitude) / 1e7
+	if e.FlagPos&flagPosLOHS != 0 {
+		rec.Longitude = -rec.Longitude
+	}
+	rec.Valid = e.FlagPos&flagPosValid != 0
+	rec.Moving = e.FlagPos&flagPosMoving != 0
+	rec.BlackBox = e.FlagPos&flagPosBlackBox != 0
+	if e.FlagPos&flagPosALTE != 0 {
+		rec.AltitudeValid = true
+		rec.Altitude = float64(e.Altitude)
+		if e.AltitudeSign == 1 {
+			rec.Altitude = -rec.Altitude
+		}
+	}
+	// скорость передается с дискретностью 0,1 км/ч
+	rec.Speed = float64(e.Speed) / 10
+	// старший бит направления при разборе переносится в 7-й бит Direction,
+	// восстанавливаем полное значение 0..359
+	rec.Course = uint16(e.Direction&^(e.DirectionHighestBit<<7)) | uint16(e.DirectionHighestBit)<<8
+	// пробег передается с дискретностью 0,1 км
+	rec.Odometer = float64(e.Odometer) / 10
+	rec.DigitalInputs = uint32(e.DigitalInputs)
+	applySource(rec, e.Source)
+}
+
+// ApplyTo переносит дополнительные навигационные данные в запись.
+// Геометрические факторы передаются с дискретностью 0,1.
+func (e *SrExtPosData) ApplyTo(rec *models.NavRecord) {
+	rec.Pdop = float64(e.PositionDilutionOfPrecision) / 10
+	rec.Hdop = float64(e.HorizontalDilutionOfPrecision) / 10
+	rec.Vdop = float64(e.VerticalDilutionOfPrecision) / 10
+	rec.Satellites = e.Satellites
+	rec.NavSystem = e.NavigationSystem
+}
+
+// ApplyTo переносит состояние дополнительных входов и выходов в запись:
+// аналоговые входы 1..8 - в датчики ain1..ain8, дополнительные дискретные входы
+// 9..72 - в датчики din9..din72 со значением 0 или 1.
+func (e *SrAdSensorsData) ApplyTo(rec *models.NavRecord) {
+	for i, value := range e.AnalogSensors {
+		if e.AnalogSensorFieldExists&(1<<i) != 0 {
+			rec.SetSensor(models.SensorName(models.SensorAnalogInput, i+1), float64(value))
+		}
+	}
+	for i, octet := range e.AdditionalDigitalInputsOctet {
+		if e.DigitalInputsOctetExists&(1<<i) == 0 {
+			continue
+		}
+		for bit := 0; bit < 8; bit++ {
+			rec.SetSensor(models.SensorName(models.SensorDigitalInput, 9+i*8+bit), float64(octet>>bit&1))
+		}
+	}
+	rec.DigitalOutputs = uint32(e.DigitalOutputs)
+}
+
+// ToNavRecord собирает навигационную запись из подзаписей записи сервиса EGTS_TELEDATA_SERVICE.
+// Второй результат false, если в записи нет ни навигационных данных, ни показаний датчиков.
+func (sdr *ServiceDataRecord) ToNavRecord() (*models.NavRecord, bool) {
+	rec := &models.NavRecord{
+		Client: sdr.ObjectIdentifier,
+	}
+	found := false
+
+	for _, rd := range sdr.RecordDataSet {
+		switch sub := rd.SubrecordData.(type) {
+		case *SrPosData:
+			sub.ApplyTo(rec)
+			found = true
+		case *SrExtPosData:
+			sub.ApplyTo(rec)
+			found = true
+		case *SrAdSensorsData:
+			sub.ApplyTo(rec)
+			found = true
+		case *SrAbsAnSensData:
+			rec.SetSensor(models.SensorName(models.SensorAnalogInput, int(sub.SensorNumber)), float64(sub.Value))
+			found = true
+		case *SrAbsDigSensData:
+			rec.SetSensor(models.SensorName(models.SensorDigitalInput, int(sub.Number)), float64(sub.StateNumber))
+			found = true
+		case *SrLiquidLevelSensor:
+			// младшие 3 бита флагов - номер датчика ДУТ (0..7), датчики в записи нумеруются с 1
+			num := int(sub.FlagLiq&0x07) + 1
+			rec.SetSensor(models.SensorName(models.SensorLiquidLevel, num), float64(sub.LiquidLevelSensorData))
+			found = true
+		}
+	}
+
+	return rec, found
+}
+
+// sourceOf подбирает источник навигационных данных для записи, обратное к applySource:
+// событие srcN передается как есть, тревога без номера источника - как тревожная кнопка.
+func sourceOf(rec *models.NavRecord) byte {
+	if num, ok := strings.CutPrefix(rec.Event, "src"); ok {
+		if src, err := strconv.ParseUint(num, 10, 8); err == nil {
+			return byte(src)
+		}
+	}
+	if rec.Alarm {
+		return srcAlarmButton
+	}
+	return srcTimerIgnitionOn
+}
+
+// NewSrPosData формирует подзапись EGTS_SR_POS_DATA из навигационной записи.
+// Координаты хранятся как после разбора: градусы*10^7 по модулю, знак - во флагах полушарий.
+func NewSrPosData(rec *models.NavRecord) *SrPosData {
+	navTime := rec.NavigationTime
+	if navTime.IsZero() {
+		navTime = rec.ReceivedTime
+	}
+
+	pos := &SrPosData{
+		NavigationTime: uint32(navTime.Unix()),
+		Latitude:       uint32(math.Round(math.Abs(rec.Latitude) * 1e7)),
+		Longitude:      uint32(math.Round(math.Abs(rec.Longitude) * 1e7)),
+		Speed:          uint16(math.Round(rec.Speed*10)) & 0x3FFF,
+		Odometer:       uint32(math.Round(rec.Odometer*10)) & 0xFFFFFF,
+		DigitalInputs:  byte(rec.DigitalInputs),
+		Source:         sourceOf(rec),
+	}
+	if rec.Latitude < 0 {
+		pos.FlagPos |= flagPosLAHS
+	}
+	if rec.Longitude < 0 {
+		pos.FlagPos |= flagPosLOHS
+	}
+	if rec.Valid {
+		pos.FlagPos |= flagPosValid
+	}
+	if rec.Moving {
+		pos.FlagPos |= flagPosMoving
+	}
+	if rec.BlackBox {
+		pos.FlagPos |= flagPosBlackBox
+	}
+	if rec.AltitudeValid {
+		pos.FlagPos |= flagPosALTE
+		pos.Altitude = uint32(math.Round(math.Abs(rec.Altitude)))
+		if rec.Altitude < 0 {
+			pos.AltitudeSign = 1
+		}
+	}
+	// старший (8-й) бит направления передается отдельно, в Direction он хранится
+	// в 7-м бите, как после разбора
+	pos.DirectionHighestBit = uint8(rec.Course >> 8 & 0x1)
+	pos.Direction = byte(rec.Course) | pos.DirectionHighestBit<<7
+
+	return pos
+}
+
+// NewSrExtPosData формирует подзапись EGTS_SR_EXT_POS_DATA из навигационной записи.
+// Поля передаются, только если в записи есть их значения; nil, если передавать нечего.
+func NewSrExtPosData(rec *models.NavRecord) *SrExtPosData {
+	ext := &SrExtPosData{
+		NavigationSystemFieldExists: "0",
+		SatellitesFieldExists:       "0",
+		PdopFieldExists:             "0",
+		HdopFieldExists:             "0",
+		VdopFieldExists:             "0",
+	}
+	found := false
+
+	if rec.Pdop > 0 {
+		ext.PdopFieldExists = "1"
+		ext.PositionDilutionOfPrecision = uint16(math.Round(rec.Pdop * 10))
+		found = true
+	}
+	if rec.Hdop > 0 {
+		ext.HdopFieldExists = "1"
+		ext.HorizontalDilutionOfPrecision = uint16(math.Round(rec.Hdop * 10))
+		found = true
+	}
+	if rec.Vdop > 0 {
+		ext.VdopFieldExists = "1"
+		ext.VerticalDilutionOfPrecision = uint16(math.Round(rec.Vdop * 10))
+		found = true
+	}
+	if rec.Satellites > 0 {
+		ext.SatellitesFieldExists = "1"
+		ext.Satellites = rec.Satellites
+		found = true
+	}
+	if rec.NavSystem != 0 {
+		ext.NavigationSystemFieldExists = "1"
+		ext.NavigationSystem = rec.NavSystem
+		found = true
+	}
+
+	if !found {
+		return nil
+	}
+	return ext
+}
+
+// sensorNumber разбирает имя датчика вида <prefix><N>, например ain3
+func sensorNumber(name, prefix string) (int, bool) {
+	num, ok := strings.CutPrefix(name, prefix)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(num)
+	if err != nil || n < 1 {
+		return 0, false
+	}
+	return n, true
+}
+
+// sensorRecords формирует подзаписи показаний датчиков: аналоговые входы ain1..ain255 -
+// EGTS_SR_ABS_AN_SENS_DATA, датчики уровня топлива lls1..lls8 - EGTS_SR_LIQUID_LEVEL_SENSOR.
+// Остальные датчики в ЕГТС не передаются.
+func sensorRecords(rec *models.NavRecord) RecordDataSet {
+	names := make([]string, 0, len(rec.Sensors))
+	for name := range rec.Sensors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rds := RecordDataSet{}
+	for _, name := range names {
+		value := uint32(math.Round(math.Max(rec.Sensors[name], 0)))
+		if n, ok := sensorNumber(name, models.SensorAnalogInput); ok && n <= 0xFF {
+			rds = append(rds, RecordData{
+				SubrecordType: EGTS_SR_ABS_AN_SENS_DATA,
+				SubrecordData: &SrAbsAnSensData{SensorNumber: uint8(n), Value: value & 0xFFFFFF},
+			})
+		} else if n, ok := sensorNumber(name, models.SensorLiquidLevel); ok && n <= 8 {
+			rds = append(rds, RecordData{
+				SubrecordType: EGTS_SR_LIQUID_LEVEL_SENSOR,
+				SubrecordData: &SrLiquidLevelSensor{FlagLiq: byte(n - 1), LiquidLevelSensorData: value},
+			})
+		}
+	}
+	return rds
+}
+
+// NewNavServiceRecord формирует запись сервиса EGTS_TELEDATA_SERVICE из навигационной записи,
+// обратное к ToNavRecord. Идентификатор объекта (OID) - числовой ID устройства.
+func NewNavServiceRecord(rn uint16, rec *models.NavRecord) ServiceDataRecord {
+	rds := RecordDataSet{
+		RecordData{SubrecordType: EGTS_SR_POS_DATA, SubrecordData: NewSrPosData(rec)},
+	}
+	if ext := NewSrExtPosData(rec); ext != nil {
+		rds = append(rds, RecordData{SubrecordType: EGTS_SR_EXT_POS_DATA, SubrecordData: ext})
+	}
+	rds = append(rds, sensorRecords(rec)...)
+
+	sdr := newServiceRecord(rn, SERVICE_DATA, rds)
+	sdr.ObjectIDFieldExists = "1"
+	sdr.ObjectIdentifier = rec.Client
+	return sdr
+}
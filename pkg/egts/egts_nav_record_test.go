@@ -0,0 +1,205 @@
+package egts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceDataRecord_ToNavRecord(t *testing.T) {
+	sdr := ServiceDataRecord{
+		ObjectIDFieldExists: "1",
+		ObjectIdentifier:    133552,
+		SourceServiceType:   SERVICE_DATA,
+		RecordDataSet: RecordDataSet{
+			RecordData{
+				SubrecordData: &SrPosData{
+					NavigationTime:      1533570258,
+					Latitude:            2582371588,
+					Longitude:           1240237060,
+					FlagPos:             0x81,
+					DirectionHighestBit: 1,
+					Speed:               340,
+					// старший бит направления уже перенесен в 7-й бит при разборе
+					Direction: 0x80 | 0x2C,
+					Odometer:  191,
+				},
+			},
+			RecordData{
+				SubrecordData: &SrExtPosData{
+					HorizontalDilutionOfPrecision: 50,
+					Satellites:                    12,
+				},
+			},
+			RecordData{
+				SubrecordData: &SrLiquidLevelSensor{
+					FlagLiq:               0x02,
+					LiquidLevelSensorData: 1200,
+				},
+			},
+		},
+	}
+
+	rec, ok := sdr.ToNavRecord()
+	if assert.True(t, ok) {
+		assert.Equal(t, uint32(133552), rec.Client)
+		assert.Equal(t, time.Unix(1533570258, 0).UTC(), rec.NavigationTime)
+		assert.InDelta(t, 258.2371588, rec.Latitude, 1e-9)
+		assert.InDelta(t, 124.023706, rec.Longitude, 1e-9)
+		assert.True(t, rec.Valid)
+		assert.True(t, rec.AltitudeValid)
+		assert.Equal(t, float64(34), rec.Speed)
+		assert.Equal(t, uint16(300), rec.Course)
+		assert.Equal(t, 19.1, rec.Odometer)
+		assert.Equal(t, 5.0, rec.Hdop)
+		assert.Equal(t, uint8(12), rec.Satellites)
+		assert.Equal(t, map[string]float64{"lls3": 1200}, rec.Sensors)
+		// источник 0 - таймер, это не событие
+		assert.False(t, rec.Alarm)
+		assert.Empty(t, rec.Event)
+	}
+}
+
+func TestServiceDataRecord_ToNavRecordSource(t *testing.T) {
+	tests := []struct {
+		src   byte
+		alarm bool
+		event string
+	}{
+		{srcDistance, false, ""},
+		{4, false, "src4"},
+		{srcAlarmButton, true, "src13"},
+	}
+
+	for _, tt := range tests {
+		sdr := ServiceDataRecord{
+			RecordDataSet: RecordDataSet{
+				RecordData{SubrecordData: &SrPosData{Source: tt.src}},
+			},
+		}
+		rec, ok := sdr.ToNavRecord()
+		if assert.True(t, ok) {
+			assert.Equal(t, tt.alarm, rec.Alarm, "src %d", tt.src)
+			assert.Equal(t, tt.event, rec.Event, "src %d", tt.src)
+		}
+	}
+}
+
+func TestSrPosData_ApplyTo(t *testing.T) {
+	pos := SrPosData{
+		NavigationTime: 1533570258,
+		Latitude:       557522000,
+		Longitude:      376156000,
+		FlagPos:        flagPosValid | flagPosMoving | flagPosLAHS | flagPosLOHS | flagPosALTE,
+		AltitudeSign:   1,
+		Altitude:       25,
+	}
+
+	var rec models.NavRecord
+	pos.ApplyTo(&rec)
+	assert.InDelta(t, -55.7522, rec.Latitude, 1e-9)
+	assert.InDelta(t, -37.6156, rec.Longitude, 1e-9)
+	assert.Equal(t, -25.0, rec.Altitude)
+	assert.True(t, rec.Moving)
+	assert.False(t, rec.BlackBox)
+}
+
+func TestSrAdSensorsData_ApplyTo(t *testing.T) {
+	ad := SrAdSensorsData{
+		DigitalInputsOctetExists:     0x02,
+		DigitalOutputs:               0x03,
+		AnalogSensorFieldExists:      0x01,
+		AdditionalDigitalInputsOctet: [8]byte{0, 0x81},
+		AnalogSensors:                [8]uint32{12000},
+	}
+
+	var rec models.NavRecord
+	ad.ApplyTo(&rec)
+	assert.Equal(t, uint32(0x03), rec.DigitalOutputs)
+	assert.Equal(t, 12000.0, rec.Sensors["ain1"])
+	// второй октет - входы 17..24
+	assert.Equal(t, 1.0, rec.Sensors["din17"])
+	assert.Equal(t, 0.0, rec.Sensors["din18"])
+	assert.Equal(t, 1.0, rec.Sensors["din24"])
+	assert.Len(t, rec.Sensors, 9)
+}
+
+func TestServiceDataRecord_ToNavRecordEmpty(t *testing.T) {
+	sdr := ServiceDataRecord{
+		SourceServiceType: SERVICE_DATA,
+		RecordDataSet: RecordDataSet{
+			RecordData{SubrecordData: &SrResponse{}},
+		},
+	}
+
+	_, ok := sdr.ToNavRecord()
+	assert.False(t, ok)
+}
+
+func TestNewNavServiceRecord_RoundTrip(t *testing.T) {
+	rec := &models.NavRecord{
+		Client:         133552,
+		NavigationTime: time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC),
+		Latitude:       -55.7522,
+		Longitude:      37.6156,
+		AltitudeValid:  true,
+		Altitude:       -12,
+		Speed:          61.7,
+		Course:         300,
+		Odometer:       1234.5,
+		Satellites:     9,
+		Hdop:           0.8,
+		Valid:          true,
+		Moving:         true,
+		Alarm:          true,
+		Event:          "src13",
+		DigitalInputs:  0x05,
+		Sensors:        map[string]float64{"ain2": 4100, "lls1": 350, "io66": 1},
+	}
+
+	raw, err := EncodeNavRecords(7, 20, []*models.NavRecord{rec})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	pkg := Package{}
+	_, err = pkg.Decode(raw)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, uint16(7), pkg.PacketIdentifier)
+
+	sdr := (*pkg.ServicesFrameData.(*ServiceDataSet))[0]
+	assert.Equal(t, uint16(20), sdr.RecordNumber)
+	assert.Equal(t, byte(SERVICE_DATA), sdr.SourceServiceType)
+
+	got, ok := sdr.ToNavRecord()
+	if assert.True(t, ok) {
+		assert.Equal(t, rec.Client, got.Client)
+		assert.Equal(t, rec.NavigationTime, got.NavigationTime)
+		assert.InDelta(t, rec.Latitude, got.Latitude, 1e-6)
+		assert.InDelta(t, rec.Longitude, got.Longitude, 1e-6)
+		assert.Equal(t, rec.Altitude, got.Altitude)
+		assert.Equal(t, rec.Speed, got.Speed)
+		assert.Equal(t, rec.Course, got.Course)
+		assert.Equal(t, rec.Odometer, got.Odometer)
+		assert.Equal(t, rec.Satellites, got.Satellites)
+		assert.Equal(t, rec.Hdop, got.Hdop)
+		assert.True(t, got.Valid)
+		assert.True(t, got.Moving)
+		assert.True(t, got.Alarm)
+		assert.Equal(t, "src13", got.Event)
+		assert.Equal(t, rec.DigitalInputs, got.DigitalInputs)
+		// io66 в ЕГТС не передается
+		assert.Equal(t, map[string]float64{"ain2": 4100, "lls1": 350}, got.Sensors)
+	}
+}
+
+func TestNewSrPosData_Source(t *testing.T) {
+	assert.Equal(t, byte(srcTimerIgnitionOn), NewSrPosData(&models.NavRecord{}).Source)
+	assert.Equal(t, byte(4), NewSrPosData(&models.NavRecord{Event: "src4"}).Source)
+	assert.Equal(t, byte(srcAlarmButton), NewSrPosData(&models.NavRecord{Alarm: true}).Source)
+	assert.Nil(t, NewSrExtPosData(&models.NavRecord{}))
+}
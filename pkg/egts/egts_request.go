@@ -0,0 +1,83 @@
+package egts
+
+import (
+	"github.com/rackov/NavControlSystem/pkg/models"
+)
+
+// Пакеты, которые отправляет клиентская сторона: АСН или авторизуемая ТП при ретрансляции
+
+// EncodeTermIdentity формирует пакет EGTS_PT_APPDATA с подзаписью EGTS_SR_TERM_IDENTITY,
+// которым АСН запрашивает авторизацию. IMEI передается, если он задан (15 символов).
+func EncodeTermIdentity(pid uint16, rn uint16, tid uint32, imei string) ([]byte, error) {
+	ident := &SrTermIdentity{
+		TerminalIdentifier: tid,
+		MNE:                "0",
+		BSE:                "0",
+		NIDE:               "0",
+		SSRA:               "0",
+		LNGCE:              "0",
+		IMSIE:              "0",
+		IMEIE:              "0",
+		HDIDE:              "0",
+	}
+	if len(imei) == 15 {
+		ident.IMEIE = "1"
+		ident.IMEI = imei
+	}
+
+	sdr := ServiceDataSet{
+		newServiceRecord(rn, SERVICE_AUTH, RecordDataSet{
+			RecordData{
+				SubrecordType: EGTS_SR_TERM_IDENTITY,
+				SubrecordData: ident,
+			},
+		}),
+	}
+
+	return newPackage(pid, EGTS_PT_APPDATA, &sdr).Encode()
+}
+
+// EncodeDispatcherIdentity формирует пакет EGTS_PT_APPDATA с подзаписью EGTS_SR_DISPATCHER_IDENTITY,
+// которым ТП авторизуется на другой (авторизующей) ТП
+func EncodeDispatcherIdentity(pid uint16, rn uint16, dispatcherID uint32, description string) ([]byte, error) {
+	sdr := ServiceDataSet{
+		newServiceRecord(rn, SERVICE_AUTH, RecordDataSet{
+			RecordData{
+				SubrecordType: EGTS_SR_DISPATCHER_IDENTITY,
+				SubrecordData: &SrDispatcherIdentity{
+					DispatcherID: dispatcherID,
+					Description:  description,
+				},
+			},
+		}),
+	}
+
+	return newPackage(pid, EGTS_PT_APPDATA, &sdr).Encode()
+}
+
+// EncodeNavRecords формирует пакет EGTS_PT_APPDATA сервиса EGTS_TELEDATA_SERVICE:
+// по одной записи ППУ на навигационную запись, номера записей начинаются с rn
+func EncodeNavRecords(pid uint16, rn uint16, recs []*models.NavRecord) ([]byte, error) {
+	sdr := make(ServiceDataSet, 0, len(recs))
+	for i, rec := range recs {
+		sdr = append(sdr, NewNavServiceRecord(rn+uint16(i), rec))
+	}
+
+	return newPackage(pid, EGTS_PT_APPDATA, &sdr).Encode()
+}
+
+// FindResultCode ищет подзапись EGTS_SR_RESULT_CODE, которой авторизующая ТП
+// сообщает результат авторизации
+func FindResultCode(sdrs *ServiceDataSet) *SrResultCode {
+	for _, sdr := range *sdrs {
+		if sdr.SourceServiceType != SERVICE_AUTH {
+			continue
+		}
+		for _, rd := range sdr.RecordDataSet {
+			if code, ok := rd.SubrecordData.(*SrResultCode); ok {
+				return code
+			}
+		}
+	}
+	return nil
+}
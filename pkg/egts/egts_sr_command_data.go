@@ -0,0 +1,184 @@
+package egts
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// SrCommandData структура подзаписи типа EGTS_SR_COMMAND_DATA сервиса EGTS_COMMANDS_SERVICE.
+// ТП передает в ней команды АС (CT_COM), АС - подтверждения и результаты их выполнения (CT_COMCONF).
+type SrCommandData struct {
+	CommandType         uint8  `json:"CT"`    // тип команды (CT_COM, CT_COMCONF, ...)
+	CommandConfirmType  uint8  `json:"CCT"`   // тип подтверждения (CC_OK, CC_ERROR, ...)
+	CommandID           uint32 `json:"CID"`   // идентификатор команды, подтверждение содержит тот же CID
+	SourceID            uint32 `json:"SID"`   // идентификатор отправителя
+	AuthCodeFieldExists string `json:"ACFE"`  // есть поля ACL и AC
+	CharsetFieldExists  string `json:"CHSFE"` // есть поле CHS
+	Charset             uint8  `json:"CHS"`   // кодировка символов в поле DT
+	AuthCode            []byte `json:"AC"`    // код авторизации на АС
+	Address             uint16 `json:"ADR"`   // адрес модуля, которому предназначена команда
+	Size                uint8  `json:"SZ"`    // объем памяти для параметра (ACT установки значения)
+	Action              uint8  `json:"ACT"`   // действие (CA_PARAMS, CA_SET, ...)
+	CommandCode         uint16 `json:"CCD"`   // код команды (EGTS_RAW_DATA, ...)
+	Data                []byte `json:"DT"`    // параметры команды или результат ее выполнения
+	CommandDataExists   bool   `json:"-"`     // поле CD передается; у CT_COMCONF оно может отсутствовать
+}
+
+// hasCommandData возвращает true, если для типа команды поле CD содержит ADR, SZ/ACT и CCD.
+// Поле CD остальных типов (сообщения CT_MSGTO и т.п.) хранится в Data целиком.
+func (s *SrCommandData) hasCommandData() bool {
+	return s.CommandType == CT_COM || s.CommandType == CT_COMCONF
+}
+
+// Decode разбирает байты в структуру подзаписи
+func (s *SrCommandData) Decode(content []byte) error {
+	var (
+		err   error
+		flags byte
+	)
+	buf := bytes.NewReader(content)
+
+	if flags, err = buf.ReadByte(); err != nil {
+		return fmt.Errorf("Не удалось получить тип команды: %v", err)
+	}
+	s.CommandType = flags >> 4
+	s.CommandConfirmType = flags & 0x0F
+
+	tmpBuf := make([]byte, 4)
+	if _, err = buf.Read(tmpBuf); err != nil {
+		return fmt.Errorf("Не удалось получить идентификатор команды: %v", err)
+	}
+	s.CommandID = binary.LittleEndian.Uint32(tmpBuf)
+
+	if _, err = buf.Read(tmpBuf); err != nil {
+		return fmt.Errorf("Не удалось получить идентификатор отправителя команды: %v", err)
+	}
+	s.SourceID = binary.LittleEndian.Uint32(tmpBuf)
+
+	if flags, err = buf.ReadByte(); err != nil {
+		return fmt.Errorf("Не удалось получить флаги команды: %v", err)
+	}
+	flagBits := fmt.Sprintf("%08b", flags)
+	s.AuthCodeFieldExists = flagBits[6:7]
+	s.CharsetFieldExists = flagBits[7:]
+
+	if s.CharsetFieldExists == "1" {
+		if s.Charset, err = buf.ReadByte(); err != nil {
+			return fmt.Errorf("Не удалось получить кодировку команды: %v", err)
+		}
+	}
+
+	if s.AuthCodeFieldExists == "1" {
+		acl, err := buf.ReadByte()
+		if err != nil {
+			return fmt.Errorf("Не удалось получить длину кода авторизации: %v", err)
+		}
+		s.AuthCode = make([]byte, acl)
+		if _, err = buf.Read(s.AuthCode); err != nil {
+			return fmt.Errorf("Не удалось получить код авторизации: %v", err)
+		}
+	}
+
+	s.CommandDataExists = buf.Len() > 0
+	if !s.CommandDataExists {
+		return nil
+	}
+	if !s.hasCommandData() {
+		s.Data = make([]byte, buf.Len())
+		_, err = buf.Read(s.Data)
+		return err
+	}
+
+	tmpBuf = make([]byte, 2)
+	if _, err = buf.Read(tmpBuf); err != nil {
+		return fmt.Errorf("Не удалось получить адрес модуля команды: %v", err)
+	}
+	s.Address = binary.LittleEndian.Uint16(tmpBuf)
+
+	if flags, err = buf.ReadByte(); err != nil {
+		return fmt.Errorf("Не удалось получить действие команды: %v", err)
+	}
+	s.Size = flags >> 4
+	s.Action = flags & 0x0F
+
+	if _, err = buf.Read(tmpBuf); err != nil {
+		return fmt.Errorf("Не удалось получить код команды: %v", err)
+	}
+	s.CommandCode = binary.LittleEndian.Uint16(tmpBuf)
+
+	if buf.Len() > 0 {
+		s.Data = make([]byte, buf.Len())
+		if _, err = buf.Read(s.Data); err != nil {
+			return fmt.Errorf("Не удалось получить данные команды: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// Encode преобразовывает подзапись в набор байт
+func (s *SrCommandData) Encode() ([]byte, error) {
+	var (
+		result []byte
+		err    error
+	)
+	buf := new(bytes.Buffer)
+
+	if err = buf.WriteByte(s.CommandType<<4 | s.CommandConfirmType&0x0F); err != nil {
+		return result, fmt.Errorf("Не удалось записать тип команды: %v", err)
+	}
+	if err = binary.Write(buf, binary.LittleEndian, s.CommandID); err != nil {
+		return result, fmt.Errorf("Не удалось записать идентификатор команды: %v", err)
+	}
+	if err = binary.Write(buf, binary.LittleEndian, s.SourceID); err != nil {
+		return result, fmt.Errorf("Не удалось записать идентификатор отправителя команды: %v", err)
+	}
+
+	var flags byte
+	if s.AuthCodeFieldExists == "1" {
+		flags |= 0x02
+	}
+	if s.CharsetFieldExists == "1" {
+		flags |= 0x01
+	}
+	buf.WriteByte(flags)
+
+	if s.CharsetFieldExists == "1" {
+		buf.WriteByte(s.Charset)
+	}
+	if s.AuthCodeFieldExists == "1" {
+		if len(s.AuthCode) > 255 {
+			return result, fmt.Errorf("Слишком длинный код авторизации: %d", len(s.AuthCode))
+		}
+		buf.WriteByte(byte(len(s.AuthCode)))
+		buf.Write(s.AuthCode)
+	}
+
+	if s.hasCommandData() && (s.CommandDataExists || s.CommandType == CT_COM) {
+		if err = binary.Write(buf, binary.LittleEndian, s.Address); err != nil {
+			return result, fmt.Errorf("Не удалось записать адрес модуля команды: %v", err)
+		}
+		buf.WriteByte(s.Size<<4 | s.Action&0x0F)
+		if err = binary.Write(buf, binary.LittleEndian, s.CommandCode); err != nil {
+			return result, fmt.Errorf("Не удалось записать код команды: %v", err)
+		}
+	}
+	buf.Write(s.Data)
+
+	result = buf.Bytes()
+	return result, err
+}
+
+// Length получает длинну закодированной подзаписи
+func (s *SrCommandData) Length() uint16 {
+	var result uint16
+
+	if recBytes, err := s.Encode(); err != nil {
+		result = uint16(0)
+	} else {
+		result = uint16(len(recBytes))
+	}
+
+	return result
+}
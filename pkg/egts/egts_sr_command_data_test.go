@@ -0,0 +1,59 @@
+package egts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	// CT_COM, CID 1, SID 0, без CHS и AC, EGTS_RAW_DATA с текстом "OUT1"
+	testSrCommandDataBin = []byte{0x50, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x4F, 0x55, 0x54, 0x31}
+
+	testSrCommandData = SrCommandData{
+		CommandType:         CT_COM,
+		CommandConfirmType:  CC_OK,
+		CommandID:           1,
+		AuthCodeFieldExists: "0",
+		CharsetFieldExists:  "0",
+		CommandCode:         EGTS_RAW_DATA,
+		Data:                []byte("OUT1"),
+		CommandDataExists:   true,
+	}
+)
+
+func TestSrCommandData_Encode(t *testing.T) {
+	data, err := testSrCommandData.Encode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, testSrCommandDataBin, data)
+	}
+}
+
+func TestSrCommandData_Decode(t *testing.T) {
+	cmd := SrCommandData{}
+	if assert.NoError(t, cmd.Decode(testSrCommandDataBin)) {
+		assert.Equal(t, testSrCommandData, cmd)
+	}
+}
+
+func TestSrCommandData_ConfirmWithoutData(t *testing.T) {
+	// CT_COMCONF/CC_ERROR на команду 7 без поля CD, с кодом авторизации
+	bin := []byte{0x11, 0x07, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x02, 0x02, 0x31, 0x32}
+
+	cmd := SrCommandData{}
+	if !assert.NoError(t, cmd.Decode(bin)) {
+		return
+	}
+	assert.Equal(t, uint8(CT_COMCONF), cmd.CommandType)
+	assert.Equal(t, uint8(CC_ERROR), cmd.CommandConfirmType)
+	assert.Equal(t, uint32(7), cmd.CommandID)
+	assert.Equal(t, uint32(2), cmd.SourceID)
+	assert.Equal(t, []byte("12"), cmd.AuthCode)
+	assert.False(t, cmd.CommandDataExists)
+
+	data, err := cmd.Encode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, bin, data)
+	}
+}
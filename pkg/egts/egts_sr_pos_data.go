@@ -48,7 +48,7 @@ type SrPosData struct {
 	// VLD                 string `json:"VLD"`
 	DirectionHighestBit uint8  `json:"DIRH"`
 	AltitudeSign        uint8  `json:"ALTS"`
-	Speed               uint16 `json:"SPD"`
+	Speed               uint16 `json:"SPD"` // скорость с дискретностью 0,1 км/ч, как в пакете
 	Direction           byte   `json:"DIR"`
 	Odometer            uint32 `json:"ODM"`
 	DigitalInputs       byte   `json:"DIN"`
@@ -121,8 +121,8 @@ func (e *SrPosData) Decode(content []byte) error {
 		return fmt.Errorf("не удалось расшифровать скорость из битов: %v", err)
 	}
 
-	// т.к. скорость с дискретностью 0,1 км
-	e.Speed = uint16(speed) / 10
+	// скорость с дискретностью 0,1 км/ч, в км/ч переводит ApplyTo
+	e.Speed = uint16(speed)
 
 	if e.Direction, err = buf.ReadByte(); err != nil {
 		return fmt.Errorf("не удалось получить направление движения: %v", err)
@@ -192,8 +192,8 @@ func (e *SrPosData) Encode() ([]byte, error) {
 	}
 
 	// скорость
-	speed := e.Speed*10 | uint16(e.DirectionHighestBit)<<15 // 15 бит
-	speed = speed | uint16(e.AltitudeSign)<<14              //14 бит
+	speed := e.Speed&0x3FFF | uint16(e.DirectionHighestBit)<<15 // 15 бит
+	speed = speed | uint16(e.AltitudeSign)<<14                  //14 бит
 	spd := make([]byte, 2)
 	binary.LittleEndian.PutUint16(spd, speed)
 	if _, err = buf.Write(spd); err != nil {
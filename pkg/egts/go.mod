@@ -0,0 +1,18 @@
+module github.com/rackov/NavControlSystem/pkg/egts
+
+go 1.23.12
+
+require (
+	github.com/rackov/NavControlSystem/pkg/models v0.0.0
+	github.com/sirupsen/logrus v1.9.3
+	github.com/stretchr/testify v1.10.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/rackov/NavControlSystem/pkg/models => ../models
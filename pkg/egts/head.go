@@ -45,6 +45,52 @@ const (
 	EGTS_SR_PASSENGERS_COUNTERS = 28 //АСН->данных о показаниях счетчиков пассажиропотока
 )
 
+/* Типы подзаписей сервиса команд */
+const (
+	EGTS_SR_COMMAND_DATA = 51 // команды ТП->АСН и подтверждения АСН->ТП
+)
+
+/* Типы команд EGTS_SR_COMMAND_DATA (поле CT) */
+const (
+	CT_COMCONF = 1 // подтверждение о приеме, обработке или результат выполнения команды
+	CT_MSGCONF = 2 // подтверждение о приеме, отображении и/или обработке информационного сообщения
+	CT_MSGFROM = 3 // информационное сообщение от АСН
+	CT_MSGTO   = 4 // информационное сообщение для вывода на устройство отображения АСН
+	CT_COM     = 5 // команда для выполнения на АСН
+	CT_DELCOM  = 6 // удаление из очереди на выполнение переданной ранее команды
+	CT_SUBREQ  = 7 // дополнительный подзапрос для выполнения (к переданной ранее команде)
+	CT_DELIV   = 8 // подтверждение о доставке команды или информационного сообщения
+)
+
+/* Типы подтверждения EGTS_SR_COMMAND_DATA (поле CCT) */
+const (
+	CC_OK     = 0 // успешное выполнение, положительный ответ
+	CC_ERROR  = 1 // обработка завершилась ошибкой
+	CC_ILL    = 2 // команда не может быть выполнена по причине отсутствия в списке разрешенных
+	CC_DEL    = 3 // команда успешно удалена
+	CC_NFOUND = 4 // команда для удаления не найдена
+	CC_NCONF  = 5 // успешное выполнение, отрицательный ответ
+	CC_INPROG = 6 // команда передана на обработку, но для ее выполнения требуется длительное время
+)
+
+/* Действия EGTS_SR_COMMAND_DATA (поле ACT) */
+const (
+	CA_PARAMS = 0 // параметры команды
+	CA_GET    = 1 // запрос значения
+	CA_SET    = 2 // установка значения
+	CA_ADD    = 3 // добавление нового параметра
+	CA_DEL    = 4 // удаление параметра
+)
+
+/* Коды команд EGTS_SR_COMMAND_DATA (поле CCD) */
+const (
+	EGTS_RAW_DATA      = 0x0000 // команда для передачи произвольных данных
+	EGTS_TEST_MODE     = 0x0001 // начало/завершение тестирования
+	EGTS_CONFIG_RESET  = 0x0006 // возврат к заводским установкам
+	EGTS_SET_AUTH_CODE = 0x0007 // установка кода авторизации на ТП
+	EGTS_RESTART       = 0x0008 // перезапуск основного ПО АСН
+)
+
 /* коды ошибок */
 const (
 	EGTS_PC_OK              = 0x00 // Успешно
@@ -94,8 +140,9 @@ const HEADERLEN = 10
 
 // Типы сервисов
 const (
-	SERVICE_AUTH = 1 // Сервис AUTH_SERVICE
-	SERVICE_DATA = 2 // Сервис TELEDATA_SERVICE
+	SERVICE_AUTH     = 1 // Сервис AUTH_SERVICE
+	SERVICE_DATA     = 2 // Сервис TELEDATA_SERVICE
+	SERVICE_COMMANDS = 4 // Сервис COMMANDS_SERVICE
 )
 
 // RecordDataSet описывает массив с подзаписями протокола ЕГТС
@@ -78,6 +78,8 @@ func (rds *RecordDataSet) Decode(recDS []byte) error {
 			rd.SubrecordData = &SrDispatcherIdentity{}
 		case EGTS_SR_PASSENGERS_COUNTERS:
 			rd.SubrecordData = &SrPassengersCountersData{}
+		case EGTS_SR_COMMAND_DATA:
+			rd.SubrecordData = &SrCommandData{}
 		default:
 			log.Infof("Не известный тип подзаписи: %d. Длина: %d. Содержимое: %X", rd.SubrecordType, rd.SubrecordLength, subRecordBytes)
 			continue
@@ -130,6 +132,8 @@ func (rds *RecordDataSet) Encode() ([]byte, error) {
 			// 	rd.SubrecordType = SrEgtsPlusDataType
 			case *SrAbsAnSensData:
 				rd.SubrecordType = EGTS_SR_ABS_AN_SENS_DATA
+			case *SrCommandData:
+				rd.SubrecordType = EGTS_SR_COMMAND_DATA
 			default:
 				return result, fmt.Errorf("не известен код для данного типа подзаписи")
 			}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Типы команд устройству
+const (
+	CommandEngineBlock     = "engine_block"     // заблокировать двигатель (включить выход блокировки)
+	CommandEngineUnblock   = "engine_unblock"   // снять блокировку двигателя
+	CommandSetOutput       = "set_output"       // включить или выключить дискретный выход Output
+	CommandRequestPosition = "request_position" // запросить внеочередную навигационную запись
+	CommandRaw             = "raw"              // передать Payload как есть, в формате команды протокола
+)
+
+// Статусы выполнения команды
+const (
+	CommandDelivered    = "delivered"     // устройство подтвердило выполнение
+	CommandFailed       = "failed"        // устройство отклонило команду или ее не удалось передать
+	CommandTimeout      = "timeout"       // устройство не ответило за Command.Timeout
+	CommandNoConnection = "no_connection" // устройство не подключено
+	CommandUnsupported  = "unsupported"   // протокол устройства не поддерживает команду
+	CommandExpired      = "expired"       // устройство не подключилось, пока команда ждала в очереди
+)
+
+// Command - команда устройству, которую диспетчер публикует в NATS.
+// Устройство выбирается по DeviceID (IMEI или ID, см. NavRecord.DeviceID).
+type Command struct {
+	ID        string    `json:"id"`                // идентификатор команды, возвращается в CommandResult
+	DeviceID  string    `json:"device_id"`         // IMEI или ID устройства
+	Type      string    `json:"type"`              // тип команды (CommandEngineBlock, ...)
+	Output    uint8     `json:"output,omitempty"`  // номер выхода для CommandSetOutput и блокировки двигателя, с 1
+	On        bool      `json:"on,omitempty"`      // состояние выхода для CommandSetOutput
+	Payload   []byte    `json:"payload,omitempty"` // данные команды CommandRaw
+	Timeout   int       `json:"timeout,omitempty"` // сколько секунд ждать ответа устройства; 0 - по умолчанию
+	CreatedAt time.Time `json:"created_at"`        // время создания команды
+}
+
+// Validate проверяет обязательные поля команды
+func (c *Command) Validate() error {
+	if c.DeviceID == "" {
+		return fmt.Errorf("device_id is not specified")
+	}
+	switch c.Type {
+	case CommandEngineBlock, CommandEngineUnblock, CommandRequestPosition:
+	case CommandSetOutput:
+		if c.Output == 0 {
+			return fmt.Errorf("output number is not specified")
+		}
+	case CommandRaw:
+		if len(c.Payload) == 0 {
+			return fmt.Errorf("payload is empty")
+		}
+	default:
+		return fmt.Errorf("unknown command type %q", c.Type)
+	}
+	return nil
+}
+
+// CommandResult - результат выполнения команды, который RECEIVER публикует в NATS
+type CommandResult struct {
+	CommandID string    `json:"command_id"`        // Command.ID
+	DeviceID  string    `json:"device_id"`         // Command.DeviceID
+	Status    string    `json:"status"`            // статус (CommandDelivered, ...)
+	Code      uint32    `json:"code,omitempty"`    // код ответа устройства в терминах протокола
+	Message   string    `json:"message,omitempty"` // описание ошибки
+	Time      time.Time `json:"time"`              // время получения результата
+}
+
+// NewCommandResult создает результат команды cmd с текущим временем
+func NewCommandResult(cmd *Command, status string, code uint32, message string) *CommandResult {
+	return &CommandResult{
+		CommandID: cmd.ID,
+		DeviceID:  cmd.DeviceID,
+		Status:    status,
+		Code:      code,
+		Message:   message,
+		Time:      time.Now().UTC(),
+	}
+}
@@ -2,83 +2,143 @@ package models
 
 import (
 	"encoding/json"
+	"strconv"
+	"time"
 )
 
-/*
-EGTS_SR_EGTS_PLUS_DATA      = 15 //EGTS_SR_EGTS_PLUS_DATA
-  EGTS_SR_POS_DATA            = 16 // ACN-> основных данных определения местоположения
-  EGTS_SR_EXT_POS_DATA        = 17 // ACN-> дополнительных данных определения местоположения
-  EGTS_SR_AD_SENSORS_DATA     = 18 // ACN-> аппаратно-программный комплекс информации о состоянии дополнительных дискретных и аналоговых входов
-  EGTS_SR_COUNTERS_DATA       = 19 // ->ACN данных о значении счетных входов
-  EGTS_SR_STATE_DATA          = 20 // ACN-> информации о состоянии АСН
-  EGTS_SR_ACCEL_DATA          = 21 // ->ACN ???
-  EGTS_SR_LOOPIN_DATA         = 22 //АСН->данных о состоянии шлейфовых входов
-  EGTS_SR_ABS_DIG_SENS_DATA   = 23 //АСН->данных о состоянии одного дискретного входа
-  EGTS_SR_ABS_AN_SENS_DATA    = 24 //АСН->данных о состоянии одного аналогового входа
-  EGTS_SR_ABS_CNTR_DATA       = 25 //АСН->данных о состоянии одного счетного входа
-  EGTS_SR_ABS_LOOPIN_DATA     = 26 //АСН->данных о состоянии одного шлейфового входа
-  EGTS_SR_LIQUID_LEVEL_SENSOR = 27 //АСН->данных о показаниях ДУТ
-  EGTS_SR_PASSENGERS_COUNTERS = 28 //АСН->данных о показаниях счетчиков пассажиропотока
-*/
-
+// NavRecord - навигационная запись в физических единицах, общая модель для всех сервисов.
+// RECEIVER собирает ее из пакетов любого протокола, поэтому потребителям не нужно знать
+// масштабы протоколов (EGTS Pos2int, градусы*10^7 Arnavi и т.п.): координаты - в градусах
+// со знаком полушария, скорость - в км/ч, высота - в метрах, время - в UTC.
 type NavRecord struct {
-	Client              uint32       `json:"tid"`
-	PacketID            uint32       `json:"pk_id"`
-	NavigationTimestamp uint32       `json:"nav_time"`
-	ReceivedTimestamp   uint32       `json:"rec_time"`
-	Latitude            uint32       `json:"lat"`
-	Longitude           uint32       `json:"lng"`
-	Speed               uint16       `json:"speed"`
-	FlagPos             byte         `json:"flag"`
-	Pdop                uint16       `json:"pdop"`
-	Hdop                uint16       `json:"hdop"`
-	Vdop                uint16       `json:"vdop"`
-	Nsat                uint8        `json:"nsat"`
-	Ns                  uint16       `json:"ns"`
-	DigInput            byte         `json:"din"`
-	Odometer            uint32       `json:"odm"`
-	Course              uint8        `json:"course"`
-	Imei                string       `json:"imei"`
-	Imsi                string       `json:"imsi"`
-	AnSenAbs            []Sensor     `json:"in_abs_in"`  // одного аналогового входа
-	DigSenAbs           []DiSensor   `json:"in_abs_dig"` // одного дискретного входа
-	AnSensors           []DopAnIn    `json:"in_an"`      // дополнительных аналоговых входов
-	DigSenonrs          []DopDigIn   `json:"in_dig"`     // дополнительных дискретного входа
-	DigSenOuts          []int        `json:"out_dig"`    // дополнительных дискретного выхода
-	LiquidSensors       LiquidSensor `json:"sn_liq"`     // данных о показаниях ДУТ
+	// --- Устройство и источник записи ---
+	Client   uint32 `json:"client"`             // числовой ID устройства (EGTS OID, ID Arnavi и т.п.)
+	Imei     string `json:"imei,omitempty"`     // IMEI устройства, если протокол его передает
+	Imsi     string `json:"imsi,omitempty"`     // IMSI SIM-карты
+	Protocol string `json:"protocol,omitempty"` // протокол порта (EGTS, ARNAVI, ...)
+	PortID   string `json:"port_id,omitempty"`  // ID порта из конфигурации RECEIVER
+	PacketID uint32 `json:"packet_id"`          // номер пакета протокола
+
+	// --- Время ---
+	NavigationTime time.Time `json:"nav_time"` // время определения координат
+	ReceivedTime   time.Time `json:"rec_time"` // время приема пакета сервером
+
+	// --- Местоположение ---
+	Latitude   float64 `json:"lat"`                  // градусы, южная широта отрицательная
+	Longitude  float64 `json:"lon"`                  // градусы, западная долгота отрицательная
+	Altitude   float64 `json:"alt,omitempty"`        // высота над уровнем моря, м
+	Speed      float64 `json:"speed"`                // скорость, км/ч
+	Course     uint16  `json:"course"`               // направление движения, градусы 0..359
+	Satellites uint8   `json:"sats"`                 // количество спутников
+	Pdop       float64 `json:"pdop,omitempty"`       // геометрические факторы точности
+	Hdop       float64 `json:"hdop,omitempty"`       //
+	Vdop       float64 `json:"vdop,omitempty"`       //
+	NavSystem  uint16  `json:"nav_system,omitempty"` // битовые флаги навигационных систем (EGTS NS)
+	Odometer   float64 `json:"odometer,omitempty"`   // пробег, км
+
+	// --- Признаки ---
+	Valid         bool   `json:"valid"`               // координаты валидны
+	AltitudeValid bool   `json:"alt_valid,omitempty"` // высота передана
+	Moving        bool   `json:"moving,omitempty"`    // признак движения
+	BlackBox      bool   `json:"black_box,omitempty"` // данные из памяти ("черный ящик")
+	Alarm         bool   `json:"alarm,omitempty"`     // запись отправлена по тревоге
+	Event         string `json:"event,omitempty"`     // событие, по которому отправлена запись (например, src4 для EGTS)
+
+	// --- Датчики ---
+	DigitalInputs  uint32             `json:"din"`               // основные дискретные входы, битовая маска (вход 1 - бит 0)
+	DigitalOutputs uint32             `json:"dout,omitempty"`    // дискретные выходы, битовая маска
+	Sensors        map[string]float64 `json:"sensors,omitempty"` // показания датчиков по имени, см. SensorName
+	Params         map[string]string  `json:"params,omitempty"`  // произвольные строковые параметры (например, Wialon IPS)
+}
+
+// Префиксы имен датчиков в NavRecord.Sensors. Датчики нумеруются с 1.
+const (
+	SensorAnalogInput  = "ain" // аналоговый вход N: ain1, ain2, ...
+	SensorDigitalInput = "din" // состояние дискретного входа N (0 или 1), для входов сверх основных 8
+	SensorLiquidLevel  = "lls" // показание датчика уровня топлива (ДУТ) N
+	SensorIO           = "io"  // IO элемент с ID N, если протокол не описывает его значение (Teltonika)
+)
+
+// SensorName возвращает имя датчика по префиксу и номеру, например SensorName(SensorAnalogInput, 1) = "ain1"
+func SensorName(prefix string, n int) string {
+	return prefix + strconv.Itoa(n)
+}
+
+// SetSensor сохраняет показание датчика
+func (r *NavRecord) SetSensor(name string, value float64) {
+	if r.Sensors == nil {
+		r.Sensors = make(map[string]float64)
+	}
+	r.Sensors[name] = value
 }
 
-func (eep *NavRecord) ToBytes() ([]byte, error) {
-	return json.Marshal(eep)
+// Sensor возвращает показание датчика и признак его наличия
+func (r *NavRecord) Sensor(name string) (float64, bool) {
+	v, ok := r.Sensors[name]
+	return v, ok
 }
 
-type DopAnIn struct {
-	Asfe byte      `json:"asfe"`
-	Ansi [8]uint32 `json:"ansi"`
+// SetParam сохраняет строковый параметр
+func (r *NavRecord) SetParam(name, value string) {
+	if r.Params == nil {
+		r.Params = make(map[string]string)
+	}
+	r.Params[name] = value
 }
 
-type DopDigIn struct {
-	Dioe byte    `json:"dioe"`
-	Adio [8]byte `json:"adio"`
+// DeviceID возвращает идентификатор устройства: IMEI, а если его нет - числовой ID клиента
+func (r *NavRecord) DeviceID() string {
+	if r.Imei != "" {
+		return r.Imei
+	}
+	return strconv.FormatUint(uint64(r.Client), 10)
 }
 
-type DiSensor struct {
-	StateNumber byte `json:"st_num"`
-	Number      byte `json:"num"`
+// HasPosition сообщает, что в записи есть координаты
+func (r *NavRecord) HasPosition() bool {
+	return r.Valid || r.Latitude != 0 || r.Longitude != 0
 }
 
-type LiquidSensor struct {
-	FlagLiqNum uint8     `json:"fl_ln"`
-	Value      [8]uint32 `json:"val_l"`
+// RecordKind - тип навигационной записи, по нему выбирается топик NATS
+type RecordKind int
+
+const (
+	RecordPosition RecordKind = iota // запись с координатами
+	RecordSensors                    // только показания датчиков, без координат
+	RecordAlarm                      // тревога (тревожная кнопка, экстренный вызов и т.п.)
+	RecordEvent                      // событие устройства
+)
+
+func (k RecordKind) String() string {
+	switch k {
+	case RecordPosition:
+		return "positions"
+	case RecordSensors:
+		return "sensors"
+	case RecordAlarm:
+		return "alarms"
+	case RecordEvent:
+		return "events"
+	default:
+		return "RecordKind(" + strconv.Itoa(int(k)) + ")"
+	}
 }
 
-type Sensor struct {
-	SensorNumber uint8  `json:"sn_n"`
-	Value        uint32 `json:"val"`
+// Kind определяет тип записи. Тревога важнее события, событие - координат:
+// запись по нажатию тревожной кнопки уходит в топик тревог, даже если в ней есть координаты.
+func (r *NavRecord) Kind() RecordKind {
+	switch {
+	case r.Alarm:
+		return RecordAlarm
+	case r.Event != "":
+		return RecordEvent
+	case r.HasPosition():
+		return RecordPosition
+	default:
+		return RecordSensors
+	}
 }
 
-type NavRecords struct {
-	PacketType int16       `json:"packet_type"`
-	PacketID   uint32      `json:"packet_id"`
-	RecNav     []NavRecord `json:"record"`
+func (r *NavRecord) ToBytes() ([]byte, error) {
+	return json.Marshal(r)
 }
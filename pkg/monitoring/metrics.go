@@ -24,6 +24,9 @@ type ServiceMetrics struct {
 
 	// ServiceGauges корректен как простой Gauge.
 	ServiceGauges map[string]prometheus.Gauge
+
+	// labels - общие метки метрик сервиса, нужны для RegisterGauge
+	labels prometheus.Labels
 }
 
 // NewServiceMetrics - это конструктор для ServiceMetrics.
@@ -37,6 +40,7 @@ func NewServiceMetrics(serviceName string) *ServiceMetrics {
 		OperationDurations: make(map[string]*prometheus.HistogramVec),
 		ErrorCounters:      make(map[string]*prometheus.CounterVec),
 		ServiceGauges:      make(map[string]prometheus.Gauge),
+		labels:             commonLabels,
 	}
 
 	// --- Предопределенные метрики ---
@@ -137,3 +141,30 @@ func (m *ServiceMetrics) SetGauge(gaugeName string, value float64) {
 		gauge.Set(value)
 	}
 }
+
+// AddOperations увеличивает счетчик операций на n, например на число записей в пачке.
+func (m *ServiceMetrics) AddOperations(operationName string, n int) {
+	if counterVec, ok := m.OperationCounters["operations_total"]; ok {
+		counterVec.WithLabelValues(operationName).Add(float64(n))
+	}
+}
+
+// AddErrors увеличивает счетчик ошибок типа errorType на n.
+func (m *ServiceMetrics) AddErrors(errorType string, n int) {
+	if counterVec, ok := m.ErrorCounters["errors_total"]; ok {
+		counterVec.WithLabelValues(errorType).Add(float64(n))
+	}
+}
+
+// RegisterGauge создает gauge-метрику app_<gaugeName>, после чего ее можно менять через SetGauge.
+// Повторная регистрация того же имени ничего не делает.
+func (m *ServiceMetrics) RegisterGauge(gaugeName, help string) {
+	if _, ok := m.ServiceGauges[gaugeName]; ok {
+		return
+	}
+	m.ServiceGauges[gaugeName] = promauto.NewGauge(prometheus.GaugeOpts{
+		Name:        "app_" + gaugeName,
+		Help:        help,
+		ConstLabels: m.labels,
+	})
+}
@@ -0,0 +1,64 @@
+-- Схема хранения навигационных данных.
+-- positions и sensor_values секционированы по времени навигации (одна секция на сутки UTC),
+-- секции создает postgres.EnsurePartitions. Записи вне созданных секций попадают в *_default.
+
+-- Устройства, от которых приходили данные
+CREATE TABLE devices (
+    id            BIGSERIAL PRIMARY KEY,
+    device_id     TEXT        NOT NULL UNIQUE,   -- IMEI или числовой ID устройства (NavRecord.DeviceID)
+    client        BIGINT      NOT NULL DEFAULT 0,
+    imei          TEXT        NOT NULL DEFAULT '',
+    imsi          TEXT        NOT NULL DEFAULT '',
+    protocol      TEXT        NOT NULL DEFAULT '',
+    port_id       TEXT        NOT NULL DEFAULT '',
+    first_seen    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_seen     TIMESTAMPTZ NOT NULL DEFAULT now(), -- время приема последней записи
+    last_nav_time TIMESTAMPTZ                         -- время навигации последней записи
+);
+
+-- Навигационные записи, одна строка на NavRecord
+CREATE TABLE positions (
+    device_id       BIGINT           NOT NULL REFERENCES devices (id),
+    nav_time        TIMESTAMPTZ      NOT NULL,
+    packet_id       BIGINT           NOT NULL,
+    received_time   TIMESTAMPTZ      NOT NULL,
+    kind            TEXT             NOT NULL, -- positions | sensors | alarms | events
+    latitude        DOUBLE PRECISION,          -- NULL, если в записи нет координат
+    longitude       DOUBLE PRECISION,
+    altitude        DOUBLE PRECISION,          -- NULL, если высота не передана
+    speed           DOUBLE PRECISION,
+    course          SMALLINT,
+    satellites      SMALLINT,
+    pdop            DOUBLE PRECISION,
+    hdop            DOUBLE PRECISION,
+    vdop            DOUBLE PRECISION,
+    nav_system      INTEGER,
+    odometer        DOUBLE PRECISION,
+    valid           BOOLEAN          NOT NULL DEFAULT FALSE,
+    moving          BOOLEAN          NOT NULL DEFAULT FALSE,
+    black_box       BOOLEAN          NOT NULL DEFAULT FALSE,
+    alarm           BOOLEAN          NOT NULL DEFAULT FALSE,
+    event           TEXT             NOT NULL DEFAULT '',
+    digital_inputs  BIGINT           NOT NULL DEFAULT 0,
+    digital_outputs BIGINT           NOT NULL DEFAULT 0,
+    params          JSONB,
+    -- повторная доставка из JetStream не создает дубликатов
+    PRIMARY KEY (device_id, nav_time, packet_id)
+) PARTITION BY RANGE (nav_time);
+
+CREATE TABLE positions_default PARTITION OF positions DEFAULT;
+
+-- Показания датчиков из NavRecord.Sensors, одна строка на датчик
+CREATE TABLE sensor_values (
+    device_id BIGINT           NOT NULL REFERENCES devices (id),
+    nav_time  TIMESTAMPTZ      NOT NULL,
+    packet_id BIGINT           NOT NULL,
+    name      TEXT             NOT NULL, -- ain1, din9, lls1, io66, ...
+    value     DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (device_id, nav_time, packet_id, name)
+) PARTITION BY RANGE (nav_time);
+
+CREATE TABLE sensor_values_default PARTITION OF sensor_values DEFAULT;
+
+CREATE INDEX positions_nav_time_idx ON positions (nav_time);
+CREATE INDEX sensor_values_name_idx ON sensor_values (device_id, name, nav_time);
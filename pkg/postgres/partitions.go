@@ -0,0 +1,44 @@
+// NavControlSystem/pkg/postgres/partitions.go
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PartitionedTables - таблицы, секционированные по суткам nav_time
+var PartitionedTables = []string{"positions", "sensor_values"}
+
+// PartitionDay возвращает начало суток UTC, в секцию которых попадает момент t
+func PartitionDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// PartitionName возвращает имя суточной секции таблицы, например positions_20240115
+func PartitionName(table string, day time.Time) string {
+	return table + "_" + PartitionDay(day).Format("20060102")
+}
+
+// partitionDDL возвращает запрос, создающий суточную секцию таблицы, если ее еще нет
+func partitionDDL(table string, day time.Time) string {
+	from := PartitionDay(day)
+	to := from.AddDate(0, 0, 1)
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM ('%s') TO ('%s')",
+		PartitionName(table, from), table, from.Format(time.RFC3339), to.Format(time.RFC3339))
+}
+
+// EnsurePartitions создает секции всех секционированных таблиц на сутки day.
+// Секцию нужно создать до вставки строк за эти сутки: иначе строки попадут в секцию
+// по умолчанию, и создать секцию позже не получится.
+func EnsurePartitions(ctx context.Context, pool *pgxpool.Pool, day time.Time) error {
+	for _, table := range PartitionedTables {
+		if _, err := pool.Exec(ctx, partitionDDL(table, day)); err != nil {
+			return fmt.Errorf("postgres: failed to create partition %s: %w", PartitionName(table, day), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations(migrationsFS, "migrations")
+	if assert.NoError(t, err) && assert.NotEmpty(t, migrations) {
+		assert.Equal(t, 1, migrations[0].Version)
+		assert.Contains(t, migrations[0].SQL, "PARTITION BY RANGE (nav_time)")
+	}
+
+	fsys := fstest.MapFS{
+		"m/0010_b.sql":  {Data: []byte("SELECT 10")},
+		"m/0002_a.sql":  {Data: []byte("SELECT 2")},
+		"m/readme.md":   {Data: []byte("# не миграция")},
+		"bad/init.sql":  {Data: []byte("SELECT 1")},
+		"dup/01_a.sql":  {Data: []byte("SELECT 1")},
+		"dup/001_b.sql": {Data: []byte("SELECT 1")},
+	}
+	migrations, err = loadMigrations(fsys, "m")
+	if assert.NoError(t, err) && assert.Len(t, migrations, 2) {
+		assert.Equal(t, "0002_a.sql", migrations[0].Name)
+		assert.Equal(t, 10, migrations[1].Version)
+	}
+
+	_, err = loadMigrations(fsys, "bad")
+	assert.Error(t, err)
+	_, err = loadMigrations(fsys, "dup")
+	assert.Error(t, err)
+}
+
+func TestPartitions(t *testing.T) {
+	// 23:30 по Москве - еще 20:30 UTC того же дня
+	msk := time.FixedZone("MSK", 3*3600)
+	ts := time.Date(2024, 1, 15, 23, 30, 0, 0, msk)
+
+	assert.Equal(t, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), PartitionDay(ts))
+	assert.Equal(t, "positions_20240115", PartitionName("positions", ts))
+	assert.Equal(t,
+		"CREATE TABLE IF NOT EXISTS sensor_values_20240115 PARTITION OF sensor_values FOR VALUES FROM ('2024-01-15T00:00:00Z') TO ('2024-01-16T00:00:00Z')",
+		partitionDDL("sensor_values", ts))
+}
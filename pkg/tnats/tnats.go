@@ -1,7 +1,6 @@
 // NavControlSystem/pkg/nats/tnats.go
 package tnats
 
-/*
 import (
 	"errors"
 	"fmt"
@@ -14,72 +13,174 @@ import (
 
 // Common errors
 var (
-	ErrNotConnected = errors.New("nats: not connected")
+	ErrNotConnected      = errors.New("nats: not connected")
+	ErrJetStreamDisabled = errors.New("nats: jetstream is disabled")
 )
 
+// State - состояние соединения, о котором сообщает StateHandler
+type State int
+
+const (
+	StateConnected    State = iota // первое подключение (в т.ч. отложенное, см. RetryOnFailedConnect)
+	StateDisconnected              // соединение потеряно, клиент пытается переподключиться
+	StateReconnected               // соединение восстановлено
+	StateClosed                    // соединение закрыто окончательно (Close или исчерпаны попытки)
+)
+
+func (s State) String() string {
+	switch s {
+	case StateConnected:
+		return "connected"
+	case StateDisconnected:
+		return "disconnected"
+	case StateReconnected:
+		return "reconnected"
+	case StateClosed:
+		return "closed"
+	default:
+		return fmt.Sprintf("state(%d)", int(s))
+	}
+}
+
+// StateHandler вызывается при каждом изменении состояния соединения.
+// err заполнен для StateDisconnected и StateClosed, если причина известна.
+type StateHandler func(state State, err error)
+
+// Config - параметры подключения к NATS
+type Config struct {
+	URL  string
+	Name string // имя клиента, видно в мониторинге NATS
+
+	// Политика переподключения
+	MaxReconnects   int           // число попыток; -1 - без ограничения
+	ReconnectWait   time.Duration // пауза между попытками
+	ReconnectJitter time.Duration // случайная добавка к паузе, чтобы клиенты не переподключались разом
+	ConnectTimeout  time.Duration
+
+	// RetryOnFailedConnect - если сервер недоступен при старте, NewClient не возвращает ошибку,
+	// а продолжает подключаться в фоне (о подключении сообщит StateConnected)
+	RetryOnFailedConnect bool
+
+	// JetStream - создать контекст JetStream
+	JetStream bool
+	// AsyncMaxPending - сколько асинхронных публикаций JetStream может ждать подтверждения одновременно
+	AsyncMaxPending int
+
+	OnStateChange StateHandler
+}
+
+// DefaultConfig возвращает конфигурацию с бесконечным переподключением раз в 2 секунды
+func DefaultConfig(url string) Config {
+	return Config{
+		URL:             url,
+		MaxReconnects:   -1,
+		ReconnectWait:   2 * time.Second,
+		ReconnectJitter: 500 * time.Millisecond,
+		ConnectTimeout:  5 * time.Second,
+		JetStream:       true,
+		AsyncMaxPending: 4000,
+	}
+}
+
 // Client - это наша обертка для NATS-соединения.
-// Он управляет жизненным циклом подключения и предоставляет методы для создания Publisher/Subscriber.
+// Он управляет жизненным циклом подключения и предоставляет методы для публикации,
+// подписки и настройки JetStream.
 type Client struct {
 	conn   *nats.Conn
 	js     nats.JetStreamContext // Для продвинутых возможностей (streams, consumers)
-	logger *logrus.Logger
+	logger logrus.FieldLogger
 	mu     sync.RWMutex
 	closed bool
 }
 
 // Publisher - это интерфейс для публикации сообщений.
-// type Publisher interface {
-// 	Publish(subject string, data []byte) error
-// 	Close() error
-// }
+type Publisher interface {
+	Publish(subject string, data []byte) error
+	PublishMsg(msg *nats.Msg) error
+	Close() error
+}
 
 // Subscriber - это интерфейс для подписки на сообщения.
 type Subscriber interface {
 	Subscribe(subject string, handler nats.MsgHandler) (*nats.Subscription, error)
+	QueueSubscribe(subject, queue string, handler nats.MsgHandler) (*nats.Subscription, error)
 	Close() error
 }
 
 // NewClient создает новый NATS клиент.
 // Он пытается установить соединение при создании.
-func NewClient(url string, logger *logrus.Logger) (*Client, error) {
+func NewClient(cfg Config, logger logrus.FieldLogger) (*Client, error) {
 	if logger == nil {
-		logger = logrus.New()
-		logger.Warn("No logger provided to NATS client, using default")
+		l := logrus.New()
+		l.Warn("No logger provided to NATS client, using default")
+		logger = l
+	}
+
+	c := &Client{logger: logger}
+
+	notify := func(state State, err error) {
+		if cfg.OnStateChange != nil {
+			cfg.OnStateChange(state, err)
+		}
 	}
 
 	opts := []nats.Option{
-		nats.ReconnectWait(2 * time.Second),
-		nats.MaxReconnects(5),
+		nats.MaxReconnects(cfg.MaxReconnects),
+		nats.ReconnectWait(cfg.ReconnectWait),
+		nats.RetryOnFailedConnect(cfg.RetryOnFailedConnect),
+		nats.ConnectHandler(func(nc *nats.Conn) {
+			logger.WithField("url", nc.ConnectedUrl()).Info("NATS connection established")
+			notify(StateConnected, nil)
+		}),
 		nats.DisconnectErrHandler(func(nc *nats.Conn, err error) {
 			logger.WithError(err).Warn("NATS connection disconnected")
+			notify(StateDisconnected, err)
 		}),
 		nats.ReconnectHandler(func(nc *nats.Conn) {
-			logger.Info("NATS connection reconnected")
+			logger.WithField("url", nc.ConnectedUrl()).Info("NATS connection reconnected")
+			notify(StateReconnected, nil)
 		}),
 		nats.ClosedHandler(func(nc *nats.Conn) {
-			logger.Info("NATS connection closed")
+			logger.WithError(nc.LastError()).Info("NATS connection closed")
+			notify(StateClosed, nc.LastError())
 		}),
 	}
+	if cfg.Name != "" {
+		opts = append(opts, nats.Name(cfg.Name))
+	}
+	if cfg.ReconnectJitter > 0 {
+		opts = append(opts, nats.ReconnectJitter(cfg.ReconnectJitter, cfg.ReconnectJitter))
+	}
+	if cfg.ConnectTimeout > 0 {
+		opts = append(opts, nats.Timeout(cfg.ConnectTimeout))
+	}
 
-	nc, err := nats.Connect(url, opts...)
+	nc, err := nats.Connect(cfg.URL, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
 	}
+	c.conn = nc
 
-	// Включаем JetStream для будущих нужд (например, для гарантированной доставки)
-	js, err := nc.JetStream()
-	if err != nil {
-		// Если JetStream не доступен, можно продолжить без него, но лучше предупредить
-		logger.WithError(err).Warn("JetStream is not available, continuing without it")
+	if cfg.JetStream {
+		// Контекст создается без обращения к серверу, поэтому работает и при отложенном подключении
+		var jsOpts []nats.JSOpt
+		if cfg.AsyncMaxPending > 0 {
+			jsOpts = append(jsOpts, nats.PublishAsyncMaxPending(cfg.AsyncMaxPending))
+		}
+		c.js, err = nc.JetStream(jsOpts...)
+		if err != nil {
+			// Если JetStream не доступен, можно продолжить без него, но лучше предупредить
+			logger.WithError(err).Warn("JetStream is not available, continuing without it")
+		}
 	}
 
-	logger.Info("Successfully connected to NATS")
+	if nc.IsConnected() {
+		logger.Info("Successfully connected to NATS")
+	} else {
+		logger.WithField("url", cfg.URL).Warn("NATS is not available yet, connecting in background")
+	}
 
-	return &Client{
-		conn:   nc,
-		js:     js,
-		logger: logger,
-	}, nil
+	return c, nil
 }
 
 // Close закрывает соединение с NATS.
@@ -106,6 +207,19 @@ func (c *Client) IsConnected() bool {
 	return c.conn != nil && c.conn.IsConnected() && !c.closed
 }
 
+// Conn возвращает исходное соединение для возможностей, которых нет в обертке.
+func (c *Client) Conn() *nats.Conn {
+	return c.conn
+}
+
+// JetStream возвращает контекст JetStream.
+func (c *Client) JetStream() (nats.JetStreamContext, error) {
+	if c.js == nil {
+		return nil, ErrJetStreamDisabled
+	}
+	return c.js, nil
+}
+
 // NewPublisher создает новый Publisher.
 // В данном случае, сам Client реализует интерфейс Publisher для простоты.
 func (c *Client) NewPublisher() (Publisher, error) {
@@ -119,23 +233,86 @@ func (c *Client) NewPublisher() (Publisher, error) {
 
 // Publish публикует сообщение в указанный топик.
 func (c *Client) Publish(subject string, data []byte) error {
+	return c.PublishMsg(&nats.Msg{Subject: subject, Data: data})
+}
+
+// PublishMsg публикует сообщение вместе с заголовками.
+func (c *Client) PublishMsg(msg *nats.Msg) error {
 	if !c.IsConnected() {
 		return ErrNotConnected
 	}
 
-	err := c.conn.Publish(subject, data)
+	err := c.conn.PublishMsg(msg)
 	if err != nil {
 		c.logger.WithFields(logrus.Fields{
-			"subject": subject,
+			"subject": msg.Subject,
 			"error":   err,
 		}).Error("Failed to publish message")
 		return fmt.Errorf("nats publish failed: %w", err)
 	}
 
-	c.logger.WithField("subject", subject).Debug("Message published successfully")
+	c.logger.WithField("subject", msg.Subject).Debug("Message published successfully")
 	return nil
 }
 
+// JSPublishMsg публикует сообщение в JetStream и ждет подтверждения (PubAck) от сервера.
+func (c *Client) JSPublishMsg(msg *nats.Msg, opts ...nats.PubOpt) (*nats.PubAck, error) {
+	if c.js == nil {
+		return nil, ErrJetStreamDisabled
+	}
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	ack, err := c.js.PublishMsg(msg, opts...)
+	if err != nil {
+		c.logger.WithFields(logrus.Fields{
+			"subject": msg.Subject,
+			"error":   err,
+		}).Error("Failed to publish message to JetStream")
+		return nil, fmt.Errorf("jetstream publish failed: %w", err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"subject": msg.Subject,
+		"stream":  ack.Stream,
+		"seq":     ack.Sequence,
+	}).Debug("Message published to JetStream")
+	return ack, nil
+}
+
+// JSPublishMsgAsync публикует сообщение в JetStream, не дожидаясь подтверждения.
+// Результат (PubAck или ошибка) приходит через возвращаемый PubAckFuture.
+func (c *Client) JSPublishMsgAsync(msg *nats.Msg, opts ...nats.PubOpt) (nats.PubAckFuture, error) {
+	if c.js == nil {
+		return nil, ErrJetStreamDisabled
+	}
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+
+	future, err := c.js.PublishMsgAsync(msg, opts...)
+	if err != nil {
+		c.logger.WithFields(logrus.Fields{
+			"subject": msg.Subject,
+			"error":   err,
+		}).Error("Failed to publish message to JetStream asynchronously")
+		return nil, fmt.Errorf("jetstream async publish failed: %w", err)
+	}
+	return future, nil
+}
+
+// JSPublishAsyncComplete возвращает канал, который закрывается, когда у всех асинхронных
+// публикаций JetStream есть результат (PubAck или ошибка).
+func (c *Client) JSPublishAsyncComplete() <-chan struct{} {
+	if c.js == nil {
+		done := make(chan struct{})
+		close(done)
+		return done
+	}
+	return c.js.PublishAsyncComplete()
+}
+
 // NewSubscriber создает новый Subscriber.
 // Аналогично Publisher, сам Client реализует этот интерфейс.
 func (c *Client) NewSubscriber() (Subscriber, error) {
@@ -189,4 +366,67 @@ func (c *Client) QueueSubscribe(subject, queue string, handler nats.MsgHandler)
 	}).Info("Successfully queue subscribed to subject")
 	return sub, nil
 }
-*/
+
+// --- JetStream provisioning ---
+
+// EnsureStream создает поток cfg или обновляет его конфигурацию, если он уже существует.
+func (c *Client) EnsureStream(cfg *nats.StreamConfig) (*nats.StreamInfo, error) {
+	if c.js == nil {
+		return nil, ErrJetStreamDisabled
+	}
+
+	info, err := c.js.StreamInfo(cfg.Name)
+	switch {
+	case errors.Is(err, nats.ErrStreamNotFound):
+		info, err = c.js.AddStream(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stream %s: %w", cfg.Name, err)
+		}
+		c.logger.WithFields(logrus.Fields{
+			"stream":   cfg.Name,
+			"subjects": cfg.Subjects,
+		}).Info("JetStream stream created")
+		return info, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to get stream %s info: %w", cfg.Name, err)
+	}
+
+	info, err = c.js.UpdateStream(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update stream %s: %w", cfg.Name, err)
+	}
+	c.logger.WithField("stream", cfg.Name).Debug("JetStream stream is up to date")
+	return info, nil
+}
+
+// EnsureConsumer создает durable-потребителя потока stream или обновляет его конфигурацию.
+func (c *Client) EnsureConsumer(stream string, cfg *nats.ConsumerConfig) (*nats.ConsumerInfo, error) {
+	if c.js == nil {
+		return nil, ErrJetStreamDisabled
+	}
+	if cfg.Durable == "" {
+		return nil, fmt.Errorf("consumer of stream %s must be durable", stream)
+	}
+
+	_, err := c.js.ConsumerInfo(stream, cfg.Durable)
+	switch {
+	case errors.Is(err, nats.ErrConsumerNotFound):
+		info, err := c.js.AddConsumer(stream, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create consumer %s on stream %s: %w", cfg.Durable, stream, err)
+		}
+		c.logger.WithFields(logrus.Fields{
+			"stream":   stream,
+			"consumer": cfg.Durable,
+		}).Info("JetStream consumer created")
+		return info, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to get consumer %s info: %w", cfg.Durable, err)
+	}
+
+	info, err := c.js.UpdateConsumer(stream, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update consumer %s on stream %s: %w", cfg.Durable, stream, err)
+	}
+	return info, nil
+}
@@ -0,0 +1,38 @@
+package tnats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestState_String(t *testing.T) {
+	assert.Equal(t, "connected", StateConnected.String())
+	assert.Equal(t, "disconnected", StateDisconnected.String())
+	assert.Equal(t, "reconnected", StateReconnected.String())
+	assert.Equal(t, "closed", StateClosed.String())
+}
+
+func TestNewClient_RetryOnFailedConnect(t *testing.T) {
+	cfg := DefaultConfig("nats://127.0.0.1:1")
+	cfg.ReconnectWait = 10 * time.Millisecond
+	cfg.ConnectTimeout = 100 * time.Millisecond
+
+	// без RetryOnFailedConnect недоступный сервер - ошибка
+	_, err := NewClient(cfg, nil)
+	assert.Error(t, err)
+
+	// с RetryOnFailedConnect клиент создается и подключается в фоне
+	cfg.RetryOnFailedConnect = true
+	c, err := NewClient(cfg, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer c.Close()
+
+	assert.False(t, c.IsConnected())
+	assert.ErrorIs(t, c.Publish("test", []byte("data")), ErrNotConnected)
+	_, err = c.JetStream()
+	assert.NoError(t, err)
+}
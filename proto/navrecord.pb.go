@@ -0,0 +1,402 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        v3.12.4
+// source: navrecord.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Навигационная запись, которую RECEIVER публикует в NATS при encoding = "protobuf".
+// Сообщение публикуется с заголовком Content-Type: application/x-protobuf.
+// Поля повторяют models.NavRecord (pkg/models): значения в физических единицах,
+// отсутствующие значения не передаются (optional) вместо нулей.
+type NavRecord struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// --- Устройство и источник записи ---
+	Client   uint32 `protobuf:"varint,1,opt,name=client,proto3" json:"client,omitempty"` // числовой ID устройства (EGTS OID, ID Arnavi и т.п.)
+	Imei     string `protobuf:"bytes,2,opt,name=imei,proto3" json:"imei,omitempty"`
+	Imsi     string `protobuf:"bytes,3,opt,name=imsi,proto3" json:"imsi,omitempty"`
+	Protocol string `protobuf:"bytes,4,opt,name=protocol,proto3" json:"protocol,omitempty"`                  // протокол порта (EGTS, ARNAVI, ...)
+	PortId   string `protobuf:"bytes,5,opt,name=port_id,json=portId,proto3" json:"port_id,omitempty"`        // ID порта из конфигурации RECEIVER
+	PacketId uint32 `protobuf:"varint,6,opt,name=packet_id,json=packetId,proto3" json:"packet_id,omitempty"` // номер пакета протокола
+	// --- Время, миллисекунды Unix UTC ---
+	NavigationTime int64 `protobuf:"varint,7,opt,name=navigation_time,json=navigationTime,proto3" json:"navigation_time,omitempty"`
+	ReceivedTime   int64 `protobuf:"varint,8,opt,name=received_time,json=receivedTime,proto3" json:"received_time,omitempty"`
+	// --- Координаты, передаются только при их наличии ---
+	Latitude   *float64 `protobuf:"fixed64,9,opt,name=latitude,proto3,oneof" json:"latitude,omitempty"`    // градусы, южная широта отрицательная
+	Longitude  *float64 `protobuf:"fixed64,10,opt,name=longitude,proto3,oneof" json:"longitude,omitempty"` // градусы, западная долгота отрицательная
+	Altitude   *float64 `protobuf:"fixed64,11,opt,name=altitude,proto3,oneof" json:"altitude,omitempty"`   // высота над уровнем моря, м
+	Speed      *float64 `protobuf:"fixed64,12,opt,name=speed,proto3,oneof" json:"speed,omitempty"`         // скорость, км/ч
+	Course     *uint32  `protobuf:"varint,13,opt,name=course,proto3,oneof" json:"course,omitempty"`        // направление, градусы 0..359
+	Satellites *uint32  `protobuf:"varint,14,opt,name=satellites,proto3,oneof" json:"satellites,omitempty"`
+	Pdop       *float64 `protobuf:"fixed64,15,opt,name=pdop,proto3,oneof" json:"pdop,omitempty"`
+	Hdop       *float64 `protobuf:"fixed64,16,opt,name=hdop,proto3,oneof" json:"hdop,omitempty"`
+	Vdop       *float64 `protobuf:"fixed64,17,opt,name=vdop,proto3,oneof" json:"vdop,omitempty"`
+	NavSystem  *uint32  `protobuf:"varint,18,opt,name=nav_system,json=navSystem,proto3,oneof" json:"nav_system,omitempty"` // битовые флаги навигационных систем (EGTS NS)
+	Odometer   *float64 `protobuf:"fixed64,19,opt,name=odometer,proto3,oneof" json:"odometer,omitempty"`                   // пробег, км
+	// --- Признаки ---
+	Valid    bool   `protobuf:"varint,20,opt,name=valid,proto3" json:"valid,omitempty"`                       // координаты валидны
+	Moving   bool   `protobuf:"varint,21,opt,name=moving,proto3" json:"moving,omitempty"`                     // признак движения
+	BlackBox bool   `protobuf:"varint,22,opt,name=black_box,json=blackBox,proto3" json:"black_box,omitempty"` // данные из памяти ("черный ящик")
+	Alarm    bool   `protobuf:"varint,23,opt,name=alarm,proto3" json:"alarm,omitempty"`                       // запись отправлена по тревоге
+	Event    string `protobuf:"bytes,24,opt,name=event,proto3" json:"event,omitempty"`                        // событие, по которому отправлена запись
+	// --- Датчики ---
+	DigitalInputs  uint32             `protobuf:"varint,25,opt,name=digital_inputs,json=digitalInputs,proto3" json:"digital_inputs,omitempty"`                                           // основные дискретные входы, битовая маска
+	DigitalOutputs uint32             `protobuf:"varint,26,opt,name=digital_outputs,json=digitalOutputs,proto3" json:"digital_outputs,omitempty"`                                        // дискретные выходы, битовая маска
+	Sensors        map[string]float64 `protobuf:"bytes,27,rep,name=sensors,proto3" json:"sensors,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"` // показания датчиков по имени (ain1, din9, lls1, io66, ...)
+	Params         map[string]string  `protobuf:"bytes,28,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`     // произвольные строковые параметры
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *NavRecord) Reset() {
+	*x = NavRecord{}
+	mi := &file_navrecord_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NavRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NavRecord) ProtoMessage() {}
+
+func (x *NavRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_navrecord_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NavRecord.ProtoReflect.Descriptor instead.
+func (*NavRecord) Descriptor() ([]byte, []int) {
+	return file_navrecord_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *NavRecord) GetClient() uint32 {
+	if x != nil {
+		return x.Client
+	}
+	return 0
+}
+
+func (x *NavRecord) GetImei() string {
+	if x != nil {
+		return x.Imei
+	}
+	return ""
+}
+
+func (x *NavRecord) GetImsi() string {
+	if x != nil {
+		return x.Imsi
+	}
+	return ""
+}
+
+func (x *NavRecord) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *NavRecord) GetPortId() string {
+	if x != nil {
+		return x.PortId
+	}
+	return ""
+}
+
+func (x *NavRecord) GetPacketId() uint32 {
+	if x != nil {
+		return x.PacketId
+	}
+	return 0
+}
+
+func (x *NavRecord) GetNavigationTime() int64 {
+	if x != nil {
+		return x.NavigationTime
+	}
+	return 0
+}
+
+func (x *NavRecord) GetReceivedTime() int64 {
+	if x != nil {
+		return x.ReceivedTime
+	}
+	return 0
+}
+
+func (x *NavRecord) GetLatitude() float64 {
+	if x != nil && x.Latitude != nil {
+		return *x.Latitude
+	}
+	return 0
+}
+
+func (x *NavRecord) GetLongitude() float64 {
+	if x != nil && x.Longitude != nil {
+		return *x.Longitude
+	}
+	return 0
+}
+
+func (x *NavRecord) GetAltitude() float64 {
+	if x != nil && x.Altitude != nil {
+		return *x.Altitude
+	}
+	return 0
+}
+
+func (x *NavRecord) GetSpeed() float64 {
+	if x != nil && x.Speed != nil {
+		return *x.Speed
+	}
+	return 0
+}
+
+func (x *NavRecord) GetCourse() uint32 {
+	if x != nil && x.Course != nil {
+		return *x.Course
+	}
+	return 0
+}
+
+func (x *NavRecord) GetSatellites() uint32 {
+	if x != nil && x.Satellites != nil {
+		return *x.Satellites
+	}
+	return 0
+}
+
+func (x *NavRecord) GetPdop() float64 {
+	if x != nil && x.Pdop != nil {
+		return *x.Pdop
+	}
+	return 0
+}
+
+func (x *NavRecord) GetHdop() float64 {
+	if x != nil && x.Hdop != nil {
+		return *x.Hdop
+	}
+	return 0
+}
+
+func (x *NavRecord) GetVdop() float64 {
+	if x != nil && x.Vdop != nil {
+		return *x.Vdop
+	}
+	return 0
+}
+
+func (x *NavRecord) GetNavSystem() uint32 {
+	if x != nil && x.NavSystem != nil {
+		return *x.NavSystem
+	}
+	return 0
+}
+
+func (x *NavRecord) GetOdometer() float64 {
+	if x != nil && x.Odometer != nil {
+		return *x.Odometer
+	}
+	return 0
+}
+
+func (x *NavRecord) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *NavRecord) GetMoving() bool {
+	if x != nil {
+		return x.Moving
+	}
+	return false
+}
+
+func (x *NavRecord) GetBlackBox() bool {
+	if x != nil {
+		return x.BlackBox
+	}
+	return false
+}
+
+func (x *NavRecord) GetAlarm() bool {
+	if x != nil {
+		return x.Alarm
+	}
+	return false
+}
+
+func (x *NavRecord) GetEvent() string {
+	if x != nil {
+		return x.Event
+	}
+	return ""
+}
+
+func (x *NavRecord) GetDigitalInputs() uint32 {
+	if x != nil {
+		return x.DigitalInputs
+	}
+	return 0
+}
+
+func (x *NavRecord) GetDigitalOutputs() uint32 {
+	if x != nil {
+		return x.DigitalOutputs
+	}
+	return 0
+}
+
+func (x *NavRecord) GetSensors() map[string]float64 {
+	if x != nil {
+		return x.Sensors
+	}
+	return nil
+}
+
+func (x *NavRecord) GetParams() map[string]string {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+var File_navrecord_proto protoreflect.FileDescriptor
+
+const file_navrecord_proto_rawDesc = "" +
+	"\n" +
+	"\x0fnavrecord.proto\x12\x05proto\"\xed\b\n" +
+	"\tNavRecord\x12\x16\n" +
+	"\x06client\x18\x01 \x01(\rR\x06client\x12\x12\n" +
+	"\x04imei\x18\x02 \x01(\tR\x04imei\x12\x12\n" +
+	"\x04imsi\x18\x03 \x01(\tR\x04imsi\x12\x1a\n" +
+	"\bprotocol\x18\x04 \x01(\tR\bprotocol\x12\x17\n" +
+	"\aport_id\x18\x05 \x01(\tR\x06portId\x12\x1b\n" +
+	"\tpacket_id\x18\x06 \x01(\rR\bpacketId\x12'\n" +
+	"\x0fnavigation_time\x18\a \x01(\x03R\x0enavigationTime\x12#\n" +
+	"\rreceived_time\x18\b \x01(\x03R\freceivedTime\x12\x1f\n" +
+	"\blatitude\x18\t \x01(\x01H\x00R\blatitude\x88\x01\x01\x12!\n" +
+	"\tlongitude\x18\n" +
+	" \x01(\x01H\x01R\tlongitude\x88\x01\x01\x12\x1f\n" +
+	"\baltitude\x18\v \x01(\x01H\x02R\baltitude\x88\x01\x01\x12\x19\n" +
+	"\x05speed\x18\f \x01(\x01H\x03R\x05speed\x88\x01\x01\x12\x1b\n" +
+	"\x06course\x18\r \x01(\rH\x04R\x06course\x88\x01\x01\x12#\n" +
+	"\n" +
+	"satellites\x18\x0e \x01(\rH\x05R\n" +
+	"satellites\x88\x01\x01\x12\x17\n" +
+	"\x04pdop\x18\x0f \x01(\x01H\x06R\x04pdop\x88\x01\x01\x12\x17\n" +
+	"\x04hdop\x18\x10 \x01(\x01H\aR\x04hdop\x88\x01\x01\x12\x17\n" +
+	"\x04vdop\x18\x11 \x01(\x01H\bR\x04vdop\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"nav_system\x18\x12 \x01(\rH\tR\tnavSystem\x88\x01\x01\x12\x1f\n" +
+	"\bodometer\x18\x13 \x01(\x01H\n" +
+	"R\bodometer\x88\x01\x01\x12\x14\n" +
+	"\x05valid\x18\x14 \x01(\bR\x05valid\x12\x16\n" +
+	"\x06moving\x18\x15 \x01(\bR\x06moving\x12\x1b\n" +
+	"\tblack_box\x18\x16 \x01(\bR\bblackBox\x12\x14\n" +
+	"\x05alarm\x18\x17 \x01(\bR\x05alarm\x12\x14\n" +
+	"\x05event\x18\x18 \x01(\tR\x05event\x12%\n" +
+	"\x0edigital_inputs\x18\x19 \x01(\rR\rdigitalInputs\x12'\n" +
+	"\x0fdigital_outputs\x18\x1a \x01(\rR\x0edigitalOutputs\x127\n" +
+	"\asensors\x18\x1b \x03(\v2\x1d.proto.NavRecord.SensorsEntryR\asensors\x124\n" +
+	"\x06params\x18\x1c \x03(\v2\x1c.proto.NavRecord.ParamsEntryR\x06params\x1a:\n" +
+	"\fSensorsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\x1a9\n" +
+	"\vParamsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\v\n" +
+	"\t_latitudeB\f\n" +
+	"\n" +
+	"_longitudeB\v\n" +
+	"\t_altitudeB\b\n" +
+	"\x06_speedB\t\n" +
+	"\a_courseB\r\n" +
+	"\v_satellitesB\a\n" +
+	"\x05_pdopB\a\n" +
+	"\x05_hdopB\a\n" +
+	"\x05_vdopB\r\n" +
+	"\v_nav_systemB\v\n" +
+	"\t_odometerB\x18Z\x16NavControlSystem/protob\x06proto3"
+
+var (
+	file_navrecord_proto_rawDescOnce sync.Once
+	file_navrecord_proto_rawDescData []byte
+)
+
+func file_navrecord_proto_rawDescGZIP() []byte {
+	file_navrecord_proto_rawDescOnce.Do(func() {
+		file_navrecord_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_navrecord_proto_rawDesc), len(file_navrecord_proto_rawDesc)))
+	})
+	return file_navrecord_proto_rawDescData
+}
+
+var file_navrecord_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_navrecord_proto_goTypes = []any{
+	(*NavRecord)(nil), // 0: proto.NavRecord
+	nil,               // 1: proto.NavRecord.SensorsEntry
+	nil,               // 2: proto.NavRecord.ParamsEntry
+}
+var file_navrecord_proto_depIdxs = []int32{
+	1, // 0: proto.NavRecord.sensors:type_name -> proto.NavRecord.SensorsEntry
+	2, // 1: proto.NavRecord.params:type_name -> proto.NavRecord.ParamsEntry
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_navrecord_proto_init() }
+func file_navrecord_proto_init() {
+	if File_navrecord_proto != nil {
+		return
+	}
+	file_navrecord_proto_msgTypes[0].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_navrecord_proto_rawDesc), len(file_navrecord_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_navrecord_proto_goTypes,
+		DependencyIndexes: file_navrecord_proto_depIdxs,
+		MessageInfos:      file_navrecord_proto_msgTypes,
+	}.Build()
+	File_navrecord_proto = out.File
+	file_navrecord_proto_goTypes = nil
+	file_navrecord_proto_depIdxs = nil
+}
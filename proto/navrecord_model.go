@@ -0,0 +1,167 @@
+package proto
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/models"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// Кодировки навигационных записей в NATS
+const (
+	EncodingJSON     = "json"
+	EncodingProtobuf = "protobuf"
+)
+
+// Значения заголовка Content-Type, по которым потребители различают кодировки
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+// ValidateEncoding проверяет имя кодировки
+func ValidateEncoding(encoding string) error {
+	switch strings.ToLower(encoding) {
+	case EncodingJSON, EncodingProtobuf:
+		return nil
+	default:
+		return fmt.Errorf("неизвестная кодировка %q, допустимы %s и %s", encoding, EncodingJSON, EncodingProtobuf)
+	}
+}
+
+// MarshalNavRecord кодирует запись в заданной кодировке и возвращает данные и Content-Type
+func MarshalNavRecord(rec *models.NavRecord, encoding string) ([]byte, string, error) {
+	switch strings.ToLower(encoding) {
+	case EncodingJSON, "":
+		data, err := json.Marshal(rec)
+		return data, ContentTypeJSON, err
+	case EncodingProtobuf:
+		data, err := protobuf.Marshal(NavRecordFromModel(rec))
+		return data, ContentTypeProtobuf, err
+	default:
+		return nil, "", ValidateEncoding(encoding)
+	}
+}
+
+// UnmarshalNavRecord разбирает запись по значению заголовка Content-Type.
+// Пустой Content-Type - JSON (сообщения, опубликованные до появления заголовка).
+func UnmarshalNavRecord(data []byte, contentType string) (*models.NavRecord, error) {
+	switch contentType {
+	case ContentTypeJSON, "":
+		rec := &models.NavRecord{}
+		if err := json.Unmarshal(data, rec); err != nil {
+			return nil, err
+		}
+		return rec, nil
+	case ContentTypeProtobuf:
+		msg := &NavRecord{}
+		if err := protobuf.Unmarshal(data, msg); err != nil {
+			return nil, err
+		}
+		return msg.ToModel(), nil
+	default:
+		return nil, fmt.Errorf("неизвестный Content-Type %q", contentType)
+	}
+}
+
+// NavRecordFromModel переводит запись в сообщение NavRecord.
+// Навигационные поля заполняются только для записей с координатами, нулевые DOP и пробег не передаются.
+func NavRecordFromModel(rec *models.NavRecord) *NavRecord {
+	msg := &NavRecord{
+		Client:         rec.Client,
+		Imei:           rec.Imei,
+		Imsi:           rec.Imsi,
+		Protocol:       rec.Protocol,
+		PortId:         rec.PortID,
+		PacketId:       rec.PacketID,
+		NavigationTime: unixMilli(rec.NavigationTime),
+		ReceivedTime:   unixMilli(rec.ReceivedTime),
+		Valid:          rec.Valid,
+		Moving:         rec.Moving,
+		BlackBox:       rec.BlackBox,
+		Alarm:          rec.Alarm,
+		Event:          rec.Event,
+		DigitalInputs:  rec.DigitalInputs,
+		DigitalOutputs: rec.DigitalOutputs,
+		Sensors:        rec.Sensors,
+		Params:         rec.Params,
+	}
+
+	if rec.HasPosition() {
+		msg.Latitude = protobuf.Float64(rec.Latitude)
+		msg.Longitude = protobuf.Float64(rec.Longitude)
+		msg.Speed = protobuf.Float64(rec.Speed)
+		msg.Course = protobuf.Uint32(uint32(rec.Course))
+		msg.Satellites = protobuf.Uint32(uint32(rec.Satellites))
+	}
+	if rec.AltitudeValid {
+		msg.Altitude = protobuf.Float64(rec.Altitude)
+	}
+	msg.Pdop = optFloat64(rec.Pdop)
+	msg.Hdop = optFloat64(rec.Hdop)
+	msg.Vdop = optFloat64(rec.Vdop)
+	msg.Odometer = optFloat64(rec.Odometer)
+	if rec.NavSystem != 0 {
+		msg.NavSystem = protobuf.Uint32(uint32(rec.NavSystem))
+	}
+	return msg
+}
+
+// ToModel переводит сообщение в models.NavRecord
+func (x *NavRecord) ToModel() *models.NavRecord {
+	return &models.NavRecord{
+		Client:         x.GetClient(),
+		Imei:           x.GetImei(),
+		Imsi:           x.GetImsi(),
+		Protocol:       x.GetProtocol(),
+		PortID:         x.GetPortId(),
+		PacketID:       x.GetPacketId(),
+		NavigationTime: fromUnixMilli(x.GetNavigationTime()),
+		ReceivedTime:   fromUnixMilli(x.GetReceivedTime()),
+		Latitude:       x.GetLatitude(),
+		Longitude:      x.GetLongitude(),
+		Altitude:       x.GetAltitude(),
+		Speed:          x.GetSpeed(),
+		Course:         uint16(x.GetCourse()),
+		Satellites:     uint8(x.GetSatellites()),
+		Pdop:           x.GetPdop(),
+		Hdop:           x.GetHdop(),
+		Vdop:           x.GetVdop(),
+		NavSystem:      uint16(x.GetNavSystem()),
+		Odometer:       x.GetOdometer(),
+		Valid:          x.GetValid(),
+		AltitudeValid:  x.Altitude != nil,
+		Moving:         x.GetMoving(),
+		BlackBox:       x.GetBlackBox(),
+		Alarm:          x.GetAlarm(),
+		Event:          x.GetEvent(),
+		DigitalInputs:  x.GetDigitalInputs(),
+		DigitalOutputs: x.GetDigitalOutputs(),
+		Sensors:        x.GetSensors(),
+		Params:         x.GetParams(),
+	}
+}
+
+func optFloat64(v float64) *float64 {
+	if v == 0 {
+		return nil
+	}
+	return &v
+}
+
+func unixMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+func fromUnixMilli(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms).UTC()
+}
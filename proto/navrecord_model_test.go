@@ -0,0 +1,84 @@
+package proto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func testRecord() *models.NavRecord {
+	return &models.NavRecord{
+		Client:         133552,
+		Imei:           "866795030000000",
+		Protocol:       "EGTS",
+		PortID:         "p1",
+		PacketID:       7,
+		NavigationTime: time.Date(2018, 8, 6, 15, 44, 18, 0, time.UTC),
+		ReceivedTime:   time.Date(2018, 8, 6, 15, 44, 20, 0, time.UTC),
+		Latitude:       55.7522,
+		Longitude:      -37.6156,
+		Speed:          34.5,
+		Course:         300,
+		Satellites:     12,
+		Hdop:           0.5,
+		Odometer:       19.1,
+		Valid:          true,
+		Moving:         true,
+		DigitalInputs:  0x05,
+		Sensors:        map[string]float64{"ain1": 12000, "lls3": 1200},
+		Params:         map[string]string{"io300": "5"},
+		Event:          "src4",
+	}
+}
+
+func TestNavRecord_ProtobufRoundTrip(t *testing.T) {
+	rec := testRecord()
+
+	data, contentType, err := MarshalNavRecord(rec, EncodingProtobuf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, ContentTypeProtobuf, contentType)
+
+	decoded, err := UnmarshalNavRecord(data, contentType)
+	if assert.NoError(t, err) {
+		assert.Equal(t, rec, decoded)
+	}
+}
+
+func TestNavRecord_JSONRoundTrip(t *testing.T) {
+	rec := testRecord()
+
+	data, contentType, err := MarshalNavRecord(rec, EncodingJSON)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, ContentTypeJSON, contentType)
+
+	decoded, err := UnmarshalNavRecord(data, "")
+	if assert.NoError(t, err) {
+		assert.Equal(t, rec, decoded)
+	}
+}
+
+func TestNavRecordFromModel_WithoutPosition(t *testing.T) {
+	msg := NavRecordFromModel(&models.NavRecord{Client: 1, DigitalInputs: 0x01})
+	assert.Nil(t, msg.Latitude)
+	assert.Nil(t, msg.Speed)
+	assert.Nil(t, msg.Altitude)
+	assert.False(t, msg.GetValid())
+	assert.Equal(t, uint32(1), msg.GetDigitalInputs())
+}
+
+func TestValidateEncoding(t *testing.T) {
+	assert.NoError(t, ValidateEncoding("json"))
+	assert.NoError(t, ValidateEncoding("Protobuf"))
+	assert.Error(t, ValidateEncoding("xml"))
+
+	_, _, err := MarshalNavRecord(&models.NavRecord{}, "xml")
+	assert.Error(t, err)
+	_, err = UnmarshalNavRecord(nil, "text/plain")
+	assert.Error(t, err)
+}
@@ -62,6 +62,7 @@ type GetStatusResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	NatsConnected bool                   `protobuf:"varint,1,opt,name=nats_connected,json=natsConnected,proto3" json:"nats_connected,omitempty"`
 	Ports         []*PortStatus          `protobuf:"bytes,2,rep,name=ports,proto3" json:"ports,omitempty"`
+	Protocols     []*ProtocolInfo        `protobuf:"bytes,3,rep,name=protocols,proto3" json:"protocols,omitempty"` // Зарегистрированные протоколы, доступные для AddPort
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -110,31 +111,1377 @@ func (x *GetStatusResponse) GetPorts() []*PortStatus {
 	return nil
 }
 
+func (x *GetStatusResponse) GetProtocols() []*ProtocolInfo {
+	if x != nil {
+		return x.Protocols
+	}
+	return nil
+}
+
+// Описание протокола из реестра обработчиков
+type ProtocolInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // "EGTS", "ARNAVI", ...
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Options       []*ProtocolOption      `protobuf:"bytes,3,rep,name=options,proto3" json:"options,omitempty"` // Параметры порта, которые понимает протокол
+	Udp           bool                   `protobuf:"varint,4,opt,name=udp,proto3" json:"udp,omitempty"`        // Протокол допускает transport udp
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProtocolInfo) Reset() {
+	*x = ProtocolInfo{}
+	mi := &file_receiver_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProtocolInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProtocolInfo) ProtoMessage() {}
+
+func (x *ProtocolInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProtocolInfo.ProtoReflect.Descriptor instead.
+func (*ProtocolInfo) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ProtocolInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ProtocolInfo) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ProtocolInfo) GetOptions() []*ProtocolOption {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *ProtocolInfo) GetUdp() bool {
+	if x != nil {
+		return x.Udp
+	}
+	return false
+}
+
+type ProtocolOption struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	DefaultValue  string                 `protobuf:"bytes,3,opt,name=default_value,json=defaultValue,proto3" json:"default_value,omitempty"`
+	Required      bool                   `protobuf:"varint,4,opt,name=required,proto3" json:"required,omitempty"` // Параметр без значения по умолчанию
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProtocolOption) Reset() {
+	*x = ProtocolOption{}
+	mi := &file_receiver_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProtocolOption) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProtocolOption) ProtoMessage() {}
+
+func (x *ProtocolOption) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProtocolOption.ProtoReflect.Descriptor instead.
+func (*ProtocolOption) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ProtocolOption) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ProtocolOption) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ProtocolOption) GetDefaultValue() string {
+	if x != nil {
+		return x.DefaultValue
+	}
+	return ""
+}
+
+func (x *ProtocolOption) GetRequired() bool {
+	if x != nil {
+		return x.Required
+	}
+	return false
+}
+
 type PortStatus struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`                        //  Уникальный ID порта
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`                    // "EGTS", "Arnavi", "NDTP"
-	Port          int32                  `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`                   // Номер порта
-	IsOpen        bool                   `protobuf:"varint,4,opt,name=is_open,json=isOpen,proto3" json:"is_open,omitempty"` // Открыт ли порт сейчас
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`                                                                                     //  Уникальный ID порта
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`                                                                                 // "EGTS", "Arnavi", "NDTP"
+	Port          int32                  `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`                                                                                // Номер порта
+	IsOpen        bool                   `protobuf:"varint,4,opt,name=is_open,json=isOpen,proto3" json:"is_open,omitempty"`                                                              // Открыт ли порт сейчас
+	Options       map[string]string      `protobuf:"bytes,5,rep,name=options,proto3" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Параметры протокола для порта
+	Tls           *PortTLS               `protobuf:"bytes,6,opt,name=tls,proto3" json:"tls,omitempty"`                                                                                   // Настройки TLS; не задано - подключения без шифрования
+	Transport     string                 `protobuf:"bytes,7,opt,name=transport,proto3" json:"transport,omitempty"`                                                                       // tcp или udp
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PortStatus) Reset() {
+	*x = PortStatus{}
+	mi := &file_receiver_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PortStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PortStatus) ProtoMessage() {}
+
+func (x *PortStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PortStatus.ProtoReflect.Descriptor instead.
+func (*PortStatus) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PortStatus) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PortStatus) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PortStatus) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *PortStatus) GetIsOpen() bool {
+	if x != nil {
+		return x.IsOpen
+	}
+	return false
+}
+
+func (x *PortStatus) GetOptions() map[string]string {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *PortStatus) GetTls() *PortTLS {
+	if x != nil {
+		return x.Tls
+	}
+	return nil
+}
+
+func (x *PortStatus) GetTransport() string {
+	if x != nil {
+		return x.Transport
+	}
+	return ""
+}
+
+// Настройки TLS порта
+type PortTLS struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CertFile      string                 `protobuf:"bytes,1,opt,name=cert_file,json=certFile,proto3" json:"cert_file,omitempty"`                // Сертификат сервера (PEM)
+	KeyFile       string                 `protobuf:"bytes,2,opt,name=key_file,json=keyFile,proto3" json:"key_file,omitempty"`                   // Закрытый ключ (PEM)
+	ClientCaFile  string                 `protobuf:"bytes,3,opt,name=client_ca_file,json=clientCaFile,proto3" json:"client_ca_file,omitempty"`  // УЦ клиентских сертификатов (PEM)
+	ClientAuth    string                 `protobuf:"bytes,4,opt,name=client_auth,json=clientAuth,proto3" json:"client_auth,omitempty"`          // none | verify_if_given | require
+	CertSubject   string                 `protobuf:"bytes,5,opt,name=cert_subject,json=certSubject,proto3" json:"cert_subject,omitempty"`       // Субъект загруженного сертификата (только в GetStatus)
+	CertNotAfter  int64                  `protobuf:"varint,6,opt,name=cert_not_after,json=certNotAfter,proto3" json:"cert_not_after,omitempty"` // Срок действия загруженного сертификата, unix (только в GetStatus)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PortTLS) Reset() {
+	*x = PortTLS{}
+	mi := &file_receiver_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PortTLS) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PortTLS) ProtoMessage() {}
+
+func (x *PortTLS) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PortTLS.ProtoReflect.Descriptor instead.
+func (*PortTLS) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PortTLS) GetCertFile() string {
+	if x != nil {
+		return x.CertFile
+	}
+	return ""
+}
+
+func (x *PortTLS) GetKeyFile() string {
+	if x != nil {
+		return x.KeyFile
+	}
+	return ""
+}
+
+func (x *PortTLS) GetClientCaFile() string {
+	if x != nil {
+		return x.ClientCaFile
+	}
+	return ""
+}
+
+func (x *PortTLS) GetClientAuth() string {
+	if x != nil {
+		return x.ClientAuth
+	}
+	return ""
+}
+
+func (x *PortTLS) GetCertSubject() string {
+	if x != nil {
+		return x.CertSubject
+	}
+	return ""
+}
+
+func (x *PortTLS) GetCertNotAfter() int64 {
+	if x != nil {
+		return x.CertNotAfter
+	}
+	return 0
+}
+
+type GetClientsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProtocolName  string                 `protobuf:"bytes,1,opt,name=protocol_name,json=protocolName,proto3" json:"protocol_name,omitempty"` // ID порта; пусто - клиенты всех портов
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetClientsRequest) Reset() {
+	*x = GetClientsRequest{}
+	mi := &file_receiver_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetClientsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetClientsRequest) ProtoMessage() {}
+
+func (x *GetClientsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetClientsRequest.ProtoReflect.Descriptor instead.
+func (*GetClientsRequest) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetClientsRequest) GetProtocolName() string {
+	if x != nil {
+		return x.ProtocolName
+	}
+	return ""
+}
+
+type ClientInfo struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`           // ID устройства (IMEI)
+	Address         string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"` // Сетевой адрес подключения
+	ConnectedSince  int64                  `protobuf:"varint,3,opt,name=connected_since,json=connectedSince,proto3" json:"connected_since,omitempty"`
+	PortId          string                 `protobuf:"bytes,4,opt,name=port_id,json=portId,proto3" json:"port_id,omitempty"`                             // ID порта, к которому подключено устройство
+	Protocol        string                 `protobuf:"bytes,5,opt,name=protocol,proto3" json:"protocol,omitempty"`                                       // Протокол порта
+	BytesIn         uint64                 `protobuf:"varint,6,opt,name=bytes_in,json=bytesIn,proto3" json:"bytes_in,omitempty"`                         // Принято байт
+	BytesOut        uint64                 `protobuf:"varint,7,opt,name=bytes_out,json=bytesOut,proto3" json:"bytes_out,omitempty"`                      // Отправлено байт
+	Packets         uint64                 `protobuf:"varint,8,opt,name=packets,proto3" json:"packets,omitempty"`                                        // Разобрано пакетов
+	Records         uint64                 `protobuf:"varint,9,opt,name=records,proto3" json:"records,omitempty"`                                        // Опубликовано навигационных записей
+	DecodeErrors    uint64                 `protobuf:"varint,10,opt,name=decode_errors,json=decodeErrors,proto3" json:"decode_errors,omitempty"`         // Пакетов, которые не удалось разобрать
+	LastPacket      int64                  `protobuf:"varint,11,opt,name=last_packet,json=lastPacket,proto3" json:"last_packet,omitempty"`               // Время последнего пакета (unix), 0 - пакетов не было
+	LastFix         int64                  `protobuf:"varint,12,opt,name=last_fix,json=lastFix,proto3" json:"last_fix,omitempty"`                        // Время навигации последней записи с валидными координатами (unix)
+	ProtocolVersion string                 `protobuf:"bytes,13,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"` // Версия протокола, которую сообщило устройство
+	Transport       string                 `protobuf:"bytes,14,opt,name=transport,proto3" json:"transport,omitempty"`                                    // tcp или udp (псевдосессия по адресу устройства)
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ClientInfo) Reset() {
+	*x = ClientInfo{}
+	mi := &file_receiver_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClientInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientInfo) ProtoMessage() {}
+
+func (x *ClientInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientInfo.ProtoReflect.Descriptor instead.
+func (*ClientInfo) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ClientInfo) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ClientInfo) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *ClientInfo) GetConnectedSince() int64 {
+	if x != nil {
+		return x.ConnectedSince
+	}
+	return 0
+}
+
+func (x *ClientInfo) GetPortId() string {
+	if x != nil {
+		return x.PortId
+	}
+	return ""
+}
+
+func (x *ClientInfo) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *ClientInfo) GetBytesIn() uint64 {
+	if x != nil {
+		return x.BytesIn
+	}
+	return 0
+}
+
+func (x *ClientInfo) GetBytesOut() uint64 {
+	if x != nil {
+		return x.BytesOut
+	}
+	return 0
+}
+
+func (x *ClientInfo) GetPackets() uint64 {
+	if x != nil {
+		return x.Packets
+	}
+	return 0
+}
+
+func (x *ClientInfo) GetRecords() uint64 {
+	if x != nil {
+		return x.Records
+	}
+	return 0
+}
+
+func (x *ClientInfo) GetDecodeErrors() uint64 {
+	if x != nil {
+		return x.DecodeErrors
+	}
+	return 0
+}
+
+func (x *ClientInfo) GetLastPacket() int64 {
+	if x != nil {
+		return x.LastPacket
+	}
+	return 0
+}
+
+func (x *ClientInfo) GetLastFix() int64 {
+	if x != nil {
+		return x.LastFix
+	}
+	return 0
+}
+
+func (x *ClientInfo) GetProtocolVersion() string {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return ""
+}
+
+func (x *ClientInfo) GetTransport() string {
+	if x != nil {
+		return x.Transport
+	}
+	return ""
+}
+
+type GetClientsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Clients       []*ClientInfo          `protobuf:"bytes,1,rep,name=clients,proto3" json:"clients,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetClientsResponse) Reset() {
+	*x = GetClientsResponse{}
+	mi := &file_receiver_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetClientsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetClientsResponse) ProtoMessage() {}
+
+func (x *GetClientsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetClientsResponse.ProtoReflect.Descriptor instead.
+func (*GetClientsResponse) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetClientsResponse) GetClients() []*ClientInfo {
+	if x != nil {
+		return x.Clients
+	}
+	return nil
+}
+
+type DisconnectClientRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProtocolName  string                 `protobuf:"bytes,1,opt,name=protocol_name,json=protocolName,proto3" json:"protocol_name,omitempty"`
+	ClientAddress string                 `protobuf:"bytes,2,opt,name=client_address,json=clientAddress,proto3" json:"client_address,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DisconnectClientRequest) Reset() {
+	*x = DisconnectClientRequest{}
+	mi := &file_receiver_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisconnectClientRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisconnectClientRequest) ProtoMessage() {}
+
+func (x *DisconnectClientRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisconnectClientRequest.ProtoReflect.Descriptor instead.
+func (*DisconnectClientRequest) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DisconnectClientRequest) GetProtocolName() string {
+	if x != nil {
+		return x.ProtocolName
+	}
+	return ""
+}
+
+func (x *DisconnectClientRequest) GetClientAddress() string {
+	if x != nil {
+		return x.ClientAddress
+	}
+	return ""
+}
+
+type DisconnectClientResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DisconnectClientResponse) Reset() {
+	*x = DisconnectClientResponse{}
+	mi := &file_receiver_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisconnectClientResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisconnectClientResponse) ProtoMessage() {}
+
+func (x *DisconnectClientResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisconnectClientResponse.ProtoReflect.Descriptor instead.
+func (*DisconnectClientResponse) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DisconnectClientResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type DeviceIdentifier struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeviceIdentifier) Reset() {
+	*x = DeviceIdentifier{}
+	mi := &file_receiver_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceIdentifier) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceIdentifier) ProtoMessage() {}
+
+func (x *DeviceIdentifier) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceIdentifier.ProtoReflect.Descriptor instead.
+func (*DeviceIdentifier) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DeviceIdentifier) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+type DisconnectDeviceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	ProtocolName  string                 `protobuf:"bytes,2,opt,name=protocol_name,json=protocolName,proto3" json:"protocol_name,omitempty"` // ID порта; пусто - отключить устройство на всех портах
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DisconnectDeviceRequest) Reset() {
+	*x = DisconnectDeviceRequest{}
+	mi := &file_receiver_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisconnectDeviceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisconnectDeviceRequest) ProtoMessage() {}
+
+func (x *DisconnectDeviceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisconnectDeviceRequest.ProtoReflect.Descriptor instead.
+func (*DisconnectDeviceRequest) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DisconnectDeviceRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *DisconnectDeviceRequest) GetProtocolName() string {
+	if x != nil {
+		return x.ProtocolName
+	}
+	return ""
+}
+
+type PortIdentifier struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // Уникальный ID порта
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PortIdentifier) Reset() {
+	*x = PortIdentifier{}
+	mi := &file_receiver_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PortIdentifier) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PortIdentifier) ProtoMessage() {}
+
+func (x *PortIdentifier) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PortIdentifier.ProtoReflect.Descriptor instead.
+func (*PortIdentifier) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *PortIdentifier) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type PortDefinition struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`                                                                                 // Имя протокола (EGTS, ARNAVI, NDTP)
+	Port          int32                  `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`                                                                                // Номер порта
+	Options       map[string]string      `protobuf:"bytes,3,rep,name=options,proto3" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Параметры протокола (см. GetStatusResponse.protocols)
+	Tls           *PortTLS               `protobuf:"bytes,4,opt,name=tls,proto3" json:"tls,omitempty"`                                                                                   // Настройки TLS; не задано - подключения без шифрования
+	Transport     string                 `protobuf:"bytes,5,opt,name=transport,proto3" json:"transport,omitempty"`                                                                       // tcp (по умолчанию) или udp, если протокол его поддерживает
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PortDefinition) Reset() {
+	*x = PortDefinition{}
+	mi := &file_receiver_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PortDefinition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PortDefinition) ProtoMessage() {}
+
+func (x *PortDefinition) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PortDefinition.ProtoReflect.Descriptor instead.
+func (*PortDefinition) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *PortDefinition) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PortDefinition) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *PortDefinition) GetOptions() map[string]string {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *PortDefinition) GetTls() *PortTLS {
+	if x != nil {
+		return x.Tls
+	}
+	return nil
+}
+
+func (x *PortDefinition) GetTransport() string {
+	if x != nil {
+		return x.Transport
+	}
+	return ""
+}
+
+type PortOperationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`                            // Описание результата или ошибки
+	PortDetails   *PortDefinition        `protobuf:"bytes,3,opt,name=port_details,json=portDetails,proto3" json:"port_details,omitempty"` // Детали созданного/измененного порта
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PortOperationResponse) Reset() {
+	*x = PortOperationResponse{}
+	mi := &file_receiver_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PortOperationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PortOperationResponse) ProtoMessage() {}
+
+func (x *PortOperationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PortOperationResponse.ProtoReflect.Descriptor instead.
+func (*PortOperationResponse) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *PortOperationResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *PortOperationResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *PortOperationResponse) GetPortDetails() *PortDefinition {
+	if x != nil {
+		return x.PortDetails
+	}
+	return nil
+}
+
+type SendCommandRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`  // IMEI или ID устройства
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`                          // engine_block, engine_unblock, set_output, request_position, raw
+	Output        uint32                 `protobuf:"varint,3,opt,name=output,proto3" json:"output,omitempty"`                     // Номер выхода для set_output и блокировки двигателя
+	On            bool                   `protobuf:"varint,4,opt,name=on,proto3" json:"on,omitempty"`                             // Состояние выхода для set_output
+	Payload       []byte                 `protobuf:"bytes,5,opt,name=payload,proto3" json:"payload,omitempty"`                    // Данные команды raw
+	Timeout       int32                  `protobuf:"varint,6,opt,name=timeout,proto3" json:"timeout,omitempty"`                   // Сколько секунд ждать ответа устройства; 0 - [commands] timeout
+	QueueTtl      int32                  `protobuf:"varint,7,opt,name=queue_ttl,json=queueTtl,proto3" json:"queue_ttl,omitempty"` // Сколько секунд ждать подключения устройства; 0 - [commands] queue_ttl
+	Id            string                 `protobuf:"bytes,8,opt,name=id,proto3" json:"id,omitempty"`                              // Идентификатор команды; пусто - будет сгенерирован
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendCommandRequest) Reset() {
+	*x = SendCommandRequest{}
+	mi := &file_receiver_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendCommandRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendCommandRequest) ProtoMessage() {}
+
+func (x *SendCommandRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendCommandRequest.ProtoReflect.Descriptor instead.
+func (*SendCommandRequest) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SendCommandRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *SendCommandRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SendCommandRequest) GetOutput() uint32 {
+	if x != nil {
+		return x.Output
+	}
+	return 0
+}
+
+func (x *SendCommandRequest) GetOn() bool {
+	if x != nil {
+		return x.On
+	}
+	return false
+}
+
+func (x *SendCommandRequest) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *SendCommandRequest) GetTimeout() int32 {
+	if x != nil {
+		return x.Timeout
+	}
+	return 0
+}
+
+func (x *SendCommandRequest) GetQueueTtl() int32 {
+	if x != nil {
+		return x.QueueTtl
+	}
+	return 0
+}
+
+func (x *SendCommandRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type CommandIdentifier struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandIdentifier) Reset() {
+	*x = CommandIdentifier{}
+	mi := &file_receiver_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandIdentifier) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandIdentifier) ProtoMessage() {}
+
+func (x *CommandIdentifier) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandIdentifier.ProtoReflect.Descriptor instead.
+func (*CommandIdentifier) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *CommandIdentifier) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type CommandStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`     // queued, sent, acknowledged, failed, expired
+	Result        string                 `protobuf:"bytes,5,opt,name=result,proto3" json:"result,omitempty"`     // Результат последней передачи: delivered, timeout, no_connection, ...
+	Code          uint32                 `protobuf:"varint,6,opt,name=code,proto3" json:"code,omitempty"`        // Код ответа устройства в терминах протокола
+	Message       string                 `protobuf:"bytes,7,opt,name=message,proto3" json:"message,omitempty"`   // Описание ошибки
+	Protocol      string                 `protobuf:"bytes,8,opt,name=protocol,proto3" json:"protocol,omitempty"` // Протокол порта, через который передана команда
+	PortId        string                 `protobuf:"bytes,9,opt,name=port_id,json=portId,proto3" json:"port_id,omitempty"`
+	Attempts      int32                  `protobuf:"varint,10,opt,name=attempts,proto3" json:"attempts,omitempty"` // Сколько раз команду передавали устройству
+	CreatedAt     int64                  `protobuf:"varint,11,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     int64                  `protobuf:"varint,12,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	ExpiresAt     int64                  `protobuf:"varint,13,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // До какого времени команда ждет подключения устройства
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandStatus) Reset() {
+	*x = CommandStatus{}
+	mi := &file_receiver_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandStatus) ProtoMessage() {}
+
+func (x *CommandStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandStatus.ProtoReflect.Descriptor instead.
+func (*CommandStatus) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *CommandStatus) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CommandStatus) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *CommandStatus) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *CommandStatus) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *CommandStatus) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+func (x *CommandStatus) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *CommandStatus) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *CommandStatus) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *CommandStatus) GetPortId() string {
+	if x != nil {
+		return x.PortId
+	}
+	return ""
+}
+
+func (x *CommandStatus) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *CommandStatus) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *CommandStatus) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+func (x *CommandStatus) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+type ListPendingCommandsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"` // Пусто - команды всех устройств
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPendingCommandsRequest) Reset() {
+	*x = ListPendingCommandsRequest{}
+	mi := &file_receiver_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPendingCommandsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPendingCommandsRequest) ProtoMessage() {}
+
+func (x *ListPendingCommandsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPendingCommandsRequest.ProtoReflect.Descriptor instead.
+func (*ListPendingCommandsRequest) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListPendingCommandsRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+type ListPendingCommandsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Commands      []*CommandStatus       `protobuf:"bytes,1,rep,name=commands,proto3" json:"commands,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPendingCommandsResponse) Reset() {
+	*x = ListPendingCommandsResponse{}
+	mi := &file_receiver_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPendingCommandsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPendingCommandsResponse) ProtoMessage() {}
+
+func (x *ListPendingCommandsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPendingCommandsResponse.ProtoReflect.Descriptor instead.
+func (*ListPendingCommandsResponse) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListPendingCommandsResponse) GetCommands() []*CommandStatus {
+	if x != nil {
+		return x.Commands
+	}
+	return nil
+}
+
+type ListBansRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProtocolName  string                 `protobuf:"bytes,1,opt,name=protocol_name,json=protocolName,proto3" json:"protocol_name,omitempty"` // ID порта; пусто - блокировки всех портов
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBansRequest) Reset() {
+	*x = ListBansRequest{}
+	mi := &file_receiver_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBansRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBansRequest) ProtoMessage() {}
+
+func (x *ListBansRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBansRequest.ProtoReflect.Descriptor instead.
+func (*ListBansRequest) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListBansRequest) GetProtocolName() string {
+	if x != nil {
+		return x.ProtocolName
+	}
+	return ""
+}
+
+type BanInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ip            string                 `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	PortId        string                 `protobuf:"bytes,2,opt,name=port_id,json=portId,proto3" json:"port_id,omitempty"` // ID порта, на котором заблокирован IP
+	Protocol      string                 `protobuf:"bytes,3,opt,name=protocol,proto3" json:"protocol,omitempty"`           // Протокол порта
+	Until         int64                  `protobuf:"varint,4,opt,name=until,proto3" json:"until,omitempty"`                // Время окончания блокировки (unix)
+	Failures      int32                  `protobuf:"varint,5,opt,name=failures,proto3" json:"failures,omitempty"`          // Неудачных авторизаций, после которых IP заблокирован
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PortStatus) Reset() {
-	*x = PortStatus{}
-	mi := &file_receiver_proto_msgTypes[2]
+func (x *BanInfo) Reset() {
+	*x = BanInfo{}
+	mi := &file_receiver_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PortStatus) String() string {
+func (x *BanInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PortStatus) ProtoMessage() {}
+func (*BanInfo) ProtoMessage() {}
 
-func (x *PortStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_receiver_proto_msgTypes[2]
+func (x *BanInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -145,61 +1492,68 @@ func (x *PortStatus) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PortStatus.ProtoReflect.Descriptor instead.
-func (*PortStatus) Descriptor() ([]byte, []int) {
-	return file_receiver_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use BanInfo.ProtoReflect.Descriptor instead.
+func (*BanInfo) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{22}
 }
 
-func (x *PortStatus) GetId() string {
+func (x *BanInfo) GetIp() string {
 	if x != nil {
-		return x.Id
+		return x.Ip
 	}
 	return ""
 }
 
-func (x *PortStatus) GetName() string {
+func (x *BanInfo) GetPortId() string {
 	if x != nil {
-		return x.Name
+		return x.PortId
 	}
 	return ""
 }
 
-func (x *PortStatus) GetPort() int32 {
+func (x *BanInfo) GetProtocol() string {
 	if x != nil {
-		return x.Port
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *BanInfo) GetUntil() int64 {
+	if x != nil {
+		return x.Until
 	}
 	return 0
 }
 
-func (x *PortStatus) GetIsOpen() bool {
+func (x *BanInfo) GetFailures() int32 {
 	if x != nil {
-		return x.IsOpen
+		return x.Failures
 	}
-	return false
+	return 0
 }
 
-type GetClientsRequest struct {
+type ListBansResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ProtocolName  string                 `protobuf:"bytes,1,opt,name=protocol_name,json=protocolName,proto3" json:"protocol_name,omitempty"`
+	Bans          []*BanInfo             `protobuf:"bytes,1,rep,name=bans,proto3" json:"bans,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetClientsRequest) Reset() {
-	*x = GetClientsRequest{}
-	mi := &file_receiver_proto_msgTypes[3]
+func (x *ListBansResponse) Reset() {
+	*x = ListBansResponse{}
+	mi := &file_receiver_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetClientsRequest) String() string {
+func (x *ListBansResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetClientsRequest) ProtoMessage() {}
+func (*ListBansResponse) ProtoMessage() {}
 
-func (x *GetClientsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_receiver_proto_msgTypes[3]
+func (x *ListBansResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -210,42 +1564,95 @@ func (x *GetClientsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetClientsRequest.ProtoReflect.Descriptor instead.
-func (*GetClientsRequest) Descriptor() ([]byte, []int) {
-	return file_receiver_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use ListBansResponse.ProtoReflect.Descriptor instead.
+func (*ListBansResponse) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{23}
 }
 
-func (x *GetClientsRequest) GetProtocolName() string {
+func (x *ListBansResponse) GetBans() []*BanInfo {
+	if x != nil {
+		return x.Bans
+	}
+	return nil
+}
+
+type UnbanRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ip            string                 `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	ProtocolName  string                 `protobuf:"bytes,2,opt,name=protocol_name,json=protocolName,proto3" json:"protocol_name,omitempty"` // ID порта; пусто - снять блокировку на всех портах
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnbanRequest) Reset() {
+	*x = UnbanRequest{}
+	mi := &file_receiver_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnbanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnbanRequest) ProtoMessage() {}
+
+func (x *UnbanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnbanRequest.ProtoReflect.Descriptor instead.
+func (*UnbanRequest) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *UnbanRequest) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *UnbanRequest) GetProtocolName() string {
 	if x != nil {
 		return x.ProtocolName
 	}
 	return ""
 }
 
-type ClientInfo struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Address        string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
-	ConnectedSince int64                  `protobuf:"varint,3,opt,name=connected_since,json=connectedSince,proto3" json:"connected_since,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+type RegisteredDevice struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`             // IMEI или ID терминала, которым устройство авторизуется
+	Protocol      string                 `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"` // Разрешенный протокол; пусто - любой
+	Customer      string                 `protobuf:"bytes,3,opt,name=customer,proto3" json:"customer,omitempty"` // Владелец устройства
+	Enabled       bool                   `protobuf:"varint,4,opt,name=enabled,proto3" json:"enabled,omitempty"`  // Устройство обслуживается
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ClientInfo) Reset() {
-	*x = ClientInfo{}
-	mi := &file_receiver_proto_msgTypes[4]
+func (x *RegisteredDevice) Reset() {
+	*x = RegisteredDevice{}
+	mi := &file_receiver_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ClientInfo) String() string {
+func (x *RegisteredDevice) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ClientInfo) ProtoMessage() {}
+func (*RegisteredDevice) ProtoMessage() {}
 
-func (x *ClientInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_receiver_proto_msgTypes[4]
+func (x *RegisteredDevice) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -256,54 +1663,61 @@ func (x *ClientInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ClientInfo.ProtoReflect.Descriptor instead.
-func (*ClientInfo) Descriptor() ([]byte, []int) {
-	return file_receiver_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use RegisteredDevice.ProtoReflect.Descriptor instead.
+func (*RegisteredDevice) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *ClientInfo) GetId() string {
+func (x *RegisteredDevice) GetId() string {
 	if x != nil {
 		return x.Id
 	}
 	return ""
 }
 
-func (x *ClientInfo) GetAddress() string {
+func (x *RegisteredDevice) GetProtocol() string {
 	if x != nil {
-		return x.Address
+		return x.Protocol
 	}
 	return ""
 }
 
-func (x *ClientInfo) GetConnectedSince() int64 {
+func (x *RegisteredDevice) GetCustomer() string {
 	if x != nil {
-		return x.ConnectedSince
+		return x.Customer
 	}
-	return 0
+	return ""
 }
 
-type GetClientsResponse struct {
+func (x *RegisteredDevice) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+type ListDevicesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Clients       []*ClientInfo          `protobuf:"bytes,1,rep,name=clients,proto3" json:"clients,omitempty"`
+	Customer      string                 `protobuf:"bytes,1,opt,name=customer,proto3" json:"customer,omitempty"` // Пусто - все устройства
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetClientsResponse) Reset() {
-	*x = GetClientsResponse{}
-	mi := &file_receiver_proto_msgTypes[5]
+func (x *ListDevicesRequest) Reset() {
+	*x = ListDevicesRequest{}
+	mi := &file_receiver_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetClientsResponse) String() string {
+func (x *ListDevicesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetClientsResponse) ProtoMessage() {}
+func (*ListDevicesRequest) ProtoMessage() {}
 
-func (x *GetClientsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_receiver_proto_msgTypes[5]
+func (x *ListDevicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -314,41 +1728,40 @@ func (x *GetClientsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetClientsResponse.ProtoReflect.Descriptor instead.
-func (*GetClientsResponse) Descriptor() ([]byte, []int) {
-	return file_receiver_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use ListDevicesRequest.ProtoReflect.Descriptor instead.
+func (*ListDevicesRequest) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{26}
 }
 
-func (x *GetClientsResponse) GetClients() []*ClientInfo {
+func (x *ListDevicesRequest) GetCustomer() string {
 	if x != nil {
-		return x.Clients
+		return x.Customer
 	}
-	return nil
+	return ""
 }
 
-type DisconnectClientRequest struct {
+type ListDevicesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ProtocolName  string                 `protobuf:"bytes,1,opt,name=protocol_name,json=protocolName,proto3" json:"protocol_name,omitempty"`
-	ClientAddress string                 `protobuf:"bytes,2,opt,name=client_address,json=clientAddress,proto3" json:"client_address,omitempty"`
+	Devices       []*RegisteredDevice    `protobuf:"bytes,1,rep,name=devices,proto3" json:"devices,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DisconnectClientRequest) Reset() {
-	*x = DisconnectClientRequest{}
-	mi := &file_receiver_proto_msgTypes[6]
+func (x *ListDevicesResponse) Reset() {
+	*x = ListDevicesResponse{}
+	mi := &file_receiver_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DisconnectClientRequest) String() string {
+func (x *ListDevicesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DisconnectClientRequest) ProtoMessage() {}
+func (*ListDevicesResponse) ProtoMessage() {}
 
-func (x *DisconnectClientRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_receiver_proto_msgTypes[6]
+func (x *ListDevicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -359,47 +1772,39 @@ func (x *DisconnectClientRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DisconnectClientRequest.ProtoReflect.Descriptor instead.
-func (*DisconnectClientRequest) Descriptor() ([]byte, []int) {
-	return file_receiver_proto_rawDescGZIP(), []int{6}
-}
-
-func (x *DisconnectClientRequest) GetProtocolName() string {
-	if x != nil {
-		return x.ProtocolName
-	}
-	return ""
+// Deprecated: Use ListDevicesResponse.ProtoReflect.Descriptor instead.
+func (*ListDevicesResponse) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{27}
 }
 
-func (x *DisconnectClientRequest) GetClientAddress() string {
+func (x *ListDevicesResponse) GetDevices() []*RegisteredDevice {
 	if x != nil {
-		return x.ClientAddress
+		return x.Devices
 	}
-	return ""
+	return nil
 }
 
-type DisconnectClientResponse struct {
+type ReloadDevicesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DisconnectClientResponse) Reset() {
-	*x = DisconnectClientResponse{}
-	mi := &file_receiver_proto_msgTypes[7]
+func (x *ReloadDevicesRequest) Reset() {
+	*x = ReloadDevicesRequest{}
+	mi := &file_receiver_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DisconnectClientResponse) String() string {
+func (x *ReloadDevicesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DisconnectClientResponse) ProtoMessage() {}
+func (*ReloadDevicesRequest) ProtoMessage() {}
 
-func (x *DisconnectClientResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_receiver_proto_msgTypes[7]
+func (x *ReloadDevicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -410,40 +1815,33 @@ func (x *DisconnectClientResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DisconnectClientResponse.ProtoReflect.Descriptor instead.
-func (*DisconnectClientResponse) Descriptor() ([]byte, []int) {
-	return file_receiver_proto_rawDescGZIP(), []int{7}
-}
-
-func (x *DisconnectClientResponse) GetSuccess() bool {
-	if x != nil {
-		return x.Success
-	}
-	return false
+// Deprecated: Use ReloadDevicesRequest.ProtoReflect.Descriptor instead.
+func (*ReloadDevicesRequest) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{28}
 }
 
-type PortIdentifier struct {
+type ReloadDevicesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // Уникальный ID порта
+	Count         int32                  `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"` // Устройств в реестре после перезагрузки
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PortIdentifier) Reset() {
-	*x = PortIdentifier{}
-	mi := &file_receiver_proto_msgTypes[8]
+func (x *ReloadDevicesResponse) Reset() {
+	*x = ReloadDevicesResponse{}
+	mi := &file_receiver_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PortIdentifier) String() string {
+func (x *ReloadDevicesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PortIdentifier) ProtoMessage() {}
+func (*ReloadDevicesResponse) ProtoMessage() {}
 
-func (x *PortIdentifier) ProtoReflect() protoreflect.Message {
-	mi := &file_receiver_proto_msgTypes[8]
+func (x *ReloadDevicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -454,41 +1852,42 @@ func (x *PortIdentifier) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PortIdentifier.ProtoReflect.Descriptor instead.
-func (*PortIdentifier) Descriptor() ([]byte, []int) {
-	return file_receiver_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use ReloadDevicesResponse.ProtoReflect.Descriptor instead.
+func (*ReloadDevicesResponse) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{29}
 }
 
-func (x *PortIdentifier) GetId() string {
+func (x *ReloadDevicesResponse) GetCount() int32 {
 	if x != nil {
-		return x.Id
+		return x.Count
 	}
-	return ""
+	return 0
 }
 
-type PortDefinition struct {
+type SetCaptureRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`  // Имя протокола (EGTS, ARNAVI, NDTP)
-	Port          int32                  `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"` // Номер порта
+	ProtocolName  string                 `protobuf:"bytes,1,opt,name=protocol_name,json=protocolName,proto3" json:"protocol_name,omitempty"` // ID порта: записывать все его сессии
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`             // ID устройства: записывать его сессии на всех портах
+	Enabled       bool                   `protobuf:"varint,3,opt,name=enabled,proto3" json:"enabled,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PortDefinition) Reset() {
-	*x = PortDefinition{}
-	mi := &file_receiver_proto_msgTypes[9]
+func (x *SetCaptureRequest) Reset() {
+	*x = SetCaptureRequest{}
+	mi := &file_receiver_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PortDefinition) String() string {
+func (x *SetCaptureRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PortDefinition) ProtoMessage() {}
+func (*SetCaptureRequest) ProtoMessage() {}
 
-func (x *PortDefinition) ProtoReflect() protoreflect.Message {
-	mi := &file_receiver_proto_msgTypes[9]
+func (x *SetCaptureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -499,49 +1898,53 @@ func (x *PortDefinition) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PortDefinition.ProtoReflect.Descriptor instead.
-func (*PortDefinition) Descriptor() ([]byte, []int) {
-	return file_receiver_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use SetCaptureRequest.ProtoReflect.Descriptor instead.
+func (*SetCaptureRequest) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{30}
 }
 
-func (x *PortDefinition) GetName() string {
+func (x *SetCaptureRequest) GetProtocolName() string {
 	if x != nil {
-		return x.Name
+		return x.ProtocolName
 	}
 	return ""
 }
 
-func (x *PortDefinition) GetPort() int32 {
+func (x *SetCaptureRequest) GetDeviceId() string {
 	if x != nil {
-		return x.Port
+		return x.DeviceId
 	}
-	return 0
+	return ""
 }
 
-type PortOperationResponse struct {
+func (x *SetCaptureRequest) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+type GetCaptureRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`                            // Описание результата или ошибки
-	PortDetails   *PortDefinition        `protobuf:"bytes,3,opt,name=port_details,json=portDetails,proto3" json:"port_details,omitempty"` // Детали созданного/измененного порта
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PortOperationResponse) Reset() {
-	*x = PortOperationResponse{}
-	mi := &file_receiver_proto_msgTypes[10]
+func (x *GetCaptureRequest) Reset() {
+	*x = GetCaptureRequest{}
+	mi := &file_receiver_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PortOperationResponse) String() string {
+func (x *GetCaptureRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PortOperationResponse) ProtoMessage() {}
+func (*GetCaptureRequest) ProtoMessage() {}
 
-func (x *PortOperationResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_receiver_proto_msgTypes[10]
+func (x *GetCaptureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -552,28 +1955,67 @@ func (x *PortOperationResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PortOperationResponse.ProtoReflect.Descriptor instead.
-func (*PortOperationResponse) Descriptor() ([]byte, []int) {
-	return file_receiver_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use GetCaptureRequest.ProtoReflect.Descriptor instead.
+func (*GetCaptureRequest) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{31}
 }
 
-func (x *PortOperationResponse) GetSuccess() bool {
+type CaptureStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Dir           string                 `protobuf:"bytes,1,opt,name=dir,proto3" json:"dir,omitempty"`                              // Каталог файлов записи ([capture] dir)
+	PortIds       []string               `protobuf:"bytes,2,rep,name=port_ids,json=portIds,proto3" json:"port_ids,omitempty"`       // Порты, все сессии которых записываются
+	DeviceIds     []string               `protobuf:"bytes,3,rep,name=device_ids,json=deviceIds,proto3" json:"device_ids,omitempty"` // Устройства, сессии которых записываются
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CaptureStatus) Reset() {
+	*x = CaptureStatus{}
+	mi := &file_receiver_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CaptureStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureStatus) ProtoMessage() {}
+
+func (x *CaptureStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_receiver_proto_msgTypes[32]
 	if x != nil {
-		return x.Success
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return false
+	return mi.MessageOf(x)
 }
 
-func (x *PortOperationResponse) GetMessage() string {
+// Deprecated: Use CaptureStatus.ProtoReflect.Descriptor instead.
+func (*CaptureStatus) Descriptor() ([]byte, []int) {
+	return file_receiver_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *CaptureStatus) GetDir() string {
 	if x != nil {
-		return x.Message
+		return x.Dir
 	}
 	return ""
 }
 
-func (x *PortOperationResponse) GetPortDetails() *PortDefinition {
+func (x *CaptureStatus) GetPortIds() []string {
 	if x != nil {
-		return x.PortDetails
+		return x.PortIds
+	}
+	return nil
+}
+
+func (x *CaptureStatus) GetDeviceIds() []string {
+	if x != nil {
+		return x.DeviceIds
 	}
 	return nil
 }
@@ -583,50 +2025,184 @@ var File_receiver_proto protoreflect.FileDescriptor
 const file_receiver_proto_rawDesc = "" +
 	"\n" +
 	"\x0ereceiver.proto\x12\x05proto\x1a\rservice.proto\x1a\x1egoogle/protobuf/wrappers.proto\"\x12\n" +
-	"\x10GetStatusRequest\"c\n" +
+	"\x10GetStatusRequest\"\x96\x01\n" +
 	"\x11GetStatusResponse\x12%\n" +
 	"\x0enats_connected\x18\x01 \x01(\bR\rnatsConnected\x12'\n" +
-	"\x05ports\x18\x02 \x03(\v2\x11.proto.PortStatusR\x05ports\"]\n" +
+	"\x05ports\x18\x02 \x03(\v2\x11.proto.PortStatusR\x05ports\x121\n" +
+	"\tprotocols\x18\x03 \x03(\v2\x13.proto.ProtocolInfoR\tprotocols\"\x87\x01\n" +
+	"\fProtocolInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12/\n" +
+	"\aoptions\x18\x03 \x03(\v2\x15.proto.ProtocolOptionR\aoptions\x12\x10\n" +
+	"\x03udp\x18\x04 \x01(\bR\x03udp\"\x87\x01\n" +
+	"\x0eProtocolOption\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12#\n" +
+	"\rdefault_value\x18\x03 \x01(\tR\fdefaultValue\x12\x1a\n" +
+	"\brequired\x18\x04 \x01(\bR\brequired\"\x93\x02\n" +
 	"\n" +
 	"PortStatus\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
 	"\x04port\x18\x03 \x01(\x05R\x04port\x12\x17\n" +
-	"\ais_open\x18\x04 \x01(\bR\x06isOpen\"8\n" +
+	"\ais_open\x18\x04 \x01(\bR\x06isOpen\x128\n" +
+	"\aoptions\x18\x05 \x03(\v2\x1e.proto.PortStatus.OptionsEntryR\aoptions\x12 \n" +
+	"\x03tls\x18\x06 \x01(\v2\x0e.proto.PortTLSR\x03tls\x12\x1c\n" +
+	"\ttransport\x18\a \x01(\tR\ttransport\x1a:\n" +
+	"\fOptionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xd1\x01\n" +
+	"\aPortTLS\x12\x1b\n" +
+	"\tcert_file\x18\x01 \x01(\tR\bcertFile\x12\x19\n" +
+	"\bkey_file\x18\x02 \x01(\tR\akeyFile\x12$\n" +
+	"\x0eclient_ca_file\x18\x03 \x01(\tR\fclientCaFile\x12\x1f\n" +
+	"\vclient_auth\x18\x04 \x01(\tR\n" +
+	"clientAuth\x12!\n" +
+	"\fcert_subject\x18\x05 \x01(\tR\vcertSubject\x12$\n" +
+	"\x0ecert_not_after\x18\x06 \x01(\x03R\fcertNotAfter\"8\n" +
 	"\x11GetClientsRequest\x12#\n" +
-	"\rprotocol_name\x18\x01 \x01(\tR\fprotocolName\"_\n" +
+	"\rprotocol_name\x18\x01 \x01(\tR\fprotocolName\"\xaa\x03\n" +
 	"\n" +
 	"ClientInfo\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
 	"\aaddress\x18\x02 \x01(\tR\aaddress\x12'\n" +
-	"\x0fconnected_since\x18\x03 \x01(\x03R\x0econnectedSince\"A\n" +
+	"\x0fconnected_since\x18\x03 \x01(\x03R\x0econnectedSince\x12\x17\n" +
+	"\aport_id\x18\x04 \x01(\tR\x06portId\x12\x1a\n" +
+	"\bprotocol\x18\x05 \x01(\tR\bprotocol\x12\x19\n" +
+	"\bbytes_in\x18\x06 \x01(\x04R\abytesIn\x12\x1b\n" +
+	"\tbytes_out\x18\a \x01(\x04R\bbytesOut\x12\x18\n" +
+	"\apackets\x18\b \x01(\x04R\apackets\x12\x18\n" +
+	"\arecords\x18\t \x01(\x04R\arecords\x12#\n" +
+	"\rdecode_errors\x18\n" +
+	" \x01(\x04R\fdecodeErrors\x12\x1f\n" +
+	"\vlast_packet\x18\v \x01(\x03R\n" +
+	"lastPacket\x12\x19\n" +
+	"\blast_fix\x18\f \x01(\x03R\alastFix\x12)\n" +
+	"\x10protocol_version\x18\r \x01(\tR\x0fprotocolVersion\x12\x1c\n" +
+	"\ttransport\x18\x0e \x01(\tR\ttransport\"A\n" +
 	"\x12GetClientsResponse\x12+\n" +
 	"\aclients\x18\x01 \x03(\v2\x11.proto.ClientInfoR\aclients\"e\n" +
 	"\x17DisconnectClientRequest\x12#\n" +
 	"\rprotocol_name\x18\x01 \x01(\tR\fprotocolName\x12%\n" +
 	"\x0eclient_address\x18\x02 \x01(\tR\rclientAddress\"4\n" +
 	"\x18DisconnectClientResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\" \n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"/\n" +
+	"\x10DeviceIdentifier\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\"[\n" +
+	"\x17DisconnectDeviceRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12#\n" +
+	"\rprotocol_name\x18\x02 \x01(\tR\fprotocolName\" \n" +
 	"\x0ePortIdentifier\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"8\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xf2\x01\n" +
 	"\x0ePortDefinition\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
-	"\x04port\x18\x02 \x01(\x05R\x04port\"\x85\x01\n" +
+	"\x04port\x18\x02 \x01(\x05R\x04port\x12<\n" +
+	"\aoptions\x18\x03 \x03(\v2\".proto.PortDefinition.OptionsEntryR\aoptions\x12 \n" +
+	"\x03tls\x18\x04 \x01(\v2\x0e.proto.PortTLSR\x03tls\x12\x1c\n" +
+	"\ttransport\x18\x05 \x01(\tR\ttransport\x1a:\n" +
+	"\fOptionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x85\x01\n" +
 	"\x15PortOperationResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x128\n" +
-	"\fport_details\x18\x03 \x01(\v2\x15.proto.PortDefinitionR\vportDetails2\x92\x05\n" +
+	"\fport_details\x18\x03 \x01(\v2\x15.proto.PortDefinitionR\vportDetails\"\xce\x01\n" +
+	"\x12SendCommandRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x16\n" +
+	"\x06output\x18\x03 \x01(\rR\x06output\x12\x0e\n" +
+	"\x02on\x18\x04 \x01(\bR\x02on\x12\x18\n" +
+	"\apayload\x18\x05 \x01(\fR\apayload\x12\x18\n" +
+	"\atimeout\x18\x06 \x01(\x05R\atimeout\x12\x1b\n" +
+	"\tqueue_ttl\x18\a \x01(\x05R\bqueueTtl\x12\x0e\n" +
+	"\x02id\x18\b \x01(\tR\x02id\"#\n" +
+	"\x11CommandIdentifier\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xdc\x02\n" +
+	"\rCommandStatus\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x16\n" +
+	"\x06result\x18\x05 \x01(\tR\x06result\x12\x12\n" +
+	"\x04code\x18\x06 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\a \x01(\tR\amessage\x12\x1a\n" +
+	"\bprotocol\x18\b \x01(\tR\bprotocol\x12\x17\n" +
+	"\aport_id\x18\t \x01(\tR\x06portId\x12\x1a\n" +
+	"\battempts\x18\n" +
+	" \x01(\x05R\battempts\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\v \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\f \x01(\x03R\tupdatedAt\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\r \x01(\x03R\texpiresAt\"9\n" +
+	"\x1aListPendingCommandsRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\"O\n" +
+	"\x1bListPendingCommandsResponse\x120\n" +
+	"\bcommands\x18\x01 \x03(\v2\x14.proto.CommandStatusR\bcommands\"6\n" +
+	"\x0fListBansRequest\x12#\n" +
+	"\rprotocol_name\x18\x01 \x01(\tR\fprotocolName\"\x80\x01\n" +
+	"\aBanInfo\x12\x0e\n" +
+	"\x02ip\x18\x01 \x01(\tR\x02ip\x12\x17\n" +
+	"\aport_id\x18\x02 \x01(\tR\x06portId\x12\x1a\n" +
+	"\bprotocol\x18\x03 \x01(\tR\bprotocol\x12\x14\n" +
+	"\x05until\x18\x04 \x01(\x03R\x05until\x12\x1a\n" +
+	"\bfailures\x18\x05 \x01(\x05R\bfailures\"6\n" +
+	"\x10ListBansResponse\x12\"\n" +
+	"\x04bans\x18\x01 \x03(\v2\x0e.proto.BanInfoR\x04bans\"C\n" +
+	"\fUnbanRequest\x12\x0e\n" +
+	"\x02ip\x18\x01 \x01(\tR\x02ip\x12#\n" +
+	"\rprotocol_name\x18\x02 \x01(\tR\fprotocolName\"t\n" +
+	"\x10RegisteredDevice\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x12\x1a\n" +
+	"\bcustomer\x18\x03 \x01(\tR\bcustomer\x12\x18\n" +
+	"\aenabled\x18\x04 \x01(\bR\aenabled\"0\n" +
+	"\x12ListDevicesRequest\x12\x1a\n" +
+	"\bcustomer\x18\x01 \x01(\tR\bcustomer\"H\n" +
+	"\x13ListDevicesResponse\x121\n" +
+	"\adevices\x18\x01 \x03(\v2\x17.proto.RegisteredDeviceR\adevices\"\x16\n" +
+	"\x14ReloadDevicesRequest\"-\n" +
+	"\x15ReloadDevicesResponse\x12\x14\n" +
+	"\x05count\x18\x01 \x01(\x05R\x05count\"o\n" +
+	"\x11SetCaptureRequest\x12#\n" +
+	"\rprotocol_name\x18\x01 \x01(\tR\fprotocolName\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12\x18\n" +
+	"\aenabled\x18\x03 \x01(\bR\aenabled\"\x13\n" +
+	"\x11GetCaptureRequest\"[\n" +
+	"\rCaptureStatus\x12\x10\n" +
+	"\x03dir\x18\x01 \x01(\tR\x03dir\x12\x19\n" +
+	"\bport_ids\x18\x02 \x03(\tR\aportIds\x12\x1d\n" +
+	"\n" +
+	"device_ids\x18\x03 \x03(\tR\tdeviceIds2\xe5\f\n" +
 	"\x0fReceiverControl\x12D\n" +
 	"\vSetLogLevel\x12\x19.proto.SetLogLevelRequest\x1a\x1a.proto.SetLogLevelResponse\x12>\n" +
 	"\tGetStatus\x12\x17.proto.GetStatusRequest\x1a\x18.proto.GetStatusResponse\x12R\n" +
 	"\x19GetActiveConnectionsCount\x12\x18.proto.GetClientsRequest\x1a\x1b.google.protobuf.Int32Value\x12J\n" +
 	"\x13GetConnectedClients\x12\x18.proto.GetClientsRequest\x1a\x19.proto.GetClientsResponse\x12S\n" +
-	"\x10DisconnectClient\x12\x1e.proto.DisconnectClientRequest\x1a\x1f.proto.DisconnectClientResponse\x12?\n" +
+	"\x10DisconnectClient\x12\x1e.proto.DisconnectClientRequest\x1a\x1f.proto.DisconnectClientResponse\x12@\n" +
+	"\n" +
+	"FindDevice\x12\x17.proto.DeviceIdentifier\x1a\x19.proto.GetClientsResponse\x12S\n" +
+	"\x10DisconnectDevice\x12\x1e.proto.DisconnectDeviceRequest\x1a\x1f.proto.DisconnectClientResponse\x12?\n" +
 	"\bOpenPort\x12\x15.proto.PortIdentifier\x1a\x1c.proto.PortOperationResponse\x12@\n" +
 	"\tClosePort\x12\x15.proto.PortIdentifier\x1a\x1c.proto.PortOperationResponse\x12>\n" +
 	"\aAddPort\x12\x15.proto.PortDefinition\x1a\x1c.proto.PortOperationResponse\x12A\n" +
 	"\n" +
-	"DeletePort\x12\x15.proto.PortIdentifier\x1a\x1c.proto.PortOperationResponseB\x18Z\x16NavControlSystem/protob\x06proto3"
+	"DeletePort\x12\x15.proto.PortIdentifier\x1a\x1c.proto.PortOperationResponse\x12F\n" +
+	"\x13SendCommandToDevice\x12\x19.proto.SendCommandRequest\x1a\x14.proto.CommandStatus\x12B\n" +
+	"\x10GetCommandStatus\x12\x18.proto.CommandIdentifier\x1a\x14.proto.CommandStatus\x12\\\n" +
+	"\x13ListPendingCommands\x12!.proto.ListPendingCommandsRequest\x1a\".proto.ListPendingCommandsResponse\x12;\n" +
+	"\bListBans\x12\x16.proto.ListBansRequest\x1a\x17.proto.ListBansResponse\x12=\n" +
+	"\x05Unban\x12\x13.proto.UnbanRequest\x1a\x1f.proto.DisconnectClientResponse\x12D\n" +
+	"\vListDevices\x12\x19.proto.ListDevicesRequest\x1a\x1a.proto.ListDevicesResponse\x12=\n" +
+	"\tGetDevice\x12\x17.proto.DeviceIdentifier\x1a\x17.proto.RegisteredDevice\x12=\n" +
+	"\tPutDevice\x12\x17.proto.RegisteredDevice\x1a\x17.proto.RegisteredDevice\x12H\n" +
+	"\fDeleteDevice\x12\x17.proto.DeviceIdentifier\x1a\x1f.proto.DisconnectClientResponse\x12J\n" +
+	"\rReloadDevices\x12\x1b.proto.ReloadDevicesRequest\x1a\x1c.proto.ReloadDevicesResponse\x12<\n" +
+	"\n" +
+	"SetCapture\x12\x18.proto.SetCaptureRequest\x1a\x14.proto.CaptureStatus\x12<\n" +
+	"\n" +
+	"GetCapture\x12\x18.proto.GetCaptureRequest\x1a\x14.proto.CaptureStatusB\x18Z\x16NavControlSystem/protob\x06proto3"
 
 var (
 	file_receiver_proto_rawDescOnce sync.Once
@@ -640,50 +2216,111 @@ func file_receiver_proto_rawDescGZIP() []byte {
 	return file_receiver_proto_rawDescData
 }
 
-var file_receiver_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_receiver_proto_msgTypes = make([]protoimpl.MessageInfo, 35)
 var file_receiver_proto_goTypes = []any{
-	(*GetStatusRequest)(nil),         // 0: proto.GetStatusRequest
-	(*GetStatusResponse)(nil),        // 1: proto.GetStatusResponse
-	(*PortStatus)(nil),               // 2: proto.PortStatus
-	(*GetClientsRequest)(nil),        // 3: proto.GetClientsRequest
-	(*ClientInfo)(nil),               // 4: proto.ClientInfo
-	(*GetClientsResponse)(nil),       // 5: proto.GetClientsResponse
-	(*DisconnectClientRequest)(nil),  // 6: proto.DisconnectClientRequest
-	(*DisconnectClientResponse)(nil), // 7: proto.DisconnectClientResponse
-	(*PortIdentifier)(nil),           // 8: proto.PortIdentifier
-	(*PortDefinition)(nil),           // 9: proto.PortDefinition
-	(*PortOperationResponse)(nil),    // 10: proto.PortOperationResponse
-	(*SetLogLevelRequest)(nil),       // 11: proto.SetLogLevelRequest
-	(*SetLogLevelResponse)(nil),      // 12: proto.SetLogLevelResponse
-	(*wrappers.Int32Value)(nil),      // 13: google.protobuf.Int32Value
+	(*GetStatusRequest)(nil),            // 0: proto.GetStatusRequest
+	(*GetStatusResponse)(nil),           // 1: proto.GetStatusResponse
+	(*ProtocolInfo)(nil),                // 2: proto.ProtocolInfo
+	(*ProtocolOption)(nil),              // 3: proto.ProtocolOption
+	(*PortStatus)(nil),                  // 4: proto.PortStatus
+	(*PortTLS)(nil),                     // 5: proto.PortTLS
+	(*GetClientsRequest)(nil),           // 6: proto.GetClientsRequest
+	(*ClientInfo)(nil),                  // 7: proto.ClientInfo
+	(*GetClientsResponse)(nil),          // 8: proto.GetClientsResponse
+	(*DisconnectClientRequest)(nil),     // 9: proto.DisconnectClientRequest
+	(*DisconnectClientResponse)(nil),    // 10: proto.DisconnectClientResponse
+	(*DeviceIdentifier)(nil),            // 11: proto.DeviceIdentifier
+	(*DisconnectDeviceRequest)(nil),     // 12: proto.DisconnectDeviceRequest
+	(*PortIdentifier)(nil),              // 13: proto.PortIdentifier
+	(*PortDefinition)(nil),              // 14: proto.PortDefinition
+	(*PortOperationResponse)(nil),       // 15: proto.PortOperationResponse
+	(*SendCommandRequest)(nil),          // 16: proto.SendCommandRequest
+	(*CommandIdentifier)(nil),           // 17: proto.CommandIdentifier
+	(*CommandStatus)(nil),               // 18: proto.CommandStatus
+	(*ListPendingCommandsRequest)(nil),  // 19: proto.ListPendingCommandsRequest
+	(*ListPendingCommandsResponse)(nil), // 20: proto.ListPendingCommandsResponse
+	(*ListBansRequest)(nil),             // 21: proto.ListBansRequest
+	(*BanInfo)(nil),                     // 22: proto.BanInfo
+	(*ListBansResponse)(nil),            // 23: proto.ListBansResponse
+	(*UnbanRequest)(nil),                // 24: proto.UnbanRequest
+	(*RegisteredDevice)(nil),            // 25: proto.RegisteredDevice
+	(*ListDevicesRequest)(nil),          // 26: proto.ListDevicesRequest
+	(*ListDevicesResponse)(nil),         // 27: proto.ListDevicesResponse
+	(*ReloadDevicesRequest)(nil),        // 28: proto.ReloadDevicesRequest
+	(*ReloadDevicesResponse)(nil),       // 29: proto.ReloadDevicesResponse
+	(*SetCaptureRequest)(nil),           // 30: proto.SetCaptureRequest
+	(*GetCaptureRequest)(nil),           // 31: proto.GetCaptureRequest
+	(*CaptureStatus)(nil),               // 32: proto.CaptureStatus
+	nil,                                 // 33: proto.PortStatus.OptionsEntry
+	nil,                                 // 34: proto.PortDefinition.OptionsEntry
+	(*SetLogLevelRequest)(nil),          // 35: proto.SetLogLevelRequest
+	(*SetLogLevelResponse)(nil),         // 36: proto.SetLogLevelResponse
+	(*wrappers.Int32Value)(nil),         // 37: google.protobuf.Int32Value
 }
 var file_receiver_proto_depIdxs = []int32{
-	2,  // 0: proto.GetStatusResponse.ports:type_name -> proto.PortStatus
-	4,  // 1: proto.GetClientsResponse.clients:type_name -> proto.ClientInfo
-	9,  // 2: proto.PortOperationResponse.port_details:type_name -> proto.PortDefinition
-	11, // 3: proto.ReceiverControl.SetLogLevel:input_type -> proto.SetLogLevelRequest
-	0,  // 4: proto.ReceiverControl.GetStatus:input_type -> proto.GetStatusRequest
-	3,  // 5: proto.ReceiverControl.GetActiveConnectionsCount:input_type -> proto.GetClientsRequest
-	3,  // 6: proto.ReceiverControl.GetConnectedClients:input_type -> proto.GetClientsRequest
-	6,  // 7: proto.ReceiverControl.DisconnectClient:input_type -> proto.DisconnectClientRequest
-	8,  // 8: proto.ReceiverControl.OpenPort:input_type -> proto.PortIdentifier
-	8,  // 9: proto.ReceiverControl.ClosePort:input_type -> proto.PortIdentifier
-	9,  // 10: proto.ReceiverControl.AddPort:input_type -> proto.PortDefinition
-	8,  // 11: proto.ReceiverControl.DeletePort:input_type -> proto.PortIdentifier
-	12, // 12: proto.ReceiverControl.SetLogLevel:output_type -> proto.SetLogLevelResponse
-	1,  // 13: proto.ReceiverControl.GetStatus:output_type -> proto.GetStatusResponse
-	13, // 14: proto.ReceiverControl.GetActiveConnectionsCount:output_type -> google.protobuf.Int32Value
-	5,  // 15: proto.ReceiverControl.GetConnectedClients:output_type -> proto.GetClientsResponse
-	7,  // 16: proto.ReceiverControl.DisconnectClient:output_type -> proto.DisconnectClientResponse
-	10, // 17: proto.ReceiverControl.OpenPort:output_type -> proto.PortOperationResponse
-	10, // 18: proto.ReceiverControl.ClosePort:output_type -> proto.PortOperationResponse
-	10, // 19: proto.ReceiverControl.AddPort:output_type -> proto.PortOperationResponse
-	10, // 20: proto.ReceiverControl.DeletePort:output_type -> proto.PortOperationResponse
-	12, // [12:21] is the sub-list for method output_type
-	3,  // [3:12] is the sub-list for method input_type
-	3,  // [3:3] is the sub-list for extension type_name
-	3,  // [3:3] is the sub-list for extension extendee
-	0,  // [0:3] is the sub-list for field type_name
+	4,  // 0: proto.GetStatusResponse.ports:type_name -> proto.PortStatus
+	2,  // 1: proto.GetStatusResponse.protocols:type_name -> proto.ProtocolInfo
+	3,  // 2: proto.ProtocolInfo.options:type_name -> proto.ProtocolOption
+	33, // 3: proto.PortStatus.options:type_name -> proto.PortStatus.OptionsEntry
+	5,  // 4: proto.PortStatus.tls:type_name -> proto.PortTLS
+	7,  // 5: proto.GetClientsResponse.clients:type_name -> proto.ClientInfo
+	34, // 6: proto.PortDefinition.options:type_name -> proto.PortDefinition.OptionsEntry
+	5,  // 7: proto.PortDefinition.tls:type_name -> proto.PortTLS
+	14, // 8: proto.PortOperationResponse.port_details:type_name -> proto.PortDefinition
+	18, // 9: proto.ListPendingCommandsResponse.commands:type_name -> proto.CommandStatus
+	22, // 10: proto.ListBansResponse.bans:type_name -> proto.BanInfo
+	25, // 11: proto.ListDevicesResponse.devices:type_name -> proto.RegisteredDevice
+	35, // 12: proto.ReceiverControl.SetLogLevel:input_type -> proto.SetLogLevelRequest
+	0,  // 13: proto.ReceiverControl.GetStatus:input_type -> proto.GetStatusRequest
+	6,  // 14: proto.ReceiverControl.GetActiveConnectionsCount:input_type -> proto.GetClientsRequest
+	6,  // 15: proto.ReceiverControl.GetConnectedClients:input_type -> proto.GetClientsRequest
+	9,  // 16: proto.ReceiverControl.DisconnectClient:input_type -> proto.DisconnectClientRequest
+	11, // 17: proto.ReceiverControl.FindDevice:input_type -> proto.DeviceIdentifier
+	12, // 18: proto.ReceiverControl.DisconnectDevice:input_type -> proto.DisconnectDeviceRequest
+	13, // 19: proto.ReceiverControl.OpenPort:input_type -> proto.PortIdentifier
+	13, // 20: proto.ReceiverControl.ClosePort:input_type -> proto.PortIdentifier
+	14, // 21: proto.ReceiverControl.AddPort:input_type -> proto.PortDefinition
+	13, // 22: proto.ReceiverControl.DeletePort:input_type -> proto.PortIdentifier
+	16, // 23: proto.ReceiverControl.SendCommandToDevice:input_type -> proto.SendCommandRequest
+	17, // 24: proto.ReceiverControl.GetCommandStatus:input_type -> proto.CommandIdentifier
+	19, // 25: proto.ReceiverControl.ListPendingCommands:input_type -> proto.ListPendingCommandsRequest
+	21, // 26: proto.ReceiverControl.ListBans:input_type -> proto.ListBansRequest
+	24, // 27: proto.ReceiverControl.Unban:input_type -> proto.UnbanRequest
+	26, // 28: proto.ReceiverControl.ListDevices:input_type -> proto.ListDevicesRequest
+	11, // 29: proto.ReceiverControl.GetDevice:input_type -> proto.DeviceIdentifier
+	25, // 30: proto.ReceiverControl.PutDevice:input_type -> proto.RegisteredDevice
+	11, // 31: proto.ReceiverControl.DeleteDevice:input_type -> proto.DeviceIdentifier
+	28, // 32: proto.ReceiverControl.ReloadDevices:input_type -> proto.ReloadDevicesRequest
+	30, // 33: proto.ReceiverControl.SetCapture:input_type -> proto.SetCaptureRequest
+	31, // 34: proto.ReceiverControl.GetCapture:input_type -> proto.GetCaptureRequest
+	36, // 35: proto.ReceiverControl.SetLogLevel:output_type -> proto.SetLogLevelResponse
+	1,  // 36: proto.ReceiverControl.GetStatus:output_type -> proto.GetStatusResponse
+	37, // 37: proto.ReceiverControl.GetActiveConnectionsCount:output_type -> google.protobuf.Int32Value
+	8,  // 38: proto.ReceiverControl.GetConnectedClients:output_type -> proto.GetClientsResponse
+	10, // 39: proto.ReceiverControl.DisconnectClient:output_type -> proto.DisconnectClientResponse
+	8,  // 40: proto.ReceiverControl.FindDevice:output_type -> proto.GetClientsResponse
+	10, // 41: proto.ReceiverControl.DisconnectDevice:output_type -> proto.DisconnectClientResponse
+	15, // 42: proto.ReceiverControl.OpenPort:output_type -> proto.PortOperationResponse
+	15, // 43: proto.ReceiverControl.ClosePort:output_type -> proto.PortOperationResponse
+	15, // 44: proto.ReceiverControl.AddPort:output_type -> proto.PortOperationResponse
+	15, // 45: proto.ReceiverControl.DeletePort:output_type -> proto.PortOperationResponse
+	18, // 46: proto.ReceiverControl.SendCommandToDevice:output_type -> proto.CommandStatus
+	18, // 47: proto.ReceiverControl.GetCommandStatus:output_type -> proto.CommandStatus
+	20, // 48: proto.ReceiverControl.ListPendingCommands:output_type -> proto.ListPendingCommandsResponse
+	23, // 49: proto.ReceiverControl.ListBans:output_type -> proto.ListBansResponse
+	10, // 50: proto.ReceiverControl.Unban:output_type -> proto.DisconnectClientResponse
+	27, // 51: proto.ReceiverControl.ListDevices:output_type -> proto.ListDevicesResponse
+	25, // 52: proto.ReceiverControl.GetDevice:output_type -> proto.RegisteredDevice
+	25, // 53: proto.ReceiverControl.PutDevice:output_type -> proto.RegisteredDevice
+	10, // 54: proto.ReceiverControl.DeleteDevice:output_type -> proto.DisconnectClientResponse
+	29, // 55: proto.ReceiverControl.ReloadDevices:output_type -> proto.ReloadDevicesResponse
+	32, // 56: proto.ReceiverControl.SetCapture:output_type -> proto.CaptureStatus
+	32, // 57: proto.ReceiverControl.GetCapture:output_type -> proto.CaptureStatus
+	35, // [35:58] is the sub-list for method output_type
+	12, // [12:35] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
 }
 
 func init() { file_receiver_proto_init() }
@@ -698,7 +2335,7 @@ func file_receiver_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_receiver_proto_rawDesc), len(file_receiver_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   11,
+			NumMessages:   35,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
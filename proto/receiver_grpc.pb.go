@@ -25,10 +25,24 @@ const (
 	ReceiverControl_GetActiveConnectionsCount_FullMethodName = "/proto.ReceiverControl/GetActiveConnectionsCount"
 	ReceiverControl_GetConnectedClients_FullMethodName       = "/proto.ReceiverControl/GetConnectedClients"
 	ReceiverControl_DisconnectClient_FullMethodName          = "/proto.ReceiverControl/DisconnectClient"
+	ReceiverControl_FindDevice_FullMethodName                = "/proto.ReceiverControl/FindDevice"
+	ReceiverControl_DisconnectDevice_FullMethodName          = "/proto.ReceiverControl/DisconnectDevice"
 	ReceiverControl_OpenPort_FullMethodName                  = "/proto.ReceiverControl/OpenPort"
 	ReceiverControl_ClosePort_FullMethodName                 = "/proto.ReceiverControl/ClosePort"
 	ReceiverControl_AddPort_FullMethodName                   = "/proto.ReceiverControl/AddPort"
 	ReceiverControl_DeletePort_FullMethodName                = "/proto.ReceiverControl/DeletePort"
+	ReceiverControl_SendCommandToDevice_FullMethodName       = "/proto.ReceiverControl/SendCommandToDevice"
+	ReceiverControl_GetCommandStatus_FullMethodName          = "/proto.ReceiverControl/GetCommandStatus"
+	ReceiverControl_ListPendingCommands_FullMethodName       = "/proto.ReceiverControl/ListPendingCommands"
+	ReceiverControl_ListBans_FullMethodName                  = "/proto.ReceiverControl/ListBans"
+	ReceiverControl_Unban_FullMethodName                     = "/proto.ReceiverControl/Unban"
+	ReceiverControl_ListDevices_FullMethodName               = "/proto.ReceiverControl/ListDevices"
+	ReceiverControl_GetDevice_FullMethodName                 = "/proto.ReceiverControl/GetDevice"
+	ReceiverControl_PutDevice_FullMethodName                 = "/proto.ReceiverControl/PutDevice"
+	ReceiverControl_DeleteDevice_FullMethodName              = "/proto.ReceiverControl/DeleteDevice"
+	ReceiverControl_ReloadDevices_FullMethodName             = "/proto.ReceiverControl/ReloadDevices"
+	ReceiverControl_SetCapture_FullMethodName                = "/proto.ReceiverControl/SetCapture"
+	ReceiverControl_GetCapture_FullMethodName                = "/proto.ReceiverControl/GetCapture"
 )
 
 // ReceiverControlClient is the client API for ReceiverControl service.
@@ -47,6 +61,10 @@ type ReceiverControlClient interface {
 	GetConnectedClients(ctx context.Context, in *GetClientsRequest, opts ...grpc.CallOption) (*GetClientsResponse, error)
 	// Отключить клиента
 	DisconnectClient(ctx context.Context, in *DisconnectClientRequest, opts ...grpc.CallOption) (*DisconnectClientResponse, error)
+	// Найти подключения устройства по его ID (IMEI) на всех портах
+	FindDevice(ctx context.Context, in *DeviceIdentifier, opts ...grpc.CallOption) (*GetClientsResponse, error)
+	// Отключить устройство по его ID (IMEI)
+	DisconnectDevice(ctx context.Context, in *DisconnectDeviceRequest, opts ...grpc.CallOption) (*DisconnectClientResponse, error)
 	// Открыть порт (сделать active=true)
 	OpenPort(ctx context.Context, in *PortIdentifier, opts ...grpc.CallOption) (*PortOperationResponse, error)
 	// Закрыть порт (сделать active=false)
@@ -55,6 +73,29 @@ type ReceiverControlClient interface {
 	AddPort(ctx context.Context, in *PortDefinition, opts ...grpc.CallOption) (*PortOperationResponse, error)
 	// Удалить порт из конфигурации
 	DeletePort(ctx context.Context, in *PortIdentifier, opts ...grpc.CallOption) (*PortOperationResponse, error)
+	// Отправить команду устройству и дождаться ответа. Если устройство не подключено,
+	// команда ставится в очередь и передается при его следующей авторизации
+	SendCommandToDevice(ctx context.Context, in *SendCommandRequest, opts ...grpc.CallOption) (*CommandStatus, error)
+	// Получить статус команды
+	GetCommandStatus(ctx context.Context, in *CommandIdentifier, opts ...grpc.CallOption) (*CommandStatus, error)
+	// Получить список команд, ожидающих подключения устройства или его ответа
+	ListPendingCommands(ctx context.Context, in *ListPendingCommandsRequest, opts ...grpc.CallOption) (*ListPendingCommandsResponse, error)
+	// Получить IP-адреса, заблокированные после неудачных авторизаций
+	ListBans(ctx context.Context, in *ListBansRequest, opts ...grpc.CallOption) (*ListBansResponse, error)
+	// Снять блокировку IP-адреса
+	Unban(ctx context.Context, in *UnbanRequest, opts ...grpc.CallOption) (*DisconnectClientResponse, error)
+	// Реестр устройств, допущенных к портам
+	ListDevices(ctx context.Context, in *ListDevicesRequest, opts ...grpc.CallOption) (*ListDevicesResponse, error)
+	GetDevice(ctx context.Context, in *DeviceIdentifier, opts ...grpc.CallOption) (*RegisteredDevice, error)
+	// Добавить устройство или изменить его запись
+	PutDevice(ctx context.Context, in *RegisteredDevice, opts ...grpc.CallOption) (*RegisteredDevice, error)
+	DeleteDevice(ctx context.Context, in *DeviceIdentifier, opts ...grpc.CallOption) (*DisconnectClientResponse, error)
+	// Перечитать файл реестра
+	ReloadDevices(ctx context.Context, in *ReloadDevicesRequest, opts ...grpc.CallOption) (*ReloadDevicesResponse, error)
+	// Включить или выключить запись сырого трафика порта или устройства (воспроизведение - receiver replay)
+	SetCapture(ctx context.Context, in *SetCaptureRequest, opts ...grpc.CallOption) (*CaptureStatus, error)
+	// Получить, какие порты и устройства записываются
+	GetCapture(ctx context.Context, in *GetCaptureRequest, opts ...grpc.CallOption) (*CaptureStatus, error)
 }
 
 type receiverControlClient struct {
@@ -115,6 +156,26 @@ func (c *receiverControlClient) DisconnectClient(ctx context.Context, in *Discon
 	return out, nil
 }
 
+func (c *receiverControlClient) FindDevice(ctx context.Context, in *DeviceIdentifier, opts ...grpc.CallOption) (*GetClientsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetClientsResponse)
+	err := c.cc.Invoke(ctx, ReceiverControl_FindDevice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *receiverControlClient) DisconnectDevice(ctx context.Context, in *DisconnectDeviceRequest, opts ...grpc.CallOption) (*DisconnectClientResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DisconnectClientResponse)
+	err := c.cc.Invoke(ctx, ReceiverControl_DisconnectDevice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *receiverControlClient) OpenPort(ctx context.Context, in *PortIdentifier, opts ...grpc.CallOption) (*PortOperationResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(PortOperationResponse)
@@ -155,6 +216,126 @@ func (c *receiverControlClient) DeletePort(ctx context.Context, in *PortIdentifi
 	return out, nil
 }
 
+func (c *receiverControlClient) SendCommandToDevice(ctx context.Context, in *SendCommandRequest, opts ...grpc.CallOption) (*CommandStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CommandStatus)
+	err := c.cc.Invoke(ctx, ReceiverControl_SendCommandToDevice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *receiverControlClient) GetCommandStatus(ctx context.Context, in *CommandIdentifier, opts ...grpc.CallOption) (*CommandStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CommandStatus)
+	err := c.cc.Invoke(ctx, ReceiverControl_GetCommandStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *receiverControlClient) ListPendingCommands(ctx context.Context, in *ListPendingCommandsRequest, opts ...grpc.CallOption) (*ListPendingCommandsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPendingCommandsResponse)
+	err := c.cc.Invoke(ctx, ReceiverControl_ListPendingCommands_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *receiverControlClient) ListBans(ctx context.Context, in *ListBansRequest, opts ...grpc.CallOption) (*ListBansResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBansResponse)
+	err := c.cc.Invoke(ctx, ReceiverControl_ListBans_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *receiverControlClient) Unban(ctx context.Context, in *UnbanRequest, opts ...grpc.CallOption) (*DisconnectClientResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DisconnectClientResponse)
+	err := c.cc.Invoke(ctx, ReceiverControl_Unban_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *receiverControlClient) ListDevices(ctx context.Context, in *ListDevicesRequest, opts ...grpc.CallOption) (*ListDevicesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDevicesResponse)
+	err := c.cc.Invoke(ctx, ReceiverControl_ListDevices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *receiverControlClient) GetDevice(ctx context.Context, in *DeviceIdentifier, opts ...grpc.CallOption) (*RegisteredDevice, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisteredDevice)
+	err := c.cc.Invoke(ctx, ReceiverControl_GetDevice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *receiverControlClient) PutDevice(ctx context.Context, in *RegisteredDevice, opts ...grpc.CallOption) (*RegisteredDevice, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisteredDevice)
+	err := c.cc.Invoke(ctx, ReceiverControl_PutDevice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *receiverControlClient) DeleteDevice(ctx context.Context, in *DeviceIdentifier, opts ...grpc.CallOption) (*DisconnectClientResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DisconnectClientResponse)
+	err := c.cc.Invoke(ctx, ReceiverControl_DeleteDevice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *receiverControlClient) ReloadDevices(ctx context.Context, in *ReloadDevicesRequest, opts ...grpc.CallOption) (*ReloadDevicesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReloadDevicesResponse)
+	err := c.cc.Invoke(ctx, ReceiverControl_ReloadDevices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *receiverControlClient) SetCapture(ctx context.Context, in *SetCaptureRequest, opts ...grpc.CallOption) (*CaptureStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CaptureStatus)
+	err := c.cc.Invoke(ctx, ReceiverControl_SetCapture_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *receiverControlClient) GetCapture(ctx context.Context, in *GetCaptureRequest, opts ...grpc.CallOption) (*CaptureStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CaptureStatus)
+	err := c.cc.Invoke(ctx, ReceiverControl_GetCapture_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ReceiverControlServer is the server API for ReceiverControl service.
 // All implementations must embed UnimplementedReceiverControlServer
 // for forward compatibility.
@@ -171,6 +352,10 @@ type ReceiverControlServer interface {
 	GetConnectedClients(context.Context, *GetClientsRequest) (*GetClientsResponse, error)
 	// Отключить клиента
 	DisconnectClient(context.Context, *DisconnectClientRequest) (*DisconnectClientResponse, error)
+	// Найти подключения устройства по его ID (IMEI) на всех портах
+	FindDevice(context.Context, *DeviceIdentifier) (*GetClientsResponse, error)
+	// Отключить устройство по его ID (IMEI)
+	DisconnectDevice(context.Context, *DisconnectDeviceRequest) (*DisconnectClientResponse, error)
 	// Открыть порт (сделать active=true)
 	OpenPort(context.Context, *PortIdentifier) (*PortOperationResponse, error)
 	// Закрыть порт (сделать active=false)
@@ -179,6 +364,29 @@ type ReceiverControlServer interface {
 	AddPort(context.Context, *PortDefinition) (*PortOperationResponse, error)
 	// Удалить порт из конфигурации
 	DeletePort(context.Context, *PortIdentifier) (*PortOperationResponse, error)
+	// Отправить команду устройству и дождаться ответа. Если устройство не подключено,
+	// команда ставится в очередь и передается при его следующей авторизации
+	SendCommandToDevice(context.Context, *SendCommandRequest) (*CommandStatus, error)
+	// Получить статус команды
+	GetCommandStatus(context.Context, *CommandIdentifier) (*CommandStatus, error)
+	// Получить список команд, ожидающих подключения устройства или его ответа
+	ListPendingCommands(context.Context, *ListPendingCommandsRequest) (*ListPendingCommandsResponse, error)
+	// Получить IP-адреса, заблокированные после неудачных авторизаций
+	ListBans(context.Context, *ListBansRequest) (*ListBansResponse, error)
+	// Снять блокировку IP-адреса
+	Unban(context.Context, *UnbanRequest) (*DisconnectClientResponse, error)
+	// Реестр устройств, допущенных к портам
+	ListDevices(context.Context, *ListDevicesRequest) (*ListDevicesResponse, error)
+	GetDevice(context.Context, *DeviceIdentifier) (*RegisteredDevice, error)
+	// Добавить устройство или изменить его запись
+	PutDevice(context.Context, *RegisteredDevice) (*RegisteredDevice, error)
+	DeleteDevice(context.Context, *DeviceIdentifier) (*DisconnectClientResponse, error)
+	// Перечитать файл реестра
+	ReloadDevices(context.Context, *ReloadDevicesRequest) (*ReloadDevicesResponse, error)
+	// Включить или выключить запись сырого трафика порта или устройства (воспроизведение - receiver replay)
+	SetCapture(context.Context, *SetCaptureRequest) (*CaptureStatus, error)
+	// Получить, какие порты и устройства записываются
+	GetCapture(context.Context, *GetCaptureRequest) (*CaptureStatus, error)
 	mustEmbedUnimplementedReceiverControlServer()
 }
 
@@ -204,6 +412,12 @@ func (UnimplementedReceiverControlServer) GetConnectedClients(context.Context, *
 func (UnimplementedReceiverControlServer) DisconnectClient(context.Context, *DisconnectClientRequest) (*DisconnectClientResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DisconnectClient not implemented")
 }
+func (UnimplementedReceiverControlServer) FindDevice(context.Context, *DeviceIdentifier) (*GetClientsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindDevice not implemented")
+}
+func (UnimplementedReceiverControlServer) DisconnectDevice(context.Context, *DisconnectDeviceRequest) (*DisconnectClientResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisconnectDevice not implemented")
+}
 func (UnimplementedReceiverControlServer) OpenPort(context.Context, *PortIdentifier) (*PortOperationResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method OpenPort not implemented")
 }
@@ -216,6 +430,42 @@ func (UnimplementedReceiverControlServer) AddPort(context.Context, *PortDefiniti
 func (UnimplementedReceiverControlServer) DeletePort(context.Context, *PortIdentifier) (*PortOperationResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeletePort not implemented")
 }
+func (UnimplementedReceiverControlServer) SendCommandToDevice(context.Context, *SendCommandRequest) (*CommandStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendCommandToDevice not implemented")
+}
+func (UnimplementedReceiverControlServer) GetCommandStatus(context.Context, *CommandIdentifier) (*CommandStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCommandStatus not implemented")
+}
+func (UnimplementedReceiverControlServer) ListPendingCommands(context.Context, *ListPendingCommandsRequest) (*ListPendingCommandsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPendingCommands not implemented")
+}
+func (UnimplementedReceiverControlServer) ListBans(context.Context, *ListBansRequest) (*ListBansResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBans not implemented")
+}
+func (UnimplementedReceiverControlServer) Unban(context.Context, *UnbanRequest) (*DisconnectClientResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unban not implemented")
+}
+func (UnimplementedReceiverControlServer) ListDevices(context.Context, *ListDevicesRequest) (*ListDevicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDevices not implemented")
+}
+func (UnimplementedReceiverControlServer) GetDevice(context.Context, *DeviceIdentifier) (*RegisteredDevice, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDevice not implemented")
+}
+func (UnimplementedReceiverControlServer) PutDevice(context.Context, *RegisteredDevice) (*RegisteredDevice, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutDevice not implemented")
+}
+func (UnimplementedReceiverControlServer) DeleteDevice(context.Context, *DeviceIdentifier) (*DisconnectClientResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteDevice not implemented")
+}
+func (UnimplementedReceiverControlServer) ReloadDevices(context.Context, *ReloadDevicesRequest) (*ReloadDevicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReloadDevices not implemented")
+}
+func (UnimplementedReceiverControlServer) SetCapture(context.Context, *SetCaptureRequest) (*CaptureStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetCapture not implemented")
+}
+func (UnimplementedReceiverControlServer) GetCapture(context.Context, *GetCaptureRequest) (*CaptureStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCapture not implemented")
+}
 func (UnimplementedReceiverControlServer) mustEmbedUnimplementedReceiverControlServer() {}
 func (UnimplementedReceiverControlServer) testEmbeddedByValue()                         {}
 
@@ -327,6 +577,42 @@ func _ReceiverControl_DisconnectClient_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ReceiverControl_FindDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeviceIdentifier)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverControlServer).FindDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReceiverControl_FindDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverControlServer).FindDevice(ctx, req.(*DeviceIdentifier))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReceiverControl_DisconnectDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DisconnectDeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverControlServer).DisconnectDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReceiverControl_DisconnectDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverControlServer).DisconnectDevice(ctx, req.(*DisconnectDeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ReceiverControl_OpenPort_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(PortIdentifier)
 	if err := dec(in); err != nil {
@@ -399,6 +685,222 @@ func _ReceiverControl_DeletePort_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ReceiverControl_SendCommandToDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendCommandRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverControlServer).SendCommandToDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReceiverControl_SendCommandToDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverControlServer).SendCommandToDevice(ctx, req.(*SendCommandRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReceiverControl_GetCommandStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommandIdentifier)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverControlServer).GetCommandStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReceiverControl_GetCommandStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverControlServer).GetCommandStatus(ctx, req.(*CommandIdentifier))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReceiverControl_ListPendingCommands_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPendingCommandsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverControlServer).ListPendingCommands(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReceiverControl_ListPendingCommands_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverControlServer).ListPendingCommands(ctx, req.(*ListPendingCommandsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReceiverControl_ListBans_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBansRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverControlServer).ListBans(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReceiverControl_ListBans_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverControlServer).ListBans(ctx, req.(*ListBansRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReceiverControl_Unban_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnbanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverControlServer).Unban(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReceiverControl_Unban_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverControlServer).Unban(ctx, req.(*UnbanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReceiverControl_ListDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDevicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverControlServer).ListDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReceiverControl_ListDevices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverControlServer).ListDevices(ctx, req.(*ListDevicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReceiverControl_GetDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeviceIdentifier)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverControlServer).GetDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReceiverControl_GetDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverControlServer).GetDevice(ctx, req.(*DeviceIdentifier))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReceiverControl_PutDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisteredDevice)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverControlServer).PutDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReceiverControl_PutDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverControlServer).PutDevice(ctx, req.(*RegisteredDevice))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReceiverControl_DeleteDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeviceIdentifier)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverControlServer).DeleteDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReceiverControl_DeleteDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverControlServer).DeleteDevice(ctx, req.(*DeviceIdentifier))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReceiverControl_ReloadDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReloadDevicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverControlServer).ReloadDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReceiverControl_ReloadDevices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverControlServer).ReloadDevices(ctx, req.(*ReloadDevicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReceiverControl_SetCapture_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetCaptureRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverControlServer).SetCapture(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReceiverControl_SetCapture_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverControlServer).SetCapture(ctx, req.(*SetCaptureRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReceiverControl_GetCapture_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCaptureRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverControlServer).GetCapture(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReceiverControl_GetCapture_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverControlServer).GetCapture(ctx, req.(*GetCaptureRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ReceiverControl_ServiceDesc is the grpc.ServiceDesc for ReceiverControl service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -426,6 +928,14 @@ var ReceiverControl_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DisconnectClient",
 			Handler:    _ReceiverControl_DisconnectClient_Handler,
 		},
+		{
+			MethodName: "FindDevice",
+			Handler:    _ReceiverControl_FindDevice_Handler,
+		},
+		{
+			MethodName: "DisconnectDevice",
+			Handler:    _ReceiverControl_DisconnectDevice_Handler,
+		},
 		{
 			MethodName: "OpenPort",
 			Handler:    _ReceiverControl_OpenPort_Handler,
@@ -442,6 +952,54 @@ var ReceiverControl_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeletePort",
 			Handler:    _ReceiverControl_DeletePort_Handler,
 		},
+		{
+			MethodName: "SendCommandToDevice",
+			Handler:    _ReceiverControl_SendCommandToDevice_Handler,
+		},
+		{
+			MethodName: "GetCommandStatus",
+			Handler:    _ReceiverControl_GetCommandStatus_Handler,
+		},
+		{
+			MethodName: "ListPendingCommands",
+			Handler:    _ReceiverControl_ListPendingCommands_Handler,
+		},
+		{
+			MethodName: "ListBans",
+			Handler:    _ReceiverControl_ListBans_Handler,
+		},
+		{
+			MethodName: "Unban",
+			Handler:    _ReceiverControl_Unban_Handler,
+		},
+		{
+			MethodName: "ListDevices",
+			Handler:    _ReceiverControl_ListDevices_Handler,
+		},
+		{
+			MethodName: "GetDevice",
+			Handler:    _ReceiverControl_GetDevice_Handler,
+		},
+		{
+			MethodName: "PutDevice",
+			Handler:    _ReceiverControl_PutDevice_Handler,
+		},
+		{
+			MethodName: "DeleteDevice",
+			Handler:    _ReceiverControl_DeleteDevice_Handler,
+		},
+		{
+			MethodName: "ReloadDevices",
+			Handler:    _ReceiverControl_ReloadDevices_Handler,
+		},
+		{
+			MethodName: "SetCapture",
+			Handler:    _ReceiverControl_SetCapture_Handler,
+		},
+		{
+			MethodName: "GetCapture",
+			Handler:    _ReceiverControl_GetCapture_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "receiver.proto",
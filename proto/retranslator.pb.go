@@ -0,0 +1,546 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        v3.12.4
+// source: retranslator.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetRetranslatorStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRetranslatorStatusRequest) Reset() {
+	*x = GetRetranslatorStatusRequest{}
+	mi := &file_retranslator_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRetranslatorStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRetranslatorStatusRequest) ProtoMessage() {}
+
+func (x *GetRetranslatorStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_retranslator_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRetranslatorStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetRetranslatorStatusRequest) Descriptor() ([]byte, []int) {
+	return file_retranslator_proto_rawDescGZIP(), []int{0}
+}
+
+type GetRetranslatorStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NatsConnected bool                   `protobuf:"varint,1,opt,name=nats_connected,json=natsConnected,proto3" json:"nats_connected,omitempty"`
+	Targets       []*TargetStatus        `protobuf:"bytes,2,rep,name=targets,proto3" json:"targets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRetranslatorStatusResponse) Reset() {
+	*x = GetRetranslatorStatusResponse{}
+	mi := &file_retranslator_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRetranslatorStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRetranslatorStatusResponse) ProtoMessage() {}
+
+func (x *GetRetranslatorStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_retranslator_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRetranslatorStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetRetranslatorStatusResponse) Descriptor() ([]byte, []int) {
+	return file_retranslator_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetRetranslatorStatusResponse) GetNatsConnected() bool {
+	if x != nil {
+		return x.NatsConnected
+	}
+	return false
+}
+
+func (x *GetRetranslatorStatusResponse) GetTargets() []*TargetStatus {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+type TargetDefinition struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`                                                                                                           // Уникальный идентификатор; пусто - будет сгенерирован
+	Address       string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`                                                                                                 // Адрес сервера host:port
+	AuthMode      string                 `protobuf:"bytes,3,opt,name=auth_mode,json=authMode,proto3" json:"auth_mode,omitempty"`                                                                               // dispatcher - одна сессия ТП, terminal - сессия на каждое устройство
+	DispatcherId  uint32                 `protobuf:"varint,4,opt,name=dispatcher_id,json=dispatcherId,proto3" json:"dispatcher_id,omitempty"`                                                                  // ID диспетчера для режима dispatcher
+	Description   string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`                                                                                         // Описание диспетчера
+	Devices       []string               `protobuf:"bytes,6,rep,name=devices,proto3" json:"devices,omitempty"`                                                                                                 // Передаваемые устройства (IMEI или ID); пусто - все
+	DeviceIds     map[string]uint32      `protobuf:"bytes,7,rep,name=device_ids,json=deviceIds,proto3" json:"device_ids,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // TID/OID на сервере по устройствам (IMEI или ID); без него - числовой ID устройства
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TargetDefinition) Reset() {
+	*x = TargetDefinition{}
+	mi := &file_retranslator_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TargetDefinition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TargetDefinition) ProtoMessage() {}
+
+func (x *TargetDefinition) ProtoReflect() protoreflect.Message {
+	mi := &file_retranslator_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TargetDefinition.ProtoReflect.Descriptor instead.
+func (*TargetDefinition) Descriptor() ([]byte, []int) {
+	return file_retranslator_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TargetDefinition) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TargetDefinition) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *TargetDefinition) GetAuthMode() string {
+	if x != nil {
+		return x.AuthMode
+	}
+	return ""
+}
+
+func (x *TargetDefinition) GetDispatcherId() uint32 {
+	if x != nil {
+		return x.DispatcherId
+	}
+	return 0
+}
+
+func (x *TargetDefinition) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *TargetDefinition) GetDevices() []string {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+func (x *TargetDefinition) GetDeviceIds() map[string]uint32 {
+	if x != nil {
+		return x.DeviceIds
+	}
+	return nil
+}
+
+type TargetIdentifier struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TargetIdentifier) Reset() {
+	*x = TargetIdentifier{}
+	mi := &file_retranslator_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TargetIdentifier) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TargetIdentifier) ProtoMessage() {}
+
+func (x *TargetIdentifier) ProtoReflect() protoreflect.Message {
+	mi := &file_retranslator_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TargetIdentifier.ProtoReflect.Descriptor instead.
+func (*TargetIdentifier) Descriptor() ([]byte, []int) {
+	return file_retranslator_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *TargetIdentifier) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type TargetOperationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`                                  // Описание результата или ошибки
+	TargetDetails *TargetDefinition      `protobuf:"bytes,3,opt,name=target_details,json=targetDetails,proto3" json:"target_details,omitempty"` // Детали добавленного/удаленного сервера
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TargetOperationResponse) Reset() {
+	*x = TargetOperationResponse{}
+	mi := &file_retranslator_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TargetOperationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TargetOperationResponse) ProtoMessage() {}
+
+func (x *TargetOperationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_retranslator_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TargetOperationResponse.ProtoReflect.Descriptor instead.
+func (*TargetOperationResponse) Descriptor() ([]byte, []int) {
+	return file_retranslator_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TargetOperationResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *TargetOperationResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *TargetOperationResponse) GetTargetDetails() *TargetDefinition {
+	if x != nil {
+		return x.TargetDetails
+	}
+	return nil
+}
+
+type TargetStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Target        *TargetDefinition      `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
+	Consumer      string                 `protobuf:"bytes,2,opt,name=consumer,proto3" json:"consumer,omitempty"`                                // имя durable-потребителя NATS
+	Sessions      uint32                 `protobuf:"varint,3,opt,name=sessions,proto3" json:"sessions,omitempty"`                               // открытых сессий ЕГТС
+	Pending       uint64                 `protobuf:"varint,4,opt,name=pending,proto3" json:"pending,omitempty"`                                 // записей в потоке, еще не доставленных потребителю
+	RecordsSent   uint64                 `protobuf:"varint,5,opt,name=records_sent,json=recordsSent,proto3" json:"records_sent,omitempty"`      // записей передано с момента запуска
+	PacketsSent   uint64                 `protobuf:"varint,6,opt,name=packets_sent,json=packetsSent,proto3" json:"packets_sent,omitempty"`      // пакетов подтверждено сервером
+	Retransmits   uint64                 `protobuf:"varint,7,opt,name=retransmits,proto3" json:"retransmits,omitempty"`                         // повторов пакетов без подтверждения
+	Errors        uint64                 `protobuf:"varint,8,opt,name=errors,proto3" json:"errors,omitempty"`                                   // неудачных попыток передачи
+	LastSendTime  int64                  `protobuf:"varint,9,opt,name=last_send_time,json=lastSendTime,proto3" json:"last_send_time,omitempty"` // время последней передачи (Unix timestamp в секундах), 0 - не было
+	LastError     string                 `protobuf:"bytes,10,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`            // последняя ошибка передачи
+	Skipped       uint64                 `protobuf:"varint,11,opt,name=skipped,proto3" json:"skipped,omitempty"`                                // записей пропущено: у устройства нет TID/OID на сервере
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TargetStatus) Reset() {
+	*x = TargetStatus{}
+	mi := &file_retranslator_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TargetStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TargetStatus) ProtoMessage() {}
+
+func (x *TargetStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_retranslator_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TargetStatus.ProtoReflect.Descriptor instead.
+func (*TargetStatus) Descriptor() ([]byte, []int) {
+	return file_retranslator_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TargetStatus) GetTarget() *TargetDefinition {
+	if x != nil {
+		return x.Target
+	}
+	return nil
+}
+
+func (x *TargetStatus) GetConsumer() string {
+	if x != nil {
+		return x.Consumer
+	}
+	return ""
+}
+
+func (x *TargetStatus) GetSessions() uint32 {
+	if x != nil {
+		return x.Sessions
+	}
+	return 0
+}
+
+func (x *TargetStatus) GetPending() uint64 {
+	if x != nil {
+		return x.Pending
+	}
+	return 0
+}
+
+func (x *TargetStatus) GetRecordsSent() uint64 {
+	if x != nil {
+		return x.RecordsSent
+	}
+	return 0
+}
+
+func (x *TargetStatus) GetPacketsSent() uint64 {
+	if x != nil {
+		return x.PacketsSent
+	}
+	return 0
+}
+
+func (x *TargetStatus) GetRetransmits() uint64 {
+	if x != nil {
+		return x.Retransmits
+	}
+	return 0
+}
+
+func (x *TargetStatus) GetErrors() uint64 {
+	if x != nil {
+		return x.Errors
+	}
+	return 0
+}
+
+func (x *TargetStatus) GetLastSendTime() int64 {
+	if x != nil {
+		return x.LastSendTime
+	}
+	return 0
+}
+
+func (x *TargetStatus) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *TargetStatus) GetSkipped() uint64 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
+var File_retranslator_proto protoreflect.FileDescriptor
+
+const file_retranslator_proto_rawDesc = "" +
+	"\n" +
+	"\x12retranslator.proto\x12\x05proto\x1a\rservice.proto\"\x1e\n" +
+	"\x1cGetRetranslatorStatusRequest\"u\n" +
+	"\x1dGetRetranslatorStatusResponse\x12%\n" +
+	"\x0enats_connected\x18\x01 \x01(\bR\rnatsConnected\x12-\n" +
+	"\atargets\x18\x02 \x03(\v2\x13.proto.TargetStatusR\atargets\"\xbf\x02\n" +
+	"\x10TargetDefinition\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12\x1b\n" +
+	"\tauth_mode\x18\x03 \x01(\tR\bauthMode\x12#\n" +
+	"\rdispatcher_id\x18\x04 \x01(\rR\fdispatcherId\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\x12\x18\n" +
+	"\adevices\x18\x06 \x03(\tR\adevices\x12E\n" +
+	"\n" +
+	"device_ids\x18\a \x03(\v2&.proto.TargetDefinition.DeviceIdsEntryR\tdeviceIds\x1a<\n" +
+	"\x0eDeviceIdsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\rR\x05value:\x028\x01\"\"\n" +
+	"\x10TargetIdentifier\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x8d\x01\n" +
+	"\x17TargetOperationResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12>\n" +
+	"\x0etarget_details\x18\x03 \x01(\v2\x17.proto.TargetDefinitionR\rtargetDetails\"\xf0\x02\n" +
+	"\fTargetStatus\x12/\n" +
+	"\x06target\x18\x01 \x01(\v2\x17.proto.TargetDefinitionR\x06target\x12\x1a\n" +
+	"\bconsumer\x18\x02 \x01(\tR\bconsumer\x12\x1a\n" +
+	"\bsessions\x18\x03 \x01(\rR\bsessions\x12\x18\n" +
+	"\apending\x18\x04 \x01(\x04R\apending\x12!\n" +
+	"\frecords_sent\x18\x05 \x01(\x04R\vrecordsSent\x12!\n" +
+	"\fpackets_sent\x18\x06 \x01(\x04R\vpacketsSent\x12 \n" +
+	"\vretransmits\x18\a \x01(\x04R\vretransmits\x12\x16\n" +
+	"\x06errors\x18\b \x01(\x04R\x06errors\x12$\n" +
+	"\x0elast_send_time\x18\t \x01(\x03R\flastSendTime\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\n" +
+	" \x01(\tR\tlastError\x12\x18\n" +
+	"\askipped\x18\v \x01(\x04R\askipped2\xce\x02\n" +
+	"\x13RetranslatorControl\x12D\n" +
+	"\vSetLogLevel\x12\x19.proto.SetLogLevelRequest\x1a\x1a.proto.SetLogLevelResponse\x12b\n" +
+	"\x15GetRetranslatorStatus\x12#.proto.GetRetranslatorStatusRequest\x1a$.proto.GetRetranslatorStatusResponse\x12D\n" +
+	"\tAddTarget\x12\x17.proto.TargetDefinition\x1a\x1e.proto.TargetOperationResponse\x12G\n" +
+	"\fRemoveTarget\x12\x17.proto.TargetIdentifier\x1a\x1e.proto.TargetOperationResponseB\x18Z\x16NavControlSystem/protob\x06proto3"
+
+var (
+	file_retranslator_proto_rawDescOnce sync.Once
+	file_retranslator_proto_rawDescData []byte
+)
+
+func file_retranslator_proto_rawDescGZIP() []byte {
+	file_retranslator_proto_rawDescOnce.Do(func() {
+		file_retranslator_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_retranslator_proto_rawDesc), len(file_retranslator_proto_rawDesc)))
+	})
+	return file_retranslator_proto_rawDescData
+}
+
+var file_retranslator_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_retranslator_proto_goTypes = []any{
+	(*GetRetranslatorStatusRequest)(nil),  // 0: proto.GetRetranslatorStatusRequest
+	(*GetRetranslatorStatusResponse)(nil), // 1: proto.GetRetranslatorStatusResponse
+	(*TargetDefinition)(nil),              // 2: proto.TargetDefinition
+	(*TargetIdentifier)(nil),              // 3: proto.TargetIdentifier
+	(*TargetOperationResponse)(nil),       // 4: proto.TargetOperationResponse
+	(*TargetStatus)(nil),                  // 5: proto.TargetStatus
+	nil,                                   // 6: proto.TargetDefinition.DeviceIdsEntry
+	(*SetLogLevelRequest)(nil),            // 7: proto.SetLogLevelRequest
+	(*SetLogLevelResponse)(nil),           // 8: proto.SetLogLevelResponse
+}
+var file_retranslator_proto_depIdxs = []int32{
+	5, // 0: proto.GetRetranslatorStatusResponse.targets:type_name -> proto.TargetStatus
+	6, // 1: proto.TargetDefinition.device_ids:type_name -> proto.TargetDefinition.DeviceIdsEntry
+	2, // 2: proto.TargetOperationResponse.target_details:type_name -> proto.TargetDefinition
+	2, // 3: proto.TargetStatus.target:type_name -> proto.TargetDefinition
+	7, // 4: proto.RetranslatorControl.SetLogLevel:input_type -> proto.SetLogLevelRequest
+	0, // 5: proto.RetranslatorControl.GetRetranslatorStatus:input_type -> proto.GetRetranslatorStatusRequest
+	2, // 6: proto.RetranslatorControl.AddTarget:input_type -> proto.TargetDefinition
+	3, // 7: proto.RetranslatorControl.RemoveTarget:input_type -> proto.TargetIdentifier
+	8, // 8: proto.RetranslatorControl.SetLogLevel:output_type -> proto.SetLogLevelResponse
+	1, // 9: proto.RetranslatorControl.GetRetranslatorStatus:output_type -> proto.GetRetranslatorStatusResponse
+	4, // 10: proto.RetranslatorControl.AddTarget:output_type -> proto.TargetOperationResponse
+	4, // 11: proto.RetranslatorControl.RemoveTarget:output_type -> proto.TargetOperationResponse
+	8, // [8:12] is the sub-list for method output_type
+	4, // [4:8] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_retranslator_proto_init() }
+func file_retranslator_proto_init() {
+	if File_retranslator_proto != nil {
+		return
+	}
+	file_service_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_retranslator_proto_rawDesc), len(file_retranslator_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_retranslator_proto_goTypes,
+		DependencyIndexes: file_retranslator_proto_depIdxs,
+		MessageInfos:      file_retranslator_proto_msgTypes,
+	}.Build()
+	File_retranslator_proto = out.File
+	file_retranslator_proto_goTypes = nil
+	file_retranslator_proto_depIdxs = nil
+}
@@ -0,0 +1,247 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v3.12.4
+// source: retranslator.proto
+
+package proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	RetranslatorControl_SetLogLevel_FullMethodName           = "/proto.RetranslatorControl/SetLogLevel"
+	RetranslatorControl_GetRetranslatorStatus_FullMethodName = "/proto.RetranslatorControl/GetRetranslatorStatus"
+	RetranslatorControl_AddTarget_FullMethodName             = "/proto.RetranslatorControl/AddTarget"
+	RetranslatorControl_RemoveTarget_FullMethodName          = "/proto.RetranslatorControl/RemoveTarget"
+)
+
+// RetranslatorControlClient is the client API for RetranslatorControl service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Сервис управления RETRANSLATOR'ом (передача записей на сторонние серверы по ЕГТС)
+type RetranslatorControlClient interface {
+	// Устанавливаем уровень логирования, используя общий запрос/ответ
+	SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*SetLogLevelResponse, error)
+	// Получить статус сервиса и серверов ретрансляции
+	GetRetranslatorStatus(ctx context.Context, in *GetRetranslatorStatusRequest, opts ...grpc.CallOption) (*GetRetranslatorStatusResponse, error)
+	// Добавить сервер ретрансляции. Записи передаются начиная с момента добавления.
+	AddTarget(ctx context.Context, in *TargetDefinition, opts ...grpc.CallOption) (*TargetOperationResponse, error)
+	// Удалить сервер ретрансляции вместе с его потребителем NATS
+	RemoveTarget(ctx context.Context, in *TargetIdentifier, opts ...grpc.CallOption) (*TargetOperationResponse, error)
+}
+
+type retranslatorControlClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRetranslatorControlClient(cc grpc.ClientConnInterface) RetranslatorControlClient {
+	return &retranslatorControlClient{cc}
+}
+
+func (c *retranslatorControlClient) SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*SetLogLevelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetLogLevelResponse)
+	err := c.cc.Invoke(ctx, RetranslatorControl_SetLogLevel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *retranslatorControlClient) GetRetranslatorStatus(ctx context.Context, in *GetRetranslatorStatusRequest, opts ...grpc.CallOption) (*GetRetranslatorStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRetranslatorStatusResponse)
+	err := c.cc.Invoke(ctx, RetranslatorControl_GetRetranslatorStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *retranslatorControlClient) AddTarget(ctx context.Context, in *TargetDefinition, opts ...grpc.CallOption) (*TargetOperationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TargetOperationResponse)
+	err := c.cc.Invoke(ctx, RetranslatorControl_AddTarget_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *retranslatorControlClient) RemoveTarget(ctx context.Context, in *TargetIdentifier, opts ...grpc.CallOption) (*TargetOperationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TargetOperationResponse)
+	err := c.cc.Invoke(ctx, RetranslatorControl_RemoveTarget_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RetranslatorControlServer is the server API for RetranslatorControl service.
+// All implementations must embed UnimplementedRetranslatorControlServer
+// for forward compatibility.
+//
+// Сервис управления RETRANSLATOR'ом (передача записей на сторонние серверы по ЕГТС)
+type RetranslatorControlServer interface {
+	// Устанавливаем уровень логирования, используя общий запрос/ответ
+	SetLogLevel(context.Context, *SetLogLevelRequest) (*SetLogLevelResponse, error)
+	// Получить статус сервиса и серверов ретрансляции
+	GetRetranslatorStatus(context.Context, *GetRetranslatorStatusRequest) (*GetRetranslatorStatusResponse, error)
+	// Добавить сервер ретрансляции. Записи передаются начиная с момента добавления.
+	AddTarget(context.Context, *TargetDefinition) (*TargetOperationResponse, error)
+	// Удалить сервер ретрансляции вместе с его потребителем NATS
+	RemoveTarget(context.Context, *TargetIdentifier) (*TargetOperationResponse, error)
+	mustEmbedUnimplementedRetranslatorControlServer()
+}
+
+// UnimplementedRetranslatorControlServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRetranslatorControlServer struct{}
+
+func (UnimplementedRetranslatorControlServer) SetLogLevel(context.Context, *SetLogLevelRequest) (*SetLogLevelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLogLevel not implemented")
+}
+func (UnimplementedRetranslatorControlServer) GetRetranslatorStatus(context.Context, *GetRetranslatorStatusRequest) (*GetRetranslatorStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRetranslatorStatus not implemented")
+}
+func (UnimplementedRetranslatorControlServer) AddTarget(context.Context, *TargetDefinition) (*TargetOperationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddTarget not implemented")
+}
+func (UnimplementedRetranslatorControlServer) RemoveTarget(context.Context, *TargetIdentifier) (*TargetOperationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveTarget not implemented")
+}
+func (UnimplementedRetranslatorControlServer) mustEmbedUnimplementedRetranslatorControlServer() {}
+func (UnimplementedRetranslatorControlServer) testEmbeddedByValue()                             {}
+
+// UnsafeRetranslatorControlServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RetranslatorControlServer will
+// result in compilation errors.
+type UnsafeRetranslatorControlServer interface {
+	mustEmbedUnimplementedRetranslatorControlServer()
+}
+
+func RegisterRetranslatorControlServer(s grpc.ServiceRegistrar, srv RetranslatorControlServer) {
+	// If the following call pancis, it indicates UnimplementedRetranslatorControlServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&RetranslatorControl_ServiceDesc, srv)
+}
+
+func _RetranslatorControl_SetLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLogLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RetranslatorControlServer).SetLogLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RetranslatorControl_SetLogLevel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RetranslatorControlServer).SetLogLevel(ctx, req.(*SetLogLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RetranslatorControl_GetRetranslatorStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRetranslatorStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RetranslatorControlServer).GetRetranslatorStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RetranslatorControl_GetRetranslatorStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RetranslatorControlServer).GetRetranslatorStatus(ctx, req.(*GetRetranslatorStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RetranslatorControl_AddTarget_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TargetDefinition)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RetranslatorControlServer).AddTarget(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RetranslatorControl_AddTarget_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RetranslatorControlServer).AddTarget(ctx, req.(*TargetDefinition))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RetranslatorControl_RemoveTarget_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TargetIdentifier)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RetranslatorControlServer).RemoveTarget(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RetranslatorControl_RemoveTarget_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RetranslatorControlServer).RemoveTarget(ctx, req.(*TargetIdentifier))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RetranslatorControl_ServiceDesc is the grpc.ServiceDesc for RetranslatorControl service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RetranslatorControl_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.RetranslatorControl",
+	HandlerType: (*RetranslatorControlServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SetLogLevel",
+			Handler:    _RetranslatorControl_SetLogLevel_Handler,
+		},
+		{
+			MethodName: "GetRetranslatorStatus",
+			Handler:    _RetranslatorControl_GetRetranslatorStatus_Handler,
+		},
+		{
+			MethodName: "AddTarget",
+			Handler:    _RetranslatorControl_AddTarget_Handler,
+		},
+		{
+			MethodName: "RemoveTarget",
+			Handler:    _RetranslatorControl_RemoveTarget_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "retranslator.proto",
+}
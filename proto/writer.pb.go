@@ -0,0 +1,380 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        v3.12.4
+// source: writer.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetWriterStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetWriterStatusRequest) Reset() {
+	*x = GetWriterStatusRequest{}
+	mi := &file_writer_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetWriterStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWriterStatusRequest) ProtoMessage() {}
+
+func (x *GetWriterStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_writer_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWriterStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetWriterStatusRequest) Descriptor() ([]byte, []int) {
+	return file_writer_proto_rawDescGZIP(), []int{0}
+}
+
+type GetWriterStatusResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	NatsConnected  bool                   `protobuf:"varint,1,opt,name=nats_connected,json=natsConnected,proto3" json:"nats_connected,omitempty"`
+	DbConnected    bool                   `protobuf:"varint,2,opt,name=db_connected,json=dbConnected,proto3" json:"db_connected,omitempty"`
+	Paused         bool                   `protobuf:"varint,3,opt,name=paused,proto3" json:"paused,omitempty"`
+	Stream         string                 `protobuf:"bytes,4,opt,name=stream,proto3" json:"stream,omitempty"`                                        // поток JetStream, из которого читаются записи
+	Consumer       string                 `protobuf:"bytes,5,opt,name=consumer,proto3" json:"consumer,omitempty"`                                    // имя durable-потребителя
+	Pending        uint64                 `protobuf:"varint,6,opt,name=pending,proto3" json:"pending,omitempty"`                                     // сообщений в потоке, еще не доставленных потребителю
+	RecordsWritten uint64                 `protobuf:"varint,7,opt,name=records_written,json=recordsWritten,proto3" json:"records_written,omitempty"` // записей сохранено с момента запуска
+	BatchesWritten uint64                 `protobuf:"varint,8,opt,name=batches_written,json=batchesWritten,proto3" json:"batches_written,omitempty"` // пачек сохранено с момента запуска
+	WriteErrors    uint64                 `protobuf:"varint,9,opt,name=write_errors,json=writeErrors,proto3" json:"write_errors,omitempty"`          // неудачных попыток записи пачки
+	DecodeErrors   uint64                 `protobuf:"varint,10,opt,name=decode_errors,json=decodeErrors,proto3" json:"decode_errors,omitempty"`      // сообщений, которые не удалось разобрать
+	LastWriteTime  int64                  `protobuf:"varint,11,opt,name=last_write_time,json=lastWriteTime,proto3" json:"last_write_time,omitempty"` // время последней успешной записи (Unix timestamp в секундах), 0 - не было
+	LastError      string                 `protobuf:"bytes,12,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`                // последняя ошибка записи
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetWriterStatusResponse) Reset() {
+	*x = GetWriterStatusResponse{}
+	mi := &file_writer_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetWriterStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWriterStatusResponse) ProtoMessage() {}
+
+func (x *GetWriterStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_writer_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWriterStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetWriterStatusResponse) Descriptor() ([]byte, []int) {
+	return file_writer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetWriterStatusResponse) GetNatsConnected() bool {
+	if x != nil {
+		return x.NatsConnected
+	}
+	return false
+}
+
+func (x *GetWriterStatusResponse) GetDbConnected() bool {
+	if x != nil {
+		return x.DbConnected
+	}
+	return false
+}
+
+func (x *GetWriterStatusResponse) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *GetWriterStatusResponse) GetStream() string {
+	if x != nil {
+		return x.Stream
+	}
+	return ""
+}
+
+func (x *GetWriterStatusResponse) GetConsumer() string {
+	if x != nil {
+		return x.Consumer
+	}
+	return ""
+}
+
+func (x *GetWriterStatusResponse) GetPending() uint64 {
+	if x != nil {
+		return x.Pending
+	}
+	return 0
+}
+
+func (x *GetWriterStatusResponse) GetRecordsWritten() uint64 {
+	if x != nil {
+		return x.RecordsWritten
+	}
+	return 0
+}
+
+func (x *GetWriterStatusResponse) GetBatchesWritten() uint64 {
+	if x != nil {
+		return x.BatchesWritten
+	}
+	return 0
+}
+
+func (x *GetWriterStatusResponse) GetWriteErrors() uint64 {
+	if x != nil {
+		return x.WriteErrors
+	}
+	return 0
+}
+
+func (x *GetWriterStatusResponse) GetDecodeErrors() uint64 {
+	if x != nil {
+		return x.DecodeErrors
+	}
+	return 0
+}
+
+func (x *GetWriterStatusResponse) GetLastWriteTime() int64 {
+	if x != nil {
+		return x.LastWriteTime
+	}
+	return 0
+}
+
+func (x *GetWriterStatusResponse) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+type WriterControlRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriterControlRequest) Reset() {
+	*x = WriterControlRequest{}
+	mi := &file_writer_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriterControlRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriterControlRequest) ProtoMessage() {}
+
+func (x *WriterControlRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_writer_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriterControlRequest.ProtoReflect.Descriptor instead.
+func (*WriterControlRequest) Descriptor() ([]byte, []int) {
+	return file_writer_proto_rawDescGZIP(), []int{2}
+}
+
+type WriterControlResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Paused        bool                   `protobuf:"varint,3,opt,name=paused,proto3" json:"paused,omitempty"` // состояние после выполнения команды
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriterControlResponse) Reset() {
+	*x = WriterControlResponse{}
+	mi := &file_writer_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriterControlResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriterControlResponse) ProtoMessage() {}
+
+func (x *WriterControlResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_writer_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriterControlResponse.ProtoReflect.Descriptor instead.
+func (*WriterControlResponse) Descriptor() ([]byte, []int) {
+	return file_writer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *WriterControlResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *WriterControlResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *WriterControlResponse) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+var File_writer_proto protoreflect.FileDescriptor
+
+const file_writer_proto_rawDesc = "" +
+	"\n" +
+	"\fwriter.proto\x12\x05proto\x1a\rservice.proto\"\x18\n" +
+	"\x16GetWriterStatusRequest\"\xaa\x03\n" +
+	"\x17GetWriterStatusResponse\x12%\n" +
+	"\x0enats_connected\x18\x01 \x01(\bR\rnatsConnected\x12!\n" +
+	"\fdb_connected\x18\x02 \x01(\bR\vdbConnected\x12\x16\n" +
+	"\x06paused\x18\x03 \x01(\bR\x06paused\x12\x16\n" +
+	"\x06stream\x18\x04 \x01(\tR\x06stream\x12\x1a\n" +
+	"\bconsumer\x18\x05 \x01(\tR\bconsumer\x12\x18\n" +
+	"\apending\x18\x06 \x01(\x04R\apending\x12'\n" +
+	"\x0frecords_written\x18\a \x01(\x04R\x0erecordsWritten\x12'\n" +
+	"\x0fbatches_written\x18\b \x01(\x04R\x0ebatchesWritten\x12!\n" +
+	"\fwrite_errors\x18\t \x01(\x04R\vwriteErrors\x12#\n" +
+	"\rdecode_errors\x18\n" +
+	" \x01(\x04R\fdecodeErrors\x12&\n" +
+	"\x0flast_write_time\x18\v \x01(\x03R\rlastWriteTime\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\f \x01(\tR\tlastError\"\x16\n" +
+	"\x14WriterControlRequest\"c\n" +
+	"\x15WriterControlResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x16\n" +
+	"\x06paused\x18\x03 \x01(\bR\x06paused2\xb0\x02\n" +
+	"\rWriterControl\x12D\n" +
+	"\vSetLogLevel\x12\x19.proto.SetLogLevelRequest\x1a\x1a.proto.SetLogLevelResponse\x12P\n" +
+	"\x0fGetWriterStatus\x12\x1d.proto.GetWriterStatusRequest\x1a\x1e.proto.GetWriterStatusResponse\x12B\n" +
+	"\x05Pause\x12\x1b.proto.WriterControlRequest\x1a\x1c.proto.WriterControlResponse\x12C\n" +
+	"\x06Resume\x12\x1b.proto.WriterControlRequest\x1a\x1c.proto.WriterControlResponseB\x18Z\x16NavControlSystem/protob\x06proto3"
+
+var (
+	file_writer_proto_rawDescOnce sync.Once
+	file_writer_proto_rawDescData []byte
+)
+
+func file_writer_proto_rawDescGZIP() []byte {
+	file_writer_proto_rawDescOnce.Do(func() {
+		file_writer_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_writer_proto_rawDesc), len(file_writer_proto_rawDesc)))
+	})
+	return file_writer_proto_rawDescData
+}
+
+var file_writer_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_writer_proto_goTypes = []any{
+	(*GetWriterStatusRequest)(nil),  // 0: proto.GetWriterStatusRequest
+	(*GetWriterStatusResponse)(nil), // 1: proto.GetWriterStatusResponse
+	(*WriterControlRequest)(nil),    // 2: proto.WriterControlRequest
+	(*WriterControlResponse)(nil),   // 3: proto.WriterControlResponse
+	(*SetLogLevelRequest)(nil),      // 4: proto.SetLogLevelRequest
+	(*SetLogLevelResponse)(nil),     // 5: proto.SetLogLevelResponse
+}
+var file_writer_proto_depIdxs = []int32{
+	4, // 0: proto.WriterControl.SetLogLevel:input_type -> proto.SetLogLevelRequest
+	0, // 1: proto.WriterControl.GetWriterStatus:input_type -> proto.GetWriterStatusRequest
+	2, // 2: proto.WriterControl.Pause:input_type -> proto.WriterControlRequest
+	2, // 3: proto.WriterControl.Resume:input_type -> proto.WriterControlRequest
+	5, // 4: proto.WriterControl.SetLogLevel:output_type -> proto.SetLogLevelResponse
+	1, // 5: proto.WriterControl.GetWriterStatus:output_type -> proto.GetWriterStatusResponse
+	3, // 6: proto.WriterControl.Pause:output_type -> proto.WriterControlResponse
+	3, // 7: proto.WriterControl.Resume:output_type -> proto.WriterControlResponse
+	4, // [4:8] is the sub-list for method output_type
+	0, // [0:4] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_writer_proto_init() }
+func file_writer_proto_init() {
+	if File_writer_proto != nil {
+		return
+	}
+	file_service_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_writer_proto_rawDesc), len(file_writer_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_writer_proto_goTypes,
+		DependencyIndexes: file_writer_proto_depIdxs,
+		MessageInfos:      file_writer_proto_msgTypes,
+	}.Build()
+	File_writer_proto = out.File
+	file_writer_proto_goTypes = nil
+	file_writer_proto_depIdxs = nil
+}
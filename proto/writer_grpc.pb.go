@@ -0,0 +1,247 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v3.12.4
+// source: writer.proto
+
+package proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	WriterControl_SetLogLevel_FullMethodName     = "/proto.WriterControl/SetLogLevel"
+	WriterControl_GetWriterStatus_FullMethodName = "/proto.WriterControl/GetWriterStatus"
+	WriterControl_Pause_FullMethodName           = "/proto.WriterControl/Pause"
+	WriterControl_Resume_FullMethodName          = "/proto.WriterControl/Resume"
+)
+
+// WriterControlClient is the client API for WriterControl service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Сервис управления WRITER'ом
+type WriterControlClient interface {
+	// Устанавливаем уровень логирования, используя общий запрос/ответ
+	SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*SetLogLevelResponse, error)
+	// Получить статус сервиса
+	GetWriterStatus(ctx context.Context, in *GetWriterStatusRequest, opts ...grpc.CallOption) (*GetWriterStatusResponse, error)
+	// Приостановить чтение из NATS. Записи копятся в потоке JetStream.
+	Pause(ctx context.Context, in *WriterControlRequest, opts ...grpc.CallOption) (*WriterControlResponse, error)
+	// Возобновить чтение из NATS
+	Resume(ctx context.Context, in *WriterControlRequest, opts ...grpc.CallOption) (*WriterControlResponse, error)
+}
+
+type writerControlClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWriterControlClient(cc grpc.ClientConnInterface) WriterControlClient {
+	return &writerControlClient{cc}
+}
+
+func (c *writerControlClient) SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*SetLogLevelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetLogLevelResponse)
+	err := c.cc.Invoke(ctx, WriterControl_SetLogLevel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *writerControlClient) GetWriterStatus(ctx context.Context, in *GetWriterStatusRequest, opts ...grpc.CallOption) (*GetWriterStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetWriterStatusResponse)
+	err := c.cc.Invoke(ctx, WriterControl_GetWriterStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *writerControlClient) Pause(ctx context.Context, in *WriterControlRequest, opts ...grpc.CallOption) (*WriterControlResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WriterControlResponse)
+	err := c.cc.Invoke(ctx, WriterControl_Pause_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *writerControlClient) Resume(ctx context.Context, in *WriterControlRequest, opts ...grpc.CallOption) (*WriterControlResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WriterControlResponse)
+	err := c.cc.Invoke(ctx, WriterControl_Resume_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WriterControlServer is the server API for WriterControl service.
+// All implementations must embed UnimplementedWriterControlServer
+// for forward compatibility.
+//
+// Сервис управления WRITER'ом
+type WriterControlServer interface {
+	// Устанавливаем уровень логирования, используя общий запрос/ответ
+	SetLogLevel(context.Context, *SetLogLevelRequest) (*SetLogLevelResponse, error)
+	// Получить статус сервиса
+	GetWriterStatus(context.Context, *GetWriterStatusRequest) (*GetWriterStatusResponse, error)
+	// Приостановить чтение из NATS. Записи копятся в потоке JetStream.
+	Pause(context.Context, *WriterControlRequest) (*WriterControlResponse, error)
+	// Возобновить чтение из NATS
+	Resume(context.Context, *WriterControlRequest) (*WriterControlResponse, error)
+	mustEmbedUnimplementedWriterControlServer()
+}
+
+// UnimplementedWriterControlServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedWriterControlServer struct{}
+
+func (UnimplementedWriterControlServer) SetLogLevel(context.Context, *SetLogLevelRequest) (*SetLogLevelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLogLevel not implemented")
+}
+func (UnimplementedWriterControlServer) GetWriterStatus(context.Context, *GetWriterStatusRequest) (*GetWriterStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWriterStatus not implemented")
+}
+func (UnimplementedWriterControlServer) Pause(context.Context, *WriterControlRequest) (*WriterControlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Pause not implemented")
+}
+func (UnimplementedWriterControlServer) Resume(context.Context, *WriterControlRequest) (*WriterControlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Resume not implemented")
+}
+func (UnimplementedWriterControlServer) mustEmbedUnimplementedWriterControlServer() {}
+func (UnimplementedWriterControlServer) testEmbeddedByValue()                       {}
+
+// UnsafeWriterControlServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WriterControlServer will
+// result in compilation errors.
+type UnsafeWriterControlServer interface {
+	mustEmbedUnimplementedWriterControlServer()
+}
+
+func RegisterWriterControlServer(s grpc.ServiceRegistrar, srv WriterControlServer) {
+	// If the following call pancis, it indicates UnimplementedWriterControlServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&WriterControl_ServiceDesc, srv)
+}
+
+func _WriterControl_SetLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLogLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WriterControlServer).SetLogLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WriterControl_SetLogLevel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WriterControlServer).SetLogLevel(ctx, req.(*SetLogLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WriterControl_GetWriterStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWriterStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WriterControlServer).GetWriterStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WriterControl_GetWriterStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WriterControlServer).GetWriterStatus(ctx, req.(*GetWriterStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WriterControl_Pause_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WriterControlRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WriterControlServer).Pause(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WriterControl_Pause_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WriterControlServer).Pause(ctx, req.(*WriterControlRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WriterControl_Resume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WriterControlRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WriterControlServer).Resume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WriterControl_Resume_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WriterControlServer).Resume(ctx, req.(*WriterControlRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// WriterControl_ServiceDesc is the grpc.ServiceDesc for WriterControl service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var WriterControl_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.WriterControl",
+	HandlerType: (*WriterControlServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SetLogLevel",
+			Handler:    _WriterControl_SetLogLevel_Handler,
+		},
+		{
+			MethodName: "GetWriterStatus",
+			Handler:    _WriterControl_GetWriterStatus_Handler,
+		},
+		{
+			MethodName: "Pause",
+			Handler:    _WriterControl_Pause_Handler,
+		},
+		{
+			MethodName: "Resume",
+			Handler:    _WriterControl_Resume_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "writer.proto",
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"sort"
+
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/proto"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/protocol"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// captureConfig возвращает настройки записи трафика порта p
+func (s *ReceiverServer) captureConfig(p ProtocolConfig) protocol.CaptureConfig {
+	s.captureMu.Lock()
+	defer s.captureMu.Unlock()
+
+	devices := make(map[string]bool, len(s.captureDevices))
+	for id := range s.captureDevices {
+		devices[id] = true
+	}
+	return protocol.CaptureConfig{
+		Dir:      s.cfg.Capture.Dir,
+		Protocol: p.Name,
+		PortID:   p.ID,
+		Options:  p.Options,
+		All:      s.capturePorts[p.ID],
+		Devices:  devices,
+	}
+}
+
+// applyCapture передает настройки записи трафика открытым портам
+func (s *ReceiverServer) applyCapture() {
+	s.handlersMu.RLock()
+	defer s.handlersMu.RUnlock()
+
+	for id, handler := range s.handlers {
+		portCfg, err := s.cfg.GetPortByID(id)
+		if err != nil {
+			continue
+		}
+		handler.SetCapture(s.captureConfig(*portCfg))
+	}
+}
+
+// captureStatus возвращает порты и устройства, трафик которых записывается
+func (s *ReceiverServer) captureStatus() *proto.CaptureStatus {
+	s.captureMu.Lock()
+	defer s.captureMu.Unlock()
+
+	resp := &proto.CaptureStatus{Dir: s.cfg.Capture.Dir}
+	for id := range s.capturePorts {
+		resp.PortIds = append(resp.PortIds, id)
+	}
+	for id := range s.captureDevices {
+		resp.DeviceIds = append(resp.DeviceIds, id)
+	}
+	sort.Strings(resp.PortIds)
+	sort.Strings(resp.DeviceIds)
+	return resp
+}
+
+// SetCapture включает или выключает запись трафика всех сессий порта (protocol_name - ID порта)
+// или сессий устройства на всех портах. Запись применяется к сессиям, открытым после вызова.
+func (s *ReceiverServer) SetCapture(ctx context.Context, req *proto.SetCaptureRequest) (*proto.CaptureStatus, error) {
+	if s.cfg.Capture.Dir == "" {
+		return nil, status.Error(codes.FailedPrecondition, "traffic capture is not configured ([capture] dir)")
+	}
+	if (req.ProtocolName == "") == (req.DeviceId == "") {
+		return nil, status.Error(codes.InvalidArgument, "either protocol_name (port ID) or device_id must be specified")
+	}
+	if req.ProtocolName != "" {
+		if _, err := s.cfg.GetPortByID(req.ProtocolName); err != nil {
+			return nil, status.Errorf(codes.NotFound, "port with ID %s not found", req.ProtocolName)
+		}
+	}
+
+	logger.Infof("GRPC call: SetCapture port=%q device=%q enabled=%t", req.ProtocolName, req.DeviceId, req.Enabled)
+	s.captureMu.Lock()
+	set, id := s.capturePorts, req.ProtocolName
+	if req.DeviceId != "" {
+		set, id = s.captureDevices, req.DeviceId
+	}
+	if req.Enabled {
+		set[id] = true
+	} else {
+		delete(set, id)
+	}
+	s.captureMu.Unlock()
+
+	s.applyCapture()
+	return s.captureStatus(), nil
+}
+
+// GetCapture возвращает порты и устройства, трафик которых записывается.
+func (s *ReceiverServer) GetCapture(ctx context.Context, req *proto.GetCaptureRequest) (*proto.CaptureStatus, error) {
+	return s.captureStatus(), nil
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/pkg/models"
+	"github.com/rackov/NavControlSystem/proto"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/commandqueue"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/protocol"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// commandQueueInterval - период проверки сроков команд в очереди
+const commandQueueInterval = time.Minute
+
+// subscribeCommands подписывается на топики команд. Подписка создается один раз:
+// после переподключения к NATS клиент восстанавливает ее сам.
+func (s *ReceiverServer) subscribeCommands() {
+	client := s.nats.Load()
+	if s.cfg.Commands.Disabled || s.commandSub != nil || client == nil {
+		return
+	}
+
+	subject := protocol.SubjectTemplate(s.cfg.Commands.Subject).Wildcard()
+	sub, err := client.Subscribe(subject, s.onCommand)
+	if err != nil {
+		logger.Errorf("Failed to subscribe to commands %s: %v", subject, err)
+		return
+	}
+	s.commandSub = sub
+	logger.Infof("Listening for device commands on %s", subject)
+}
+
+// onCommand разбирает команду из NATS и выполняет ее в отдельной горутине,
+// чтобы ожидание ответа устройства не задерживало остальные команды.
+// Одновременно выполняется не больше commands.max_concurrent команд, остальные сразу
+// завершаются со статусом failed.
+func (s *ReceiverServer) onCommand(msg *nats.Msg) {
+	ServiceMetrics.IncOperationCounter("commands_received")
+
+	cmd := &models.Command{}
+	if err := json.Unmarshal(msg.Data, cmd); err != nil {
+		ServiceMetrics.IncErrorCounter("command_decode_failed")
+		logger.Warnf("Failed to decode command from %s: %v", msg.Subject, err)
+		return
+	}
+	if cmd.DeviceID == "" {
+		cmd.DeviceID, _ = protocol.SubjectTemplate(s.cfg.Commands.Subject).Extract(msg.Subject, protocol.SubjectVarDeviceID)
+	}
+	if cmd.ID == "" {
+		cmd.ID = uuid.New().String()
+	}
+	if cmd.CreatedAt.IsZero() {
+		cmd.CreatedAt = time.Now().UTC()
+	}
+
+	select {
+	case s.commandSlots <- struct{}{}:
+	default:
+		ServiceMetrics.IncErrorCounter("commands_busy")
+		s.publishCommandResult(models.NewCommandResult(cmd, models.CommandFailed, 0, "receiver is busy, too many commands in progress"), "", "", msg.Reply)
+		return
+	}
+
+	go func() {
+		defer func() { <-s.commandSlots }()
+		// Команды из NATS не ставятся в очередь: диспетчер сразу получает no_connection
+		if _, err := s.commandQueue.Add(cmd, time.Time{}); err != nil {
+			s.publishCommandResult(models.NewCommandResult(cmd, models.CommandFailed, 0, err.Error()), "", "", msg.Reply)
+			return
+		}
+		s.deliverCommand(cmd, msg.Reply)
+	}()
+}
+
+// deliverCommand передает устройству команду, зарегистрированную в очереди, и сохраняет результат.
+// Результат публикуется, только если доставка завершена: команда, поставленная в очередь,
+// будет передана при следующей авторизации устройства.
+func (s *ReceiverServer) deliverCommand(cmd *models.Command, reply string) commandqueue.Entry {
+	res, portProtocol, portID := s.executeCommand(s.ctx, cmd)
+	entry, ok := s.commandQueue.Complete(res, portProtocol, portID)
+	if ok && entry.Status == commandqueue.StatusQueued {
+		logger.Infof("Device %s is not connected, command %s queued until %s",
+			cmd.DeviceID, cmd.ID, entry.ExpiresAt.Format(time.RFC3339))
+		return entry
+	}
+	s.publishCommandResult(res, portProtocol, portID, reply)
+	return entry
+}
+
+// deliverQueuedCommands передает авторизовавшемуся устройству команды из очереди в порядке их создания
+func (s *ReceiverServer) deliverQueuedCommands(ids ...string) {
+	for _, cmd := range s.commandQueue.Claim(ids...) {
+		logger.Infof("Delivering queued command %s to device %s", cmd.ID, cmd.DeviceID)
+		s.deliverCommand(&cmd, "")
+	}
+}
+
+// startCommandQueueWorker периодически завершает команды, не дождавшиеся подключения устройства,
+// и удаляет устаревшие статусы
+func (s *ReceiverServer) startCommandQueueWorker(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(commandQueueInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				for _, entry := range s.commandQueue.Expire(now.UTC()) {
+					res := models.NewCommandResult(&entry.Command, models.CommandExpired, 0, "device did not connect in time")
+					s.publishCommandResult(res, entry.Protocol, entry.PortID, "")
+				}
+				ServiceMetrics.SetGauge("commands_pending", float64(len(s.commandQueue.Pending(""))))
+			}
+		}
+	}()
+}
+
+// executeCommand передает команду устройству через порт, к которому оно подключено,
+// и возвращает результат, а также протокол и ID порта (пустые, если устройство не найдено)
+func (s *ReceiverServer) executeCommand(ctx context.Context, cmd *models.Command) (*models.CommandResult, string, string) {
+	if err := cmd.Validate(); err != nil {
+		return models.NewCommandResult(cmd, models.CommandFailed, 0, err.Error()), "", ""
+	}
+
+	timeout := s.cfg.Commands.Timeout
+	if cmd.Timeout > 0 {
+		timeout = time.Duration(cmd.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Снимок обработчиков: порт может закрыться, пока ждем ответа устройства
+	type port struct {
+		id     string
+		name   string
+		sender protocol.CommandSender
+	}
+	var ports []port
+	s.handlersMu.RLock()
+	for id, handler := range s.handlers {
+		if sender, ok := handler.(protocol.CommandSender); ok {
+			ports = append(ports, port{id: id, name: handler.GetName(), sender: sender})
+		}
+	}
+	s.handlersMu.RUnlock()
+
+	for _, p := range ports {
+		res, err := p.sender.SendCommand(ctx, cmd)
+		switch {
+		case errors.Is(err, protocol.ErrDeviceNotConnected):
+			continue
+		case errors.Is(err, protocol.ErrCommandNotSupported):
+			return models.NewCommandResult(cmd, models.CommandUnsupported, 0, err.Error()), p.name, p.id
+		case err != nil:
+			return models.NewCommandResult(cmd, models.CommandFailed, 0, err.Error()), p.name, p.id
+		}
+		return res, p.name, p.id
+	}
+	return models.NewCommandResult(cmd, models.CommandNoConnection, 0, "device is not connected"), "", ""
+}
+
+// publishCommandResult публикует результат команды в топик результатов и,
+// если команда отправлена запросом NATS, отвечает на него.
+// Пока NATS недоступен, результат для топика результатов сохраняется в дисковый буфер.
+func (s *ReceiverServer) publishCommandResult(res *models.CommandResult, portProtocol, portID, reply string) {
+	ServiceMetrics.IncOperationCounter("command_" + res.Status)
+	logger.Infof("Command %s for device %s: %s %s", res.CommandID, res.DeviceID, res.Status, res.Message)
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		logger.Errorf("Failed to marshal command result: %v", err)
+		return
+	}
+	newMsg := func(subject string) *nats.Msg {
+		msg := nats.NewMsg(subject)
+		msg.Data = data
+		msg.Header.Set(contentTypeHdr, "application/json")
+		return msg
+	}
+
+	result := newMsg(protocol.SubjectTemplate(s.cfg.Commands.ResultSubject).Render(portProtocol, portID, res.DeviceID))
+	if !s.IsConnected() {
+		// Ответ на запрос после восстановления связи уже никому не нужен
+		s.spoolCommandResult(result, errors.New("NATS is not connected"))
+		return
+	}
+	client := s.nats.Load()
+	if err := client.PublishMsg(result); err != nil {
+		s.spoolCommandResult(result, err)
+	}
+	if reply != "" {
+		if err := client.PublishMsg(newMsg(reply)); err != nil {
+			ServiceMetrics.IncErrorCounter("command_result_publish_failed")
+			logger.Errorf("Failed to reply with command result to %s: %v", reply, err)
+		}
+	}
+}
+
+// spoolCommandResult сохраняет неопубликованный результат команды в дисковый буфер.
+// Без буфера результат теряется, статус команды остается доступен через GetCommandStatus.
+func (s *ReceiverServer) spoolCommandResult(msg *nats.Msg, cause error) {
+	ServiceMetrics.IncErrorCounter("command_result_publish_failed")
+	if s.spool != nil {
+		err := s.appendSpool(msg, true)
+		if err == nil {
+			logger.Warnf("Command result for %s written to spool: %v", msg.Subject, cause)
+			return
+		}
+		cause = fmt.Errorf("%v; %w", cause, err)
+	}
+	ServiceMetrics.IncErrorCounter("command_result_lost")
+	logger.Errorf("Command result for %s is lost: %v", msg.Subject, cause)
+}
+
+// --- gRPC ---
+
+// SendCommandToDevice передает команду устройству и ждет результата, но не дольше времени жизни запроса:
+// после отмены запроса команда продолжает выполняться, ее статус можно получить через GetCommandStatus.
+// Если устройство не подключено, команда ждет в очереди его авторизации до истечения queue_ttl.
+func (s *ReceiverServer) SendCommandToDevice(ctx context.Context, req *proto.SendCommandRequest) (*proto.CommandStatus, error) {
+	if req.Output > math.MaxUint8 {
+		return nil, status.Errorf(codes.InvalidArgument, "output number %d is out of range", req.Output)
+	}
+	cmd := &models.Command{
+		ID:        req.Id,
+		DeviceID:  req.DeviceId,
+		Type:      req.Type,
+		Output:    uint8(req.Output),
+		On:        req.On,
+		Payload:   req.Payload,
+		Timeout:   int(req.Timeout),
+		CreatedAt: time.Now().UTC(),
+	}
+	if cmd.ID == "" {
+		cmd.ID = uuid.New().String()
+	}
+	if err := cmd.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ttl := s.cfg.Commands.QueueTTL
+	if req.QueueTtl > 0 {
+		ttl = time.Duration(req.QueueTtl) * time.Second
+	}
+	if _, err := s.commandQueue.Add(cmd, cmd.CreatedAt.Add(ttl)); err != nil {
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	}
+	ServiceMetrics.IncOperationCounter("commands_received")
+	logger.Infof("gRPC command %s (%s) for device %s", cmd.ID, cmd.Type, cmd.DeviceID)
+
+	done := make(chan commandqueue.Entry, 1)
+	go func() {
+		done <- s.deliverCommand(cmd, "")
+	}()
+	select {
+	case entry := <-done:
+		return commandStatusProto(entry), nil
+	case <-ctx.Done():
+		entry, _ := s.commandQueue.Get(cmd.ID)
+		return commandStatusProto(entry), nil
+	}
+}
+
+// GetCommandStatus возвращает статус команды по ее ID
+func (s *ReceiverServer) GetCommandStatus(ctx context.Context, req *proto.CommandIdentifier) (*proto.CommandStatus, error) {
+	entry, ok := s.commandQueue.Get(req.Id)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "command %s not found", req.Id)
+	}
+	return commandStatusProto(entry), nil
+}
+
+// ListPendingCommands возвращает команды, ожидающие подключения устройства или его ответа
+func (s *ReceiverServer) ListPendingCommands(ctx context.Context, req *proto.ListPendingCommandsRequest) (*proto.ListPendingCommandsResponse, error) {
+	pending := s.commandQueue.Pending(req.DeviceId)
+	resp := &proto.ListPendingCommandsResponse{Commands: make([]*proto.CommandStatus, 0, len(pending))}
+	for _, entry := range pending {
+		resp.Commands = append(resp.Commands, commandStatusProto(entry))
+	}
+	return resp, nil
+}
+
+// commandStatusProto преобразует состояние команды в ответ gRPC
+func commandStatusProto(entry commandqueue.Entry) *proto.CommandStatus {
+	st := &proto.CommandStatus{
+		Id:        entry.Command.ID,
+		DeviceId:  entry.Command.DeviceID,
+		Type:      entry.Command.Type,
+		Status:    entry.Status,
+		Protocol:  entry.Protocol,
+		PortId:    entry.PortID,
+		Attempts:  int32(entry.Attempts),
+		CreatedAt: entry.Command.CreatedAt.Unix(),
+		UpdatedAt: entry.UpdatedAt.Unix(),
+	}
+	if !entry.ExpiresAt.IsZero() {
+		st.ExpiresAt = entry.ExpiresAt.Unix()
+	}
+	if entry.Result != nil {
+		st.Result = entry.Result.Status
+		st.Code = entry.Result.Code
+		st.Message = entry.Result.Message
+	}
+	return st
+}
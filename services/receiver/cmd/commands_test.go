@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/rackov/NavControlSystem/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishCommandResult_NatsDown(t *testing.T) {
+	s := newPublishTestServer(t, true)
+	s.cfg.Commands.ResultSubject = defaultCommandResultsSubject
+
+	cmd := &models.Command{ID: "cmd-1", DeviceID: "866795030000000", Type: models.CommandEngineBlock}
+	s.publishCommandResult(models.NewCommandResult(cmd, models.CommandExpired, 0, "device did not connect in time"), "", "", "_INBOX.1")
+
+	// результат ждет в буфере и будет опубликован в core NATS, ответ на запрос не сохраняется
+	var spooled []spooledMsg
+	_, err := s.spool.Drain(func(data []byte) error {
+		var rec spooledMsg
+		assert.NoError(t, json.Unmarshal(data, &rec))
+		spooled = append(spooled, rec)
+		return nil
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, spooled, 1) {
+		assert.Equal(t, "nav.command_results.866795030000000", spooled[0].Subject)
+		assert.True(t, spooled[0].Core)
+	}
+
+	// без буфера результат теряется, но вызов не падает
+	s.spool = nil
+	s.publishCommandResult(models.NewCommandResult(cmd, models.CommandExpired, 0, ""), "", "", "")
+}
+
+func TestOnCommand_Busy(t *testing.T) {
+	s := newPublishTestServer(t, false)
+	s.cfg.Commands.Subject = defaultCommandsSubject
+	s.cfg.Commands.ResultSubject = defaultCommandResultsSubject
+	s.commandSlots = make(chan struct{}, 1)
+	s.commandSlots <- struct{}{}
+
+	msg := nats.NewMsg("nav.commands.866795030000000")
+	msg.Data = []byte(`{"id": "cmd-2", "type": "engine_block"}`)
+	s.onCommand(msg)
+
+	// все слоты заняты: команда отклонена сразу и не попала в очередь
+	_, ok := s.commandQueue.Get("cmd-2")
+	assert.False(t, ok)
+	assert.Len(t, s.commandSlots, 1)
+}
@@ -6,17 +6,176 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/rackov/NavControlSystem/pkg/models"
+	"github.com/rackov/NavControlSystem/proto"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/protocol"
 )
 
 // ProtocolConfig теперь описывает один конкретный слушающий порт.
 type ProtocolConfig struct {
-	ID     string `toml:"id"`     // Уникальный идентификатор порта (UUID)
-	Name   string `toml:"name"`   // Имя протокола (EGTS, ARNAVI, NDTP)
-	Port   int    `toml:"port"`   // Номер порта
-	Active bool   `toml:"active"` // Флаг, должен ли порт быть открыт
+	ID      string            `toml:"id"`                // Уникальный идентификатор порта (UUID)
+	Name    string            `toml:"name"`              // Имя зарегистрированного протокола (EGTS, ARNAVI, NDTP, ...)
+	Port    int               `toml:"port"`              // Номер порта
+	Active  bool              `toml:"active"`            // Флаг, должен ли порт быть открыт
+	Options map[string]string `toml:"options,omitempty"` // Параметры протокола по его схеме в реестре
+	TLS     *TLSConfig        `toml:"tls,omitempty"`     // Настройки TLS; не задано - подключения без шифрования
+	// Транспорт: tcp (по умолчанию) или udp, если протокол его поддерживает (EGTS)
+	Transport string `toml:"transport,omitempty"`
+}
+
+// transport возвращает транспорт порта, пустое значение - tcp
+func (p *ProtocolConfig) transport() string {
+	if p.Transport == "" {
+		return protocol.TransportTCP
+	}
+	return p.Transport
+}
+
+// validateTransport проверяет транспорт порта: протокол должен его поддерживать,
+// TLS (DTLS) для UDP не поддерживается
+func validateTransport(name, transport string, tls *TLSConfig) error {
+	if err := protocol.ValidateTransport(name, transport); err != nil {
+		return err
+	}
+	if transport == protocol.TransportUDP && tls != nil {
+		return fmt.Errorf("tls is not supported for transport udp")
+	}
+	return nil
+}
+
+// StreamConfig описывает поток JetStream, который создается (или обновляется) при подключении к NATS.
+type StreamConfig struct {
+	Name            string        `toml:"name"`             // Имя потока; пусто - поток не создается, публикация в core NATS
+	Subjects        []string      `toml:"subjects"`         // Топики потока, по умолчанию - маски шаблонов [nats.subjects]
+	Retention       string        `toml:"retention"`        // limits | interest | workqueue
+	Storage         string        `toml:"storage"`          // file | memory
+	MaxAge          time.Duration `toml:"max_age"`          // Время хранения сообщений, 0 - без ограничения
+	Replicas        int           `toml:"replicas"`         // Число реплик в кластере
+	DuplicateWindow time.Duration `toml:"duplicate_window"` // Окно дедупликации по Nats-Msg-Id
+}
+
+// natsConfig преобразует описание потока в конфигурацию nats.go
+func (sc StreamConfig) natsConfig() (*nats.StreamConfig, error) {
+	cfg := &nats.StreamConfig{
+		Name:       sc.Name,
+		Subjects:   sc.Subjects,
+		MaxAge:     sc.MaxAge,
+		Replicas:   sc.Replicas,
+		Duplicates: sc.DuplicateWindow,
+	}
+
+	switch strings.ToLower(sc.Retention) {
+	case "", "limits":
+		cfg.Retention = nats.LimitsPolicy
+	case "interest":
+		cfg.Retention = nats.InterestPolicy
+	case "workqueue":
+		cfg.Retention = nats.WorkQueuePolicy
+	default:
+		return nil, fmt.Errorf("unknown stream retention policy: %s", sc.Retention)
+	}
+
+	switch strings.ToLower(sc.Storage) {
+	case "", "file":
+		cfg.Storage = nats.FileStorage
+	case "memory":
+		cfg.Storage = nats.MemoryStorage
+	default:
+		return nil, fmt.Errorf("unknown stream storage type: %s", sc.Storage)
+	}
+
+	return cfg, nil
+}
+
+// Шаблоны топиков по умолчанию
+const (
+	defaultPositionsSubject = "nav.{protocol}.{port_id}.{device_id}"
+	defaultSensorsSubject   = "nav.sensors.{protocol}.{port_id}.{device_id}"
+	defaultAlarmsSubject    = "nav.alarms.{protocol}.{port_id}.{device_id}"
+	defaultEventsSubject    = "nav.events.{protocol}.{port_id}.{device_id}"
+
+	// Топики команд не должны попадать под маски потока (nav.*.*.* и т.д.),
+	// иначе команды и результаты окажутся в потоке навигационных записей
+	defaultCommandsSubject       = "nav.commands.{device_id}"
+	defaultCommandResultsSubject = "nav.command_results.{device_id}"
+)
+
+// SubjectsConfig - шаблоны топиков NATS по типам записей.
+// В шаблоне можно использовать {protocol}, {port_id} и {device_id}.
+type SubjectsConfig struct {
+	Positions string `toml:"positions"` // записи с координатами
+	Sensors   string `toml:"sensors"`   // только показания датчиков
+	Alarms    string `toml:"alarms"`    // тревоги
+	Events    string `toml:"events"`    // события устройства
+}
+
+// template возвращает шаблон топика для типа записи
+func (sc SubjectsConfig) template(kind models.RecordKind) protocol.SubjectTemplate {
+	switch kind {
+	case models.RecordSensors:
+		return protocol.SubjectTemplate(sc.Sensors)
+	case models.RecordAlarm:
+		return protocol.SubjectTemplate(sc.Alarms)
+	case models.RecordEvent:
+		return protocol.SubjectTemplate(sc.Events)
+	default:
+		return protocol.SubjectTemplate(sc.Positions)
+	}
+}
+
+// all возвращает шаблоны всех типов записей
+func (sc SubjectsConfig) all() []protocol.SubjectTemplate {
+	return []protocol.SubjectTemplate{
+		protocol.SubjectTemplate(sc.Positions),
+		protocol.SubjectTemplate(sc.Sensors),
+		protocol.SubjectTemplate(sc.Alarms),
+		protocol.SubjectTemplate(sc.Events),
+	}
+}
+
+// setDefaults заполняет незаданные шаблоны и проверяет их.
+// Старые конфиги с одним nats.subject без [nats.subjects] публикуют все записи в этот топик, как раньше.
+func (sc *SubjectsConfig) setDefaults(legacySubject string, defined bool) error {
+	if !defined && legacySubject != "" {
+		sc.Positions, sc.Sensors, sc.Alarms, sc.Events = legacySubject, legacySubject, legacySubject, legacySubject
+	}
+	if sc.Positions == "" {
+		sc.Positions = defaultPositionsSubject
+	}
+	if sc.Sensors == "" {
+		sc.Sensors = defaultSensorsSubject
+	}
+	if sc.Alarms == "" {
+		sc.Alarms = defaultAlarmsSubject
+	}
+	if sc.Events == "" {
+		sc.Events = defaultEventsSubject
+	}
+	for _, tmpl := range sc.all() {
+		if err := tmpl.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wildcards возвращает маски топиков всех шаблонов без повторов
+func (sc SubjectsConfig) wildcards() []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, tmpl := range sc.all() {
+		w := tmpl.Wildcard()
+		if !seen[w] {
+			seen[w] = true
+			result = append(result, w)
+		}
+	}
+	return result
 }
 
 // Config описывает всю конфигурацию для сервиса RECEIVER.
@@ -39,8 +198,45 @@ type Config struct {
 	configPath string
 
 	Nats struct {
-		PublishingDisabled bool `toml:"publishing_disabled"`
+		PublishingDisabled bool          `toml:"publishing_disabled"`
+		MaxReconnects      int           `toml:"max_reconnects"` // Число попыток переподключения, -1 - без ограничения (по умолчанию)
+		ReconnectWait      time.Duration `toml:"reconnect_wait"` // Пауза между попытками, например "2s"
+		Subject            string        `toml:"subject"`        // Устаревший единый топик; если задан без [nats.subjects], все записи идут в него
+		AckTimeout         time.Duration `toml:"ack_timeout"`    // Сколько ждать подтверждения JetStream на публикацию
+		MaxPending         int           `toml:"max_pending"`    // Сколько публикаций JetStream может ждать подтверждения одновременно
+		Encoding           string        `toml:"encoding"`       // Кодировка записей: json (по умолчанию) | protobuf
+
+		Subjects SubjectsConfig `toml:"subjects"`
+		Stream   StreamConfig   `toml:"stream"`
 	} `toml:"nats"`
+
+	// Дисковый буфер на время недоступности NATS
+	Spool struct {
+		Dir           string `toml:"dir"`             // Каталог сегментов; пусто - буфер выключен, порты закрываются при падении NATS
+		SegmentSizeMB int64  `toml:"segment_size_mb"` // Размер одного сегмента
+		MaxSizeMB     int64  `toml:"max_size_mb"`     // Предельный размер буфера, 0 - без ограничения
+	} `toml:"spool"`
+
+	// Команды устройствам, которые диспетчеры публикуют в NATS
+	Commands struct {
+		Disabled      bool          `toml:"disabled"`       // Не подписываться на команды
+		Subject       string        `toml:"subject"`        // Шаблон топика команд, подписка - на его маску
+		ResultSubject string        `toml:"result_subject"` // Шаблон топика результатов выполнения
+		Timeout       time.Duration `toml:"timeout"`        // Сколько ждать ответа устройства, если в команде не задано
+		QueueTTL      time.Duration `toml:"queue_ttl"`      // Сколько команда из gRPC ждет подключения устройства, если в запросе не задано
+		Retention     time.Duration `toml:"retention"`      // Сколько хранить статус завершенной команды
+		MaxConcurrent int           `toml:"max_concurrent"` // Сколько команд из NATS выполняется одновременно
+	} `toml:"commands"`
+
+	// Реестр устройств, допущенных к портам
+	Devices struct {
+		File string `toml:"file"` // TOML-файл реестра; пусто - реестр выключен, допускаются все устройства
+	} `toml:"devices"`
+
+	// Запись сырого трафика сессий (включается через gRPC SetCapture)
+	Capture struct {
+		Dir string `toml:"dir"` // Каталог файлов записи; пусто - запись недоступна
+	} `toml:"capture"`
 }
 
 // LoadConfig загружает и парсит TOML файл.
@@ -53,7 +249,8 @@ func LoadConfig(configPath *string) (*Config, error) {
 	}
 
 	var cfg Config
-	if _, err := toml.Decode(string(data), &cfg); err != nil {
+	meta, err := toml.Decode(string(data), &cfg)
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse config file %s: %w", cfgFile, err)
 	}
 
@@ -69,9 +266,79 @@ func LoadConfig(configPath *string) (*Config, error) {
 	if len(cfg.ProtocolConfigs) == 0 {
 		return nil, fmt.Errorf("no protocol configurations found in %s", cfgFile)
 	}
+	for _, p := range cfg.ProtocolConfigs {
+		if p.Transport != "" {
+			if err := validateTransport(p.Name, p.Transport, p.TLS); err != nil {
+				return nil, fmt.Errorf("invalid transport of port %d in %s: %w", p.Port, cfgFile, err)
+			}
+		}
+		if p.TLS == nil {
+			continue
+		}
+		if err := p.TLS.options().Validate(); err != nil {
+			return nil, fmt.Errorf("invalid tls of port %d in %s: %w", p.Port, cfgFile, err)
+		}
+	}
 	if cfg.NatsURL == "" {
 		return nil, fmt.Errorf("nats_url is not specified in %s", cfgFile)
 	}
+	// Раньше клиент сдавался после 5 попыток; теперь по умолчанию переподключается всегда
+	if !meta.IsDefined("nats", "max_reconnects") {
+		cfg.Nats.MaxReconnects = -1
+	}
+	if cfg.Nats.ReconnectWait <= 0 {
+		cfg.Nats.ReconnectWait = 2 * time.Second
+	}
+	if err := cfg.Nats.Subjects.setDefaults(cfg.Nats.Subject, meta.IsDefined("nats", "subjects")); err != nil {
+		return nil, fmt.Errorf("invalid [nats.subjects] in %s: %w", cfgFile, err)
+	}
+	if cfg.Nats.Encoding == "" {
+		cfg.Nats.Encoding = proto.EncodingJSON
+	}
+	if err := proto.ValidateEncoding(cfg.Nats.Encoding); err != nil {
+		return nil, fmt.Errorf("invalid nats.encoding in %s: %w", cfgFile, err)
+	}
+	if cfg.Nats.AckTimeout <= 0 {
+		cfg.Nats.AckTimeout = 5 * time.Second
+	}
+	if cfg.Nats.MaxPending <= 0 {
+		cfg.Nats.MaxPending = 1000
+	}
+	if cfg.Nats.Stream.Name != "" {
+		if len(cfg.Nats.Stream.Subjects) == 0 {
+			cfg.Nats.Stream.Subjects = cfg.Nats.Subjects.wildcards()
+		}
+		if cfg.Nats.Stream.Replicas <= 0 {
+			cfg.Nats.Stream.Replicas = 1
+		}
+		if _, err := cfg.Nats.Stream.natsConfig(); err != nil {
+			return nil, fmt.Errorf("invalid [nats.stream] in %s: %w", cfgFile, err)
+		}
+	}
+
+	if cfg.Commands.Subject == "" {
+		cfg.Commands.Subject = defaultCommandsSubject
+	}
+	if cfg.Commands.ResultSubject == "" {
+		cfg.Commands.ResultSubject = defaultCommandResultsSubject
+	}
+	if cfg.Commands.Timeout <= 0 {
+		cfg.Commands.Timeout = 30 * time.Second
+	}
+	if cfg.Commands.QueueTTL <= 0 {
+		cfg.Commands.QueueTTL = 24 * time.Hour
+	}
+	if cfg.Commands.Retention <= 0 {
+		cfg.Commands.Retention = time.Hour
+	}
+	if cfg.Commands.MaxConcurrent <= 0 {
+		cfg.Commands.MaxConcurrent = 100
+	}
+	for _, tmpl := range []string{cfg.Commands.Subject, cfg.Commands.ResultSubject} {
+		if err := protocol.SubjectTemplate(tmpl).Validate(); err != nil {
+			return nil, fmt.Errorf("invalid [commands] in %s: %w", cfgFile, err)
+		}
+	}
 
 	if cfg.Logging.FilePath != "" {
 		logDir := filepath.Dir(cfg.Logging.FilePath)
@@ -99,7 +366,11 @@ func resolveConfigPath(configPath *string) string {
 func (c *Config) Save() error {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
+	return c.saveLocked()
+}
 
+// saveLocked перезаписывает конфигурационный файл. Вызывается под c.mu.
+func (c *Config) saveLocked() error {
 	if c.configPath == "" {
 		return fmt.Errorf("config path is not set, cannot save")
 	}
@@ -130,33 +401,42 @@ func (c *Config) Save() error {
 // --- Методы для управления портами ---
 
 // AddPort добавляет новую конфигурацию порта.
-func (c *Config) AddPort(name string, port int) (*ProtocolConfig, error) {
+// Имя и параметры проверяются по реестру протоколов, неизвестные протоколы отклоняются.
+func (c *Config) AddPort(name string, port int, options map[string]string, tls *TLSConfig, transport string) (*ProtocolConfig, error) {
+	if err := protocol.ValidatePort(name, options); err != nil {
+		return nil, err
+	}
+	if err := validateTransport(name, transport, tls); err != nil {
+		return nil, err
+	}
+	if tls != nil {
+		if err := tls.options().Validate(); err != nil {
+			return nil, fmt.Errorf("invalid tls: %w", err)
+		}
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// Проверяем, не занят ли порт
+	// Проверяем, не занят ли порт; TCP и UDP порты с одним номером не пересекаются
+	newPortCfg := ProtocolConfig{Port: port, Transport: transport}
 	for _, pCfg := range c.ProtocolConfigs {
-		if pCfg.Port == port {
-			return nil, fmt.Errorf("port %d is already in use", port)
+		if pCfg.Port == port && pCfg.transport() == newPortCfg.transport() {
+			return nil, fmt.Errorf("port %d/%s is already in use", port, newPortCfg.transport())
 		}
 	}
 
-	newPortCfg := ProtocolConfig{
-		ID:     uuid.New().String(),
-		Name:   strings.ToUpper(name),
-		Port:   port,
-		Active: true, // По умолчанию добавлен, но не активен
+	newPortCfg = ProtocolConfig{
+		ID:        uuid.New().String(),
+		Name:      strings.ToUpper(name),
+		Port:      port,
+		Active:    true, // По умолчанию добавлен, но не активен
+		Options:   options,
+		TLS:       tls,
+		Transport: transport,
 	}
+	// Конфигурация сохраняется после запуска обработчика порта (см. ReceiverServer.AddPort)
 	c.ProtocolConfigs = append(c.ProtocolConfigs, newPortCfg)
-
-	// Убираем сохранение отсюда, оно будет после перезапуска обработчиков
-	// // Сразу сохраняем изменения в файл
-	// if err := c.Save(); err != nil {
-	// 	// Откатываем изменение, если не удалось сохранить
-	// 	c.ProtocolConfigs = c.ProtocolConfigs[:len(c.ProtocolConfigs)-1]
-	// 	return nil, fmt.Errorf("failed to save config after adding port: %w", err)
-	// }
-
 	return &newPortCfg, nil
 }
 
@@ -179,11 +459,12 @@ func (c *Config) DeletePort(id string) error {
 		return fmt.Errorf("port with id %s not found", id)
 	}
 
+	oldConfigs := c.ProtocolConfigs
 	c.ProtocolConfigs = newConfigs
 
-	if err := c.Save(); err != nil {
+	if err := c.saveLocked(); err != nil {
 		// Откатываем изменение
-		c.ProtocolConfigs = append(c.ProtocolConfigs, ProtocolConfig{ID: id}) // Упрощенный откат
+		c.ProtocolConfigs = oldConfigs
 		return fmt.Errorf("failed to save config after deleting port: %w", err)
 	}
 
@@ -202,7 +483,7 @@ func (c *Config) SetPortState(id string, active bool) error {
 			}
 			c.ProtocolConfigs[i].Active = active
 
-			if err := c.Save(); err != nil {
+			if err := c.saveLocked(); err != nil {
 				// Откатываем изменение
 				c.ProtocolConfigs[i].Active = !active
 				return fmt.Errorf("failed to save config after changing port state: %w", err)
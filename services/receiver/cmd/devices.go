@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/proto"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/devices"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AuthorizeDevice реализует protocol.DeviceAuthorizer: проверяет устройство по реестру
+func (p *portPublisher) AuthorizeDevice(ids ...string) error {
+	if p.server.devices == nil {
+		return nil
+	}
+	return p.server.devices.Authorize(p.protocol, ids...)
+}
+
+// reloadDevices перечитывает файл реестра устройств (по SIGHUP и через gRPC).
+// Новое содержимое применяется к следующим авторизациям.
+func (s *ReceiverServer) reloadDevices() (int, error) {
+	if s.devices == nil {
+		return 0, errors.New("device registry is not configured")
+	}
+	if err := s.devices.Reload(); err != nil {
+		return 0, err
+	}
+	count := len(s.devices.List(""))
+	logger.Infof("Device registry reloaded (%d devices)", count)
+	return count, nil
+}
+
+// disconnectDenied отключает текущие сессии устройства, если после изменения реестра
+// оно больше не допущено к порту
+func (s *ReceiverServer) disconnectDenied(deviceID string) {
+	s.handlersMu.RLock()
+	defer s.handlersMu.RUnlock()
+
+	for id, handler := range s.handlers {
+		if _, ok := handler.GetClient(deviceID); !ok {
+			continue
+		}
+		if err := s.devices.Authorize(handler.GetName(), deviceID); err == nil {
+			continue
+		}
+		logger.Infof("Disconnecting device %s on port %s: no longer allowed by registry", deviceID, id)
+		if err := handler.DisconnectDevice(deviceID); err != nil {
+			logger.Errorf("Failed to disconnect device %s on port %s: %v", deviceID, id, err)
+		}
+	}
+}
+
+// checkDeviceRegistry возвращает ошибку gRPC, если реестр устройств выключен
+func (s *ReceiverServer) checkDeviceRegistry() error {
+	if s.devices == nil {
+		return status.Error(codes.FailedPrecondition, "device registry is not configured ([devices] file)")
+	}
+	return nil
+}
+
+// ListDevices возвращает устройства реестра.
+func (s *ReceiverServer) ListDevices(ctx context.Context, req *proto.ListDevicesRequest) (*proto.ListDevicesResponse, error) {
+	if err := s.checkDeviceRegistry(); err != nil {
+		return nil, err
+	}
+
+	list := s.devices.List(req.Customer)
+	resp := &proto.ListDevicesResponse{Devices: make([]*proto.RegisteredDevice, 0, len(list))}
+	for _, d := range list {
+		resp.Devices = append(resp.Devices, deviceProto(d))
+	}
+	return resp, nil
+}
+
+// GetDevice возвращает запись реестра по ID устройства.
+func (s *ReceiverServer) GetDevice(ctx context.Context, req *proto.DeviceIdentifier) (*proto.RegisteredDevice, error) {
+	if err := s.checkDeviceRegistry(); err != nil {
+		return nil, err
+	}
+	if req.DeviceId == "" {
+		return nil, status.Error(codes.InvalidArgument, "device_id is not specified")
+	}
+
+	d, ok := s.devices.Get(req.DeviceId)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "device %s is not registered", req.DeviceId)
+	}
+	return deviceProto(d), nil
+}
+
+// PutDevice добавляет устройство в реестр или изменяет его запись.
+func (s *ReceiverServer) PutDevice(ctx context.Context, req *proto.RegisteredDevice) (*proto.RegisteredDevice, error) {
+	if err := s.checkDeviceRegistry(); err != nil {
+		return nil, err
+	}
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is not specified")
+	}
+
+	d := devices.Device{ID: req.Id, Protocol: req.Protocol, Customer: req.Customer, Enabled: req.Enabled}
+	logger.Infof("GRPC call: PutDevice %+v", d)
+	if err := s.devices.Put(d); err != nil {
+		logger.Errorf("Failed to save device %s: %v", req.Id, err)
+		return nil, status.Errorf(codes.Internal, "failed to save device: %v", err)
+	}
+	s.disconnectDenied(req.Id)
+
+	d, _ = s.devices.Get(req.Id)
+	return deviceProto(d), nil
+}
+
+// DeleteDevice удаляет устройство из реестра и закрывает его сессии.
+func (s *ReceiverServer) DeleteDevice(ctx context.Context, req *proto.DeviceIdentifier) (*proto.DisconnectClientResponse, error) {
+	if err := s.checkDeviceRegistry(); err != nil {
+		return nil, err
+	}
+	if req.DeviceId == "" {
+		return nil, status.Error(codes.InvalidArgument, "device_id is not specified")
+	}
+
+	logger.Infof("GRPC call: DeleteDevice %s", req.DeviceId)
+	if err := s.devices.Delete(req.DeviceId); err != nil {
+		if errors.Is(err, devices.ErrNotFound) {
+			return &proto.DisconnectClientResponse{Success: false}, status.Errorf(codes.NotFound, "device %s is not registered", req.DeviceId)
+		}
+		logger.Errorf("Failed to delete device %s: %v", req.DeviceId, err)
+		return nil, status.Errorf(codes.Internal, "failed to delete device: %v", err)
+	}
+	s.disconnectDenied(req.DeviceId)
+	return &proto.DisconnectClientResponse{Success: true}, nil
+}
+
+// ReloadDevices перечитывает файл реестра устройств.
+func (s *ReceiverServer) ReloadDevices(ctx context.Context, req *proto.ReloadDevicesRequest) (*proto.ReloadDevicesResponse, error) {
+	if err := s.checkDeviceRegistry(); err != nil {
+		return nil, err
+	}
+
+	count, err := s.reloadDevices()
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to reload device registry: %v", err)
+	}
+	return &proto.ReloadDevicesResponse{Count: int32(count)}, nil
+}
+
+// deviceProto преобразует запись реестра в ответ gRPC
+func deviceProto(d devices.Device) *proto.RegisteredDevice {
+	return &proto.RegisteredDevice{
+		Id:       d.ID,
+		Protocol: d.Protocol,
+		Customer: d.Customer,
+		Enabled:  d.Enabled,
+	}
+}
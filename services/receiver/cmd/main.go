@@ -13,6 +13,11 @@ import (
 )
 
 func main() {
+	// receiver replay <файл> - воспроизведение записи трафика (см. replay.go)
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		os.Exit(runReplay(os.Args[2:]))
+	}
+
 	// 1. Определяем флаг для пути к конфигурационному файлу
 	var configPath string
 	flag.StringVar(&configPath, "config", "", "Path to the TOML configuration file (e.g., ./configs/receiver.toml)")
@@ -64,9 +69,21 @@ func main() {
 
 	// 6. Настройка graceful shutdown
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 
-	<-sigChan // Ждем сигнала
+	// SIGHUP перечитывает реестр устройств и сертификаты TLS, остальные сигналы завершают работу
+	for sig := range sigChan {
+		if sig != syscall.SIGHUP {
+			break
+		}
+		logger.Info("SIGHUP received, reloading device registry and TLS certificates...")
+		if receiverServer.devices != nil {
+			if _, err := receiverServer.reloadDevices(); err != nil {
+				logger.Errorf("Failed to reload device registry: %v", err)
+			}
+		}
+		receiverServer.reloadCertificates()
+	}
 	logger.Info("Shutdown signal received, stopping server...")
 
 	receiverServer.Stop()
@@ -15,5 +15,11 @@ func InitMetrics(serviceName string) {
 	// Здесь можно добавить и другие, специфичные для RECEIVER, метрики,
 	// если они не покрываются стандартным набором из pkg/monitoring.
 	// Например, мы можем заранее создать gauge для NATS.
+	ServiceMetrics.RegisterGauge("nats_connected", "NATS connection state (1 - connected).")
+	ServiceMetrics.RegisterGauge("spool_size_bytes", "Data waiting in the disk spool for NATS.")
+	ServiceMetrics.RegisterGauge("commands_pending", "Device commands waiting for connection or reply.")
+	ServiceMetrics.RegisterGauge("clients_silent", "Connected devices that sent no packets recently.")
+	ServiceMetrics.RegisterGauge("clients_with_decode_errors", "Connected devices that sent undecodable packets.")
+
 	ServiceMetrics.SetGauge("nats_connected", 0) // Инициализируем значением по умолчанию (0 - отключен)
 }
@@ -0,0 +1,12 @@
+package main
+
+// Подключаемые обработчики протоколов. Каждый пакет регистрирует себя
+// в реестре internal/protocol из init(), поэтому для добавления нового
+// протокола достаточно добавить сюда его импорт.
+import (
+	_ "github.com/rackov/NavControlSystem/services/receiver/internal/handler/arnavi"
+	_ "github.com/rackov/NavControlSystem/services/receiver/internal/handler/egts"
+	_ "github.com/rackov/NavControlSystem/services/receiver/internal/handler/ndtp"
+	_ "github.com/rackov/NavControlSystem/services/receiver/internal/handler/teltonika"
+	_ "github.com/rackov/NavControlSystem/services/receiver/internal/handler/wialon"
+)
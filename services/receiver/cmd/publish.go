@@ -0,0 +1,344 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/pkg/models"
+	"github.com/rackov/NavControlSystem/pkg/tnats"
+	"github.com/rackov/NavControlSystem/proto"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/spool"
+)
+
+// contentTypeHdr - заголовок с кодировкой записи (application/json или application/x-protobuf)
+const contentTypeHdr = "Content-Type"
+
+// spooledMsg - сообщение NATS в дисковом буфере.
+// Топик и заголовки (Nats-Msg-Id) нужны, чтобы при выгрузке опубликовать его так же, как при приеме.
+type spooledMsg struct {
+	Subject string      `json:"subject"`
+	Header  nats.Header `json:"header,omitempty"`
+	Data    []byte      `json:"data"`
+	Core    bool        `json:"core,omitempty"` // публиковать в core NATS, минуя поток JetStream (результаты команд)
+}
+
+// navRecordMsgID возвращает идентификатор записи для дедупликации JetStream (Nats-Msg-Id).
+// В одном пакете устройства бывает несколько записей, поэтому к номеру пакета добавляется время навигации.
+func navRecordMsgID(rec *models.NavRecord) string {
+	return fmt.Sprintf("%s-%d-%d", rec.DeviceID(), rec.PacketID, rec.NavigationTime.UnixMilli())
+}
+
+// portPublisher - DataPublisher одного порта. Дописывает в запись протокол и ID порта,
+// по которым ReceiverServer выбирает топик.
+type portPublisher struct {
+	server   *ReceiverServer
+	protocol string
+	portID   string
+}
+
+func (p *portPublisher) Publish(data *models.NavRecord) error {
+	data.Protocol = p.protocol
+	data.PortID = p.portID
+	return p.server.Publish(data)
+}
+
+func (p *portPublisher) IsConnected() bool {
+	return p.server.IsConnected()
+}
+
+// DeviceAuthorized реализует protocol.SessionObserver: передает устройству команды из очереди
+func (p *portPublisher) DeviceAuthorized(ids ...string) {
+	go p.server.deliverQueuedCommands(ids...)
+}
+
+// recordSubject возвращает топик записи по шаблону для ее типа
+func (s *ReceiverServer) recordSubject(rec *models.NavRecord) string {
+	return s.cfg.Nats.Subjects.template(rec.Kind()).Render(rec.Protocol, rec.PortID, rec.DeviceID())
+}
+
+// Publish публикует запись в топик по шаблонам [nats.subjects].
+// Если NATS недоступен и включен дисковый буфер, данные сохраняются в него и
+// считаются принятыми: устройство получит подтверждение, а запись уйдет в NATS
+// после восстановления связи в порядке поступления.
+func (s *ReceiverServer) Publish(data *models.NavRecord) error {
+	// Проверяем флаг из конфигурации
+	if s.cfg.Nats.PublishingDisabled {
+		logger.Warnf("NATS publishing is DISABLED in configuration. Skipping publish for client ID: %d", data.Client)
+		// Возвращаем nil, чтобы не прерывать обработку данных от оборудования
+		return nil
+	}
+
+	payload, contentType, err := proto.MarshalNavRecord(data, s.cfg.Nats.Encoding)
+	if err != nil {
+		ServiceMetrics.IncErrorCounter("nats_marshal_failed")
+		return fmt.Errorf("failed to marshal navigation data: %w", err)
+	}
+
+	msg := nats.NewMsg(s.recordSubject(data))
+	msg.Data = payload
+	msg.Header.Set(contentTypeHdr, contentType)
+	msg.Header.Set(nats.MsgIdHdr, navRecordMsgID(data))
+
+	if s.spool == nil {
+		if !s.IsConnected() {
+			return fmt.Errorf("NATS is not connected")
+		}
+		return s.publishMsg(msg)
+	}
+
+	if s.IsConnected() {
+		// Пока буфер не выгружен, новые данные встают за ним в очередь.
+		// Запись для буфера формируется, только если она туда пишется.
+		appended, err := s.spool.AppendIfPending(func() ([]byte, error) { return spoolRecord(msg, false) })
+		if err != nil {
+			ServiceMetrics.IncErrorCounter("spool_append_failed")
+			return fmt.Errorf("failed to write to spool: %w", err)
+		}
+		if appended {
+			ServiceMetrics.IncOperationCounter("spool_appended")
+			return nil
+		}
+		err = s.publishMsg(msg)
+		if err == nil {
+			return nil
+		}
+		logger.Warnf("Publish failed, writing data for client ID %d to spool: %v", data.Client, err)
+	}
+
+	if err := s.appendSpool(msg, false); err != nil {
+		return err
+	}
+
+	// NATS доступен, но публикация не прошла - пробуем выгрузить буфер позже
+	if s.IsConnected() {
+		s.drainSpool()
+	}
+	return nil
+}
+
+// publishMsg отправляет сообщение в NATS. Если настроен поток JetStream, публикация асинхронная:
+// подтверждения (PubAck) ждет awaitAck, а одновременно их ждут не больше max_pending сообщений.
+// Когда окно заполнено, publishMsg ждет свободного слота не дольше ack_timeout.
+// Сообщение без подтверждения сохраняется в буфер или публикуется повторно (см. publishFailed),
+// повтор отсеет дедупликация по Nats-Msg-Id.
+func (s *ReceiverServer) publishMsg(msg *nats.Msg) error {
+	return s.publishAttempt(msg, 1)
+}
+
+func (s *ReceiverServer) publishAttempt(msg *nats.Msg, attempt int) error {
+	client := s.nats.Load()
+	if client == nil {
+		return tnats.ErrNotConnected
+	}
+	if s.cfg.Nats.Stream.Name == "" {
+		if err := client.PublishMsg(msg); err != nil {
+			ServiceMetrics.IncErrorCounter("nats_publish_failed")
+			return fmt.Errorf("failed to publish to NATS: %w", err)
+		}
+		ServiceMetrics.IncOperationCounter("nats_published")
+		return nil
+	}
+
+	select {
+	case s.pubWindow <- struct{}{}:
+	case <-time.After(s.cfg.Nats.AckTimeout):
+		ServiceMetrics.IncErrorCounter("nats_publish_window_full")
+		return fmt.Errorf("%d JetStream publishes are still awaiting ack", cap(s.pubWindow))
+	}
+
+	start := time.Now()
+	future, err := client.JSPublishMsgAsync(msg)
+	if err != nil {
+		<-s.pubWindow
+		ServiceMetrics.IncErrorCounter("nats_publish_failed")
+		return fmt.Errorf("failed to publish to NATS: %w", err)
+	}
+	s.pubWG.Add(1)
+	go s.awaitAck(msg, future, start, attempt)
+	return nil
+}
+
+// awaitAck ждет подтверждения публикации не дольше ack_timeout и освобождает ее слот в окне
+func (s *ReceiverServer) awaitAck(msg *nats.Msg, future nats.PubAckFuture, start time.Time, attempt int) {
+	defer s.pubWG.Done()
+
+	var err error
+	select {
+	case ack := <-future.Ok():
+		if ack.Duplicate {
+			ServiceMetrics.IncOperationCounter("nats_duplicates")
+			logger.Debugf("JetStream dropped duplicate message %s", msg.Header.Get(nats.MsgIdHdr))
+		}
+	case ackErr := <-future.Err():
+		ServiceMetrics.IncErrorCounter("nats_ack_failed")
+		err = fmt.Errorf("JetStream did not accept message: %w", ackErr)
+	case <-time.After(s.cfg.Nats.AckTimeout):
+		ServiceMetrics.IncErrorCounter("nats_ack_timeout")
+		err = fmt.Errorf("no JetStream ack within %s", s.cfg.Nats.AckTimeout)
+	}
+	<-s.pubWindow
+
+	if err != nil {
+		s.publishFailed(msg, attempt, err)
+		return
+	}
+	ServiceMetrics.IncOperationCounter("nats_published")
+	ServiceMetrics.ObserveOperationDuration("nats_publish", time.Since(start))
+}
+
+// maxPublishAttempts - сколько раз публиковать сообщение без подтверждения, если буфер выключен
+const maxPublishAttempts = 3
+
+// publishFailed сохраняет сообщение, которое JetStream не подтвердил, в дисковый буфер,
+// а без буфера публикует повторно. Устройство уже получило подтверждение, поэтому
+// сообщение, которое не удалось ни сохранить, ни опубликовать, потеряно.
+func (s *ReceiverServer) publishFailed(msg *nats.Msg, attempt int, cause error) {
+	msgID := msg.Header.Get(nats.MsgIdHdr)
+	switch {
+	case s.spool != nil:
+		err := s.appendSpool(msg, false)
+		if err == nil {
+			logger.Warnf("Message %s written to spool: %v", msgID, cause)
+			if s.IsConnected() {
+				s.drainSpool()
+			}
+			return
+		}
+		cause = fmt.Errorf("%v; %w", cause, err)
+	case attempt < maxPublishAttempts && s.IsConnected():
+		logger.Warnf("Retrying message %s (attempt %d): %v", msgID, attempt+1, cause)
+		err := s.publishAttempt(msg, attempt+1)
+		if err == nil {
+			return
+		}
+		cause = err
+	}
+	ServiceMetrics.IncErrorCounter("nats_publish_lost")
+	logger.Errorf("Message %s to %s is lost: %v", msgID, msg.Subject, cause)
+}
+
+// spoolRecord формирует запись дискового буфера для сообщения NATS
+func spoolRecord(msg *nats.Msg, core bool) ([]byte, error) {
+	spooled, err := json.Marshal(spooledMsg{Subject: msg.Subject, Header: msg.Header, Data: msg.Data, Core: core})
+	if err != nil {
+		ServiceMetrics.IncErrorCounter("nats_marshal_failed")
+		return nil, fmt.Errorf("failed to marshal spool record: %w", err)
+	}
+	return spooled, nil
+}
+
+// appendSpool сохраняет сообщение в дисковый буфер. core - при выгрузке опубликовать
+// сообщение в core NATS: топик может не входить в поток JetStream.
+func (s *ReceiverServer) appendSpool(msg *nats.Msg, core bool) error {
+	spooled, err := spoolRecord(msg, core)
+	if err != nil {
+		return err
+	}
+	if err := s.spool.Append(spooled); err != nil {
+		ServiceMetrics.IncErrorCounter("spool_append_failed")
+		return fmt.Errorf("failed to write to spool: %w", err)
+	}
+	ServiceMetrics.IncOperationCounter("spool_appended")
+	ServiceMetrics.SetGauge("spool_size_bytes", float64(s.spool.Size()))
+	return nil
+}
+
+// ensureStream создает поток JetStream из конфигурации или обновляет его.
+// Вызывается при каждом (пере)подключении к NATS.
+func (s *ReceiverServer) ensureStream() error {
+	client := s.nats.Load()
+	if s.cfg.Nats.Stream.Name == "" || client == nil {
+		return nil
+	}
+
+	streamCfg, err := s.cfg.Nats.Stream.natsConfig()
+	if err != nil {
+		return err
+	}
+	info, err := client.EnsureStream(streamCfg)
+	if err != nil {
+		return err
+	}
+	logger.Infof("JetStream stream %s is ready (subjects: %v, messages: %d)", info.Config.Name, info.Config.Subjects, info.State.Msgs)
+	return nil
+}
+
+// spoolRetryInterval - пауза между попытками выгрузки буфера, если NATS подключен, но публикация не проходит
+const spoolRetryInterval = 2 * time.Second
+
+// drainSpool в отдельной горутине выгружает дисковый буфер в NATS.
+// Повторный вызов во время выгрузки ничего не делает.
+func (s *ReceiverServer) drainSpool() {
+	if s.spool == nil || !s.draining.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		defer s.draining.Store(false)
+
+		for {
+			sent, err := s.spool.Drain(s.publishSpooled)
+			ServiceMetrics.SetGauge("spool_size_bytes", float64(s.spool.Size()))
+			if err == nil {
+				if sent > 0 {
+					logger.Infof("Spool drained: %d records published to NATS", sent)
+				}
+				return
+			}
+			if errors.Is(err, spool.ErrCorrupt) {
+				// Остальные данные выгружены: поврежденные сегменты отложены на диске, нечитаемые записи пропущены
+				ServiceMetrics.IncErrorCounter("spool_corrupt")
+				logger.Errorf("Spool drained with %d records published, corrupt data skipped: %v", sent, err)
+				return
+			}
+
+			logger.Warnf("Spool drain interrupted after %d records: %v", sent, err)
+			if errors.Is(err, spool.ErrClosed) || !s.IsConnected() {
+				// Продолжим по событию переподключения
+				return
+			}
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-time.After(spoolRetryInterval):
+			}
+		}
+	}()
+}
+
+// publishSpooled публикует одну запись из дискового буфера.
+// Нечитаемая запись отвергается с spool.ErrCorrupt, и Drain ее пропускает.
+func (s *ReceiverServer) publishSpooled(data []byte) error {
+	var rec spooledMsg
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return fmt.Errorf("%w: invalid spool record: %v", spool.ErrCorrupt, err)
+	}
+	if rec.Subject == "" {
+		return fmt.Errorf("%w: spool record without subject", spool.ErrCorrupt)
+	}
+	if !s.IsConnected() {
+		return fmt.Errorf("NATS is not connected")
+	}
+
+	msg := nats.NewMsg(rec.Subject)
+	msg.Data = rec.Data
+	for key, values := range rec.Header {
+		msg.Header[key] = values
+	}
+	if rec.Core {
+		if err := s.nats.Load().PublishMsg(msg); err != nil {
+			return fmt.Errorf("failed to publish to NATS: %w", err)
+		}
+		return nil
+	}
+	return s.publishMsg(msg)
+}
+
+func (s *ReceiverServer) IsConnected() bool {
+	client := s.nats.Load()
+	return client != nil && client.IsConnected()
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/spool"
+	"github.com/stretchr/testify/assert"
+)
+
+// testFuture - результат асинхронной публикации, который задает тест
+type testFuture struct {
+	msg *nats.Msg
+	ok  chan *nats.PubAck
+	err chan error
+}
+
+func newTestFuture(msg *nats.Msg) *testFuture {
+	return &testFuture{msg: msg, ok: make(chan *nats.PubAck, 1), err: make(chan error, 1)}
+}
+
+func (f *testFuture) Ok() <-chan *nats.PubAck { return f.ok }
+func (f *testFuture) Err() <-chan error       { return f.err }
+func (f *testFuture) Msg() *nats.Msg          { return f.msg }
+
+// newPublishTestServer создает сервер без NATS с окном публикаций на одно сообщение
+func newPublishTestServer(t *testing.T, withSpool bool) *ReceiverServer {
+	level, _ := logger.ParseLevel("ERROR")
+	logger.Init(level)
+	if ServiceMetrics == nil {
+		InitMetrics("receiver_test")
+	}
+
+	cfg := &Config{}
+	cfg.Nats.AckTimeout = 50 * time.Millisecond
+	cfg.Nats.MaxPending = 1
+	s := NewReceiverServer(cfg)
+	if withSpool {
+		sp, err := spool.Open(t.TempDir(), spool.Options{})
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		t.Cleanup(func() { sp.Close() })
+		s.spool = sp
+	}
+	return s
+}
+
+// storeOfflineClient подключает сервер к недоступному NATS: клиент создан, но не подключен
+func storeOfflineClient(t *testing.T, s *ReceiverServer) {
+	s.cfg.NatsURL = "nats://127.0.0.1:1"
+	s.cfg.Nats.MaxReconnects = -1
+	s.cfg.Nats.ReconnectWait = time.Hour
+	assert.NoError(t, s.connectNats())
+	if client := s.nats.Load(); assert.NotNil(t, client) {
+		t.Cleanup(func() { client.Close() })
+	}
+}
+
+func testMsg(id string) *nats.Msg {
+	msg := nats.NewMsg("nav.wialon.port.1")
+	msg.Data = []byte(id)
+	msg.Header.Set(nats.MsgIdHdr, id)
+	return msg
+}
+
+// spooledIDs вычитывает буфер и возвращает Nats-Msg-Id сохраненных сообщений
+func spooledIDs(t *testing.T, sp *spool.Spool) []string {
+	var ids []string
+	_, err := sp.Drain(func(data []byte) error {
+		var rec spooledMsg
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		ids = append(ids, rec.Header.Get(nats.MsgIdHdr))
+		return nil
+	})
+	assert.NoError(t, err)
+	return ids
+}
+
+func TestAwaitAck_FailedPublishGoesToSpool(t *testing.T) {
+	tests := []struct {
+		name    string
+		resolve func(f *testFuture)
+		spooled []string
+	}{
+		{"ack", func(f *testFuture) { f.ok <- &nats.PubAck{} }, nil},
+		{"error", func(f *testFuture) { f.err <- errors.New("stream not found") }, []string{"1"}},
+		{"timeout", func(f *testFuture) {}, []string{"1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newPublishTestServer(t, true)
+
+			msg := testMsg("1")
+			future := newTestFuture(msg)
+			tt.resolve(future)
+
+			s.pubWindow <- struct{}{}
+			s.pubWG.Add(1)
+			s.awaitAck(msg, future, time.Now(), 1)
+			s.pubWG.Wait()
+
+			assert.Len(t, s.pubWindow, 0, "window slot must be released")
+			assert.Equal(t, tt.spooled, spooledIDs(t, s.spool))
+		})
+	}
+}
+
+func TestPublishAttempt_WindowFull(t *testing.T) {
+	s := newPublishTestServer(t, false)
+	s.cfg.Nats.Stream.Name = "NAV"
+	storeOfflineClient(t, s)
+
+	// единственный слот занят неподтвержденной публикацией
+	s.pubWindow <- struct{}{}
+	start := time.Now()
+	assert.Error(t, s.publishAttempt(testMsg("2"), 1))
+	assert.GreaterOrEqual(t, time.Since(start), s.cfg.Nats.AckTimeout)
+}
+
+func TestPublishFailed_WithoutSpoolAndNats(t *testing.T) {
+	s := newPublishTestServer(t, false)
+
+	// без буфера и без связи повторять некуда: сообщение считается потерянным и не блокирует вызов
+	done := make(chan struct{})
+	go func() {
+		s.publishFailed(testMsg("3"), 1, errors.New("no ack"))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publishFailed did not return")
+	}
+}
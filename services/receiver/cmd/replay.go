@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/capture"
+)
+
+// runReplay выполняет команду "receiver replay": воспроизводит запись трафика через
+// обработчик протокола и сравнивает полученные навигационные записи с ожидаемыми.
+// Возвращает код завершения: 0 - записи совпали, 1 - есть расхождения, 2 - ошибка.
+func runReplay(args []string) int {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	expect := fs.String("expect", "", "JSON lines file with expected NavRecords to diff against")
+	out := fs.String("out", "", "Write produced NavRecords to this JSON lines file")
+	timeout := fs.Duration("timeout", 2*time.Second, "How long to wait for each handler response")
+	logLevel := fs.String("log_level", "ERROR", "Log level of the replayed handler")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: receiver replay [flags] <capture file>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	level, err := logger.ParseLevel(*logLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid log level: %v\n", err)
+		return 2
+	}
+	logger.Init(level)
+
+	result, err := capture.Replay(context.Background(), fs.Arg(0), *timeout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Replay failed: %v\n", err)
+		return 2
+	}
+	h := result.Header
+	fmt.Printf("%s port %d (%s), device %q from %s at %s: %d records\n",
+		h.Protocol, h.Port, h.Transport, h.DeviceID, h.Remote, h.Started.Format(time.RFC3339), len(result.Records))
+	if h.Truncated {
+		fmt.Println("warning: capture is truncated before authorization")
+	}
+	for _, m := range result.Mismatches {
+		fmt.Println(m)
+	}
+
+	if *out != "" {
+		if err := writeRecordsFile(*out, result); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write %s: %v\n", *out, err)
+			return 2
+		}
+	}
+	if *expect == "" {
+		if *out == "" {
+			capture.WriteRecords(os.Stdout, result.Records)
+		}
+		return 0
+	}
+
+	f, err := os.Open(*expect)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open %s: %v\n", *expect, err)
+		return 2
+	}
+	defer f.Close()
+	want, err := capture.ReadRecords(f)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read %s: %v\n", *expect, err)
+		return 2
+	}
+	diffs := capture.DiffRecords(want, result.Records)
+	for _, d := range diffs {
+		fmt.Println(d)
+	}
+	if len(diffs) > 0 {
+		fmt.Printf("%d differences from %s\n", len(diffs), *expect)
+		return 1
+	}
+	fmt.Printf("records match %s\n", *expect)
+	return 0
+}
+
+// writeRecordsFile сохраняет записи воспроизведения, чтобы использовать их как ожидаемые (-expect)
+func writeRecordsFile(path string, result *capture.ReplayResult) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := capture.WriteRecords(f, result.Records); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -3,20 +3,25 @@ package main
 import (
 	"bufio"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"os"
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/nats-io/nats.go"
 	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/pkg/tnats"
 	"github.com/rackov/NavControlSystem/proto"
-	"github.com/rackov/NavControlSystem/services/receiver/internal/handler/arnavi"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/commandqueue"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/devices"
 	"github.com/rackov/NavControlSystem/services/receiver/internal/protocol"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/spool"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/tlsconfig"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
@@ -31,12 +36,10 @@ type ReceiverServer struct {
 	proto.UnimplementedReceiverControlServer
 	proto.UnimplementedLogReaderServer
 	cfg                  *Config
-	nc                   *nats.Conn
-	js                   nats.JetStreamContext
-	handlers             map[string]protocol.ProtocolHandler // Карта обработчиков по имени
+	nats                 atomic.Pointer[tnats.Client]        // клиент публикуется до обработки его событий, см. connectNats
+	handlers             map[string]protocol.ProtocolHandler // Карта обработчиков по ID порта
 	handlersMu           sync.RWMutex
 	grpcServer           *grpc.Server
-	natsSubject          string    // Топик NATS для публикации данных
 	natsStatusChangeChan chan bool // Канал для получения уведомлений о статусе NATS (true=connected, false=disconnected)
 	// --- НОВЫЕ ПОЛЯ ДЛЯ АСИНХРОННОСТИ ---
 	configChangeChan chan func() error // Канал для функций, меняющих конфигурацию
@@ -44,23 +47,57 @@ type ReceiverServer struct {
 
 	// Список ID портов, которые были активны до последнего падения NATS.
 	lastActivePortIDs map[string]bool
-	// --- НОВОЕ ПОЛЕ ДЛЯ ДЕДУПЛИКАЦИИ ---
-	natsDisconnectedFlag bool
+	// Отключение уже обработано: обработчики NATS вызываются из горутин клиента,
+	// а ClosedHandler может сработать после DisconnectErrHandler
+	natsDisconnectedFlag atomic.Bool
 	isStopping           bool
+
+	// Контекст сервиса. Обработчики портов, открытых через gRPC, живут в нем,
+	// а не в контексте запроса.
+	ctx context.Context
+
+	// Дисковый буфер данных на время недоступности NATS (nil, если выключен)
+	spool    *spool.Spool
+	draining atomic.Bool // идет выгрузка буфера в NATS
+
+	// Окно асинхронных публикаций JetStream: слот занят, пока публикация ждет подтверждения
+	pubWindow chan struct{}
+	pubWG     sync.WaitGroup // горутины ожидания подтверждений
+
+	// Подписка на команды устройствам и их статусы (см. commands.go)
+	commandSub   *nats.Subscription
+	commandQueue *commandqueue.Queue
+	commandSlots chan struct{} // занятые слоты - команды из NATS, которые выполняются сейчас
+
+	// Реестр устройств (nil, если выключен, см. devices.go)
+	devices *devices.Registry
+
+	// Сертификаты открытых TLS-портов, ключ - ID порта (под handlersMu, см. tls.go)
+	tlsSources map[string]*tlsconfig.Source
+
+	// Запись трафика, включенная через gRPC: ключ - ID порта или устройства (под captureMu, см. capture.go)
+	captureMu      sync.Mutex
+	capturePorts   map[string]bool
+	captureDevices map[string]bool
 }
 
 // NewReceiverServer создает новый экземпляр сервера.
 func NewReceiverServer(cfg *Config) *ReceiverServer {
 	return &ReceiverServer{
-		cfg:         cfg,
-		handlers:    make(map[string]protocol.ProtocolHandler),
-		natsSubject: "nav.data", // Стандартный топик для данных
+		cfg:        cfg,
+		handlers:   make(map[string]protocol.ProtocolHandler),
+		tlsSources: make(map[string]*tlsconfig.Source),
+		// Запись трафика не сохраняется в конфигурации и после перезапуска выключена
+		capturePorts:   make(map[string]bool),
+		captureDevices: make(map[string]bool),
 		// Инициализируем канал при создании сервера
 		natsStatusChangeChan: make(chan bool, 1),          // Буферизированный канал на 1 сообщение
 		configChangeChan:     make(chan func() error, 10), // Буферизированный канал
 		shutdownChan:         make(chan struct{}),
 		lastActivePortIDs:    make(map[string]bool),
-		natsDisconnectedFlag: false,
+		commandQueue:         commandqueue.New(cfg.Commands.Retention),
+		pubWindow:            make(chan struct{}, cfg.Nats.MaxPending),
+		commandSlots:         make(chan struct{}, cfg.Commands.MaxConcurrent),
 	}
 }
 
@@ -68,6 +105,33 @@ func NewReceiverServer(cfg *Config) *ReceiverServer {
 // Start запускает все компоненты сервиса в правильной последовательности.
 func (s *ReceiverServer) Start(ctx context.Context) error {
 	logger.Info("Starting RECEIVER server...")
+	s.ctx = ctx
+
+	// 0. Открываем дисковый буфер до подключения к NATS, чтобы не потерять данные с первых секунд
+	if s.cfg.Spool.Dir != "" {
+		sp, err := spool.Open(s.cfg.Spool.Dir, spool.Options{
+			SegmentSize: s.cfg.Spool.SegmentSizeMB << 20,
+			MaxSize:     s.cfg.Spool.MaxSizeMB << 20,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to open spool: %w", err)
+		}
+		s.spool = sp
+		ServiceMetrics.SetGauge("spool_size_bytes", float64(sp.Size()))
+		logger.Infof("Spool opened at %s (%d bytes pending)", s.cfg.Spool.Dir, sp.Size())
+	}
+
+	// Реестр устройств нужен до открытия портов: без него порты допустили бы всех
+	if s.cfg.Devices.File != "" {
+		registry, err := devices.Open(s.cfg.Devices.File)
+		if err != nil {
+			return fmt.Errorf("failed to open device registry: %w", err)
+		}
+		s.devices = registry
+		logger.Infof("Device registry loaded from %s (%d devices)", s.cfg.Devices.File, len(registry.List("")))
+	} else {
+		logger.Warn("Device registry is not configured, all devices are allowed")
+	}
 
 	// 1. Запускаем мониторинг событий NATS.
 	// Эта функция теперь запускает горутину natsEventLoop и сразу возвращает управление.
@@ -103,6 +167,8 @@ func (s *ReceiverServer) Start(ctx context.Context) error {
 	// 5. Запускаем воркер для изменений конфигурации.
 	s.startConfigWorker(ctx)
 	logger.Info("Configuration worker started.")
+	s.startCommandQueueWorker(ctx)
+	s.startStatsCollector(ctx)
 
 	// // 6. Запускаем сервер Prometheus метрик.
 	// go func() {
@@ -130,92 +196,94 @@ func (s *ReceiverServer) Stop() {
 	logger.Debug("Stopping protocol handlers...")
 	s.stopProtocolHandlers()
 
-	// Закрываем соединение с NATS
-	if s.nc != nil {
+	// Закрываем соединение с NATS, дождавшись подтверждений отправленных сообщений.
+	// Неподтвержденные сообщения попадут в буфер (см. publishFailed).
+	if client := s.nats.Load(); client != nil {
+		select {
+		case <-client.JSPublishAsyncComplete():
+		case <-time.After(s.cfg.Nats.AckTimeout):
+			logger.Warn("Not all JetStream publishes were acknowledged before shutdown")
+		}
 		logger.Debug("Closing NATS connection...")
-		s.nc.Close()
+		client.Close()
 		ServiceMetrics.SetGauge("nats_connected", 0)
 	}
+	s.pubWG.Wait()
+
+	// Закрываем буфер последним: невыгруженные данные останутся на диске до следующего запуска
+	if s.spool != nil {
+		if err := s.spool.Close(); err != nil {
+			logger.Errorf("Failed to close spool: %v", err)
+		}
+	}
 	logger.Info("RECEIVER server stopped.")
 }
 
 // --- NATS Management ---
 
+// natsClientWait - пауза перед повторной обработкой подключения, если клиент NATS еще не сохранен
+const natsClientWait = 100 * time.Millisecond
+
 // connectNats настраивает и запускает процесс подключения к NATS.
 // Он не блокирует, а только инициирует подключение.
 func (s *ReceiverServer) connectNats() error {
-	var err error
-	// Настраиваем опции, включая обработчики
-	opts := []nats.Option{
-		nats.ReconnectWait(2 * time.Second),
-		nats.MaxReconnects(5),
-		nats.DisconnectErrHandler(func(nc *nats.Conn, err error) {
-			logger.Warnf("NATS connection disconnected: %v. Triggering immediate port shutdown.", err)
-			select {
-			case s.natsStatusChangeChan <- false:
-				logger.Debug("Sent 'disconnected' signal to NATS status channel")
-				s.natsDisconnectedFlag = true
-			default:
-				// Канал полон
-				logger.Debug("NATS status channel is full, 'disconnected' signal not sent")
-			}
-		}),
-		nats.ReconnectHandler(func(nc *nats.Conn) {
-			logger.Info("NATS connection reestablished. Triggering port restore.")
-			s.natsDisconnectedFlag = false
-			select {
-			case s.natsStatusChangeChan <- true:
-				logger.Debug("Sent 'connected' signal to NATS status channel")
-			default:
-				logger.Debug("NATS status channel is full, 'connected' signal not sent")
-			}
-		}),
-		nats.ClosedHandler(func(nc *nats.Conn) {
-			logger.Errorf("NATS connection closed permanently: %v. Triggering port shutdown.", nc.LastError())
-			// ClosedHandler может сработать после DisconnectErrHandler, проверяем флаг
-			if !s.natsDisconnectedFlag {
-				select {
-				case s.natsStatusChangeChan <- false:
-					s.natsDisconnectedFlag = true
-					logger.Debug("Sent 'disconnected' signal to NATS status channel (from ClosedHandler)")
-				default:
-					logger.Debug("NATS status channel is full, 'disconnected' signal not sent (from ClosedHandler)")
-				}
-			}
-		}),
+	natsCfg := tnats.DefaultConfig(s.cfg.NatsURL)
+	natsCfg.Name = "receiver"
+	natsCfg.MaxReconnects = s.cfg.Nats.MaxReconnects
+	natsCfg.ReconnectWait = s.cfg.Nats.ReconnectWait
+	natsCfg.AsyncMaxPending = s.cfg.Nats.MaxPending
+	// Сервис стартует и без NATS, подключение продолжается в фоне
+	natsCfg.RetryOnFailedConnect = true
+	natsCfg.OnStateChange = s.onNatsStateChange
+
+	client, err := tnats.NewClient(natsCfg, logger.GetLogger())
+	if err != nil {
+		// Ошибка конфигурации (например, неверный URL): повторять подключение бессмысленно
+		logger.Errorf("Initial NATS connection failed: %v", err)
+		s.sendNatsStatus(false)
+		return nil
 	}
+	// StateConnected первого подключения может прийти в natsEventLoop раньше, чем NewClient
+	// вернет управление, поэтому сигнал повторяется здесь, когда клиент уже доступен.
+	s.nats.Store(client)
 
-	// Просто пытаемся подключиться. Если не получится, клиент будет пытаться в фоне.
-	s.nc, err = nats.Connect(s.cfg.NatsURL, opts...)
-	if err != nil {
-		// Не фатальная ошибка, клиент будет пытаться переподключиться.
-		// Но мы должны сообщить об этом.
-		logger.Errorf("Initial NATS connection failed: %v. Client will attempt to reconnect in background.", err)
-		// В этом случае мы считаем, что NATS отключен.
-		select {
-		case s.natsStatusChangeChan <- false:
-			logger.Debug("Sent 'disconnected' signal to NATS status channel (on initial connect fail)")
-		default:
-			logger.Debug("NATS status channel is full, 'disconnected' signal not sent (on initial connect fail)")
-		}
-	} else {
+	if client.IsConnected() {
 		logger.Infof("Successfully connected to NATS at %s", s.cfg.NatsURL)
-		// ServiceMetrics.SetGauge("nats_connected", 1)
-		// При успешном подключении отправляем сигнал "подключен"
-		select {
-		case s.natsStatusChangeChan <- true:
-			logger.Debug("Sent 'connected' signal to NATS status channel (on initial success)")
-		default:
-			logger.Debug("NATS status channel is full, 'connected' signal not sent (on initial success)")
+		ServiceMetrics.SetGauge("nats_connected", 1)
+		s.sendNatsStatus(true)
+	} else {
+		logger.Warnf("NATS at %s is not available yet, connecting in background", s.cfg.NatsURL)
+		s.sendNatsStatus(false)
+	}
+	return nil // Всегда возвращаем nil, т.к. запуск асинхронный
+}
+
+// onNatsStateChange переводит события клиента NATS в сигналы для natsEventLoop.
+func (s *ReceiverServer) onNatsStateChange(state tnats.State, err error) {
+	logger.Infof("NATS connection state changed: %s", state)
+	switch state {
+	case tnats.StateConnected, tnats.StateReconnected:
+		s.natsDisconnectedFlag.Store(false)
+		ServiceMetrics.SetGauge("nats_connected", 1)
+		s.sendNatsStatus(true)
+	case tnats.StateDisconnected, tnats.StateClosed:
+		ServiceMetrics.SetGauge("nats_connected", 0)
+		// ClosedHandler может сработать после DisconnectErrHandler, проверяем флаг
+		if !s.natsDisconnectedFlag.CompareAndSwap(false, true) {
+			return
 		}
+		s.sendNatsStatus(false)
 	}
+}
 
-	s.js, err = s.nc.JetStream()
-	if err != nil {
-		logger.Warnf("JetStream not available, falling back to core NATS. Error: %v", err)
-		s.js = nil
+// sendNatsStatus неблокирующе отправляет состояние NATS в natsEventLoop
+func (s *ReceiverServer) sendNatsStatus(connected bool) {
+	select {
+	case s.natsStatusChangeChan <- connected:
+		logger.Debugf("Sent NATS status signal (connected=%t)", connected)
+	default:
+		logger.Debugf("NATS status channel is full, signal (connected=%t) not sent", connected)
 	}
-	return nil // Всегда возвращаем nil, т.к. запуск асинхронный
 }
 
 // monitorNatsConnection слушает канал с уведомлениями и управляет обработчиками.
@@ -226,53 +294,14 @@ func (s *ReceiverServer) monitorNatsConnection(ctx context.Context) {
 	// Эта горутина теперь просто завершается.
 }
 
-// Реализация интерфейса DataPublisher для передачи в обработчики.
-func (s *ReceiverServer) Publish(data *protocol.NavRecord) error {
-	// Проверяем флаг из конфигурации
-	if s.cfg.Nats.PublishingDisabled {
-		logger.Warnf("NATS publishing is DISABLED in configuration. Skipping publish for client ID: %d", data.Client)
-		// Возвращаем nil, чтобы не прерывать обработку данных от оборудования
-		return nil
-	}
-
-	if s.nc == nil || !s.nc.IsConnected() {
-		return fmt.Errorf("NATS is not connected")
-	}
-
-	jsonData, err := json.Marshal(data)
-	if err != nil {
-		ServiceMetrics.IncErrorCounter("nats_marshal_failed")
-		return fmt.Errorf("failed to marshal navigation data: %w", err)
-	}
-
-	if s.js != nil {
-		_, err = s.js.Publish(s.natsSubject, jsonData)
-	} else {
-		err = s.nc.Publish(s.natsSubject, jsonData)
-	}
-
-	if err != nil {
-		ServiceMetrics.IncErrorCounter("nats_publish_failed")
-		return fmt.Errorf("failed to publish to NATS: %w", err)
-	}
-
-	ServiceMetrics.IncOperationCounter("nats_published")
-	return nil
-}
-
-func (s *ReceiverServer) IsConnected() bool {
-	return s.nc != nil && s.nc.IsConnected()
-}
-
 // --- Protocol Handlers Management ---
 
-// startProtocolHandlers инициализирует и запускает обработчики для каждого
-// протокола, указанного в конфигурации.
+// startProtocolHandlers запускает обработчики для портов из конфигурации.
+// Уже запущенные порты не перезапускаются, поэтому подключенные к ним трекеры не теряют сессию.
 // startProtocolHandlers теперь принимает флаг `restoreMode`.
 func (s *ReceiverServer) startProtocolHandlers(ctx context.Context, restoreMode bool) error {
 	s.handlersMu.Lock()
 	defer s.handlersMu.Unlock()
-	s.stopProtocolHandlersInternal()
 
 	s.cfg.mu.RLock()
 	defer s.cfg.mu.RUnlock()
@@ -304,25 +333,25 @@ func (s *ReceiverServer) startProtocolHandlers(ctx context.Context, restoreMode
 		return nil
 	}
 
+	started := make([]string, 0, len(portsToStart))
 	for _, protoCfg := range portsToStart {
-		var handler protocol.ProtocolHandler
-		switch protoCfg.Name {
-		case "ARNAVI":
-			handler = arnavi.NewArnaviHandler()
-		// case "ARNAVI": ...
-		default:
-			logger.Warnf("Unsupported protocol: %s, skipping", protoCfg.Name)
+		if _, running := s.handlers[protoCfg.ID]; running {
+			logger.Debugf("Port %d (ID: %s) is already running, skipping.", protoCfg.Port, protoCfg.ID)
 			continue
 		}
-
-		if err := handler.Start(ctx, s, protoCfg.Port); err != nil {
-			logger.Errorf("Failed to start %s handler on port %d (ID: %s): %v", protoCfg.Name, protoCfg.Port, protoCfg.ID, err)
-			s.stopProtocolHandlersInternal()
-			return fmt.Errorf("failed to start %s handler", protoCfg.Name)
+		if err := protocol.ValidatePort(protoCfg.Name, protoCfg.Options); err != nil {
+			logger.Warnf("Cannot start port %d (ID: %s): %v, skipping", protoCfg.Port, protoCfg.ID, err)
+			continue
 		}
 
-		s.handlers[protoCfg.ID] = handler
-		logger.Infof("%s handler started successfully on port %d (ID: %s)", protoCfg.Name, protoCfg.Port, protoCfg.ID)
+		if err := s.startPortLocked(ctx, protoCfg); err != nil {
+			// Останавливаем только то, что запустили в этом вызове
+			for _, id := range started {
+				s.stopPortLocked(id)
+			}
+			return err
+		}
+		started = append(started, protoCfg.ID)
 	}
 
 	// После успешного восстановления, очищаем сохраненное состояние, чтобы не влиять на будущие перезапуски
@@ -334,6 +363,105 @@ func (s *ReceiverServer) startProtocolHandlers(ctx context.Context, restoreMode
 	return nil
 }
 
+// startPortLocked создает и запускает обработчик одного порта.
+// Вызывается, когда мьютекс s.handlersMu уже захвачен.
+func (s *ReceiverServer) startPortLocked(ctx context.Context, protoCfg ProtocolConfig) error {
+	if _, running := s.handlers[protoCfg.ID]; running {
+		return nil
+	}
+
+	handlerCfg := protocol.HandlerConfig{
+		PortID:    protoCfg.ID,
+		Port:      protoCfg.Port,
+		Options:   protoCfg.Options,
+		Transport: protoCfg.Transport,
+	}
+	var tlsSource *tlsconfig.Source
+	if protoCfg.TLS != nil {
+		src, err := tlsconfig.New(protoCfg.TLS.options())
+		if err != nil {
+			return fmt.Errorf("failed to load TLS certificate for port %d: %w", protoCfg.Port, err)
+		}
+		tlsSource = src
+		handlerCfg.TLS = src.Config()
+	}
+
+	handler, err := protocol.NewHandler(protoCfg.Name, handlerCfg)
+	if err != nil {
+		return fmt.Errorf("failed to create %s handler: %w", protoCfg.Name, err)
+	}
+
+	handler.SetCapture(s.captureConfig(protoCfg))
+
+	publisher := &portPublisher{server: s, protocol: handler.GetName(), portID: protoCfg.ID}
+	if err := handler.Start(ctx, publisher, protoCfg.Port); err != nil {
+		logger.Errorf("Failed to start %s handler on port %d (ID: %s): %v", protoCfg.Name, protoCfg.Port, protoCfg.ID, err)
+		return fmt.Errorf("failed to start %s handler on port %d: %w", protoCfg.Name, protoCfg.Port, err)
+	}
+
+	s.handlers[protoCfg.ID] = handler
+	if tlsSource != nil {
+		s.tlsSources[protoCfg.ID] = tlsSource
+	}
+	logger.Infof("%s handler started successfully on port %d (ID: %s)", protoCfg.Name, protoCfg.Port, protoCfg.ID)
+	return nil
+}
+
+// stopPortLocked останавливает обработчик одного порта, остальные порты не затрагиваются.
+// Вызывается, когда мьютекс s.handlersMu уже захвачен.
+func (s *ReceiverServer) stopPortLocked(id string) {
+	handler, running := s.handlers[id]
+	if !running {
+		return
+	}
+	delete(s.handlers, id)
+	delete(s.tlsSources, id)
+	stopHandlerWithTimeout(id, handler, handlerStopTimeout)
+}
+
+// startPort запускает обработчик порта id по его текущей конфигурации.
+func (s *ReceiverServer) startPort(id string) error {
+	portCfg, err := s.cfg.GetPortByID(id)
+	if err != nil {
+		return err
+	}
+
+	s.handlersMu.Lock()
+	defer s.handlersMu.Unlock()
+	return s.startPortLocked(s.ctx, *portCfg)
+}
+
+// stopPort останавливает обработчик порта id, если он запущен.
+func (s *ReceiverServer) stopPort(id string) {
+	s.handlersMu.Lock()
+	defer s.handlersMu.Unlock()
+	s.stopPortLocked(id)
+}
+
+// handlerStopTimeout - сколько ждем остановки одного обработчика
+const handlerStopTimeout = 10 * time.Second
+
+// stopHandlerWithTimeout останавливает обработчик, не дожидаясь его дольше timeout.
+func stopHandlerWithTimeout(name string, h protocol.ProtocolHandler, timeout time.Duration) {
+	done := make(chan struct{})
+	// Запускаем остановку в отдельной горутине
+	go func() {
+		defer close(done)
+		if err := h.Stop(); err != nil {
+			logger.Errorf("Failed to stop %s handler: %v", name, err)
+		}
+	}()
+
+	// Ждем, либо пока остановка завершится, либо сработает таймаут
+	select {
+	case <-done:
+		logger.Infof("Handler %s stopped gracefully.", name)
+	case <-time.After(timeout):
+		// Таймаут! Принудительно логируем и продолжаем.
+		logger.Warnf("Handler %s did not stop in time, forcing shutdown.", name)
+	}
+}
+
 // stopProtocolHandlers останавливает все активные обработчики.
 func (s *ReceiverServer) stopProtocolHandlers() error {
 	// --- ЗАЩИТА ОТ ПОВТОРНОГО ВХОДА ---
@@ -363,28 +491,11 @@ func (s *ReceiverServer) stopProtocolHandlers() error {
 	logger.Infof("Saved %d active port IDs for future restore.", len(s.lastActivePortIDs))
 
 	var wg sync.WaitGroup
-	stopCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
 	for name, handler := range s.handlers {
 		wg.Add(1)
 		go func(name string, h protocol.ProtocolHandler) {
 			defer wg.Done()
-
-			done := make(chan struct{})
-			go func() {
-				defer close(done)
-				if err := h.Stop(); err != nil {
-					logger.Errorf("Failed to stop %s handler: %v", name, err)
-				}
-			}()
-
-			select {
-			case <-done:
-				logger.Infof("Handler %s stopped gracefully.", name)
-			case <-stopCtx.Done():
-				logger.Warnf("Handler %s did not stop in time, forcing shutdown.", name)
-			}
+			stopHandlerWithTimeout(name, h, handlerStopTimeout)
 		}(name, handler)
 	}
 
@@ -395,65 +506,6 @@ func (s *ReceiverServer) stopProtocolHandlers() error {
 	return nil
 }
 
-// stopProtocolHandlersInternal - внутренняя версия без мьютекса.
-// Вызывается, когда mьютекс s.handlersMu уже захвачен.
-func (s *ReceiverServer) stopProtocolHandlersInternal() {
-	if len(s.handlers) == 0 {
-		return
-	}
-
-	logger.Infof("Stopping all protocol handlers...")
-
-	// --- ШАГ 1: Сохранение состояния для восстановления ---
-	// Копируем ID всех активных обработчиков в специальную карту.
-	// Это нужно, чтобы знать, какие порты открывать после восстановления NATS.
-	s.lastActivePortIDs = make(map[string]bool)
-	for id := range s.handlers {
-		s.lastActivePortIDs[id] = true
-	}
-	logger.Infof("Saved %d active port IDs for future restore.", len(s.lastActivePortIDs))
-
-	// --- ШАГ 2: Остановка каждого обработчика с таймаутом ---
-	// Это ключевое место для решения проблемы зависания.
-	var wg sync.WaitGroup
-	// Создаем контекст с таймаутом, чтобы не ждать вечно.
-	stopCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel() // Важно!
-
-	for name, handler := range s.handlers {
-		wg.Add(1)
-		go func(name string, h protocol.ProtocolHandler) {
-			defer wg.Done()
-
-			done := make(chan struct{})
-			// Запускаем остановку в отдельной горутине
-			go func() {
-				defer close(done)
-				if err := h.Stop(); err != nil {
-					logger.Errorf("Failed to stop %s handler: %v", name, err)
-				}
-			}()
-
-			// Ждем, либо пока остановка завершится, либо сработает таймаут
-			select {
-			case <-done:
-				logger.Infof("Handler %s stopped gracefully.", name)
-			case <-stopCtx.Done():
-				// Таймаут! Принудительно логируем и продолжаем.
-				logger.Warnf("Handler %s did not stop in time, forcing shutdown.", name)
-			}
-		}(name, handler)
-	}
-
-	// Ждем, пока все горутины либо завершатся, либо истечет таймаут.
-	wg.Wait()
-
-	// --- ШАГ 3: Очистка ---
-	// Очищаем карту запущенных обработчиков.
-	s.handlers = make(map[string]protocol.ProtocolHandler)
-	logger.Info("All protocol handlers stopped (or timed out).")
-}
-
 // --- gRPC Server Management ---
 
 // startGrpcServer инициализирует и запускает gRPC-сервер в отдельной горутине.
@@ -502,6 +554,8 @@ func (s *ReceiverServer) GetStatus(ctx context.Context, req *proto.GetStatusRequ
 	s.handlersMu.RLock()
 	defer s.handlersMu.RUnlock()
 
+	tlsInfo := s.portTLSInfoLocked()
+
 	// Блокируем конфигурацию на время чтения
 	s.cfg.mu.RLock()
 	defer s.cfg.mu.RUnlock()
@@ -517,11 +571,33 @@ func (s *ReceiverServer) GetStatus(ctx context.Context, req *proto.GetStatusRequ
 		isOpen := portCfg.Active
 
 		response.Ports = append(response.Ports, &proto.PortStatus{
-			Id:     portCfg.ID, // <-- Добавляем ID
-			Name:   portCfg.Name,
-			Port:   int32(portCfg.Port),
-			IsOpen: isOpen,
+			Id:        portCfg.ID, // <-- Добавляем ID
+			Name:      portCfg.Name,
+			Port:      int32(portCfg.Port),
+			IsOpen:    isOpen,
+			Options:   portCfg.Options,
+			Transport: portCfg.transport(),
 		})
+		info, loaded := tlsInfo[portCfg.ID]
+		response.Ports[len(response.Ports)-1].Tls = portTLSProto(portCfg.TLS, info, loaded)
+	}
+
+	// Протоколы, которые можно использовать в AddPort
+	for _, d := range protocol.Protocols() {
+		info := &proto.ProtocolInfo{
+			Name:        d.Name,
+			Description: d.Description,
+			Udp:         d.UDP,
+		}
+		for _, opt := range d.Options {
+			info.Options = append(info.Options, &proto.ProtocolOption{
+				Name:         opt.Name,
+				Description:  opt.Description,
+				DefaultValue: opt.Default,
+				Required:     opt.Default == "" && !opt.Optional,
+			})
+		}
+		response.Protocols = append(response.Protocols, info)
 	}
 
 	logger.Debugf("GRPC call: GetStatus. Found %d port configurations.", len(response.Ports))
@@ -544,32 +620,160 @@ func (s *ReceiverServer) GetActiveConnectionsCount(ctx context.Context, req *pro
 	return wrapperspb.Int32(int32(count)), nil
 }
 
-// GetConnectedClients возвращает список информации о подключенных клиентах для протокола.
+// GetConnectedClients возвращает список информации о подключенных клиентах порта.
+// Если порт не указан, возвращаются клиенты всех портов.
 func (s *ReceiverServer) GetConnectedClients(ctx context.Context, req *proto.GetClientsRequest) (*proto.GetClientsResponse, error) {
 	s.handlersMu.RLock()
 	defer s.handlersMu.RUnlock()
 
-	handler, exists := s.handlers[req.ProtocolName]
-	if !exists {
-		logger.Warnf("GRPC call GetConnectedClients for unknown protocol: %s", req.ProtocolName)
-		return nil, status.Errorf(codes.NotFound, "protocol handler '%s' not found", req.ProtocolName)
+	handlers := s.handlers
+	if req.ProtocolName != "" {
+		handler, exists := s.handlers[req.ProtocolName]
+		if !exists {
+			logger.Warnf("GRPC call GetConnectedClients for unknown protocol: %s", req.ProtocolName)
+			return nil, status.Errorf(codes.NotFound, "protocol handler '%s' not found", req.ProtocolName)
+		}
+		handlers = map[string]protocol.ProtocolHandler{req.ProtocolName: handler}
 	}
 
-	connectedClients := handler.GetConnectedClients()
-
-	grpcClients := make([]*proto.ClientInfo, 0, len(connectedClients))
-	for _, client := range connectedClients {
-		grpcClients = append(grpcClients, &proto.ClientInfo{
-			Id:             client.ID,
-			Address:        client.Addr,
-			ConnectedSince: client.Since.Unix(),
-		})
+	grpcClients := make([]*proto.ClientInfo, 0)
+	for id, handler := range handlers {
+		for _, client := range handler.GetConnectedClients() {
+			grpcClients = append(grpcClients, clientInfoProto(id, handler, client))
+		}
 	}
 
 	logger.Infof("GRPC call: GetConnectedClients for %s, found %d clients", req.ProtocolName, len(grpcClients))
 	return &proto.GetClientsResponse{Clients: grpcClients}, nil
 }
 
+// FindDevice ищет подключения устройства по его ID на всех портах.
+func (s *ReceiverServer) FindDevice(ctx context.Context, req *proto.DeviceIdentifier) (*proto.GetClientsResponse, error) {
+	if req.DeviceId == "" {
+		return nil, status.Error(codes.InvalidArgument, "device_id is not specified")
+	}
+
+	s.handlersMu.RLock()
+	defer s.handlersMu.RUnlock()
+
+	grpcClients := make([]*proto.ClientInfo, 0)
+	for id, handler := range s.handlers {
+		if client, ok := handler.GetClient(req.DeviceId); ok {
+			grpcClients = append(grpcClients, clientInfoProto(id, handler, client))
+		}
+	}
+	if len(grpcClients) == 0 {
+		return nil, status.Errorf(codes.NotFound, "device %s is not connected", req.DeviceId)
+	}
+	return &proto.GetClientsResponse{Clients: grpcClients}, nil
+}
+
+// DisconnectDevice принудительно отключает устройство по его ID.
+func (s *ReceiverServer) DisconnectDevice(ctx context.Context, req *proto.DisconnectDeviceRequest) (*proto.DisconnectClientResponse, error) {
+	if req.DeviceId == "" {
+		return nil, status.Error(codes.InvalidArgument, "device_id is not specified")
+	}
+
+	s.handlersMu.RLock()
+	defer s.handlersMu.RUnlock()
+
+	logger.Infof("GRPC call: DisconnectDevice %s (port: %q)", req.DeviceId, req.ProtocolName)
+	disconnected := 0
+	for id, handler := range s.handlers {
+		if req.ProtocolName != "" && id != req.ProtocolName {
+			continue
+		}
+		if _, ok := handler.GetClient(req.DeviceId); !ok {
+			continue
+		}
+		if err := handler.DisconnectDevice(req.DeviceId); err != nil {
+			logger.Errorf("Failed to disconnect device %s on port %s: %v", req.DeviceId, id, err)
+			continue
+		}
+		disconnected++
+	}
+	if disconnected == 0 {
+		return &proto.DisconnectClientResponse{Success: false}, status.Errorf(codes.NotFound, "device %s is not connected", req.DeviceId)
+	}
+	return &proto.DisconnectClientResponse{Success: true}, nil
+}
+
+// ListBans возвращает IP-адреса, заблокированные на портах после неудачных авторизаций.
+func (s *ReceiverServer) ListBans(ctx context.Context, req *proto.ListBansRequest) (*proto.ListBansResponse, error) {
+	s.handlersMu.RLock()
+	defer s.handlersMu.RUnlock()
+
+	grpcBans := make([]*proto.BanInfo, 0)
+	for id, handler := range s.handlers {
+		if req.ProtocolName != "" && id != req.ProtocolName {
+			continue
+		}
+		for _, ban := range handler.ListBans() {
+			grpcBans = append(grpcBans, &proto.BanInfo{
+				Ip:       ban.IP,
+				PortId:   id,
+				Protocol: handler.GetName(),
+				Until:    ban.Until.Unix(),
+				Failures: int32(ban.Failures),
+			})
+		}
+	}
+	return &proto.ListBansResponse{Bans: grpcBans}, nil
+}
+
+// Unban снимает блокировку IP-адреса на портах.
+func (s *ReceiverServer) Unban(ctx context.Context, req *proto.UnbanRequest) (*proto.DisconnectClientResponse, error) {
+	if req.Ip == "" {
+		return nil, status.Error(codes.InvalidArgument, "ip is not specified")
+	}
+
+	s.handlersMu.RLock()
+	defer s.handlersMu.RUnlock()
+
+	logger.Infof("GRPC call: Unban %s (port: %q)", req.Ip, req.ProtocolName)
+	unbanned := 0
+	for id, handler := range s.handlers {
+		if req.ProtocolName != "" && id != req.ProtocolName {
+			continue
+		}
+		if handler.Unban(req.Ip) {
+			unbanned++
+		}
+	}
+	if unbanned == 0 {
+		return &proto.DisconnectClientResponse{Success: false}, status.Errorf(codes.NotFound, "ip %s is not banned", req.Ip)
+	}
+	return &proto.DisconnectClientResponse{Success: true}, nil
+}
+
+// clientInfoProto преобразует информацию о подключении клиента порта portID в ответ gRPC.
+func clientInfoProto(portID string, handler protocol.ProtocolHandler, client protocol.ClientInfo) *proto.ClientInfo {
+	return &proto.ClientInfo{
+		Id:              client.ID,
+		Address:         client.Addr,
+		ConnectedSince:  client.Since.Unix(),
+		PortId:          portID,
+		Protocol:        handler.GetName(),
+		BytesIn:         client.Stats.BytesIn,
+		BytesOut:        client.Stats.BytesOut,
+		Packets:         client.Stats.Packets,
+		Records:         client.Stats.Records,
+		DecodeErrors:    client.Stats.DecodeErrors,
+		LastPacket:      unixTime(client.Stats.LastPacket),
+		LastFix:         unixTime(client.Stats.LastFix),
+		ProtocolVersion: client.Stats.ProtocolVersion,
+		Transport:       client.Net,
+	}
+}
+
+// unixTime возвращает время в секундах Unix, для нулевого времени - 0.
+func unixTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
 // DisconnectClient принудительно отключает клиента по его адресу.
 func (s *ReceiverServer) DisconnectClient(ctx context.Context, req *proto.DisconnectClientRequest) (*proto.DisconnectClientResponse, error) {
 	s.handlersMu.RLock()
@@ -592,29 +796,38 @@ func (s *ReceiverServer) DisconnectClient(ctx context.Context, req *proto.Discon
 	return &proto.DisconnectClientResponse{Success: true}, nil
 }
 
-// OpenPort делает порт активным и перезапускает обработчики.
+// OpenPort делает порт активным и запускает только его обработчик.
+// Выполняется в воркере изменений конфигурации, по очереди с ClosePort, AddPort и DeletePort.
 func (s *ReceiverServer) OpenPort(ctx context.Context, req *proto.PortIdentifier) (*proto.PortOperationResponse, error) {
 	logger.Infof("GRPC call: OpenPort for ID %s", req.Id)
 
-	// 1. Меняем состояние в конфигурации
-	if err := s.cfg.SetPortState(req.Id, true); err != nil {
-		return &proto.PortOperationResponse{
-			Success: false,
-			Message: err.Error(),
-		}, nil
+	var portCfg ProtocolConfig
+	err := s.runConfigTask(ctx, func() error {
+		// 1. Меняем состояние в конфигурации
+		if err := s.cfg.SetPortState(req.Id, true); err != nil {
+			return err
+		}
+		// 2. Запускаем обработчик этого порта, остальные порты не трогаем
+		if err := s.startPort(req.Id); err != nil {
+			// Если запуск не удался, откатываем изменение в конфиге
+			_ = s.cfg.SetPortState(req.Id, false) // Игнорируем ошибку при откате
+			return fmt.Errorf("failed to start handler after opening port: %w", err)
+		}
+		if p, err := s.cfg.GetPortByID(req.Id); err == nil {
+			portCfg = *p
+		}
+		return nil
+	})
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return nil, status.Errorf(codes.Canceled, "request cancelled")
 	}
-
-	// 2. Перезапускаем обработчики, чтобы применить изменения
-	if err := s.startProtocolHandlers(ctx, false); err != nil {
-		// Если перезапуск не удался, откатываем изменение в конфиге
-		_ = s.cfg.SetPortState(req.Id, false) // Игнорируем ошибку при откате
+	if err != nil {
 		return &proto.PortOperationResponse{
 			Success: false,
-			Message: fmt.Sprintf("failed to restart handlers after opening port: %v", err),
+			Message: err.Error(),
 		}, nil
 	}
 
-	portCfg, _ := s.cfg.GetPortByID(req.Id) // Ошибку не проверяем, т.к. SetPortState уже прошел
 	return &proto.PortOperationResponse{
 		Success: true,
 		Message: "Port opened successfully",
@@ -625,17 +838,17 @@ func (s *ReceiverServer) OpenPort(ctx context.Context, req *proto.PortIdentifier
 	}, nil
 }
 
-// ClosePort делает порт неактивным и перезапускает обработчики.
+// ClosePort делает порт неактивным и останавливает только его обработчик.
 func (s *ReceiverServer) ClosePort(ctx context.Context, req *proto.PortIdentifier) (*proto.PortOperationResponse, error) {
 	logger.Infof("GRPC call: ClosePort for ID %s", req.Id)
 
 	task := func() error {
-		// Меняем состояние в конфигурации на неактивное
+		// Меняем состояние в конфигурации на неактивное (SetPortState сохраняет конфиг)
 		if err := s.cfg.SetPortState(req.Id, false); err != nil {
 			return err
 		}
-		// Перезапускаем обработчики
-		return s.restartProtocolHandlers(ctx)
+		s.stopPort(req.Id)
+		return nil
 	}
 
 	select {
@@ -664,22 +877,57 @@ func (s *ReceiverServer) ClosePort(ctx context.Context, req *proto.PortIdentifie
 func (s *ReceiverServer) AddPort(ctx context.Context, req *proto.PortDefinition) (*proto.PortOperationResponse, error) {
 	logger.Infof("GRPC call: AddPort for %s on port %d", req.Name, req.Port)
 
+	// Проверяем протокол до постановки задачи в очередь, чтобы сразу вернуть ошибку клиенту
+	if err := protocol.ValidatePort(req.Name, req.Options); err != nil {
+		logger.Warnf("AddPort rejected: %v", err)
+		return &proto.PortOperationResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+	if err := validateTransport(req.Name, req.Transport, tlsConfigFromProto(req.Tls)); err != nil {
+		logger.Warnf("AddPort rejected: %v", err)
+		return &proto.PortOperationResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+	if req.Tls != nil {
+		if err := tlsConfigFromProto(req.Tls).options().Validate(); err != nil {
+			logger.Warnf("AddPort rejected: invalid tls: %v", err)
+			return &proto.PortOperationResponse{
+				Success: false,
+				Message: fmt.Sprintf("invalid tls: %v", err),
+			}, nil
+		}
+	}
+
 	// Создаем задачу (замыкание), которая выполнит всю работу
 	task := func() error {
 		// 1. Добавляем порт в конфигурацию в памяти
-		newPortCfg, err := s.cfg.AddPort(req.Name, int(req.Port))
+		newPortCfg, err := s.cfg.AddPort(req.Name, int(req.Port), req.Options, tlsConfigFromProto(req.Tls), req.Transport)
 		if err != nil {
 			return err
 		}
 		// Логируем детали порта, который был добавлен в конфигурацию
 		logger.Infof("Port added to in-memory config: ID=%s, Name=%s, Port=%d, Active=%t",
 			newPortCfg.ID, newPortCfg.Name, newPortCfg.Port, newPortCfg.Active)
-		// 2. Перезапускаем обработчики, чтобы применить изменения
-		// (startProtocolHandlers теперь тоже будет вызываться асинхронно)
-		return s.restartProtocolHandlers(ctx)
+		// 2. Запускаем обработчик нового порта, остальные порты не трогаем
+		if err := s.startPort(newPortCfg.ID); err != nil {
+			// Откатываем добавление, чтобы конфигурация не расходилась с открытыми портами
+			if delErr := s.cfg.DeletePort(newPortCfg.ID); delErr != nil {
+				logger.Errorf("Failed to roll back port %s: %v", newPortCfg.ID, delErr)
+			}
+			return err
+		}
+		// 3. Сохраняем конфигурацию
+		if err := s.cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save configuration after adding port: %w", err)
+		}
+		return nil
 	}
 
-	// 3. Отправляем задачу в канал и сразу возвращаем ответ клиенту
+	// Отправляем задачу в канал и сразу возвращаем ответ клиенту
 	select {
 	case s.configChangeChan <- task:
 		logger.Info("AddPort task queued successfully.")
@@ -693,46 +941,39 @@ func (s *ReceiverServer) AddPort(ctx context.Context, req *proto.PortDefinition)
 	}
 }
 
-// restartProtocolHandlers перезапускает обработчики и сохраняет конфигурацию.
-func (s *ReceiverServer) restartProtocolHandlers(ctx context.Context) error {
-	if err := s.startProtocolHandlers(ctx, false); err != nil {
-		return fmt.Errorf("failed to restart protocol handlers: %w", err)
-	}
-	logger.Info("Protocol handlers restarted successfully. Saving configuration.")
-	if err := s.cfg.Save(); err != nil {
-		// Это критическая ошибка, т.к. состояние в памяти и на диске разошлось.
-		return fmt.Errorf("failed to save configuration after restart: %w", err)
-	}
-	return nil
-}
-
-// DeletePort удаляет конфигурацию порта и перезапускает обработчики.
+// DeletePort останавливает обработчик порта и удаляет его конфигурацию.
+// Выполняется в воркере изменений конфигурации, по очереди с OpenPort, ClosePort и AddPort.
 func (s *ReceiverServer) DeletePort(ctx context.Context, req *proto.PortIdentifier) (*proto.PortOperationResponse, error) {
 	logger.Infof("GRPC call: DeletePort for ID %s", req.Id)
 
-	// Сначала получаем детали порта для ответа, прежде чем удалять
-	portCfg, err := s.cfg.GetPortByID(req.Id)
-	if err != nil {
-		return &proto.PortOperationResponse{Success: false, Message: err.Error()}, nil
-	}
+	var portCfg ProtocolConfig
+	err := s.runConfigTask(ctx, func() error {
+		// Сначала получаем детали порта для ответа, прежде чем удалять
+		p, err := s.cfg.GetPortByID(req.Id)
+		if err != nil {
+			return err
+		}
+		portCfg = *p
 
-	if err := s.cfg.DeletePort(req.Id); err != nil {
-		return &proto.PortOperationResponse{Success: false, Message: err.Error()}, nil
-	}
+		// Закрываем порт, если он был открыт; трекеры на других портах остаются подключенными
+		s.stopPort(req.Id)
 
-	// Перезапускаем обработчики, чтобы закрыть порт, если он был активен
-	if err := s.startProtocolHandlers(ctx, false); err != nil {
-		// Откатить удаление сложно, просто логируем ошибку
-		logger.Errorf("Failed to restart handlers after deleting port %s: %v", req.Id, err)
-		// Возвращаем успех, т.к. порт удален, но сообщаем о проблеме
-		return &proto.PortOperationResponse{
-			Success: true,
-			Message: "Port deleted, but failed to restart handlers: " + err.Error(),
-			PortDetails: &proto.PortDefinition{
-				Name: portCfg.Name,
-				Port: int32(portCfg.Port),
-			},
-		}, nil
+		if err := s.cfg.DeletePort(req.Id); err != nil {
+			// Конфигурация не изменилась: порт должен работать, как до удаления
+			if portCfg.Active {
+				if startErr := s.startPort(req.Id); startErr != nil {
+					logger.Errorf("Failed to restart port %s after failed delete: %v", req.Id, startErr)
+				}
+			}
+			return err
+		}
+		return nil
+	})
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return nil, status.Errorf(codes.Canceled, "request cancelled")
+	}
+	if err != nil {
+		return &proto.PortOperationResponse{Success: false, Message: err.Error()}, nil
 	}
 
 	return &proto.PortOperationResponse{
@@ -871,6 +1112,28 @@ func (s *ReceiverServer) startConfigWorker(ctx context.Context) {
 	}()
 }
 
+// runConfigTask выполняет задачу в воркере изменений конфигурации и ждет ее результата,
+// чтобы операции с портами не пересекались с задачами, уже стоящими в очереди.
+func (s *ReceiverServer) runConfigTask(ctx context.Context, task func() error) error {
+	done := make(chan error, 1)
+	select {
+	case s.configChangeChan <- func() error {
+		err := task()
+		done <- err
+		return err
+	}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // natsEventLoop содержит логику обработки событий от NATS.
 // Он запускается один раз и работает до отмены контекста.
 func (s *ReceiverServer) natsEventLoop(ctx context.Context) {
@@ -879,9 +1142,21 @@ func (s *ReceiverServer) natsEventLoop(ctx context.Context) {
 		case <-ctx.Done():
 			logger.Info("NATS event loop stopped.")
 			return
-		case isConnected := <-s.natsStatusChangeChan:
-			logger.Infof("NATS event loop received event: Connected=%v", isConnected)
+		case signal := <-s.natsStatusChangeChan:
+			client := s.nats.Load()
+			if signal && client == nil {
+				// Первое подключение: connectNats еще не сохранил клиент, повторяем позже
+				time.AfterFunc(natsClientWait, func() { s.sendNatsStatus(true) })
+				continue
+			}
+			// Сигналы могут теряться и приходить с опозданием, решает текущее состояние клиента
+			isConnected := client != nil && client.IsConnected()
+			logger.Infof("NATS event loop received event: Connected=%v (current: %v)", signal, isConnected)
 			if isConnected {
+				// Поток должен существовать до первой публикации
+				if err := s.ensureStream(); err != nil {
+					logger.Errorf("Failed to provision JetStream stream: %v", err)
+				}
 				logger.Info("NATS is connected. Ensuring handlers are running.")
 				// Проверяем, запущены ли вообще обработчики. Если нет, запускаем.
 				s.handlersMu.RLock()
@@ -895,6 +1170,12 @@ func (s *ReceiverServer) natsEventLoop(ctx context.Context) {
 					s.handlersMu.RUnlock()
 					logger.Debug("Handlers are already running, no action needed.")
 				}
+				s.subscribeCommands()
+				// Отправляем накопленные за время простоя данные
+				s.drainSpool()
+			} else if s.spool != nil {
+				// Порты остаются открытыми, данные копятся в дисковом буфере
+				logger.Warn("NATS is disconnected. Keeping ports open, data will be spooled to disk.")
 			} else {
 				logger.Warn("NATS is disconnected. Stopping all handlers.")
 				// Остановка должна быть атомарной и быстрой.
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPortID = "2e44a907-75db-4d95-bb6d-fc95a85107fc"
+
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", ":0")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// newTestServer создает сервер с конфигурацией во временном файле: один закрытый порт WIALON.
+// Запускается только воркер изменений конфигурации, без NATS и gRPC.
+func newTestServer(t *testing.T) (*ReceiverServer, string) {
+	level, _ := logger.ParseLevel("ERROR")
+	logger.Init(level)
+
+	path := filepath.Join(t.TempDir(), "receiver.toml")
+	data := fmt.Sprintf(`nats_url = "nats://localhost:4222"
+
+[[protocols]]
+  id = %q
+  name = "WIALON"
+  port = %d
+  active = false
+`, testPortID, freePort(t))
+	if !assert.NoError(t, os.WriteFile(path, []byte(data), 0644)) {
+		t.FailNow()
+	}
+	cfg, err := LoadConfig(&path)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewReceiverServer(cfg)
+	s.ctx = ctx
+	s.startConfigWorker(ctx)
+	t.Cleanup(func() {
+		s.stopProtocolHandlers()
+		cancel()
+	})
+	return s, path
+}
+
+// call выполняет RPC, не дольше секунды: зависание - ошибка теста, а не таймаут go test
+func call(t *testing.T, rpc func() (*proto.PortOperationResponse, error)) *proto.PortOperationResponse {
+	done := make(chan *proto.PortOperationResponse, 1)
+	go func() {
+		resp, err := rpc()
+		assert.NoError(t, err)
+		done <- resp
+	}()
+	select {
+	case resp := <-done:
+		return resp
+	case <-time.After(time.Second):
+		t.Fatal("RPC did not return")
+		return nil
+	}
+}
+
+// savedPorts читает порты из файла конфигурации. LoadConfig не подходит:
+// он отвергает конфигурацию без портов.
+func savedPorts(t *testing.T, path string) map[string]ProtocolConfig {
+	cfg := &Config{}
+	if _, err := toml.DecodeFile(path, cfg); !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	ports := make(map[string]ProtocolConfig)
+	for _, p := range cfg.ProtocolConfigs {
+		ports[p.ID] = p
+	}
+	return ports
+}
+
+// isRunning проверяет, запущен ли обработчик порта id
+func isRunning(s *ReceiverServer, id string) bool {
+	s.handlersMu.RLock()
+	defer s.handlersMu.RUnlock()
+	_, ok := s.handlers[id]
+	return ok
+}
+
+func TestReceiverServer_OpenClosePort(t *testing.T) {
+	s, path := newTestServer(t)
+	ctx := context.Background()
+
+	resp := call(t, func() (*proto.PortOperationResponse, error) {
+		return s.OpenPort(ctx, &proto.PortIdentifier{Id: testPortID})
+	})
+	assert.True(t, resp.Success, resp.Message)
+	assert.True(t, isRunning(s, testPortID))
+	assert.True(t, savedPorts(t, path)[testPortID].Active)
+
+	resp = call(t, func() (*proto.PortOperationResponse, error) {
+		return s.ClosePort(ctx, &proto.PortIdentifier{Id: testPortID})
+	})
+	assert.True(t, resp.Success, resp.Message)
+	assert.Eventually(t, func() bool { return !isRunning(s, testPortID) }, time.Second, 5*time.Millisecond)
+	assert.False(t, savedPorts(t, path)[testPortID].Active)
+
+	resp = call(t, func() (*proto.PortOperationResponse, error) {
+		return s.OpenPort(ctx, &proto.PortIdentifier{Id: "missing"})
+	})
+	assert.False(t, resp.Success)
+}
+
+func TestReceiverServer_AddPort(t *testing.T) {
+	s, path := newTestServer(t)
+	ctx := context.Background()
+
+	port := freePort(t)
+	resp := call(t, func() (*proto.PortOperationResponse, error) {
+		return s.AddPort(ctx, &proto.PortDefinition{Name: "WIALON", Port: int32(port)})
+	})
+	assert.True(t, resp.Success, resp.Message)
+	assert.Eventually(t, func() bool { return len(savedPorts(t, path)) == 2 }, time.Second, 5*time.Millisecond)
+
+	// порт занят другим процессом: обработчик не запускается, порт не остается в конфигурации,
+	// а воркер конфигурации продолжает выполнять задачи
+	busy, err := net.Listen("tcp", ":0")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer busy.Close()
+	resp = call(t, func() (*proto.PortOperationResponse, error) {
+		return s.AddPort(ctx, &proto.PortDefinition{Name: "WIALON", Port: int32(busy.Addr().(*net.TCPAddr).Port)})
+	})
+	assert.True(t, resp.Success, resp.Message)
+
+	resp = call(t, func() (*proto.PortOperationResponse, error) {
+		return s.ClosePort(ctx, &proto.PortIdentifier{Id: testPortID})
+	})
+	assert.True(t, resp.Success, resp.Message)
+	assert.Eventually(t, func() bool {
+		s.cfg.mu.RLock()
+		defer s.cfg.mu.RUnlock()
+		return len(s.cfg.ProtocolConfigs) == 2
+	}, time.Second, 5*time.Millisecond)
+	assert.Len(t, savedPorts(t, path), 2)
+
+	resp = call(t, func() (*proto.PortOperationResponse, error) {
+		return s.AddPort(ctx, &proto.PortDefinition{Name: "UNKNOWN", Port: int32(port)})
+	})
+	assert.False(t, resp.Success)
+}
+
+func TestReceiverServer_DeletePort(t *testing.T) {
+	s, path := newTestServer(t)
+	ctx := context.Background()
+
+	resp := call(t, func() (*proto.PortOperationResponse, error) {
+		return s.OpenPort(ctx, &proto.PortIdentifier{Id: testPortID})
+	})
+	assert.True(t, resp.Success, resp.Message)
+
+	resp = call(t, func() (*proto.PortOperationResponse, error) {
+		return s.DeletePort(ctx, &proto.PortIdentifier{Id: testPortID})
+	})
+	assert.True(t, resp.Success, resp.Message)
+	assert.False(t, isRunning(s, testPortID))
+	assert.Empty(t, savedPorts(t, path))
+
+	resp = call(t, func() (*proto.PortOperationResponse, error) {
+		return s.DeletePort(ctx, &proto.PortIdentifier{Id: testPortID})
+	})
+	assert.False(t, resp.Success)
+}
+
+func TestReceiverServer_DeletePortSaveFailed(t *testing.T) {
+	s, _ := newTestServer(t)
+	ctx := context.Background()
+
+	resp := call(t, func() (*proto.PortOperationResponse, error) {
+		return s.OpenPort(ctx, &proto.PortIdentifier{Id: testPortID})
+	})
+	assert.True(t, resp.Success, resp.Message)
+
+	// конфигурацию не удалось сохранить: порт остается в ней и снова открыт
+	s.cfg.configPath = filepath.Join(t.TempDir(), "missing", "receiver.toml")
+	resp = call(t, func() (*proto.PortOperationResponse, error) {
+		return s.DeletePort(ctx, &proto.PortIdentifier{Id: testPortID})
+	})
+	assert.False(t, resp.Success)
+	assert.True(t, isRunning(s, testPortID))
+	_, err := s.cfg.GetPortByID(testPortID)
+	assert.NoError(t, err)
+}
+
+func TestReceiverServer_DeletePortAfterQueuedClose(t *testing.T) {
+	s, path := newTestServer(t)
+	ctx := context.Background()
+
+	resp := call(t, func() (*proto.PortOperationResponse, error) {
+		return s.OpenPort(ctx, &proto.PortIdentifier{Id: testPortID})
+	})
+	assert.True(t, resp.Success, resp.Message)
+
+	// DeletePort выполняется после уже поставленной в очередь задачи ClosePort
+	call(t, func() (*proto.PortOperationResponse, error) {
+		return s.ClosePort(ctx, &proto.PortIdentifier{Id: testPortID})
+	})
+	resp = call(t, func() (*proto.PortOperationResponse, error) {
+		return s.DeletePort(ctx, &proto.PortIdentifier{Id: testPortID})
+	})
+	assert.True(t, resp.Success, resp.Message)
+	assert.False(t, isRunning(s, testPortID))
+	assert.Empty(t, savedPorts(t, path))
+}
+
+func TestConnectNats_Unavailable(t *testing.T) {
+	s := newPublishTestServer(t, false)
+	storeOfflineClient(t, s)
+
+	// без NATS при старте клиент продолжает подключаться в фоне, порты без буфера закрываются
+	assert.False(t, s.IsConnected())
+	select {
+	case connected := <-s.natsStatusChangeChan:
+		assert.False(t, connected)
+	default:
+		t.Fatal("disconnected signal was not sent")
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/rackov/NavControlSystem/services/receiver/internal/protocol"
+)
+
+const (
+	// statsInterval - период выгрузки статистики подключений в метрики Prometheus
+	statsInterval = 15 * time.Second
+	// silentClientTimeout - через сколько без пакетов подключенное устройство считается молчащим
+	silentClientTimeout = 10 * time.Minute
+)
+
+// portStatsSample - статистика порта на момент прошлой выгрузки.
+// Обработчик запоминается, чтобы после перезапуска порта считать счетчики с нуля.
+type portStatsSample struct {
+	handler protocol.ProtocolHandler
+	stats   protocol.Stats
+}
+
+// startStatsCollector периодически переносит статистику портов и подключений в метрики:
+// счетчики трафика и пакетов по протоколам и число проблемных подключений
+func (s *ReceiverServer) startStatsCollector(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(statsInterval)
+		defer ticker.Stop()
+
+		samples := make(map[string]portStatsSample)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				s.collectStats(samples, now)
+			}
+		}
+	}()
+}
+
+// collectStats добавляет к счетчикам прирост статистики портов с прошлой выгрузки
+// и обновляет gauge-метрики подключений
+func (s *ReceiverServer) collectStats(samples map[string]portStatsSample, now time.Time) {
+	s.handlersMu.RLock()
+	handlers := make(map[string]protocol.ProtocolHandler, len(s.handlers))
+	for id, handler := range s.handlers {
+		handlers[id] = handler
+	}
+	s.handlersMu.RUnlock()
+
+	var connected, silent, withErrors int
+	for id, handler := range handlers {
+		cur := handler.GetPortStats()
+		var prev protocol.Stats
+		if sample, ok := samples[id]; ok && sample.handler == handler {
+			prev = sample.stats
+		}
+		samples[id] = portStatsSample{handler: handler, stats: cur}
+
+		name := strings.ToLower(handler.GetName())
+		ServiceMetrics.AddOperations(name+"_bytes_received", int(cur.BytesIn-prev.BytesIn))
+		ServiceMetrics.AddOperations(name+"_bytes_sent", int(cur.BytesOut-prev.BytesOut))
+		ServiceMetrics.AddOperations(name+"_packets_decoded", int(cur.Packets-prev.Packets))
+		ServiceMetrics.AddOperations(name+"_records_published", int(cur.Records-prev.Records))
+		ServiceMetrics.AddErrors(name+"_decode_errors", int(cur.DecodeErrors-prev.DecodeErrors))
+		for reason, n := range cur.Closes {
+			ServiceMetrics.AddOperations(name+"_closed_"+reason, int(n-prev.Closes[reason]))
+		}
+		for reason, n := range cur.Drops {
+			ServiceMetrics.AddErrors(name+"_udp_dropped_"+reason, int(n-prev.Drops[reason]))
+		}
+
+		for _, client := range handler.GetConnectedClients() {
+			connected++
+			last := client.Stats.LastPacket
+			if last.IsZero() {
+				last = client.Since
+			}
+			if now.Sub(last) > silentClientTimeout {
+				silent++
+			}
+			if client.Stats.DecodeErrors > 0 {
+				withErrors++
+			}
+		}
+	}
+	for id := range samples {
+		if _, ok := handlers[id]; !ok {
+			delete(samples, id)
+		}
+	}
+
+	ServiceMetrics.SetGauge("active_connections", float64(connected))
+	ServiceMetrics.SetGauge("clients_silent", float64(silent))
+	ServiceMetrics.SetGauge("clients_with_decode_errors", float64(withErrors))
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/proto"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/tlsconfig"
+)
+
+// TLSConfig - настройки TLS порта ([protocols.tls])
+type TLSConfig struct {
+	CertFile     string `toml:"cert_file"`                // Сертификат сервера (PEM)
+	KeyFile      string `toml:"key_file"`                 // Закрытый ключ (PEM)
+	ClientCAFile string `toml:"client_ca_file,omitempty"` // УЦ клиентских сертификатов (PEM)
+	ClientAuth   string `toml:"client_auth,omitempty"`    // none (по умолчанию) | verify_if_given | require
+}
+
+// options преобразует настройки в параметры tlsconfig
+func (c *TLSConfig) options() tlsconfig.Options {
+	return tlsconfig.Options{
+		CertFile:     c.CertFile,
+		KeyFile:      c.KeyFile,
+		ClientCAFile: c.ClientCAFile,
+		ClientAuth:   c.ClientAuth,
+	}
+}
+
+// tlsConfigFromProto преобразует настройки TLS из запроса gRPC, nil - порт без TLS
+func tlsConfigFromProto(p *proto.PortTLS) *TLSConfig {
+	if p == nil {
+		return nil
+	}
+	return &TLSConfig{
+		CertFile:     p.CertFile,
+		KeyFile:      p.KeyFile,
+		ClientCAFile: p.ClientCaFile,
+		ClientAuth:   p.ClientAuth,
+	}
+}
+
+// portTLSProto преобразует настройки TLS порта в ответ gRPC. info - сертификат,
+// загруженный открытым портом (loaded=false, если порт закрыт).
+func portTLSProto(c *TLSConfig, info tlsconfig.Info, loaded bool) *proto.PortTLS {
+	if c == nil {
+		return nil
+	}
+	p := &proto.PortTLS{
+		CertFile:     c.CertFile,
+		KeyFile:      c.KeyFile,
+		ClientCaFile: c.ClientCAFile,
+		ClientAuth:   c.ClientAuth,
+	}
+	if loaded {
+		p.CertSubject = info.Subject
+		p.CertNotAfter = info.NotAfter.Unix()
+	}
+	return p
+}
+
+// portTLSInfoLocked возвращает сведения о сертификатах открытых портов, ключ - ID порта.
+// Вызывается, когда мьютекс s.handlersMu уже захвачен.
+func (s *ReceiverServer) portTLSInfoLocked() map[string]tlsconfig.Info {
+	infos := make(map[string]tlsconfig.Info, len(s.tlsSources))
+	for id, src := range s.tlsSources {
+		infos[id] = src.Info()
+	}
+	return infos
+}
+
+// reloadCertificates перечитывает сертификаты открытых портов. Слушатели не пересоздаются,
+// новые сертификаты получают следующие подключения.
+func (s *ReceiverServer) reloadCertificates() {
+	s.handlersMu.RLock()
+	defer s.handlersMu.RUnlock()
+
+	for id, src := range s.tlsSources {
+		if err := src.Reload(); err != nil {
+			logger.Errorf("Failed to reload TLS certificate for port %s: %v", id, err)
+			continue
+		}
+		logger.Infof("TLS certificate for port %s reloaded (%s, valid until %s)", id, src.Info().Subject, src.Info().NotAfter)
+	}
+}
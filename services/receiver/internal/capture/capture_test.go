@@ -0,0 +1,100 @@
+package capture
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriterReader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.cap")
+	header := Header{Protocol: "WIALON", PortID: "id", Port: 20332, Remote: "127.0.0.1:5000", DeviceID: "42", Started: time.Unix(1700000000, 0)}
+
+	w, err := Create(path, header)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.NoError(t, w.Write(Chunk{Time: time.Unix(1700000001, 0), Direction: In, Data: []byte("#L#42;NA\r\n")}))
+	assert.NoError(t, w.Write(Chunk{Time: time.Unix(1700000002, 0), Direction: Out, Data: []byte("#AL#1\r\n")}))
+	assert.NoError(t, w.Close())
+
+	got, chunks, err := ReadAll(path)
+	assert.NoError(t, err)
+	assert.Equal(t, header.Protocol, got.Protocol)
+	assert.Equal(t, header.DeviceID, got.DeviceID)
+	assert.True(t, header.Started.Equal(got.Started))
+	if assert.Len(t, chunks, 2) {
+		assert.Equal(t, In, chunks[0].Direction)
+		assert.Equal(t, "#L#42;NA\r\n", string(chunks[0].Data))
+		assert.Equal(t, Out, chunks[1].Direction)
+		assert.True(t, time.Unix(1700000002, 0).Equal(chunks[1].Time))
+	}
+
+	_, err = Open(filepath.Join(t.TempDir(), "missing.cap"))
+	assert.Error(t, err)
+}
+
+func TestSession(t *testing.T) {
+	dir := t.TempDir()
+	header := Header{Protocol: "WIALON", Port: 20332, Remote: "[::1]:5000", Started: time.Now()}
+
+	// до авторизации данные копятся в памяти и попадают в файл при Start
+	s := NewSession(header)
+	s.Record(In, []byte("#L#42;NA\r\n"))
+	s.Record(Out, []byte("#AL#1\r\n"))
+	path, err := s.Start(dir, "42")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	s.Record(In, []byte("#P#\r\n"))
+	assert.NoError(t, s.Close())
+	s.Record(In, []byte("after close"))
+
+	got, chunks, err := ReadAll(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "42", got.DeviceID)
+	assert.False(t, got.Truncated)
+	assert.Len(t, chunks, 3)
+	assert.Contains(t, filepath.Base(path), "WIALON_20332_42_")
+
+	// сессия, от записи которой отказались, файл не создает
+	s = NewSession(header)
+	s.Record(In, []byte("data"))
+	s.Discard()
+	path, err = s.Start(dir, "43")
+	assert.NoError(t, err)
+	assert.Empty(t, path)
+
+	// данные сверх maxPending до авторизации отбрасываются
+	s = NewSession(header)
+	s.Record(In, make([]byte, maxPending))
+	s.Record(In, []byte("overflow"))
+	path, err = s.Start(dir, "")
+	assert.NoError(t, err)
+	s.Close()
+	got, chunks, err = ReadAll(path)
+	assert.NoError(t, err)
+	assert.True(t, got.Truncated)
+	assert.Len(t, chunks, 1)
+	assert.Contains(t, filepath.Base(path), "WIALON_20332__1_5000_")
+}
+
+func TestDiffRecords(t *testing.T) {
+	rec := func(lat float64) *models.NavRecord {
+		return &models.NavRecord{Client: 42, Latitude: lat, NavigationTime: time.Unix(1700000000, 0).UTC(), ReceivedTime: time.Now(), PortID: "id"}
+	}
+	want := []*models.NavRecord{rec(55.1), rec(55.2)}
+
+	// время приема и порт не сравниваются
+	other := rec(55.1)
+	other.ReceivedTime = time.Now().Add(time.Hour)
+	other.PortID = "other"
+	assert.Empty(t, DiffRecords(want, []*models.NavRecord{other, rec(55.2)}))
+
+	diffs := DiffRecords(want, []*models.NavRecord{rec(55.1), rec(55.3), rec(55.4)})
+	assert.Len(t, diffs, 3)
+	assert.Contains(t, diffs[0], "record count")
+}
@@ -0,0 +1,207 @@
+// Package capture записывает сырой трафик сессий устройств в файлы и воспроизводит
+// записи через обработчик протокола, чтобы ошибку разбора, пойманную на устройстве,
+// можно было повторить локально и превратить в регрессионный тест.
+//
+// Формат файла: сигнатура "NAVCAP1\n", длина заголовка (uint32, big endian), заголовок
+// в JSON (Header) и далее записи: время (int64, наносекунды Unix), направление (1 байт:
+// 0 - от устройства, 1 - устройству), длина данных (uint32) и сами данные.
+package capture
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// magic - сигнатура файла записи
+const magic = "NAVCAP1\n"
+
+// maxChunk - предельный размер одной записи при чтении (защита от поврежденных файлов)
+const maxChunk = 16 << 20
+
+// Direction - направление данных
+type Direction byte
+
+const (
+	In  Direction = 0 // принято от устройства
+	Out Direction = 1 // отправлено устройству
+)
+
+func (d Direction) String() string {
+	if d == Out {
+		return "out"
+	}
+	return "in"
+}
+
+// Header описывает записанную сессию
+type Header struct {
+	Protocol  string            `json:"protocol"`            // имя протокола в реестре (EGTS, ARNAVI, ...)
+	PortID    string            `json:"port_id"`             // ID порта
+	Port      int               `json:"port"`                // номер порта
+	Transport string            `json:"transport,omitempty"` // tcp или udp
+	Options   map[string]string `json:"options,omitempty"`   // параметры порта
+	Remote    string            `json:"remote"`              // адрес устройства
+	DeviceID  string            `json:"device_id,omitempty"` // ID устройства, пусто - авторизация не прошла
+	Started   time.Time         `json:"started"`             // время подключения
+	Truncated bool              `json:"truncated,omitempty"` // часть данных до авторизации не сохранена
+}
+
+// Chunk - данные одного чтения или одной записи в подключение
+type Chunk struct {
+	Time      time.Time
+	Direction Direction
+	Data      []byte
+}
+
+// Writer записывает сессию в файл
+type Writer struct {
+	f *os.File
+	w *bufio.Writer
+}
+
+// Create создает файл записи и записывает заголовок
+func Create(path string, h Header) (*Writer, error) {
+	data, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	w := &Writer{f: f, w: bufio.NewWriter(f)}
+	w.w.WriteString(magic)
+	binary.Write(w.w, binary.BigEndian, uint32(len(data)))
+	w.w.Write(data)
+	if err := w.w.Flush(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write добавляет данные в файл. Каждая запись сбрасывается на диск, чтобы файл
+// оставался читаемым, если сервис остановится аварийно.
+func (w *Writer) Write(c Chunk) error {
+	var prefix [13]byte
+	binary.BigEndian.PutUint64(prefix[0:8], uint64(c.Time.UnixNano()))
+	prefix[8] = byte(c.Direction)
+	binary.BigEndian.PutUint32(prefix[9:13], uint32(len(c.Data)))
+	w.w.Write(prefix[:])
+	w.w.Write(c.Data)
+	return w.w.Flush()
+}
+
+// Close закрывает файл
+func (w *Writer) Close() error {
+	if err := w.w.Flush(); err != nil {
+		w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
+
+// Reader читает файл записи
+type Reader struct {
+	f      *os.File
+	r      *bufio.Reader
+	header Header
+}
+
+// Open открывает файл записи и читает заголовок
+func Open(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Reader{f: f, r: bufio.NewReader(f)}
+	if err := r.readHeader(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return r, nil
+}
+
+func (r *Reader) readHeader() error {
+	sig := make([]byte, len(magic))
+	if _, err := io.ReadFull(r.r, sig); err != nil || string(sig) != magic {
+		return errors.New("not a capture file")
+	}
+	var size uint32
+	if err := binary.Read(r.r, binary.BigEndian, &size); err != nil {
+		return fmt.Errorf("failed to read header: %w", err)
+	}
+	if size > maxChunk {
+		return fmt.Errorf("header too large: %d bytes", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		return fmt.Errorf("failed to read header: %w", err)
+	}
+	if err := json.Unmarshal(data, &r.header); err != nil {
+		return fmt.Errorf("invalid header: %w", err)
+	}
+	return nil
+}
+
+// Header возвращает заголовок записи
+func (r *Reader) Header() Header {
+	return r.header
+}
+
+// Next возвращает следующую запись, io.EOF - записи закончились.
+// Запись, оборванная при аварийной остановке, считается концом файла.
+func (r *Reader) Next() (Chunk, error) {
+	var prefix [13]byte
+	if _, err := io.ReadFull(r.r, prefix[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return Chunk{}, io.EOF
+		}
+		return Chunk{}, err
+	}
+	size := binary.BigEndian.Uint32(prefix[9:13])
+	if size > maxChunk {
+		return Chunk{}, fmt.Errorf("chunk too large: %d bytes", size)
+	}
+	c := Chunk{
+		Time:      time.Unix(0, int64(binary.BigEndian.Uint64(prefix[0:8]))),
+		Direction: Direction(prefix[8]),
+		Data:      make([]byte, size),
+	}
+	if _, err := io.ReadFull(r.r, c.Data); err != nil {
+		return Chunk{}, io.EOF
+	}
+	return c, nil
+}
+
+// Close закрывает файл
+func (r *Reader) Close() error {
+	return r.f.Close()
+}
+
+// ReadAll читает заголовок и все записи файла
+func ReadAll(path string) (Header, []Chunk, error) {
+	r, err := Open(path)
+	if err != nil {
+		return Header{}, nil, err
+	}
+	defer r.Close()
+
+	var chunks []Chunk
+	for {
+		c, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return r.header, chunks, nil
+		}
+		if err != nil {
+			return r.header, chunks, err
+		}
+		chunks = append(chunks, c)
+	}
+}
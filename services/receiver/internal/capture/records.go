@@ -0,0 +1,72 @@
+package capture
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/models"
+)
+
+// WriteRecords записывает навигационные записи по одной на строку в JSON
+func WriteRecords(w io.Writer, records []*models.NavRecord) error {
+	enc := json.NewEncoder(w)
+	for _, rec := range records {
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadRecords читает навигационные записи, сохраненные WriteRecords
+func ReadRecords(r io.Reader) ([]*models.NavRecord, error) {
+	var records []*models.NavRecord
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		rec := &models.NavRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, rec)
+	}
+	return records, scanner.Err()
+}
+
+// DiffRecords сравнивает записи, полученные при воспроизведении, с ожидаемыми.
+// Время приема и ID порта не сравниваются: они зависят от момента и места воспроизведения.
+// Возвращает описания расхождений, пустой срез - записи совпадают.
+func DiffRecords(want, got []*models.NavRecord) []string {
+	var diffs []string
+	if len(want) != len(got) {
+		diffs = append(diffs, fmt.Sprintf("record count: want %d, got %d", len(want), len(got)))
+	}
+	for i := 0; i < len(want) || i < len(got); i++ {
+		var w, g string
+		if i < len(want) {
+			w = comparable(want[i])
+		}
+		if i < len(got) {
+			g = comparable(got[i])
+		}
+		if w != g {
+			diffs = append(diffs, fmt.Sprintf("record %d:\n  - %s\n  + %s", i+1, w, g))
+		}
+	}
+	return diffs
+}
+
+// comparable возвращает запись в JSON без полей, зависящих от воспроизведения
+func comparable(rec *models.NavRecord) string {
+	r := *rec
+	r.ReceivedTime = time.Time{}
+	r.PortID = ""
+	data, _ := json.Marshal(&r)
+	return string(data)
+}
@@ -0,0 +1,147 @@
+package capture
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/models"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/protocol"
+)
+
+// ReplayResult - результат воспроизведения записи
+type ReplayResult struct {
+	Header  Header
+	Records []*models.NavRecord // записи, опубликованные обработчиком
+	// Ответы обработчика, не совпавшие с записанными (по номеру ответа в записи)
+	Mismatches []string
+}
+
+// collector - DataPublisher воспроизведения: собирает опубликованные записи
+type collector struct {
+	mu       sync.Mutex
+	protocol string
+	portID   string
+	records  []*models.NavRecord
+}
+
+func (c *collector) Publish(data *models.NavRecord) error {
+	rec := *data
+	rec.Protocol = c.protocol
+	rec.PortID = c.portID
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.records = append(c.records, &rec)
+	return nil
+}
+
+func (c *collector) IsConnected() bool {
+	return true
+}
+
+// Replay воспроизводит запись path: запускает обработчик протокола из заголовка на
+// свободном локальном порту, передает ему данные устройства в записанном порядке,
+// после каждого записанного ответа ждет ответ обработчика (не дольше timeout)
+// и сравнивает их. Протокол должен быть зарегистрирован в реестре.
+func Replay(ctx context.Context, path string, timeout time.Duration) (*ReplayResult, error) {
+	header, chunks, err := ReadAll(path)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := header.Transport
+	if transport == "" {
+		transport = protocol.TransportTCP
+	}
+	port, err := freePort(transport)
+	if err != nil {
+		return nil, err
+	}
+	handler, err := protocol.NewHandler(header.Protocol, protocol.HandlerConfig{
+		PortID:    header.PortID,
+		Port:      port,
+		Options:   header.Options,
+		Transport: transport,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	publisher := &collector{protocol: header.Protocol, portID: header.PortID}
+	if err := handler.Start(ctx, publisher, port); err != nil {
+		return nil, err
+	}
+	defer handler.Stop()
+
+	conn, err := net.Dial(transport, fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to handler: %w", err)
+	}
+
+	result := &ReplayResult{Header: header}
+	response := 0
+	for _, c := range chunks {
+		if ctx.Err() != nil {
+			break
+		}
+		switch c.Direction {
+		case In:
+			if _, err := conn.Write(c.Data); err != nil {
+				conn.Close()
+				return nil, fmt.Errorf("failed to send captured data: %w", err)
+			}
+		case Out:
+			response++
+			got := make([]byte, len(c.Data))
+			conn.SetReadDeadline(time.Now().Add(timeout))
+			n, err := io.ReadFull(conn, got)
+			if err != nil && n == 0 {
+				result.Mismatches = append(result.Mismatches, fmt.Sprintf("response %d: want % x, got nothing (%v)", response, c.Data, err))
+				continue
+			}
+			if !bytes.Equal(got[:n], c.Data) {
+				result.Mismatches = append(result.Mismatches, fmt.Sprintf("response %d: want % x, got % x", response, c.Data, got[:n]))
+			}
+		}
+	}
+
+	// Закрываем подключение и ждем, пока обработчик разберет оставшиеся данные
+	conn.Close()
+	waitIdle(handler, timeout)
+
+	publisher.mu.Lock()
+	result.Records = publisher.records
+	publisher.mu.Unlock()
+	return result, nil
+}
+
+// waitIdle ждет, пока у обработчика не останется подключений
+func waitIdle(handler protocol.ProtocolHandler, timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for handler.GetActiveConnectionsCount() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// freePort возвращает свободный локальный порт
+func freePort(transport string) (int, error) {
+	if transport == protocol.TransportUDP {
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			return 0, err
+		}
+		defer pc.Close()
+		return pc.LocalAddr().(*net.UDPAddr).Port, nil
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
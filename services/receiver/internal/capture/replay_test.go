@@ -0,0 +1,114 @@
+package capture_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/pkg/models"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/capture"
+	_ "github.com/rackov/NavControlSystem/services/receiver/internal/handler/wialon"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordPublisher собирает записи, опубликованные обработчиком, и, как издатель порта
+// в RECEIVER, заполняет в них протокол
+type recordPublisher struct {
+	mu      sync.Mutex
+	records []*models.NavRecord
+}
+
+func (p *recordPublisher) Publish(data *models.NavRecord) error {
+	data.Protocol = "WIALON"
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.records = append(p.records, data)
+	return nil
+}
+
+func (p *recordPublisher) IsConnected() bool { return true }
+
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// captureWialonSession записывает сессию трекера Wialon IPS и возвращает файл записи
+// и опубликованные записи
+func captureWialonSession(t *testing.T) (string, []*models.NavRecord) {
+	dir := t.TempDir()
+	port := freePort(t)
+	handler, err := protocol.NewHandler("WIALON", protocol.HandlerConfig{PortID: "id", Port: port})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	handler.SetCapture(protocol.CaptureConfig{Dir: dir, Protocol: "WIALON", PortID: "id", Devices: map[string]bool{"861230043907626": true}})
+
+	publisher := &recordPublisher{}
+	if !assert.NoError(t, handler.Start(context.Background(), publisher, port)) {
+		t.FailNow()
+	}
+	defer handler.Stop()
+
+	conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	reader := bufio.NewReader(conn)
+	for _, packet := range []string{
+		"#L#861230043907626;NA\r\n",
+		"#SD#220925;101530;5544.6025;N;03739.6834;E;60;270;150;12\r\n",
+		"#SD#220925;101600;5544.7025;N;03739.7834;E;40;180;150;11\r\n",
+	} {
+		fmt.Fprint(conn, packet)
+		reader.ReadString('\n')
+	}
+	conn.Close()
+	assert.Eventually(t, func() bool { return handler.GetActiveConnectionsCount() == 0 }, time.Second, 5*time.Millisecond)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.cap"))
+	if !assert.Len(t, files, 1) {
+		t.FailNow()
+	}
+	return files[0], publisher.records
+}
+
+func TestReplay(t *testing.T) {
+	level, _ := logger.ParseLevel("ERROR")
+	logger.Init(level)
+
+	path, published := captureWialonSession(t)
+	assert.Len(t, published, 2)
+
+	result, err := capture.Replay(context.Background(), path, time.Second)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "861230043907626", result.Header.DeviceID)
+	assert.Empty(t, result.Mismatches)
+	assert.Empty(t, capture.DiffRecords(published, result.Records))
+
+	// ожидаемые записи сохраняются и читаются как JSON lines
+	expectFile := filepath.Join(t.TempDir(), "expect.json")
+	f, _ := os.Create(expectFile)
+	assert.NoError(t, capture.WriteRecords(f, published[:1]))
+	f.Close()
+	f, _ = os.Open(expectFile)
+	expected, err := capture.ReadRecords(f)
+	f.Close()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, capture.DiffRecords(expected, result.Records))
+}
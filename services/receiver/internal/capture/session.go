@@ -0,0 +1,131 @@
+package capture
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/logger"
+)
+
+// maxPending - сколько данных сессии хранится в памяти до авторизации устройства
+const maxPending = 64 << 10
+
+// unsafeName - символы, недопустимые в имени файла записи
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Session записывает трафик одной сессии. До авторизации данные копятся в памяти:
+// только после нее известно, нужно ли записывать устройство.
+type Session struct {
+	mu      sync.Mutex
+	header  Header
+	pending []Chunk
+	size    int
+	w       *Writer
+	done    bool // запись завершена или не нужна
+}
+
+// NewSession начинает накапливать данные сессии
+func NewSession(h Header) *Session {
+	return &Session{header: h}
+}
+
+// Record сохраняет данные, прочитанные из подключения или записанные в него
+func (s *Session) Record(dir Direction, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.done {
+		return
+	}
+	c := Chunk{Time: time.Now(), Direction: dir, Data: append([]byte(nil), data...)}
+	if s.w != nil {
+		if err := s.w.Write(c); err != nil {
+			logger.Errorf("Failed to write traffic capture of %s, capture stopped: %v", s.header.Remote, err)
+			s.closeLocked()
+		}
+		return
+	}
+	if s.size+len(data) > maxPending {
+		s.header.Truncated = true
+		return
+	}
+	s.pending = append(s.pending, c)
+	s.size += len(data)
+}
+
+// Start создает файл записи в каталоге dir и сохраняет в него накопленные данные.
+// deviceID пустой, если устройство не авторизовалось.
+func (s *Session) Start(dir, deviceID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.done || s.w != nil {
+		return "", nil
+	}
+	s.header.DeviceID = deviceID
+	pending := s.pending
+	s.pending, s.size = nil, 0
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		s.done = true
+		return "", err
+	}
+	path := filepath.Join(dir, s.fileName())
+	w, err := Create(path, s.header)
+	if err != nil {
+		s.done = true
+		return "", err
+	}
+	for _, c := range pending {
+		if err := w.Write(c); err != nil {
+			w.Close()
+			s.done = true
+			return "", err
+		}
+	}
+	s.w = w
+	return path, nil
+}
+
+// Discard отказывается от записи сессии
+func (s *Session) Discard() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = true
+	s.pending, s.size = nil, 0
+}
+
+// Close завершает запись сессии
+func (s *Session) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closeLocked()
+}
+
+func (s *Session) closeLocked() error {
+	s.done = true
+	s.pending, s.size = nil, 0
+	if s.w == nil {
+		return nil
+	}
+	w := s.w
+	s.w = nil
+	return w.Close()
+}
+
+// fileName возвращает имя файла: протокол, порт, устройство (или адрес) и время подключения
+func (s *Session) fileName() string {
+	who := s.header.DeviceID
+	if who == "" {
+		who = s.header.Remote
+	}
+	name := fmt.Sprintf("%s_%d_%s_%s", s.header.Protocol, s.header.Port, who, s.header.Started.Format("20060102T150405.000000000"))
+	return unsafeName.ReplaceAllString(name, "_") + ".cap"
+}
@@ -0,0 +1,205 @@
+// Package commandqueue хранит команды устройствам, отправленные через RECEIVER, и статусы их доставки.
+// Команды для неподключенных устройств ждут в очереди их следующей авторизации.
+package commandqueue
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/models"
+)
+
+// Статусы доставки команды
+const (
+	StatusQueued       = "queued"       // устройство не подключено, команда ждет его авторизации
+	StatusSent         = "sent"         // команда передается устройству, ждем ответа
+	StatusAcknowledged = "acknowledged" // устройство подтвердило выполнение
+	StatusFailed       = "failed"       // устройство отклонило команду или не ответило, протокол ее не поддерживает
+	StatusExpired      = "expired"      // устройство не подключилось до истечения срока очереди
+)
+
+// ErrDuplicateID - команда с таким ID уже есть в очереди
+var ErrDuplicateID = errors.New("command with this id already exists")
+
+// Entry - команда и состояние ее доставки
+type Entry struct {
+	Command   models.Command
+	Status    string                // статус доставки (StatusQueued, ...)
+	Result    *models.CommandResult // последний результат передачи, nil - команду еще не передавали
+	Protocol  string                // протокол порта, через который передана команда
+	PortID    string                // ID этого порта
+	Attempts  int                   // сколько раз команду передавали устройству
+	UpdatedAt time.Time             // время последней смены статуса
+	ExpiresAt time.Time             // до какого времени ждать подключения устройства; нулевое - не ставить в очередь
+}
+
+// Pending возвращает true, если доставка команды не завершена
+func (e *Entry) Pending() bool {
+	return e.Status == StatusQueued || e.Status == StatusSent
+}
+
+// Queue - потокобезопасное хранилище команд.
+// Завершенные команды хранятся retention после последней смены статуса, чтобы их статус можно было запросить.
+type Queue struct {
+	mu        sync.Mutex
+	entries   map[string]*Entry
+	retention time.Duration
+}
+
+// New создает пустую очередь
+func New(retention time.Duration) *Queue {
+	return &Queue{
+		entries:   make(map[string]*Entry),
+		retention: retention,
+	}
+}
+
+// Add регистрирует команду со статусом StatusSent: вызывающий сразу передает ее устройству.
+// Если устройство не подключено, команда будет поставлена в очередь до expiresAt (см. Complete).
+func (q *Queue) Add(cmd *models.Command, expiresAt time.Time) (Entry, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, exists := q.entries[cmd.ID]; exists {
+		return Entry{}, ErrDuplicateID
+	}
+	e := &Entry{
+		Command:   *cmd,
+		Status:    StatusSent,
+		Attempts:  1,
+		UpdatedAt: time.Now().UTC(),
+		ExpiresAt: expiresAt,
+	}
+	q.entries[cmd.ID] = e
+	return *e, nil
+}
+
+// Complete сохраняет результат передачи команды и возвращает ее новое состояние.
+// Неподключенное устройство (models.CommandNoConnection) ставит команду в очередь,
+// если срок ожидания еще не истек.
+func (q *Queue) Complete(res *models.CommandResult, portProtocol, portID string) (Entry, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	e, ok := q.entries[res.CommandID]
+	if !ok {
+		return Entry{}, false
+	}
+	e.Result = res
+	e.UpdatedAt = res.Time
+	switch res.Status {
+	case models.CommandDelivered:
+		e.Status = StatusAcknowledged
+	case models.CommandNoConnection:
+		if e.ExpiresAt.After(res.Time) {
+			e.Status = StatusQueued
+		} else {
+			e.Status = StatusFailed
+		}
+	default:
+		e.Status = StatusFailed
+	}
+	if portProtocol != "" {
+		e.Protocol, e.PortID = portProtocol, portID
+	}
+	return *e, true
+}
+
+// Claim переводит в StatusSent команды из очереди, адресованные устройству с любым из ids,
+// и возвращает их в порядке создания. Одну команду получает только один вызов Claim.
+func (q *Queue) Claim(ids ...string) []models.Command {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var claimed []*Entry
+	for _, e := range q.entries {
+		if e.Status != StatusQueued {
+			continue
+		}
+		for _, id := range ids {
+			if id != "" && e.Command.DeviceID == id {
+				claimed = append(claimed, e)
+				break
+			}
+		}
+	}
+	sortEntries(claimed)
+
+	now := time.Now().UTC()
+	cmds := make([]models.Command, 0, len(claimed))
+	for _, e := range claimed {
+		e.Status = StatusSent
+		e.Attempts++
+		e.UpdatedAt = now
+		cmds = append(cmds, e.Command)
+	}
+	return cmds
+}
+
+// Get возвращает состояние команды id
+func (q *Queue) Get(id string) (Entry, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	e, ok := q.entries[id]
+	if !ok {
+		return Entry{}, false
+	}
+	return *e, true
+}
+
+// Pending возвращает команды, доставка которых не завершена, в порядке создания.
+// Если deviceID не пуст, возвращаются только команды этого устройства.
+func (q *Queue) Pending(deviceID string) []Entry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var pending []*Entry
+	for _, e := range q.entries {
+		if e.Pending() && (deviceID == "" || e.Command.DeviceID == deviceID) {
+			pending = append(pending, e)
+		}
+	}
+	sortEntries(pending)
+
+	result := make([]Entry, 0, len(pending))
+	for _, e := range pending {
+		result = append(result, *e)
+	}
+	return result
+}
+
+// Expire переводит в StatusExpired команды, не дождавшиеся подключения устройства к now,
+// и удаляет завершенные команды старше retention. Возвращает команды с истекшим сроком.
+func (q *Queue) Expire(now time.Time) []Entry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var expired []*Entry
+	for id, e := range q.entries {
+		switch {
+		case e.Status == StatusQueued && !e.ExpiresAt.After(now):
+			e.Status = StatusExpired
+			e.UpdatedAt = now
+			expired = append(expired, e)
+		case !e.Pending() && now.Sub(e.UpdatedAt) > q.retention:
+			delete(q.entries, id)
+		}
+	}
+	sortEntries(expired)
+
+	result := make([]Entry, 0, len(expired))
+	for _, e := range expired {
+		result = append(result, *e)
+	}
+	return result
+}
+
+// sortEntries упорядочивает команды по времени создания
+func sortEntries(entries []*Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Command.CreatedAt.Before(entries[j].Command.CreatedAt)
+	})
+}
@@ -0,0 +1,105 @@
+package commandqueue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCommand(id, deviceID string, createdAt time.Time) *models.Command {
+	return &models.Command{ID: id, DeviceID: deviceID, Type: models.CommandRequestPosition, CreatedAt: createdAt}
+}
+
+func TestQueue_CompleteStatuses(t *testing.T) {
+	q := New(time.Hour)
+	now := time.Now().UTC()
+
+	tests := []struct {
+		result    string
+		expiresAt time.Time
+		want      string
+	}{
+		{models.CommandDelivered, time.Time{}, StatusAcknowledged},
+		{models.CommandNoConnection, now.Add(time.Hour), StatusQueued},
+		{models.CommandNoConnection, time.Time{}, StatusFailed},
+		{models.CommandTimeout, now.Add(time.Hour), StatusFailed},
+		{models.CommandUnsupported, now.Add(time.Hour), StatusFailed},
+	}
+	for i, tt := range tests {
+		cmd := newCommand(string(rune('a'+i)), "1", now)
+		e, err := q.Add(cmd, tt.expiresAt)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSent, e.Status)
+
+		e, ok := q.Complete(models.NewCommandResult(cmd, tt.result, 0, ""), "EGTS", "p1")
+		assert.True(t, ok)
+		assert.Equal(t, tt.want, e.Status, tt.result)
+		assert.Equal(t, tt.result, e.Result.Status)
+	}
+
+	_, err := q.Add(newCommand("a", "1", now), time.Time{})
+	assert.ErrorIs(t, err, ErrDuplicateID)
+
+	_, ok := q.Complete(&models.CommandResult{CommandID: "unknown"}, "", "")
+	assert.False(t, ok)
+}
+
+func TestQueue_ClaimByDevice(t *testing.T) {
+	q := New(time.Hour)
+	now := time.Now().UTC()
+	expires := now.Add(time.Hour)
+
+	for _, cmd := range []*models.Command{
+		newCommand("second", "imei", now.Add(time.Second)),
+		newCommand("first", "tid", now),
+		newCommand("other", "other", now),
+	} {
+		_, err := q.Add(cmd, expires)
+		assert.NoError(t, err)
+		q.Complete(models.NewCommandResult(cmd, models.CommandNoConnection, 0, ""), "", "")
+	}
+	assert.Len(t, q.Pending(""), 3)
+	assert.Len(t, q.Pending("imei"), 1)
+
+	cmds := q.Claim("tid", "imei")
+	if assert.Len(t, cmds, 2) {
+		assert.Equal(t, "first", cmds[0].ID)
+		assert.Equal(t, "second", cmds[1].ID)
+	}
+	assert.Empty(t, q.Claim("tid", "imei"))
+
+	e, _ := q.Get("first")
+	assert.Equal(t, StatusSent, e.Status)
+	assert.Equal(t, 2, e.Attempts)
+	assert.True(t, e.Pending())
+}
+
+func TestQueue_Expire(t *testing.T) {
+	q := New(time.Minute)
+	now := time.Now().UTC()
+
+	queued := newCommand("queued", "1", now)
+	q.Add(queued, now.Add(time.Hour))
+	q.Complete(models.NewCommandResult(queued, models.CommandNoConnection, 0, ""), "", "")
+
+	done := newCommand("done", "1", now)
+	q.Add(done, time.Time{})
+	q.Complete(models.NewCommandResult(done, models.CommandDelivered, 0, ""), "", "")
+
+	assert.Empty(t, q.Expire(now))
+
+	expired := q.Expire(now.Add(2 * time.Hour))
+	if assert.Len(t, expired, 1) {
+		assert.Equal(t, "queued", expired[0].Command.ID)
+		assert.Equal(t, StatusExpired, expired[0].Status)
+	}
+	assert.Empty(t, q.Pending(""))
+
+	// завершенные команды удаляются после retention
+	_, ok := q.Get("done")
+	assert.False(t, ok)
+	_, ok = q.Get("queued")
+	assert.True(t, ok)
+}
@@ -0,0 +1,83 @@
+package connectionmanager
+
+import (
+	"time"
+
+	"github.com/rackov/NavControlSystem/services/receiver/internal/protocol"
+)
+
+// Base - общая часть обработчиков протоколов: методы protocol.ProtocolHandler,
+// которые только делегируют вызовы ConnectionManager, и общие параметры порта
+// (protocol.SessionHandler). Обработчик встраивает Base, создает его через NewBase
+// и сам реализует Start, Stop, GetName и ClientData.
+type Base struct {
+	connManager *ConnectionManager
+	authTimeout time.Duration // время ожидания авторизации (параметр порта auth_timeout)
+}
+
+// NewBase создает менеджер подключений для обработчика cd
+// с временем ожидания авторизации по умолчанию authTimeout
+func NewBase(cd ClientData, authTimeout time.Duration) Base {
+	return Base{connManager: NewConnectionManager(cd), authTimeout: authTimeout}
+}
+
+// AuthTimeout возвращает время ожидания авторизации устройства
+func (b *Base) AuthTimeout() time.Duration {
+	return b.authTimeout
+}
+
+// SetAuthTimeout задает время ожидания авторизации, вызывается до Start
+func (b *Base) SetAuthTimeout(timeout time.Duration) {
+	b.authTimeout = timeout
+}
+
+// SetPolicy задает ограничения подключений порта
+func (b *Base) SetPolicy(policy protocol.SessionPolicy) {
+	b.connManager.SetPolicy(policy)
+}
+
+// Manager возвращает менеджер подключений порта
+func (b *Base) Manager() *ConnectionManager {
+	return b.connManager
+}
+
+// IsRunning проверяет состояние ConnectionManager
+func (b *Base) IsRunning() bool {
+	return b.connManager.IsRunning()
+}
+
+func (b *Base) GetActiveConnectionsCount() int {
+	return b.connManager.GetActiveConnectionsCount()
+}
+
+func (b *Base) GetConnectedClients() []protocol.ClientInfo {
+	return b.connManager.GetConnectedClients()
+}
+
+func (b *Base) DisconnectClient(clientAddr string) error {
+	return b.connManager.DisconnectClient(clientAddr)
+}
+
+func (b *Base) GetClient(deviceID string) (protocol.ClientInfo, bool) {
+	return b.connManager.GetClient(deviceID)
+}
+
+func (b *Base) DisconnectDevice(deviceID string) error {
+	return b.connManager.DisconnectDevice(deviceID)
+}
+
+func (b *Base) GetPortStats() protocol.Stats {
+	return b.connManager.GetPortStats()
+}
+
+func (b *Base) ListBans() []protocol.BanInfo {
+	return b.connManager.ListBans()
+}
+
+func (b *Base) Unban(ip string) bool {
+	return b.connManager.Unban(ip)
+}
+
+func (b *Base) SetCapture(cfg protocol.CaptureConfig) {
+	b.connManager.SetCapture(cfg)
+}
@@ -0,0 +1,61 @@
+package connectionmanager
+
+import (
+	"net"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/capture"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/protocol"
+)
+
+// SetCapture задает запись трафика порта. Применяется к сессиям, принятым после вызова.
+func (cm *ConnectionManager) SetCapture(cfg protocol.CaptureConfig) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.capture = cfg
+}
+
+// captureConfig возвращает текущие настройки записи трафика
+func (cm *ConnectionManager) captureConfig() protocol.CaptureConfig {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	return cm.capture
+}
+
+// newCapture начинает накапливать трафик нового подключения, если запись включена
+// для порта или для каких-либо устройств. До авторизации неизвестно, чье это подключение.
+func (cm *ConnectionManager) newCapture(conn net.Conn, port int) *capture.Session {
+	cfg := cm.captureConfig()
+	if !cfg.Enabled() {
+		return nil
+	}
+	return capture.NewSession(capture.Header{
+		Protocol:  cfg.Protocol,
+		PortID:    cfg.PortID,
+		Port:      port,
+		Transport: conn.RemoteAddr().Network(),
+		Options:   cfg.Options,
+		Remote:    conn.RemoteAddr().String(),
+		Started:   time.Now(),
+	})
+}
+
+// startCapture после авторизации решает, записывать ли сессию в файл.
+// clientID пустой - авторизация не прошла, такие сессии пишутся только при записи всего порта.
+func (cm *ConnectionManager) startCapture(conn *sessionConn, clientID string) {
+	if conn.capture == nil {
+		return
+	}
+	cfg := cm.captureConfig()
+	if !cfg.Captures(clientID) {
+		conn.capture.Discard()
+		return
+	}
+	path, err := conn.capture.Start(cfg.Dir, clientID)
+	if err != nil {
+		logger.Errorf("Failed to start traffic capture of %s: %v", conn.RemoteAddr(), err)
+		return
+	}
+	logger.Infof("Capturing traffic of %s (ID: %s) to %s", conn.RemoteAddr(), clientID, path)
+}
@@ -0,0 +1,159 @@
+package connectionmanager
+
+import (
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rackov/NavControlSystem/services/receiver/internal/capture"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/protocol"
+)
+
+// Причины закрытия подключения (в логах и метриках)
+const (
+	CloseClient      = "client_closed"     // устройство закрыло подключение
+	CloseIdleTimeout = "idle_timeout"      // устройство молчало дольше idle_timeout
+	CloseMaxSession  = "max_session"       // сессия длилась дольше max_session
+	CloseDuplicate   = "duplicate_session" // устройство подключилось повторно
+	CloseServer      = "server_request"    // отключено по запросу сервера (gRPC)
+	CloseShutdown    = "shutdown"          // порт закрыт или сервис останавливается
+	CloseAuthTimeout = "auth_timeout"      // устройство не авторизовалось за auth_timeout
+	CloseAuthFailed  = "auth_failed"       // ошибка авторизации
+	CloseDenied      = "device_denied"     // устройство не допущено реестром устройств
+	CloseTLS         = "tls_handshake"     // ошибка TLS-рукопожатия
+	CloseReadError   = "read_error"        // ошибка чтения (обрыв, keepalive)
+	CloseProtocol    = "protocol_error"    // обработчик завершил сессию (ошибка разбора, публикации)
+)
+
+// sessionConn - подключение, принятое менеджером. Считает трафик, закрывает подключение,
+// если устройство молчит дольше idleTimeout, и запоминает первую ошибку чтения,
+// по которой потом определяется причина закрытия. Если включена запись трафика,
+// прочитанные и отправленные данные передаются в capture.
+// Обработчик протокола получает статистику подключения через protocol.StatsOf.
+type sessionConn struct {
+	net.Conn
+	stats       *protocol.ConnStats
+	idleTimeout time.Duration
+	capture     *capture.Session // nil - запись трафика выключена
+
+	mu           sync.Mutex
+	readDeadline time.Time // срок чтения, заданный обработчиком
+	readErr      error     // первая ошибка чтения
+	idleExpired  bool      // первая ошибка - истек idleTimeout
+}
+
+func newSessionConn(conn net.Conn, stats *protocol.ConnStats, idleTimeout time.Duration) *sessionConn {
+	return &sessionConn{Conn: conn, stats: stats, idleTimeout: idleTimeout}
+}
+
+// Read читает данные, ограничивая ожидание сроком обработчика и idleTimeout
+func (c *sessionConn) Read(b []byte) (int, error) {
+	idle := false
+	if c.idleTimeout > 0 {
+		c.mu.Lock()
+		deadline := c.readDeadline
+		if idleDeadline := time.Now().Add(c.idleTimeout); deadline.IsZero() || idleDeadline.Before(deadline) {
+			deadline, idle = idleDeadline, true
+		}
+		c.mu.Unlock()
+		c.Conn.SetReadDeadline(deadline)
+	}
+
+	n, err := c.Conn.Read(b)
+	c.stats.AddBytesIn(n)
+	if c.capture != nil {
+		c.capture.Record(capture.In, b[:n])
+	}
+	if err != nil {
+		c.mu.Lock()
+		if c.readErr == nil {
+			c.readErr = err
+			c.idleExpired = idle && errors.Is(err, os.ErrDeadlineExceeded)
+		}
+		c.mu.Unlock()
+	}
+	return n, err
+}
+
+func (c *sessionConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.stats.AddBytesOut(n)
+	if c.capture != nil {
+		c.capture.Record(capture.Out, b[:n])
+	}
+	return n, err
+}
+
+// SetReadDeadline запоминает срок обработчика, чтобы Read не заменил его сроком idleTimeout
+func (c *sessionConn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.readDeadline = t
+	c.mu.Unlock()
+	return c.Conn.SetReadDeadline(t)
+}
+
+func (c *sessionConn) SetDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.readDeadline = t
+	c.mu.Unlock()
+	return c.Conn.SetDeadline(t)
+}
+
+// Stats возвращает статистику подключения
+func (c *sessionConn) Stats() *protocol.ConnStats {
+	return c.stats
+}
+
+// closeReason определяет причину закрытия по первой ошибке чтения.
+// Если чтение не завершалось ошибкой, сессию завершил обработчик.
+func (c *sessionConn) closeReason() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch {
+	case c.idleExpired:
+		return CloseIdleTimeout
+	case c.readErr == nil:
+		return CloseProtocol
+	case errors.Is(c.readErr, io.EOF), errors.Is(c.readErr, io.ErrUnexpectedEOF):
+		return CloseClient
+	default:
+		return CloseReadError
+	}
+}
+
+// authFailReason определяет причину закрытия при ошибке авторизации.
+// Обработчики не всегда оборачивают ошибку чтения через %w, поэтому
+// первая ошибка чтения подключения важнее ошибки GetClientID.
+func (c *sessionConn) authFailReason(err error) string {
+	if errors.Is(err, protocol.ErrDeviceDenied) {
+		return CloseDenied
+	}
+
+	c.mu.Lock()
+	if c.readErr != nil {
+		err = c.readErr
+	}
+	c.mu.Unlock()
+
+	switch {
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		return CloseAuthTimeout
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return CloseClient
+	case c.handshakeFailed():
+		return CloseTLS
+	default:
+		return CloseAuthFailed
+	}
+}
+
+// handshakeFailed возвращает true, если подключение TLS и рукопожатие не завершилось
+func (c *sessionConn) handshakeFailed() bool {
+	tc, ok := c.Conn.(*tls.Conn)
+	return ok && !tc.ConnectionState().HandshakeComplete
+}
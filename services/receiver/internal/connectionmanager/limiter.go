@@ -0,0 +1,192 @@
+package connectionmanager
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rackov/NavControlSystem/services/receiver/internal/protocol"
+)
+
+// Причины отказа в подключении (в метриках - как причины закрытия)
+const (
+	RejectMaxConnections = "rejected_max_connections" // превышен лимит подключений порта
+	RejectMaxPerIP       = "rejected_ip_sessions"     // превышен лимит подключений с IP
+	RejectRate           = "rejected_ip_rate"         // превышен лимит новых подключений с IP в минуту
+	RejectBanned         = "rejected_banned"          // IP заблокирован
+)
+
+// ipSweepInterval - как часто удаляются записи о неактивных IP
+const ipSweepInterval = time.Minute
+
+// ipState - состояние лимитов одного IP
+type ipState struct {
+	sessions     int       // открытых подключений
+	tokens       float64   // доступных подключений (token bucket на RatePerIP в минуту)
+	refilled     time.Time // время последнего пополнения tokens
+	failures     int       // неудачных авторизаций с firstFailure
+	firstFailure time.Time
+	bannedUntil  time.Time
+	banFailures  int // failures на момент блокировки
+}
+
+// ipLimiter ограничивает подключения с одного IP и блокирует IP, с которых
+// раз за разом не проходит авторизация (зациклившаяся прошивка, сканеры портов).
+type ipLimiter struct {
+	mu        sync.Mutex
+	ips       map[string]*ipState
+	lastSweep time.Time
+}
+
+func newIPLimiter() *ipLimiter {
+	return &ipLimiter{ips: make(map[string]*ipState)}
+}
+
+// remoteIP возвращает IP-адрес подключения без порта
+func remoteIP(conn net.Conn) string {
+	addr := conn.RemoteAddr().String()
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+// allow проверяет лимиты IP для нового подключения. Если подключение разрешено,
+// оно учитывается как открытое до вызова release; иначе возвращается причина отказа.
+func (l *ipLimiter) allow(ip string, policy protocol.SessionPolicy, now time.Time) (bool, string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(policy, now)
+
+	st, ok := l.ips[ip]
+	if !ok {
+		st = &ipState{tokens: float64(policy.RatePerIP), refilled: now}
+		l.ips[ip] = st
+	}
+
+	if now.Before(st.bannedUntil) {
+		return false, RejectBanned
+	}
+	if policy.MaxPerIP > 0 && st.sessions >= policy.MaxPerIP {
+		return false, RejectMaxPerIP
+	}
+	if policy.RatePerIP > 0 {
+		limit := float64(policy.RatePerIP)
+		st.tokens += now.Sub(st.refilled).Minutes() * limit
+		if st.tokens > limit {
+			st.tokens = limit
+		}
+		st.refilled = now
+		if st.tokens < 1 {
+			return false, RejectRate
+		}
+		st.tokens--
+	}
+
+	st.sessions++
+	return true, ""
+}
+
+// release учитывает закрытие подключения, разрешенного allow
+func (l *ipLimiter) release(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if st, ok := l.ips[ip]; ok && st.sessions > 0 {
+		st.sessions--
+	}
+}
+
+// authFailed учитывает неудачную авторизацию. Возвращает true, если IP заблокирован:
+// policy.AuthFailBan неудач за policy.BanDuration.
+func (l *ipLimiter) authFailed(ip string, policy protocol.SessionPolicy, now time.Time) bool {
+	if policy.AuthFailBan <= 0 || policy.BanDuration <= 0 {
+		return false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	st, ok := l.ips[ip]
+	if !ok {
+		st = &ipState{tokens: float64(policy.RatePerIP), refilled: now}
+		l.ips[ip] = st
+	}
+	if st.failures == 0 || now.Sub(st.firstFailure) > policy.BanDuration {
+		st.failures, st.firstFailure = 0, now
+	}
+	st.failures++
+	if st.failures < policy.AuthFailBan {
+		return false
+	}
+
+	st.bannedUntil = now.Add(policy.BanDuration)
+	st.banFailures = st.failures
+	st.failures = 0
+	return true
+}
+
+// authSucceeded сбрасывает счетчик неудачных авторизаций IP
+func (l *ipLimiter) authSucceeded(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if st, ok := l.ips[ip]; ok {
+		st.failures = 0
+	}
+}
+
+// bans возвращает действующие блокировки, отсортированные по IP
+func (l *ipLimiter) bans(now time.Time) []protocol.BanInfo {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bans := make([]protocol.BanInfo, 0)
+	for ip, st := range l.ips {
+		if now.Before(st.bannedUntil) {
+			bans = append(bans, protocol.BanInfo{IP: ip, Until: st.bannedUntil, Failures: st.banFailures})
+		}
+	}
+	sort.Slice(bans, func(i, j int) bool { return bans[i].IP < bans[j].IP })
+	return bans
+}
+
+// unban снимает блокировку IP
+func (l *ipLimiter) unban(ip string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	st, ok := l.ips[ip]
+	if !ok || !now.Before(st.bannedUntil) {
+		return false
+	}
+	st.bannedUntil = time.Time{}
+	st.failures = 0
+	return true
+}
+
+// sweep удаляет записи IP без подключений, блокировок и свежих неудач.
+// Запись с неполным token bucket живет, пока он не пополнится (за минуту), иначе лимит
+// частоты обходился бы переподключением. Вызывается под l.mu.
+func (l *ipLimiter) sweep(policy protocol.SessionPolicy, now time.Time) {
+	if now.Sub(l.lastSweep) < ipSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for ip, st := range l.ips {
+		if st.sessions > 0 || now.Before(st.bannedUntil) {
+			continue
+		}
+		if st.failures > 0 && now.Sub(st.firstFailure) <= policy.BanDuration {
+			continue
+		}
+		if now.Sub(st.refilled) < ipSweepInterval {
+			continue
+		}
+		delete(l.ips, ip)
+	}
+}
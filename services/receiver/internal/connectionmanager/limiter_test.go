@@ -0,0 +1,124 @@
+package connectionmanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rackov/NavControlSystem/services/receiver/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPLimiter_SessionsPerIP(t *testing.T) {
+	l := newIPLimiter()
+	policy := protocol.SessionPolicy{MaxPerIP: 2}
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		ok, _ := l.allow("10.0.0.1", policy, now)
+		assert.True(t, ok)
+	}
+	ok, reason := l.allow("10.0.0.1", policy, now)
+	assert.False(t, ok)
+	assert.Equal(t, RejectMaxPerIP, reason)
+
+	// лимит действует на каждый IP отдельно
+	ok, _ = l.allow("10.0.0.2", policy, now)
+	assert.True(t, ok)
+
+	l.release("10.0.0.1")
+	ok, _ = l.allow("10.0.0.1", policy, now)
+	assert.True(t, ok)
+}
+
+func TestIPLimiter_Rate(t *testing.T) {
+	l := newIPLimiter()
+	policy := protocol.SessionPolicy{RatePerIP: 3}
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		ok, _ := l.allow("10.0.0.1", policy, now)
+		assert.True(t, ok)
+		l.release("10.0.0.1")
+	}
+	ok, reason := l.allow("10.0.0.1", policy, now)
+	assert.False(t, ok)
+	assert.Equal(t, RejectRate, reason)
+
+	// за 20 секунд восстанавливается одно подключение из трех в минуту
+	ok, _ = l.allow("10.0.0.1", policy, now.Add(20*time.Second))
+	assert.True(t, ok)
+	ok, _ = l.allow("10.0.0.1", policy, now.Add(20*time.Second))
+	assert.False(t, ok)
+}
+
+func TestIPLimiter_Ban(t *testing.T) {
+	l := newIPLimiter()
+	policy := protocol.SessionPolicy{AuthFailBan: 3, BanDuration: 10 * time.Minute}
+	now := time.Now()
+
+	assert.False(t, l.authFailed("10.0.0.1", policy, now))
+	assert.False(t, l.authFailed("10.0.0.1", policy, now))
+	// успешная авторизация сбрасывает счетчик
+	l.authSucceeded("10.0.0.1")
+	assert.False(t, l.authFailed("10.0.0.1", policy, now))
+	assert.False(t, l.authFailed("10.0.0.1", policy, now))
+	assert.True(t, l.authFailed("10.0.0.1", policy, now))
+
+	ok, reason := l.allow("10.0.0.1", policy, now.Add(time.Minute))
+	assert.False(t, ok)
+	assert.Equal(t, RejectBanned, reason)
+
+	bans := l.bans(now)
+	if assert.Len(t, bans, 1) {
+		assert.Equal(t, "10.0.0.1", bans[0].IP)
+		assert.Equal(t, 3, bans[0].Failures)
+		assert.Equal(t, now.Add(policy.BanDuration), bans[0].Until)
+	}
+
+	// блокировка истекает
+	ok, _ = l.allow("10.0.0.1", policy, now.Add(11*time.Minute))
+	assert.True(t, ok)
+	assert.Empty(t, l.bans(now.Add(11*time.Minute)))
+}
+
+func TestIPLimiter_BanWindow(t *testing.T) {
+	l := newIPLimiter()
+	policy := protocol.SessionPolicy{AuthFailBan: 2, BanDuration: time.Minute}
+	now := time.Now()
+
+	// неудачи реже, чем раз в BanDuration, не приводят к блокировке
+	assert.False(t, l.authFailed("10.0.0.1", policy, now))
+	assert.False(t, l.authFailed("10.0.0.1", policy, now.Add(2*time.Minute)))
+	assert.True(t, l.authFailed("10.0.0.1", policy, now.Add(2*time.Minute+time.Second)))
+
+	// без AuthFailBan IP не блокируется
+	assert.False(t, l.authFailed("10.0.0.2", protocol.SessionPolicy{BanDuration: time.Minute}, now))
+}
+
+func TestIPLimiter_Unban(t *testing.T) {
+	l := newIPLimiter()
+	policy := protocol.SessionPolicy{AuthFailBan: 1, BanDuration: time.Hour}
+	now := time.Now()
+
+	assert.False(t, l.unban("10.0.0.1", now))
+	assert.True(t, l.authFailed("10.0.0.1", policy, now))
+	assert.True(t, l.unban("10.0.0.1", now))
+
+	ok, _ := l.allow("10.0.0.1", policy, now)
+	assert.True(t, ok)
+}
+
+func TestIPLimiter_Sweep(t *testing.T) {
+	l := newIPLimiter()
+	policy := protocol.SessionPolicy{RatePerIP: 10}
+	now := time.Now()
+
+	l.allow("10.0.0.1", policy, now)
+	l.release("10.0.0.1")
+	l.allow("10.0.0.2", policy, now)
+
+	l.allow("10.0.0.3", policy, now.Add(2*time.Minute))
+	// запись IP без подключений удалена, с открытым подключением - осталась
+	assert.NotContains(t, l.ips, "10.0.0.1")
+	assert.Contains(t, l.ips, "10.0.0.2")
+}
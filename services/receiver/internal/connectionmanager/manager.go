@@ -2,9 +2,11 @@ package connectionmanager
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rackov/NavControlSystem/pkg/logger"
@@ -17,7 +19,7 @@ type ClientData interface {
 	GetClientID(conn net.Conn) (string, error)
 }
 
-// ConnectionManager управляет всеми активными TCP-подключениями для одного протокола.
+// ConnectionManager управляет всеми активными подключениями (TCP или псевдосессиями UDP) одного порта.
 type ConnectionManager struct {
 	listener net.Listener
 	wg       sync.WaitGroup
@@ -28,7 +30,13 @@ type ConnectionManager struct {
 	// cancel context.CancelFunc // и функцию для его отмены
 
 	connections map[string]*clientConnection // Ключ - адрес клиента
+	devices     map[string]*clientConnection // Ключ - ID устройства, последнее подключение
 	clientData  ClientData                   // Зависимость для получения ID клиента
+	portStats   *protocol.ConnStats          // Статистика всех подключений порта
+	policy      protocol.SessionPolicy       // Ограничения подключений порта
+	limiter     *ipLimiter                   // Лимиты и блокировки по IP
+	accepted    atomic.Int32                 // Принятых подключений, включая неавторизованные
+	capture     protocol.CaptureConfig       // Запись трафика, см. capture.go
 
 	// --- НОВЫЕ ПОЛЯ ДЛЯ УПРАВЛЕНИЯ КОНТЕКСТОМ ---
 	internalCtx    context.Context
@@ -41,16 +49,51 @@ type clientConnection struct {
 	clientID    string
 	connectedAt time.Time
 	cancelFunc  context.CancelFunc
+	reason      string // причина закрытия по инициативе сервера, под ConnectionManager.mu
+}
+
+// close прерывает обработку подключения и закрывает его.
+// Вызывается под ConnectionManager.mu; сохраняется первая причина.
+func (c *clientConnection) close(reason string) {
+	if c.reason == "" {
+		c.reason = reason
+	}
+	c.cancelFunc()
+	if err := c.conn.Close(); err != nil {
+		logger.Debugf("Failed to close connection of client %s: %v", c.clientID, err)
+	}
+}
+
+// info возвращает информацию о подключении для gRPC
+func (c *clientConnection) info() protocol.ClientInfo {
+	return protocol.ClientInfo{
+		ID:    c.clientID,
+		Addr:  c.conn.RemoteAddr().String(),
+		Net:   c.conn.RemoteAddr().Network(),
+		Since: c.connectedAt,
+		Stats: protocol.StatsOf(c.conn).Snapshot(),
+	}
 }
 
 // NewConnectionManager создает новый экземпляр менеджера.
 func NewConnectionManager(cd ClientData) *ConnectionManager {
 	return &ConnectionManager{
 		connections: make(map[string]*clientConnection),
+		devices:     make(map[string]*clientConnection),
 		clientData:  cd,
+		portStats:   protocol.NewConnStats(nil),
+		limiter:     newIPLimiter(),
 	}
 }
 
+// SetPolicy задает ограничения подключений порта (таймауты, keepalive).
+// Применяется к слушателю и подключениям при следующем запуске Start.
+func (cm *ConnectionManager) SetPolicy(policy protocol.SessionPolicy) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.policy = policy
+}
+
 // Start запускает прослушивание порта и принимает подключения.
 func (cm *ConnectionManager) Start(parentCtx context.Context, port int, connectionHandler func(ctx context.Context, conn net.Conn, clientID string)) error {
 	cm.mu.Lock()
@@ -62,14 +105,52 @@ func (cm *ConnectionManager) Start(parentCtx context.Context, port int, connecti
 		return fmt.Errorf("connection manager is already running")
 	}
 
+	policy := cm.policy
+	// KeepAlive < 0 отключает TCP keepalive для принятых подключений
+	lc := net.ListenConfig{KeepAlive: -1}
+	if policy.KeepAlive > 0 {
+		lc.KeepAliveConfig = net.KeepAliveConfig{
+			Enable:   true,
+			Idle:     policy.KeepAlive,
+			Interval: policy.KeepAlive,
+			Count:    policy.KeepAliveCount,
+		}
+	}
+
 	var err error
 	// Создаем слушателя заново при каждом запуске, чтобы избежать ошибки "address already in use"
-	cm.listener, err = net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if policy.Transport == protocol.TransportUDP {
+		// Псевдосессии UDP не закрываются устройством, поэтому без idle_timeout
+		// они истекают через udpIdleTimeout
+		if policy.IdleTimeout == 0 {
+			policy.IdleTimeout = udpIdleTimeout
+		}
+		cm.listener, err = listenUDP(parentCtx, lc, fmt.Sprintf(":%d", port), udpAdmission{
+			maxSessions: policy.MaxConnections,
+			admit:       func(ip string) string { return cm.admit(ip, policy) },
+			release:     cm.release,
+			drop: func(addr net.Addr, reason string) {
+				// отказ получает каждая датаграмма, поэтому только в отладочный лог
+				logger.Debugf("Dropped datagram from %s on port %d: %s", addr, port, reason)
+				cm.portStats.DatagramDropped(reason)
+			},
+		})
+	} else {
+		cm.listener, err = lc.Listen(parentCtx, "tcp", fmt.Sprintf(":%d", port))
+	}
 	if err != nil {
 		logger.Errorf("Failed to listen on port %d: %v", port, err)
 		return fmt.Errorf("failed to listen on port %d: %w", port, err)
 	}
-	logger.Infof("Connection manager started listening on port %d", port)
+	if policy.Transport == protocol.TransportUDP {
+		logger.Infof("Connection manager started listening on port %d (UDP)", port)
+	} else if policy.TLS != nil {
+		// Рукопожатие выполняется при первом чтении, то есть в GetClientID под auth_timeout
+		cm.listener = tls.NewListener(cm.listener, policy.TLS)
+		logger.Infof("Connection manager started listening on port %d (TLS)", port)
+	} else {
+		logger.Infof("Connection manager started listening on port %d", port)
+	}
 	// --- ИЗМЕНЕНИЕ: Создаем внутренний контекст для управления жизненным циклом ---
 	// parentCtx нужен, чтобы если родительский контекст (весь сервис) отменяется,
 	// то и менеджер тоже остановился.
@@ -81,14 +162,14 @@ func (cm *ConnectionManager) Start(parentCtx context.Context, port int, connecti
 	// Передаем во внутренний контекст, а не внешний
 	go func() {
 		defer cm.wg.Done()
-		cm.acceptLoop(cm.internalCtx, port, connectionHandler)
+		cm.acceptLoop(cm.internalCtx, port, policy, connectionHandler)
 	}()
 
 	return nil
 }
 
 // acceptLoop теперь использует внутренний контекст менеджера.
-func (cm *ConnectionManager) acceptLoop(ctx context.Context, port int, connectionHandler func(ctx context.Context, conn net.Conn, clientID string)) {
+func (cm *ConnectionManager) acceptLoop(ctx context.Context, port int, policy protocol.SessionPolicy, connectionHandler func(ctx context.Context, conn net.Conn, clientID string)) {
 	for {
 		select {
 		// --- ИЗМЕНЕНИЕ: Проверяем сигнал отмены из ВНУТРЕННЕГО контекста ---
@@ -111,18 +192,70 @@ func (cm *ConnectionManager) acceptLoop(ctx context.Context, port int, connectio
 				}
 			}
 
+			// Псевдосессии UDP проходят лимиты при создании (см. udpListener.dispatch)
+			// и освобождают их при закрытии
+			udp := policy.Transport == protocol.TransportUDP
+			ip := remoteIP(conn)
+			if !udp {
+				if reason := cm.admit(ip, policy); reason != "" {
+					cm.reject(conn, port, reason)
+					continue
+				}
+			}
+
+			session := newSessionConn(conn, protocol.NewConnStats(cm.portStats), policy.IdleTimeout)
+			session.capture = cm.newCapture(conn, port)
 			cm.wg.Add(1)
-			go func(c net.Conn) {
+			go func() {
 				defer cm.wg.Done()
+				if !udp {
+					defer cm.release(ip)
+				}
 				// Передаем ВНУТРЕННИЙ контекст в обработчик соединения
-				cm.handleNewConnection(ctx, c, port, connectionHandler)
-			}(conn)
+				cm.handleNewConnection(ctx, session, port, policy, connectionHandler)
+			}()
 		}
 	}
 }
 
-func (cm *ConnectionManager) handleNewConnection(parentCtx context.Context, conn net.Conn, port int, connectionHandler func(ctx context.Context, conn net.Conn, clientID string)) {
+// admit проверяет лимиты порта и IP для нового подключения. Пустая строка - подключение
+// принято и учтено в лимитах, иначе - причина отказа.
+func (cm *ConnectionManager) admit(ip string, policy protocol.SessionPolicy) string {
+	if policy.MaxConnections > 0 && int(cm.accepted.Load()) >= policy.MaxConnections {
+		return RejectMaxConnections
+	}
+	if ok, reason := cm.limiter.allow(ip, policy, time.Now()); !ok {
+		return reason
+	}
+	cm.accepted.Add(1)
+	return ""
+}
+
+// release учитывает закрытие подключения, принятого admit
+func (cm *ConnectionManager) release(ip string) {
+	cm.limiter.release(ip)
+	cm.accepted.Add(-1)
+}
+
+// reject закрывает подключение, не прошедшее лимиты, и учитывает причину в статистике порта
+func (cm *ConnectionManager) reject(conn net.Conn, port int, reason string) {
+	if reason == RejectBanned {
+		// заблокированный IP продолжает подключаться, не засоряем лог
+		logger.Debugf("Rejected connection from %s on port %d: %s", conn.RemoteAddr(), port, reason)
+	} else {
+		logger.Warnf("Rejected connection from %s on port %d: %s", conn.RemoteAddr(), port, reason)
+	}
+	if err := conn.Close(); err != nil {
+		logger.Debugf("Failed to close rejected connection from %s: %v", conn.RemoteAddr(), err)
+	}
+	cm.portStats.SessionClosed(reason)
+}
+
+func (cm *ConnectionManager) handleNewConnection(parentCtx context.Context, conn *sessionConn, port int, policy protocol.SessionPolicy, connectionHandler func(ctx context.Context, conn net.Conn, clientID string)) {
 	defer conn.Close()
+	if conn.capture != nil {
+		defer conn.capture.Close()
+	}
 
 	clientAddr := conn.RemoteAddr().String()
 	logger.Debugf("Handling new connection from %s", clientAddr)
@@ -130,30 +263,73 @@ func (cm *ConnectionManager) handleNewConnection(parentCtx context.Context, conn
 	// 1. Авторизация через зависимость (обработчик протокола)
 	clientID, err := cm.clientData.GetClientID(conn)
 	if err != nil {
-		logger.Errorf("Failed to authorize client %s: %v", clientAddr, err)
+		reason := conn.authFailReason(err)
+		logger.Errorf("Failed to authorize client %s (%s): %v", clientAddr, reason, err)
+		conn.stats.SessionClosed(reason)
+		cm.startCapture(conn, "")
+
+		ip := remoteIP(conn)
+		if cm.limiter.authFailed(ip, policy, time.Now()) {
+			logger.Warnf("IP %s banned on port %d for %s after %d failed authorizations", ip, port, policy.BanDuration, policy.AuthFailBan)
+		}
 		return
 	}
+	cm.limiter.authSucceeded(remoteIP(conn))
+	cm.startCapture(conn, clientID)
 	//  protocolName:=cm.clientData.GetName()
 	logger.Infof("Client %s authorized with ID: %s on port %d ", clientAddr, clientID, port)
 
 	// 2. Регистрация подключения
 	connCtx, cancel := context.WithCancel(parentCtx)
 
-	cm.mu.Lock()
-	cm.connections[clientAddr] = &clientConnection{
+	connInfo := &clientConnection{
 		conn:        conn,
 		clientID:    clientID,
 		connectedAt: time.Now(),
 		cancelFunc:  cancel,
 	}
+	cm.mu.Lock()
+	// Устройство переподключилось, а старая сессия еще не закрыта (обрыв без FIN за NAT,
+	// смена адреса у мобильного оператора). Старую сессию закрываем, чтобы данные и команды
+	// шли через новую.
+	if prev, ok := cm.devices[clientID]; ok {
+		logger.Warnf("Duplicate session for client ID %s: closing previous connection from %s", clientID, prev.conn.RemoteAddr())
+		prev.close(CloseDuplicate)
+	}
+	cm.connections[clientAddr] = connInfo
+	cm.devices[clientID] = connInfo
 	cm.mu.Unlock()
 
+	// Сессия ограничена по длительности: устройство переподключится и заново авторизуется
+	if policy.MaxSession > 0 {
+		timer := time.AfterFunc(policy.MaxSession, func() {
+			cm.mu.Lock()
+			defer cm.mu.Unlock()
+			logger.Infof("Session of client %s (ID: %s) exceeded %s", clientAddr, clientID, policy.MaxSession)
+			connInfo.close(CloseMaxSession)
+		})
+		defer timer.Stop()
+	}
+
 	// 3. Удаление при выходе
 	defer func() {
 		cm.mu.Lock()
 		delete(cm.connections, clientAddr)
+		if cm.devices[clientID] == connInfo {
+			delete(cm.devices, clientID)
+		}
+		reason := connInfo.reason
 		cm.mu.Unlock()
-		logger.Infof("Client %s (ID: %s) disconnected", clientAddr, clientID)
+
+		if reason == "" {
+			if parentCtx.Err() != nil {
+				reason = CloseShutdown
+			} else {
+				reason = conn.closeReason()
+			}
+		}
+		conn.stats.SessionClosed(reason)
+		logger.Infof("Client %s (ID: %s) disconnected: %s", clientAddr, clientID, reason)
 	}()
 
 	// 4. Передача управления обработчику протокола
@@ -180,6 +356,9 @@ func (cm *ConnectionManager) Stop() error {
 	// Отменяем контексты для всех активных подключений
 	for addr, connInfo := range cm.connections {
 		logger.Debugf("Cancelling context for client %s (ID: %s)", addr, connInfo.clientID)
+		if connInfo.reason == "" {
+			connInfo.reason = CloseShutdown
+		}
 		connInfo.cancelFunc()
 		// connInfo.conn.Close() вызывается в defer внутри handleNewConnection
 	}
@@ -192,7 +371,10 @@ func (cm *ConnectionManager) Stop() error {
 	}
 
 	cm.running = false
+	// Ждем без мьютекса: завершающиеся подключения удаляют себя из карт под ним
+	cm.mu.Unlock()
 	cm.wg.Wait() // Ждем, пока acceptLoop и все handleConnection горутины завершатся
+	cm.mu.Lock()
 
 	logger.Infof("Connection manager for protocol %s stopped.", "cm.clientData.GetName()")
 	return nil
@@ -214,12 +396,8 @@ func (cm *ConnectionManager) GetConnectedClients() []protocol.ClientInfo {
 	defer cm.mu.Unlock()
 
 	clients := make([]protocol.ClientInfo, 0, len(cm.connections))
-	for addr, connInfo := range cm.connections {
-		clients = append(clients, protocol.ClientInfo{
-			ID:    connInfo.clientID,
-			Addr:  addr,
-			Since: connInfo.connectedAt,
-		})
+	for _, connInfo := range cm.connections {
+		clients = append(clients, connInfo.info())
 	}
 	logger.Debugf("Retrieved list of %d connected clients", len(clients))
 	return clients
@@ -237,14 +415,69 @@ func (cm *ConnectionManager) DisconnectClient(clientAddr string) error {
 	}
 
 	logger.Infof("Отключение клиента %s (ID: %s) по запросу сервера", clientAddr, connInfo.clientID)
-	connInfo.cancelFunc()
-	if err := connInfo.conn.Close(); err != nil {
-		logger.Errorf("Ошибка закрытия соединения для клиента %s во время отключения: %v", clientAddr, err)
-		// Не возвращаем ошибку, т.к. цель (отключение клиента) достигнута
+	// Ошибку закрытия не возвращаем, т.к. цель (отключение клиента) достигнута
+	connInfo.close(CloseServer)
+	return nil
+}
+
+// FindClient возвращает подключение устройства по его ID (clientID из GetClientID)
+func (cm *ConnectionManager) FindClient(clientID string) (net.Conn, bool) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	connInfo, ok := cm.devices[clientID]
+	if !ok {
+		return nil, false
+	}
+	return connInfo.conn, true
+}
+
+// GetClient возвращает информацию о подключении устройства по его ID
+func (cm *ConnectionManager) GetClient(clientID string) (protocol.ClientInfo, bool) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	connInfo, ok := cm.devices[clientID]
+	if !ok {
+		return protocol.ClientInfo{}, false
 	}
+	return connInfo.info(), true
+}
+
+// DisconnectDevice находит подключение устройства по его ID и закрывает его
+func (cm *ConnectionManager) DisconnectDevice(clientID string) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	connInfo, found := cm.devices[clientID]
+	if !found {
+		return fmt.Errorf("client with ID %s not found", clientID)
+	}
+
+	logger.Infof("Disconnecting client ID %s (%s) by server request", clientID, connInfo.conn.RemoteAddr())
+	connInfo.close(CloseServer)
 	return nil
 }
 
+// GetPortStats возвращает статистику всех подключений порта, включая закрытые
+func (cm *ConnectionManager) GetPortStats() protocol.Stats {
+	return cm.portStats.Snapshot()
+}
+
+// ListBans возвращает IP-адреса, заблокированные после неудачных авторизаций
+func (cm *ConnectionManager) ListBans() []protocol.BanInfo {
+	return cm.limiter.bans(time.Now())
+}
+
+// Unban снимает блокировку IP-адреса, false - IP не был заблокирован
+func (cm *ConnectionManager) Unban(ip string) bool {
+	if !cm.limiter.unban(ip, time.Now()) {
+		return false
+	}
+	logger.Infof("IP %s unbanned by server request", ip)
+	return true
+}
+
 func (cm *ConnectionManager) IsRunning() bool {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
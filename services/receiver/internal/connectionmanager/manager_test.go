@@ -0,0 +1,352 @@
+package connectionmanager
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/capture"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+// lineClientData читает ID клиента из первой строки подключения
+type lineClientData struct{}
+
+func (lineClientData) GetClientID(conn net.Conn) (string, error) {
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// startManager запускает менеджер на свободном порту. Обработчик подключения
+// держит его открытым до отмены контекста.
+func startManager(t *testing.T) (*ConnectionManager, string) {
+	return startManagerWith(t, protocol.SessionPolicy{}, func(ctx context.Context, conn net.Conn, clientID string) {
+		<-ctx.Done()
+	})
+}
+
+// discardHandler читает подключение, пока оно не закроется
+func discardHandler(ctx context.Context, conn net.Conn, clientID string) {
+	io.Copy(io.Discard, conn)
+}
+
+// startManagerWith запускает менеджер на свободном порту с ограничениями policy
+func startManagerWith(t *testing.T, policy protocol.SessionPolicy, handler func(ctx context.Context, conn net.Conn, clientID string)) (*ConnectionManager, string) {
+	level, _ := logger.ParseLevel("ERROR")
+	logger.Init(level)
+
+	cm := NewConnectionManager(lineClientData{})
+	cm.SetPolicy(policy)
+	err := cm.Start(context.Background(), 0, handler)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { cm.Stop() })
+	return cm, cm.listener.Addr().String()
+}
+
+// connect подключается к менеджеру и ждет регистрации клиента id
+func connect(t *testing.T, cm *ConnectionManager, addr, id string) net.Conn {
+	conn, err := net.Dial("tcp", addr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { conn.Close() })
+	fmt.Fprintf(conn, "%s\n", id)
+
+	assert.Eventually(t, func() bool {
+		c, ok := cm.FindClient(id)
+		return ok && c.RemoteAddr().String() == conn.LocalAddr().String()
+	}, time.Second, 5*time.Millisecond)
+	return conn
+}
+
+// assertCloses ждет, пока статистика порта учтет закрытые подключения
+func assertCloses(t *testing.T, cm *ConnectionManager, want map[string]uint64) {
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual(want, cm.GetPortStats().Closes)
+	}, time.Second, 5*time.Millisecond, "closes: %v", cm.GetPortStats().Closes)
+}
+
+// assertClosed проверяет, что сервер закрыл подключение
+func assertClosed(t *testing.T, conn net.Conn) {
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err := conn.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestConnectionManager_DuplicateSession(t *testing.T) {
+	cm, addr := startManager(t)
+
+	first := connect(t, cm, addr, "866795030000000")
+	connect(t, cm, addr, "866795030000001")
+	second := connect(t, cm, addr, "866795030000000")
+
+	assertClosed(t, first)
+	assert.Eventually(t, func() bool { return cm.GetActiveConnectionsCount() == 2 }, time.Second, 5*time.Millisecond)
+
+	info, ok := cm.GetClient("866795030000000")
+	assert.True(t, ok)
+	assert.Equal(t, second.LocalAddr().String(), info.Addr)
+
+	// закрытие старой сессии не убирает устройство из индекса
+	_, ok = cm.FindClient("866795030000000")
+	assert.True(t, ok)
+	_, ok = cm.GetClient("866795030000001")
+	assert.True(t, ok)
+}
+
+func TestConnectionManager_DisconnectDevice(t *testing.T) {
+	cm, addr := startManager(t)
+
+	conn := connect(t, cm, addr, "42")
+	assert.Error(t, cm.DisconnectDevice("43"))
+	assert.NoError(t, cm.DisconnectDevice("42"))
+	assertClosed(t, conn)
+
+	assert.Eventually(t, func() bool {
+		_, ok := cm.GetClient("42")
+		return !ok && cm.GetActiveConnectionsCount() == 0
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestConnectionManager_StopWithClients(t *testing.T) {
+	cm, addr := startManager(t)
+	conn := connect(t, cm, addr, "42")
+
+	stopped := make(chan struct{})
+	go func() {
+		cm.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Stop did not return with active clients")
+	}
+	assertClosed(t, conn)
+	assert.False(t, cm.IsRunning())
+}
+
+func TestConnectionManager_Stats(t *testing.T) {
+	cm, addr := startManager(t)
+	connect(t, cm, addr, "42")
+	connect(t, cm, addr, "43")
+
+	info, ok := cm.GetClient("42")
+	assert.True(t, ok)
+	assert.Equal(t, uint64(len("42\n")), info.Stats.BytesIn)
+	assert.Equal(t, uint64(2*len("42\n")), cm.GetPortStats().BytesIn)
+}
+
+func TestConnectionManager_IdleTimeout(t *testing.T) {
+	cm, addr := startManagerWith(t, protocol.SessionPolicy{IdleTimeout: 200 * time.Millisecond}, discardHandler)
+	conn := connect(t, cm, addr, "42")
+
+	// данные продлевают сессию
+	for i := 0; i < 5; i++ {
+		time.Sleep(100 * time.Millisecond)
+		fmt.Fprint(conn, "ping\n")
+	}
+	_, ok := cm.GetClient("42")
+	assert.True(t, ok)
+
+	assertClosed(t, conn)
+	assertCloses(t, cm, map[string]uint64{CloseIdleTimeout: 1})
+}
+
+func TestConnectionManager_MaxSession(t *testing.T) {
+	cm, addr := startManagerWith(t, protocol.SessionPolicy{MaxSession: 100 * time.Millisecond}, discardHandler)
+	conn := connect(t, cm, addr, "42")
+
+	assertClosed(t, conn)
+	assertCloses(t, cm, map[string]uint64{CloseMaxSession: 1})
+}
+
+func TestConnectionManager_CloseReasons(t *testing.T) {
+	cm, addr := startManagerWith(t, protocol.SessionPolicy{}, discardHandler)
+
+	first := connect(t, cm, addr, "42")
+	connect(t, cm, addr, "42")
+	assertClosed(t, first)
+
+	assert.NoError(t, cm.DisconnectDevice("42"))
+	connect(t, cm, addr, "43").Close()
+
+	assertCloses(t, cm, map[string]uint64{
+		CloseDuplicate: 1,
+		CloseServer:    1,
+		CloseClient:    1,
+	})
+}
+
+func TestConnectionManager_MaxConnections(t *testing.T) {
+	cm, addr := startManagerWith(t, protocol.SessionPolicy{MaxConnections: 1}, discardHandler)
+	connect(t, cm, addr, "42")
+
+	conn, err := net.Dial("tcp", addr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer conn.Close()
+	assertClosed(t, conn)
+	assertCloses(t, cm, map[string]uint64{RejectMaxConnections: 1})
+}
+
+func TestConnectionManager_AuthFailBan(t *testing.T) {
+	cm, addr := startManagerWith(t, protocol.SessionPolicy{AuthFailBan: 2, BanDuration: time.Minute}, discardHandler)
+
+	// подключение без ID - неудачная авторизация
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("tcp", addr)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		conn.Close()
+	}
+	assert.Eventually(t, func() bool { return len(cm.ListBans()) == 1 }, time.Second, 5*time.Millisecond)
+	ip := cm.ListBans()[0].IP
+
+	conn, err := net.Dial("tcp", addr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer conn.Close()
+	assertClosed(t, conn)
+	assertCloses(t, cm, map[string]uint64{CloseClient: 2, RejectBanned: 1})
+
+	assert.True(t, cm.Unban(ip))
+	assert.False(t, cm.Unban(ip))
+	connect(t, cm, addr, "42")
+}
+
+// selfSignedTLS возвращает настройки сервера с самоподписанным сертификатом
+func selfSignedTLS(t *testing.T) *tls.Config {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+}
+
+func TestConnectionManager_TLS(t *testing.T) {
+	cm, addr := startManagerWith(t, protocol.SessionPolicy{TLS: selfSignedTLS(t)}, discardHandler)
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer conn.Close()
+	fmt.Fprint(conn, "42\n")
+	assert.Eventually(t, func() bool {
+		_, ok := cm.FindClient("42")
+		return ok
+	}, time.Second, 5*time.Millisecond)
+
+	// подключение без TLS не проходит рукопожатие
+	plain, err := net.Dial("tcp", addr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer plain.Close()
+	fmt.Fprint(plain, "866795030000000\n")
+	assertCloses(t, cm, map[string]uint64{CloseTLS: 1})
+	_, ok := cm.FindClient("866795030000000")
+	assert.False(t, ok)
+}
+
+func TestConnectionManager_UDP(t *testing.T) {
+	// на каждую строку отвечаем подтверждением, как обработчики отвечают на пакеты
+	ackHandler := func(ctx context.Context, conn net.Conn, clientID string) {
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			fmt.Fprintf(conn, "ack %s\n", scanner.Text())
+		}
+	}
+	cm, addr := startManagerWith(t, protocol.SessionPolicy{Transport: protocol.TransportUDP, IdleTimeout: 300 * time.Millisecond}, ackHandler)
+
+	dial := func() net.Conn {
+		conn, err := net.Dial("udp", addr)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		t.Cleanup(func() { conn.Close() })
+		return conn
+	}
+	first := dial()
+	fmt.Fprint(first, "42\n")
+	fmt.Fprint(first, "ping\n")
+
+	buf := make([]byte, 64)
+	first.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := first.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "ack ping\n", string(buf[:n]))
+
+	info, ok := cm.GetClient("42")
+	assert.True(t, ok)
+	assert.Equal(t, "udp", info.Net)
+	assert.Equal(t, first.LocalAddr().String(), info.Addr)
+
+	// устройство сменило адрес (NAT): новая псевдосессия заменяет старую
+	second := dial()
+	fmt.Fprint(second, "42\n")
+	assert.Eventually(t, func() bool {
+		info, ok := cm.GetClient("42")
+		return ok && info.Addr == second.LocalAddr().String() && cm.GetActiveConnectionsCount() == 1
+	}, time.Second, 5*time.Millisecond)
+
+	// без датаграмм псевдосессия истекает
+	assertCloses(t, cm, map[string]uint64{CloseDuplicate: 1, CloseIdleTimeout: 1})
+	assert.Equal(t, 0, cm.GetActiveConnectionsCount())
+}
+
+func TestConnectionManager_Capture(t *testing.T) {
+	dir := t.TempDir()
+	cm, addr := startManagerWith(t, protocol.SessionPolicy{}, discardHandler)
+	cm.SetCapture(protocol.CaptureConfig{Dir: dir, Protocol: "LINE", Devices: map[string]bool{"42": true}})
+
+	connect(t, cm, addr, "42").Close()
+	connect(t, cm, addr, "43").Close()
+	assertCloses(t, cm, map[string]uint64{CloseClient: 2})
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.cap"))
+	if !assert.Len(t, files, 1) {
+		t.FailNow()
+	}
+	header, chunks, err := capture.ReadAll(files[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "42", header.DeviceID)
+	assert.Equal(t, "LINE", header.Protocol)
+	if assert.Len(t, chunks, 1) {
+		assert.Equal(t, "42\n", string(chunks[0].Data))
+	}
+}
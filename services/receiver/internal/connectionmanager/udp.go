@@ -0,0 +1,322 @@
+package connectionmanager
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/logger"
+)
+
+const (
+	// udpIdleTimeout - срок жизни псевдосессии без датаграмм, если у порта не задан idle_timeout
+	udpIdleTimeout = 10 * time.Minute
+	// udpQueueSize - датаграмм в очереди псевдосессии; при переполнении новые отбрасываются
+	udpQueueSize = 64
+	// udpMaxDatagram - максимальный размер датаграммы
+	udpMaxDatagram = 65535
+	// udpMaxSessions - предельное число псевдосессий порта, если у порта не задан max_connections
+	udpMaxSessions = 10000
+	// udpAcceptQueue - новых псевдосессий, ожидающих Accept; при переполнении новые отбрасываются
+	udpAcceptQueue = 128
+	// udpReadBackoffMax - предельная пауза между повторами после ошибки чтения сокета
+	udpReadBackoffMax = time.Second
+)
+
+// DropQueueFull - датаграмма отброшена: псевдосессия не успевает ее обработать
+// (остальные причины - как у отказа в подключении, Reject*)
+const DropQueueFull = "queue_full"
+
+// udpAdmission - допуск новых адресов к порту. Лимиты проверяются до создания
+// псевдосессии, чтобы поток датаграмм с новых адресов не занимал память.
+type udpAdmission struct {
+	maxSessions int
+	// admit проверяет лимиты для нового адреса: пустая строка - адрес допущен и учтен
+	// в лимитах до вызова release, иначе - причина отказа
+	admit   func(ip string) string
+	release func(ip string)
+	// drop учитывает отброшенную датаграмму
+	drop func(addr net.Addr, reason string)
+}
+
+// udpListener - net.Listener поверх UDP-сокета. Датаграммы с одного адреса образуют
+// псевдосессию (udpConn), которую менеджер принимает и обрабатывает так же, как
+// TCP-подключение: авторизация, лимиты, idle_timeout, индекс по ID устройства.
+// Псевдосессия закрывается по тем же причинам, что и TCP-подключение; следующая
+// датаграмма с того же адреса начинает новую псевдосессию.
+type udpListener struct {
+	pc        net.PacketConn
+	admission udpAdmission
+	accept    chan *udpConn
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mu    sync.Mutex
+	conns map[string]*udpConn // ключ - адрес устройства
+}
+
+// listenUDP открывает UDP-порт и запускает прием датаграмм
+func listenUDP(ctx context.Context, lc net.ListenConfig, address string, admission udpAdmission) (*udpListener, error) {
+	pc, err := lc.ListenPacket(ctx, "udp", address)
+	if err != nil {
+		return nil, err
+	}
+	if admission.maxSessions <= 0 {
+		admission.maxSessions = udpMaxSessions
+	}
+	l := &udpListener{
+		pc:        pc,
+		admission: admission,
+		accept:    make(chan *udpConn, udpAcceptQueue),
+		done:      make(chan struct{}),
+		conns:     make(map[string]*udpConn),
+	}
+	go l.readLoop()
+	return l, nil
+}
+
+// readLoop читает датаграммы и раздает их псевдосессиям. После ошибки чтения
+// повторяет с нарастающей паузой, пока сокет не закрыт.
+func (l *udpListener) readLoop() {
+	buf := make([]byte, udpMaxDatagram)
+	var backoff time.Duration
+	for {
+		n, addr, err := l.pc.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			backoff = min(max(2*backoff, 5*time.Millisecond), udpReadBackoffMax)
+			logger.Warnf("UDP read error on %s, retrying in %s: %v", l.pc.LocalAddr(), backoff, err)
+			select {
+			case <-l.done:
+				return
+			case <-time.After(backoff):
+			}
+			continue
+		}
+		backoff = 0
+		if n == 0 {
+			continue
+		}
+		l.dispatch(addr, append([]byte(nil), buf[:n]...))
+	}
+}
+
+// dispatch передает датаграмму псевдосессии адреса addr. Для нового адреса проверяет
+// лимиты и создает псевдосессию; датаграммы, не прошедшие лимиты, отбрасываются.
+// Не блокируется: медленный обработчик не задерживает датаграммы других устройств.
+func (l *udpListener) dispatch(addr net.Addr, data []byte) {
+	key := addr.String()
+	l.mu.Lock()
+	c, ok := l.conns[key]
+	if ok {
+		l.mu.Unlock()
+		c.deliver(data)
+		return
+	}
+	if len(l.conns) >= l.admission.maxSessions {
+		l.mu.Unlock()
+		l.admission.drop(addr, RejectMaxConnections)
+		return
+	}
+	ip := udpIP(addr)
+	if reason := l.admission.admit(ip); reason != "" {
+		l.mu.Unlock()
+		l.admission.drop(addr, reason)
+		return
+	}
+	c = newUDPConn(l, addr, ip)
+	l.conns[key] = c
+	l.mu.Unlock()
+
+	c.deliver(data)
+	select {
+	case l.accept <- c:
+	default:
+		// Обработчики не успевают принимать новые псевдосессии
+		c.Close()
+		l.admission.drop(addr, RejectMaxConnections)
+	}
+}
+
+// remove удаляет закрытую псевдосессию из таблицы и из лимитов
+func (l *udpListener) remove(c *udpConn) {
+	l.mu.Lock()
+	if key := c.remote.String(); l.conns[key] == c {
+		delete(l.conns, key)
+	}
+	l.mu.Unlock()
+	l.admission.release(c.ip)
+}
+
+// udpIP возвращает IP-адрес отправителя датаграммы (как remoteIP для подключения)
+func udpIP(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+// Accept возвращает псевдосессию нового адреса
+func (l *udpListener) Accept() (net.Conn, error) {
+	// после Close не отдаем псевдосессии, оставшиеся в очереди: они уже закрыты
+	select {
+	case <-l.done:
+		return nil, net.ErrClosed
+	default:
+	}
+	select {
+	case c := <-l.accept:
+		return c, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close закрывает сокет и все псевдосессии
+func (l *udpListener) Close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		close(l.done)
+		err = l.pc.Close()
+
+		l.mu.Lock()
+		conns := make([]*udpConn, 0, len(l.conns))
+		for _, c := range l.conns {
+			conns = append(conns, c)
+		}
+		l.mu.Unlock()
+		for _, c := range conns {
+			c.Close()
+		}
+	})
+	return err
+}
+
+func (l *udpListener) Addr() net.Addr {
+	return l.pc.LocalAddr()
+}
+
+// udpConn - псевдосессия устройства: датаграммы с одного адреса, читаемые как поток.
+// Ответы отправляются датаграммами на адрес устройства, каждый Write - одна датаграмма.
+type udpConn struct {
+	l         *udpListener
+	remote    net.Addr
+	ip        string // IP адреса устройства, под ним псевдосессия учтена в лимитах
+	queue     chan []byte
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	pending []byte // непрочитанный остаток датаграммы, только для читающей горутины
+
+	mu           sync.Mutex
+	readDeadline time.Time
+}
+
+func newUDPConn(l *udpListener, remote net.Addr, ip string) *udpConn {
+	return &udpConn{
+		l:      l,
+		remote: remote,
+		ip:     ip,
+		queue:  make(chan []byte, udpQueueSize),
+		closed: make(chan struct{}),
+	}
+}
+
+// deliver ставит датаграмму в очередь, при переполнении датаграмма отбрасывается
+// (устройство повторит неподтвержденный пакет)
+func (c *udpConn) deliver(data []byte) {
+	select {
+	case c.queue <- data:
+	default:
+		c.l.admission.drop(c.remote, DropQueueFull)
+	}
+}
+
+func (c *udpConn) Read(b []byte) (int, error) {
+	if len(c.pending) == 0 {
+		data, err := c.next()
+		if err != nil {
+			return 0, err
+		}
+		c.pending = data
+	}
+	n := copy(b, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
+
+// next ждет следующую датаграмму до срока чтения
+func (c *udpConn) next() ([]byte, error) {
+	c.mu.Lock()
+	deadline := c.readDeadline
+	c.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if !deadline.IsZero() {
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return nil, os.ErrDeadlineExceeded
+		}
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case data := <-c.queue:
+		return data, nil
+	case <-c.closed:
+		return nil, net.ErrClosed
+	case <-timeout:
+		return nil, os.ErrDeadlineExceeded
+	}
+}
+
+func (c *udpConn) Write(b []byte) (int, error) {
+	select {
+	case <-c.closed:
+		return 0, net.ErrClosed
+	default:
+	}
+	return c.l.pc.WriteTo(b, c.remote)
+}
+
+// Close завершает псевдосессию, сокет порта остается открытым
+func (c *udpConn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		c.l.remove(c)
+	})
+	return nil
+}
+
+func (c *udpConn) LocalAddr() net.Addr {
+	return c.l.pc.LocalAddr()
+}
+
+func (c *udpConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+func (c *udpConn) SetDeadline(t time.Time) error {
+	return c.SetReadDeadline(t)
+}
+
+// SetReadDeadline задает срок чтения; применяется к следующему ожиданию датаграммы
+func (c *udpConn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readDeadline = t
+	return nil
+}
+
+// SetWriteDeadline не используется: отправка датаграммы не блокируется
+func (c *udpConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
@@ -0,0 +1,144 @@
+package connectionmanager
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testAdmission считает допущенные и освобожденные адреса и отброшенные датаграммы
+type testAdmission struct {
+	mu       sync.Mutex
+	admitted int
+	released int
+	rejected []string
+	deny     string // IP, которому отказывать
+}
+
+func (a *testAdmission) udpAdmission(maxSessions int) udpAdmission {
+	return udpAdmission{
+		maxSessions: maxSessions,
+		admit: func(ip string) string {
+			a.mu.Lock()
+			defer a.mu.Unlock()
+			if ip == a.deny {
+				return RejectBanned
+			}
+			a.admitted++
+			return ""
+		},
+		release: func(ip string) {
+			a.mu.Lock()
+			defer a.mu.Unlock()
+			a.released++
+		},
+		drop: func(addr net.Addr, reason string) {
+			a.mu.Lock()
+			defer a.mu.Unlock()
+			a.rejected = append(a.rejected, reason)
+		},
+	}
+}
+
+func (a *testAdmission) counts() (int, int, []string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.admitted, a.released, append([]string(nil), a.rejected...)
+}
+
+func sessions(l *udpListener) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.conns)
+}
+
+func sendUDP(t *testing.T, l *udpListener, data string) net.Conn {
+	conn, err := net.Dial("udp", l.Addr().String())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { conn.Close() })
+	_, err = conn.Write([]byte(data))
+	assert.NoError(t, err)
+	return conn
+}
+
+func TestUDPListener_Admission(t *testing.T) {
+	admission := &testAdmission{}
+	l, err := listenUDP(context.Background(), net.ListenConfig{}, "127.0.0.1:0", admission.udpAdmission(2))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// псевдосессии никто не принимает, но прием датаграмм не останавливается
+	first := sendUDP(t, l, "a")
+	sendUDP(t, l, "b")
+	assert.Eventually(t, func() bool { return sessions(l) == 2 }, time.Second, 5*time.Millisecond)
+	first.Write([]byte("c"))
+
+	// сверх предела псевдосессий датаграммы новых адресов отбрасываются
+	sendUDP(t, l, "d")
+	assert.Eventually(t, func() bool {
+		_, _, rejected := admission.counts()
+		return len(rejected) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 2, sessions(l))
+
+	c, err := l.Accept()
+	if assert.NoError(t, err) {
+		buf := make([]byte, 8)
+		c.SetReadDeadline(time.Now().Add(time.Second))
+		n, _ := c.Read(buf)
+		assert.Equal(t, "a", string(buf[:n]))
+		n, _ = c.Read(buf)
+		assert.Equal(t, "c", string(buf[:n]))
+	}
+
+	// закрытие порта освобождает лимиты всех псевдосессий, в том числе не принятых
+	assert.NoError(t, l.Close())
+	admitted, released, rejected := admission.counts()
+	assert.Equal(t, 2, admitted)
+	assert.Equal(t, 2, released)
+	assert.Equal(t, []string{RejectMaxConnections}, rejected)
+	_, err = l.Accept()
+	assert.ErrorIs(t, err, net.ErrClosed)
+}
+
+func TestUDPListener_Rejected(t *testing.T) {
+	admission := &testAdmission{deny: "127.0.0.1"}
+	l, err := listenUDP(context.Background(), net.ListenConfig{}, "127.0.0.1:0", admission.udpAdmission(0))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer l.Close()
+
+	// адрес, не прошедший лимиты, не создает псевдосессию
+	sendUDP(t, l, "a")
+	assert.Eventually(t, func() bool {
+		_, _, rejected := admission.counts()
+		return len(rejected) == 1 && rejected[0] == RejectBanned
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 0, sessions(l))
+}
+
+func TestUDPListener_QueueFull(t *testing.T) {
+	admission := &testAdmission{}
+	l, err := listenUDP(context.Background(), net.ListenConfig{}, "127.0.0.1:0", admission.udpAdmission(0))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer l.Close()
+
+	// псевдосессию не читают: сверх очереди датаграммы отбрасываются и учитываются
+	addr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 2), Port: 5000}
+	for i := 0; i < udpQueueSize+2; i++ {
+		l.dispatch(addr, []byte("x"))
+	}
+	admitted, _, rejected := admission.counts()
+	assert.Equal(t, 1, admitted)
+	assert.Equal(t, []string{DropQueueFull, DropQueueFull}, rejected)
+}
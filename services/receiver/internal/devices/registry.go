@@ -0,0 +1,245 @@
+// Package devices хранит реестр устройств, которые обслуживает RECEIVER.
+// Обработчики протоколов сверяются с ним при авторизации: неизвестные и отключенные
+// устройства, а также устройства на чужом протоколе не допускаются к публикации данных.
+package devices
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+)
+
+var (
+	// ErrUnknownDevice - устройства нет в реестре
+	ErrUnknownDevice = errors.New("device is not registered")
+	// ErrDisabled - устройство отключено в реестре
+	ErrDisabled = errors.New("device is disabled")
+	// ErrProtocol - устройству разрешен другой протокол
+	ErrProtocol = errors.New("protocol is not allowed for device")
+	// ErrNotFound - устройство для изменения не найдено
+	ErrNotFound = errors.New("device not found")
+)
+
+// Device - запись реестра
+type Device struct {
+	ID       string // IMEI или ID терминала, которым устройство авторизуется
+	Protocol string // разрешенный протокол (EGTS, ARNAVI, ...); пусто - любой
+	Customer string // владелец устройства
+	Enabled  bool   // устройство обслуживается
+}
+
+// fileDevice - запись реестра в файле. Без enabled устройство включено.
+type fileDevice struct {
+	ID       string `toml:"id"`
+	Protocol string `toml:"protocol,omitempty"`
+	Customer string `toml:"customer,omitempty"`
+	Enabled  *bool  `toml:"enabled,omitempty"`
+}
+
+type registryFile struct {
+	Devices []fileDevice `toml:"devices"`
+}
+
+// Registry - реестр устройств в TOML-файле. Изменения через Put и Delete сразу
+// сохраняются в файл; правки файла вручную применяются вызовом Reload.
+type Registry struct {
+	path string
+
+	mu      sync.RWMutex
+	devices map[string]Device // ключ - ID устройства
+}
+
+// Open загружает реестр из файла path. Если файла нет, реестр пуст
+// и будет создан при первом изменении.
+func Open(path string) (*Registry, error) {
+	r := &Registry{path: path, devices: make(map[string]Device)}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload перечитывает файл реестра. При ошибке остается прежнее содержимое.
+func (r *Registry) Reload() error {
+	devices, err := load(r.path)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.devices = devices
+	r.mu.Unlock()
+	return nil
+}
+
+// load читает и проверяет файл реестра
+func load(path string) (map[string]Device, error) {
+	devices := make(map[string]Device)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return devices, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read device registry %s: %w", path, err)
+	}
+
+	var file registryFile
+	if _, err := toml.Decode(string(data), &file); err != nil {
+		return nil, fmt.Errorf("failed to parse device registry %s: %w", path, err)
+	}
+	for i, fd := range file.Devices {
+		d := Device{ID: strings.TrimSpace(fd.ID), Protocol: fd.Protocol, Customer: fd.Customer, Enabled: true}
+		if fd.Enabled != nil {
+			d.Enabled = *fd.Enabled
+		}
+		if d.ID == "" {
+			return nil, fmt.Errorf("device registry %s: device #%d has no id", path, i+1)
+		}
+		if _, ok := devices[d.ID]; ok {
+			return nil, fmt.Errorf("device registry %s: duplicate device id %s", path, d.ID)
+		}
+		devices[d.ID] = d
+	}
+	return devices, nil
+}
+
+// Authorize проверяет, допущено ли устройство на порт протокола protocol.
+// ids - идентификаторы устройства (ID терминала, IMEI); решение принимается
+// по первому из них, найденному в реестре.
+func (r *Registry) Authorize(protocol string, ids ...string) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	known := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		known = append(known, id)
+
+		d, ok := r.devices[id]
+		if !ok {
+			continue
+		}
+		if !d.Enabled {
+			return fmt.Errorf("%w: %s", ErrDisabled, id)
+		}
+		if d.Protocol != "" && !strings.EqualFold(d.Protocol, protocol) {
+			return fmt.Errorf("%w: %s allowed only on %s", ErrProtocol, id, d.Protocol)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownDevice, strings.Join(known, ", "))
+}
+
+// Get возвращает устройство по ID
+func (r *Registry) Get(id string) (Device, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	d, ok := r.devices[id]
+	return d, ok
+}
+
+// List возвращает устройства реестра, отсортированные по ID.
+// customer - только устройства владельца, пусто - все.
+func (r *Registry) List(customer string) []Device {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]Device, 0, len(r.devices))
+	for _, d := range r.devices {
+		if customer == "" || d.Customer == customer {
+			result = append(result, d)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// Put добавляет устройство или заменяет запись с тем же ID и сохраняет реестр
+func (r *Registry) Put(d Device) error {
+	d.ID = strings.TrimSpace(d.ID)
+	if d.ID == "" {
+		return fmt.Errorf("device id is not specified")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prev, existed := r.devices[d.ID]
+	r.devices[d.ID] = d
+	if err := r.save(); err != nil {
+		if existed {
+			r.devices[d.ID] = prev
+		} else {
+			delete(r.devices, d.ID)
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete удаляет устройство из реестра и сохраняет его
+func (r *Registry) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prev, ok := r.devices[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	delete(r.devices, id)
+	if err := r.save(); err != nil {
+		r.devices[id] = prev
+		return err
+	}
+	return nil
+}
+
+// save атомарно перезаписывает файл реестра. Вызывается под r.mu.
+func (r *Registry) save() error {
+	ids := make([]string, 0, len(r.devices))
+	for id := range r.devices {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	file := registryFile{Devices: make([]fileDevice, 0, len(ids))}
+	for _, id := range ids {
+		d := r.devices[id]
+		enabled := d.Enabled
+		file.Devices = append(file.Devices, fileDevice{ID: d.ID, Protocol: d.Protocol, Customer: d.Customer, Enabled: &enabled})
+	}
+
+	if dir := filepath.Dir(r.path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create device registry directory %s: %w", dir, err)
+		}
+	}
+	tmpFile := r.path + ".tmp"
+	f, err := os.Create(tmpFile)
+	if err != nil {
+		return fmt.Errorf("failed to create temp device registry file: %w", err)
+	}
+	if err := toml.NewEncoder(f).Encode(file); err != nil {
+		f.Close()
+		os.Remove(tmpFile)
+		return fmt.Errorf("failed to encode device registry: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmpFile)
+		return fmt.Errorf("failed to write device registry: %w", err)
+	}
+	if err := os.Rename(tmpFile, r.path); err != nil {
+		return fmt.Errorf("failed to replace device registry file: %w", err)
+	}
+	return nil
+}
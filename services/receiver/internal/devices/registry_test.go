@@ -0,0 +1,103 @@
+package devices
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testRegistry = `
+[[devices]]
+  id = "866795030000000"
+  protocol = "EGTS"
+  customer = "acme"
+
+[[devices]]
+  id = "42"
+  customer = "acme"
+  enabled = false
+
+[[devices]]
+  id = "100"
+`
+
+func openTest(t *testing.T, content string) (*Registry, string) {
+	path := filepath.Join(t.TempDir(), "devices.toml")
+	if content != "" {
+		if !assert.NoError(t, os.WriteFile(path, []byte(content), 0644)) {
+			t.FailNow()
+		}
+	}
+	r, err := Open(path)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return r, path
+}
+
+func TestRegistry_Authorize(t *testing.T) {
+	r, _ := openTest(t, testRegistry)
+
+	assert.NoError(t, r.Authorize("EGTS", "866795030000000"))
+	assert.NoError(t, r.Authorize("egts", "866795030000000"))
+	assert.ErrorIs(t, r.Authorize("ARNAVI", "866795030000000"), ErrProtocol)
+	assert.ErrorIs(t, r.Authorize("ARNAVI", "42"), ErrDisabled)
+	assert.NoError(t, r.Authorize("WIALON", "100"))
+	assert.ErrorIs(t, r.Authorize("ARNAVI", "43"), ErrUnknownDevice)
+	assert.ErrorIs(t, r.Authorize("ARNAVI"), ErrUnknownDevice)
+
+	// EGTS: терминал с неизвестным TID допускается по IMEI
+	assert.NoError(t, r.Authorize("EGTS", "7", "", "866795030000000"))
+}
+
+func TestRegistry_Load(t *testing.T) {
+	r, _ := openTest(t, "")
+	assert.Empty(t, r.List(""))
+
+	path := filepath.Join(t.TempDir(), "devices.toml")
+	os.WriteFile(path, []byte("[[devices]]\n  id = \"1\"\n[[devices]]\n  id = \"1\"\n"), 0644)
+	_, err := Open(path)
+	assert.Error(t, err)
+
+	os.WriteFile(path, []byte("[[devices]]\n  customer = \"acme\"\n"), 0644)
+	_, err = Open(path)
+	assert.Error(t, err)
+}
+
+func TestRegistry_PutDelete(t *testing.T) {
+	r, path := openTest(t, testRegistry)
+
+	assert.Error(t, r.Put(Device{ID: " "}))
+	assert.NoError(t, r.Put(Device{ID: "43", Protocol: "ARNAVI", Customer: "beta", Enabled: true}))
+	assert.NoError(t, r.Put(Device{ID: "42", Customer: "acme", Enabled: true}))
+	assert.ErrorIs(t, r.Delete("44"), ErrNotFound)
+	assert.NoError(t, r.Delete("100"))
+
+	assert.Len(t, r.List("acme"), 2)
+	assert.Equal(t, []Device{{ID: "43", Protocol: "ARNAVI", Customer: "beta", Enabled: true}}, r.List("beta"))
+
+	// изменения сохранены в файл
+	reopened, err := Open(path)
+	if assert.NoError(t, err) {
+		assert.Equal(t, r.List(""), reopened.List(""))
+	}
+	d, ok := reopened.Get("42")
+	assert.True(t, ok)
+	assert.True(t, d.Enabled)
+}
+
+func TestRegistry_Reload(t *testing.T) {
+	r, path := openTest(t, testRegistry)
+
+	os.WriteFile(path, []byte("[[devices]]\n  id = \"1\"\n"), 0644)
+	assert.NoError(t, r.Reload())
+	assert.NoError(t, r.Authorize("ARNAVI", "1"))
+	assert.ErrorIs(t, r.Authorize("ARNAVI", "100"), ErrUnknownDevice)
+
+	// ошибка разбора не стирает загруженный реестр
+	os.WriteFile(path, []byte("[[devices]\n"), 0644)
+	assert.Error(t, r.Reload())
+	assert.NoError(t, r.Authorize("ARNAVI", "1"))
+}
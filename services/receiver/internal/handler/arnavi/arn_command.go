@@ -0,0 +1,98 @@
+package arnavi
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/rackov/NavControlSystem/pkg/models"
+)
+
+// коды команд сервера трекеру (первый байт данных команды)
+const (
+	// включение/выключение выхода: номер выхода (с 1), состояние 0/1
+	ComSetOutput = 0x01
+	// запрос внеочередной навигационной записи, без параметров
+	ComRequestPosition = 0x02
+)
+
+// номера посылок команд, как и номера PACKAGE, лежат в диапазоне 0x01..0xFB
+const maxCommandID = 0xFB
+
+// выход блокировки двигателя, если номер не задан в команде
+const defaultEngineOutput = 1
+
+// CommandData - данные команды сервера в пакете 7B LEN ID CS DATA 7D (см. ResCom).
+// Трекер отвечает на команду AnswerCom: 5B ID CODE 5D, CODE 0 - команда выполнена.
+type CommandData struct {
+	Code   byte
+	Params []byte
+}
+
+func (c *CommandData) Decode(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("пустые данные команды")
+	}
+	c.Code = data[0]
+	c.Params = append([]byte(nil), data[1:]...)
+	return nil
+}
+
+func (c *CommandData) Encode() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(c.Code)
+	buf.Write(c.Params)
+	if buf.Len() > 0xFF {
+		return nil, fmt.Errorf("слишком длинная команда: %d байт", buf.Len())
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *CommandData) Length() uint16 {
+	return uint16(1 + len(c.Params))
+}
+
+// NewCommandData преобразует команду диспетчера в команду Arnavi
+func NewCommandData(cmd *models.Command) (*CommandData, error) {
+	output := cmd.Output
+	if output == 0 {
+		output = defaultEngineOutput
+	}
+
+	switch cmd.Type {
+	case models.CommandEngineBlock:
+		return &CommandData{Code: ComSetOutput, Params: []byte{output, 1}}, nil
+	case models.CommandEngineUnblock:
+		return &CommandData{Code: ComSetOutput, Params: []byte{output, 0}}, nil
+	case models.CommandSetOutput:
+		state := byte(0)
+		if cmd.On {
+			state = 1
+		}
+		return &CommandData{Code: ComSetOutput, Params: []byte{cmd.Output, state}}, nil
+	case models.CommandRequestPosition:
+		return &CommandData{Code: ComRequestPosition}, nil
+	case models.CommandRaw:
+		c := &CommandData{}
+		if err := c.Decode(cmd.Payload); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	return nil, fmt.Errorf("неизвестный тип команды %q", cmd.Type)
+}
+
+// EncodeCommand формирует пакет команды с номером посылки id
+func EncodeCommand(id int, data *CommandData) ([]byte, error) {
+	com := ResCom{
+		StartSign: SignedStart,
+		CodeCom:   byte(id),
+		Data:      data,
+		EndSign:   SignedEnd,
+	}
+	return com.Encode()
+}
+
+// nextCommandID возвращает номер посылки следующей команды
+func nextCommandID(prev uint32) uint32 {
+	return prev%maxCommandID + 1
+}
@@ -0,0 +1,48 @@
+package arnavi
+
+import (
+	"testing"
+
+	"github.com/rackov/NavControlSystem/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeCommand(t *testing.T) {
+	data, err := NewCommandData(&models.Command{Type: models.CommandEngineBlock})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	b, err := EncodeCommand(5, data)
+	if assert.NoError(t, err) {
+		// 7B LEN ID CS DATA 7D, CS - сумма байтов данных
+		assert.Equal(t, []byte{0x7B, 0x03, 0x05, 0x03, ComSetOutput, 0x01, 0x01, 0x7D}, b)
+	}
+}
+
+func TestNewCommandData(t *testing.T) {
+	tests := []struct {
+		cmd  models.Command
+		want *CommandData
+	}{
+		{models.Command{Type: models.CommandEngineUnblock, Output: 2}, &CommandData{Code: ComSetOutput, Params: []byte{2, 0}}},
+		{models.Command{Type: models.CommandSetOutput, Output: 3, On: true}, &CommandData{Code: ComSetOutput, Params: []byte{3, 1}}},
+		{models.Command{Type: models.CommandRequestPosition}, &CommandData{Code: ComRequestPosition}},
+		{models.Command{Type: models.CommandRaw, Payload: []byte{0x10, 0xAA}}, &CommandData{Code: 0x10, Params: []byte{0xAA}}},
+	}
+	for _, tt := range tests {
+		got, err := NewCommandData(&tt.cmd)
+		if assert.NoError(t, err, tt.cmd.Type) {
+			assert.Equal(t, tt.want, got, tt.cmd.Type)
+		}
+	}
+
+	_, err := NewCommandData(&models.Command{Type: "reboot"})
+	assert.Error(t, err)
+}
+
+func TestNextCommandID(t *testing.T) {
+	assert.Equal(t, uint32(1), nextCommandID(0))
+	assert.Equal(t, uint32(0xFB), nextCommandID(0xFA))
+	assert.Equal(t, uint32(1), nextCommandID(0xFB))
+}
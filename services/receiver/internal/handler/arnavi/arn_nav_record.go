@@ -0,0 +1,46 @@
+package arnavi
+
+import (
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/models"
+)
+
+// ApplyTo переносит теги пакета в навигационную запись.
+// Координаты в TagsData хранятся в градусах*10^7 со знаком (при разборе float32 умножается
+// на 10^6 и еще на 10), в записи - в градусах.
+func (r *TagsData) ApplyTo(rec *models.NavRecord) {
+	if r.ListActive&1 == 1 {
+		rec.Latitude = float64(r.Latitude) / 1e7
+	}
+	if r.ListActive&2 == 2 {
+		rec.Longitude = float64(r.Longitude) / 1e7
+	}
+	rec.Valid = r.ListActive&3 == 3
+
+	if r.ListActive&4 == 4 {
+		rec.Speed = float64(r.Speed)
+		rec.Course = uint16(r.Course)
+		rec.Altitude = float64(r.Altitude)
+		rec.AltitudeValid = true
+		// младшая тетрада - спутники GPS, старшая - ГЛОНАСС
+		rec.Satellites = uint8(r.Satellites&0xf + (r.Satellites>>4)&0xf)
+	}
+
+	// теги LL1..LL8 - датчики уровня топлива 1..8
+	for i, level := range r.LL {
+		if level == 0 {
+			continue
+		}
+		rec.SetSensor(models.SensorName(models.SensorLiquidLevel, i+1), float64(level))
+	}
+}
+
+// ToNavRecord переводит теги пакета в навигационную запись со временем навигации navTime (unix time)
+func (r *TagsData) ToNavRecord(navTime uint32) *models.NavRecord {
+	rec := &models.NavRecord{
+		NavigationTime: time.Unix(int64(navTime), 0).UTC(),
+	}
+	r.ApplyTo(rec)
+	return rec
+}
@@ -0,0 +1,40 @@
+package arnavi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagsData_ToNavRecord(t *testing.T) {
+	rec := dataTeg.ToNavRecord(1521113853)
+
+	assert.Equal(t, time.Unix(1521113853, 0).UTC(), rec.NavigationTime)
+	assert.InDelta(t, 55.636311, rec.Latitude, 1e-9)
+	assert.InDelta(t, 37.208553, rec.Longitude, 1e-9)
+	assert.True(t, rec.Valid)
+	assert.Equal(t, uint8(7+7), rec.Satellites)
+}
+
+func TestTagsData_ToNavRecordHemisphere(t *testing.T) {
+	tags := TagsData{
+		ListActive: 7,
+		Latitude:   -337000000,
+		Longitude:  -705000000,
+		Speed:      59.264,
+		Course:     270,
+		Altitude:   120,
+		LL:         [8]int{0, 1500},
+	}
+
+	rec := tags.ToNavRecord(0)
+
+	assert.InDelta(t, -33.7, rec.Latitude, 1e-9)
+	assert.InDelta(t, -70.5, rec.Longitude, 1e-9)
+	assert.True(t, rec.Valid)
+	assert.InDelta(t, 59.264, rec.Speed, 1e-4)
+	assert.Equal(t, uint16(270), rec.Course)
+	assert.Equal(t, 120.0, rec.Altitude)
+	assert.Equal(t, map[string]float64{"lls2": 1500}, rec.Sensors)
+}
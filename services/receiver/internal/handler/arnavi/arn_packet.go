@@ -38,7 +38,12 @@ func (p *PacketS) Decode(rec []byte) error {
 	case PackTagsType:
 		p.Data = &TagsData{}
 	}
-	p.Data.Decode(packetBuf)
+	// пакеты остальных типов пропускаем без разбора
+	if p.Data != nil {
+		if err = p.Data.Decode(packetBuf); err != nil {
+			return fmt.Errorf("не удалось разобрать данные пакета %v", err)
+		}
+	}
 
 	if p.CheckSum, err = buf.ReadByte(); err != nil {
 		return fmt.Errorf("не удалось считать crc пакета %v", err)
@@ -87,6 +87,8 @@ func (r *ResCom) Encode() ([]byte, error) {
 	switch r.Data.(type) {
 	case *ConfirmationHeader:
 		r.CodeCom = ConfirmationHeaderType
+	case *CommandData:
+		// CodeCom команды - номер посылки, его задает отправитель
 	case nil:
 
 	default:
@@ -1,25 +1,57 @@
 package arnavi
 
 import (
+	"bufio"
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
 	"net"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/pkg/models"
 	"github.com/rackov/NavControlSystem/services/receiver/internal/connectionmanager"
 	"github.com/rackov/NavControlSystem/services/receiver/internal/protocol"
 )
 
+// defaultAuthTimeout - время ожидания HEADER от трекера по умолчанию
+const defaultAuthTimeout = 30 * time.Second
+
 type ArnaviHandler struct {
-	connManager *connectionmanager.ConnectionManager
-	publisher   protocol.DataPublisher // Храним publisher для доступа в handleConnection
+	connectionmanager.Base
+
+	publisher protocol.DataPublisher // Храним publisher для доступа в handleConnection
+
+	// Сессии подключенных трекеров, ключ - net.Conn
+	sessions sync.Map
+}
+
+// arnaviSession хранит состояние одного подключения трекера
+type arnaviSession struct {
+	imei uint64
+
+	// подтверждения пакетов и команды пишутся в подключение из разных горутин
+	writeMu  sync.Mutex
+	commands protocol.PendingCommands
+}
+
+// write отправляет пакет трекеру
+func (s *arnaviSession) write(conn net.Conn, data []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	_, err := conn.Write(data)
+	return err
 }
 
 func NewArnaviHandler() *ArnaviHandler {
 	// Передаем сам обработчик (который реализует ClientData) в конструктор менеджера
 	h := &ArnaviHandler{}
 	// Теперь, когда 'h' создан, мы можем передать его
-	h.connManager = connectionmanager.NewConnectionManager(h)
+	h.Base = connectionmanager.NewBase(h, defaultAuthTimeout)
 	return h
 }
 
@@ -27,7 +59,7 @@ func NewArnaviHandler() *ArnaviHandler {
 func (h *ArnaviHandler) Start(ctx context.Context, publisher protocol.DataPublisher, port int) error {
 	h.publisher = publisher
 	// Передаем функцию, которая будет вызываться для каждого авторизованного клиента
-	return h.connManager.Start(ctx, port, h.handleConnection)
+	return h.Manager().Start(ctx, port, h.handleConnection)
 }
 
 // GetName возвращает имя протокола
@@ -39,83 +71,249 @@ func (h *ArnaviHandler) GetName() string {
 func (h *ArnaviHandler) Stop() error {
 	logger.Info("Stopping Arnavi handler...")
 
-	return h.connManager.Stop()
-}
-
-// IsRunning проверяет состояние ConnectionManager
-func (h *ArnaviHandler) IsRunning() bool {
-	return h.connManager.IsRunning()
+	return h.Manager().Stop()
 }
 
-// --- Методы, которые просто делегируют вызовы ConnectionManager ---
-
-func (h *ArnaviHandler) GetActiveConnectionsCount() int {
-	return h.connManager.GetActiveConnectionsCount()
-}
-
-func (h *ArnaviHandler) GetConnectedClients() []protocol.ClientInfo {
-	return h.connManager.GetConnectedClients()
-}
+// GetClientID реализует интерфейс ClientData для авторизации.
+// Читает HEADER (HeadOne), отвечает подтверждением и возвращает IMEI/ID как clientID.
+func (h *ArnaviHandler) GetClientID(conn net.Conn) (string, error) {
+	// Устанавливаем таймаут на чтение, чтобы не зависнуть, если клиент ничего не присылает
+	conn.SetReadDeadline(time.Now().Add(h.AuthTimeout()))
+	defer conn.SetReadDeadline(time.Time{})
 
-func (h *ArnaviHandler) DisconnectClient(clientAddr string) error {
-	return h.connManager.DisconnectClient(clientAddr)
-}
+	buf := make([]byte, SizeAuth+8)
+	if _, err := io.ReadFull(conn, buf[:SizeAuth]); err != nil {
+		return "", fmt.Errorf("failed to read header: %w", err)
+	}
+	size := SizeAuth
+	// HEADER3 дополнительно содержит EXT ID
+	if buf[1] == 0x24 {
+		if _, err := io.ReadFull(conn, buf[SizeAuth:]); err != nil {
+			return "", fmt.Errorf("failed to read extended header: %w", err)
+		}
+		size += 8
+	}
 
-// GetClientID реализует интерфейс ClientData для авторизации
-func (h *ArnaviHandler) GetClientID(conn net.Conn) (string, error) {
-	// // Устанавливаем таймаут на чтение, чтобы не зависнуть, если клиент ничего не присылает
-	// conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	stats := protocol.StatsOf(conn)
+	headOne := HeadOne{}
+	if err := headOne.Decode(buf[:size]); err != nil {
+		stats.DecodeError()
+		return "", fmt.Errorf("failed to decode header: %w", err)
+	}
+	stats.PacketDecoded()
+	stats.SetProtocolVersion(fmt.Sprintf("0x%X", buf[1]))
 
-	// buf := make([]byte, 4)
-	// n, err := conn.Read(buf)
-	// if err != nil || n != 4 {
-	// 	return "", fmt.Errorf("failed to read client ID: %w", err)
-	// }
+	clientID := strconv.FormatUint(headOne.IdImei, 10)
+	// Недопущенному трекеру HEADER не подтверждаем, сессия закрывается
+	if err := protocol.AuthorizeDevice(h.publisher, clientID); err != nil {
+		return "", err
+	}
 
-	// // Сбрасываем таймаут
-	// conn.SetReadDeadline(time.Time{})
+	if _, err := conn.Write(AnswerHeader()); err != nil {
+		return "", fmt.Errorf("failed to send header confirmation: %w", err)
+	}
 
-	// id := uint32(buf[0])<<24 | uint32(buf[1])<<16 | uint32(buf[2])<<8 | uint32(buf[3])
-	return "1", nil //strconv.FormatUint(uint64(id), 10), nil
+	h.sessions.Store(conn, &arnaviSession{imei: headOne.IdImei})
+	return clientID, nil
 }
 
 // handleConnection содержит логику, специфичную для Arnavi, после авторизации
 func (h *ArnaviHandler) handleConnection(ctx context.Context, conn net.Conn, clientID string) {
+	value, ok := h.sessions.Load(conn)
+	if !ok {
+		logger.Errorf("Arnavi session for client ID %s not found", clientID)
+		return
+	}
+	sess := value.(*arnaviSession)
+	defer func() {
+		h.sessions.Delete(conn)
+		sess.commands.Fail("connection closed")
+	}()
+
 	logger.Infof("Starting Arnavi data processing for client ID: %s", clientID)
+	protocol.NotifyAuthorized(h.publisher, clientID)
+
+	// Блокирующее чтение прерываем закрытием соединения при отмене контекста
+	connCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-connCtx.Done()
+		conn.Close()
+	}()
 
+	reader := bufio.NewReader(conn)
 	for {
-		select {
-		case <-ctx.Done():
+		err := h.processPackage(reader, conn, sess)
+		if err == nil {
+			continue
+		}
+		switch {
+		case ctx.Err() != nil:
 			logger.Infof("Arnavi processing for client ID %s cancelled", clientID)
-			if conn != nil {
-				conn.Close()
-			}
-			return
+		case errors.Is(err, io.EOF):
+			logger.Infof("Arnavi client ID %s closed connection", clientID)
 		default:
-			// ... логика парсинга пакета EGTS ...
-			navData := protocol.NavRecord{Client: 1}
-			// navData, err := ParseEgtsPacket(nil)
-			// var err error
-			// if err != nil {
-			// 	logger.Errorf("Failed to parse Arnavi packet for client ID %s: %v", clientID, err)
-			// 	continue
-			// }
-
-			if h.publisher.IsConnected() {
-				if err := h.publisher.Publish(&navData); err != nil {
-					logger.Errorf("Failed to publish Arnavi data for client ID %s: %v", clientID, err)
-					// conn.Close()
-				} else {
-					logger.Debugf("Arnavi data for client ID %s published", clientID)
-				}
-			} else {
-				if conn != nil {
-					conn.Close()
-				}
-
-				logger.Warnf("NATS is not connected, Arnavi data for client ID %s not published", clientID)
+			logger.Errorf("Arnavi client ID %s: %v", clientID, err)
+		}
+		return
+	}
+}
+
+// processPackage читает один PACKAGE (0x5B id PACKET... 0x5D), публикует данные
+// и отправляет подтверждение. Если хотя бы одна запись не опубликована,
+// подтверждение не отправляется, и трекер повторит передачу.
+// Ответ на команду сервера (0x5B id code 0x5D) передается ожидающей команде.
+func (h *ArnaviHandler) processPackage(reader *bufio.Reader, conn net.Conn, sess *arnaviSession) error {
+	imei := sess.imei
+	stats := protocol.StatsOf(conn)
+
+	head := make([]byte, SizeScan)
+	if _, err := io.ReadFull(reader, head); err != nil {
+		return err
+	}
+	scan := ScanPaked{}
+	if err := scan.Decode(head); err != nil {
+		stats.DecodeError()
+		return fmt.Errorf("failed to decode package: %w", err)
+	}
+
+	// Ответ на команду отличаем от PACKAGE по номеру ожидающей команды и длине
+	if sess.commands.Has(uint32(scan.Id)) {
+		if tail, err := reader.Peek(2); err == nil && tail[1] == SigPackEnd {
+			raw := append(head, tail...)
+			reader.Discard(2)
+			answer := AnswerCom{}
+			if err := answer.Decode(raw); err != nil {
+				stats.DecodeError()
+				return fmt.Errorf("failed to decode command answer: %w", err)
 			}
-			time.Sleep(5 * time.Second)
+			stats.PacketDecoded()
+			sess.commands.Resolve(uint32(answer.IdPacked), commandReply(answer.CodeError))
+			logger.Debugf("Arnavi client %d: answer to command %d, code %d", imei, answer.IdPacked, answer.CodeError)
+			return nil
+		}
+	}
+
+	receivedAt := time.Now().UTC()
+	published := true
+
+	for {
+		sign, err := reader.Peek(1)
+		if err != nil {
+			return err
+		}
+		if sign[0] == SigPackEnd {
+			reader.Discard(1)
+			break
+		}
+
+		header := make([]byte, 3)
+		if _, err := io.ReadFull(reader, header); err != nil {
+			return err
+		}
+		scp := ScanPacket{}
+		if err := scp.Decode(header); err != nil {
+			stats.DecodeError()
+			return fmt.Errorf("failed to decode packet header: %w", err)
 		}
+
+		// тип, длина, время, данные и контрольная сумма
+		raw := make([]byte, int(scp.LengthPacket)+8)
+		copy(raw, header)
+		if _, err := io.ReadFull(reader, raw[3:]); err != nil {
+			return err
+		}
+
+		packet := PacketS{}
+		if err := packet.Decode(raw); err != nil {
+			stats.DecodeError()
+			return fmt.Errorf("failed to decode packet: %w", err)
+		}
+
+		tags, ok := packet.Data.(*TagsData)
+		if !ok {
+			logger.Debugf("Arnavi client %d: skipped packet type %X", imei, packet.TypeContent)
+			continue
+		}
+
+		rec := tags.ToNavRecord(packet.TimePacket)
+		if imei <= math.MaxUint32 {
+			rec.Client = uint32(imei)
+		}
+		rec.Imei = strconv.FormatUint(imei, 10)
+		rec.PacketID = uint32(scan.Id)
+		rec.ReceivedTime = receivedAt
+
+		if err := h.publisher.Publish(rec); err != nil {
+			logger.Errorf("Failed to publish Arnavi data for client %d: %v", imei, err)
+			published = false
+		} else {
+			stats.RecordPublished(rec)
+			logger.Debugf("Arnavi data for client %d published", imei)
+		}
+	}
+	stats.PacketDecoded()
+
+	if !published {
+		return fmt.Errorf("package %d not confirmed: publish failed", scan.Id)
+	}
+
+	answer, err := AnswerPacked(int(scan.Id))
+	if err != nil {
+		return fmt.Errorf("failed to encode confirmation: %w", err)
+	}
+	if err := sess.write(conn, answer); err != nil {
+		return fmt.Errorf("failed to send confirmation: %w", err)
+	}
+	logger.Debugf("Arnavi client %d: package %d confirmed", imei, scan.Id)
+
+	return nil
+}
+
+// SendCommand реализует protocol.CommandSender: передает команду трекеру
+// и ждет ответа AnswerCom до отмены ctx
+func (h *ArnaviHandler) SendCommand(ctx context.Context, cmd *models.Command) (*models.CommandResult, error) {
+	data, err := NewCommandData(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", protocol.ErrCommandNotSupported, err)
+	}
+
+	conn, ok := h.Manager().FindClient(cmd.DeviceID)
+	if !ok {
+		return nil, protocol.ErrDeviceNotConnected
+	}
+	value, ok := h.sessions.Load(conn)
+	if !ok {
+		return nil, protocol.ErrDeviceNotConnected
+	}
+	sess := value.(*arnaviSession)
+
+	id, reply, err := sess.commands.Add(nextCommandID)
+	if err != nil {
+		return models.NewCommandResult(cmd, models.CommandFailed, 0, err.Error()), nil
+	}
+	packet, err := EncodeCommand(int(id), data)
+	if err == nil {
+		err = sess.write(conn, packet)
+	}
+	if err != nil {
+		sess.commands.Remove(id)
+		return models.NewCommandResult(cmd, models.CommandFailed, 0, err.Error()), nil
+	}
+	logger.Infof("Arnavi command %s (%s) sent to client %s as parcel %d", cmd.ID, cmd.Type, cmd.DeviceID, id)
+
+	res := sess.commands.Wait(ctx, id, reply)
+	return models.NewCommandResult(cmd, res.Status, res.Code, res.Message), nil
+}
+
+// commandReply преобразует код ответа трекера на команду в результат
+func commandReply(code byte) protocol.CommandReply {
+	if code == 0 {
+		return protocol.CommandReply{Status: models.CommandDelivered}
+	}
+	return protocol.CommandReply{
+		Status:  models.CommandFailed,
+		Code:    uint32(code),
+		Message: fmt.Sprintf("tracker rejected command with code %d", code),
 	}
 }
@@ -0,0 +1,14 @@
+package arnavi
+
+import (
+	"github.com/rackov/NavControlSystem/services/receiver/internal/protocol"
+)
+
+func init() {
+	protocol.Register(protocol.Descriptor{
+		Name:        "ARNAVI",
+		Description: "Arnavi, бинарный протокол трекеров Arnavi",
+		Options:     protocol.SessionOptions,
+		Factory:     protocol.SessionFactory(func() protocol.SessionHandler { return NewArnaviHandler() }),
+	})
+}
@@ -0,0 +1,159 @@
+package egts
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/rackov/NavControlSystem/pkg/egts"
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/pkg/models"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/protocol"
+)
+
+// Параметры порта с текстовыми командами терминала. ГОСТ не определяет коды команд
+// управления выходами и запроса местоположения, поэтому команды этих типов передаются
+// текстом в EGTS_RAW_DATA в формате производителя терминала. В тексте можно использовать
+// подстановки {output} (номер выхода) и {state} (1 - включить, 0 - выключить).
+const (
+	optCmdEngineBlock     = "cmd_engine_block"
+	optCmdEngineUnblock   = "cmd_engine_unblock"
+	optCmdSetOutput       = "cmd_set_output"
+	optCmdRequestPosition = "cmd_request_position"
+)
+
+// commandOptions - описания параметров порта с текстовыми командами
+var commandOptions = []protocol.OptionSchema{
+	{Name: optCmdEngineBlock, Description: "текст команды блокировки двигателя, например \"SETOUT {output},1\"", Optional: true},
+	{Name: optCmdEngineUnblock, Description: "текст команды разблокировки двигателя", Optional: true},
+	{Name: optCmdSetOutput, Description: "текст команды управления выходом с подстановками {output} и {state}", Optional: true},
+	{Name: optCmdRequestPosition, Description: "текст команды запроса местоположения", Optional: true},
+}
+
+// выход блокировки двигателя, если номер не задан в команде
+const defaultEngineOutput = 1
+
+// commandText возвращает данные EGTS_RAW_DATA для команды диспетчера
+func (h *EgtsHandler) commandText(cmd *models.Command) ([]byte, error) {
+	var (
+		option string
+		output = cmd.Output
+		state  = cmd.On
+	)
+	switch cmd.Type {
+	case models.CommandRaw:
+		return cmd.Payload, nil
+	case models.CommandEngineBlock:
+		option, state = optCmdEngineBlock, true
+	case models.CommandEngineUnblock:
+		option, state = optCmdEngineUnblock, false
+	case models.CommandSetOutput:
+		option = optCmdSetOutput
+	case models.CommandRequestPosition:
+		option = optCmdRequestPosition
+	default:
+		return nil, fmt.Errorf("%w: unknown command type %q", protocol.ErrCommandNotSupported, cmd.Type)
+	}
+
+	text := h.commands[option]
+	if text == "" {
+		return nil, fmt.Errorf("%w: port option %s is not set", protocol.ErrCommandNotSupported, option)
+	}
+	if output == 0 {
+		output = defaultEngineOutput
+	}
+	stateValue := "0"
+	if state {
+		stateValue = "1"
+	}
+	return []byte(strings.NewReplacer(
+		"{output}", strconv.Itoa(int(output)),
+		"{state}", stateValue,
+	).Replace(text)), nil
+}
+
+// findSession ищет сессию терминала по ID (TID) или IMEI
+func (h *EgtsHandler) findSession(deviceID string) (net.Conn, *egtsSession, bool) {
+	if conn, ok := h.Manager().FindClient(deviceID); ok {
+		if value, ok := h.sessions.Load(conn); ok {
+			return conn, value.(*egtsSession), true
+		}
+	}
+
+	var (
+		foundConn net.Conn
+		foundSess *egtsSession
+	)
+	h.sessions.Range(func(key, value any) bool {
+		if sess := value.(*egtsSession); sess.imei != "" && sess.imei == deviceID {
+			foundConn, foundSess = key.(net.Conn), sess
+			return false
+		}
+		return true
+	})
+	return foundConn, foundSess, foundSess != nil
+}
+
+// SendCommand реализует protocol.CommandSender: передает команду CT_COM сервиса
+// EGTS_COMMANDS_SERVICE и ждет от терминала CT_COMCONF с тем же CID до отмены ctx
+func (h *EgtsHandler) SendCommand(ctx context.Context, cmd *models.Command) (*models.CommandResult, error) {
+	data, err := h.commandText(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, sess, ok := h.findSession(cmd.DeviceID)
+	if !ok {
+		return nil, protocol.ErrDeviceNotConnected
+	}
+
+	cid, reply, err := sess.commands.Add(nextCommandID)
+	if err != nil {
+		return models.NewCommandResult(cmd, models.CommandFailed, 0, err.Error()), nil
+	}
+	err = sess.write(conn, 1, func(pid, rn uint16) ([]byte, error) {
+		return egts.EncodeCommand(pid, rn, cid, egts.EGTS_RAW_DATA, egts.CA_PARAMS, data)
+	})
+	if err != nil {
+		sess.commands.Remove(cid)
+		return models.NewCommandResult(cmd, models.CommandFailed, 0, err.Error()), nil
+	}
+	logger.Infof("EGTS command %s (%s) sent to client %d with CID %d", cmd.ID, cmd.Type, sess.tid, cid)
+
+	res := sess.commands.Wait(ctx, cid, reply)
+	return models.NewCommandResult(cmd, res.Status, res.Code, res.Message), nil
+}
+
+// resolveCommand передает ожидающей команде подтверждение терминала.
+// CC_INPROG - промежуточный ответ, результат выполнения придет следующим подтверждением.
+func (h *EgtsHandler) resolveCommand(sess *egtsSession, confirm *egts.SrCommandData) {
+	reply := protocol.CommandReply{Code: uint32(confirm.CommandConfirmType)}
+	switch confirm.CommandConfirmType {
+	case egts.CC_INPROG:
+		logger.Debugf("EGTS client %d: command %d in progress", sess.tid, confirm.CommandID)
+		return
+	case egts.CC_OK, egts.CC_NCONF:
+		reply.Status = models.CommandDelivered
+	default:
+		reply.Status = models.CommandFailed
+		reply.Message = fmt.Sprintf("terminal rejected command with code %d", confirm.CommandConfirmType)
+	}
+	if len(confirm.Data) > 0 {
+		reply.Message = string(confirm.Data)
+	}
+
+	if !sess.commands.Resolve(confirm.CommandID, reply) {
+		logger.Debugf("EGTS client %d: confirmation for unknown command %d", sess.tid, confirm.CommandID)
+	}
+}
+
+// nextCommandID возвращает CID следующей команды, 0 не используется
+func nextCommandID(prev uint32) uint32 {
+	if prev == math.MaxUint32 {
+		return 1
+	}
+	return prev + 1
+}
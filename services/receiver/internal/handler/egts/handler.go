@@ -0,0 +1,366 @@
+package egts
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/egts"
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/connectionmanager"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/protocol"
+)
+
+// defaultAuthTimeout - время ожидания первого пакета (авторизации) от терминала по умолчанию
+const defaultAuthTimeout = 30 * time.Second
+
+type EgtsHandler struct {
+	connectionmanager.Base
+
+	publisher protocol.DataPublisher // Храним publisher для доступа в handleConnection
+	commands  map[string]string      // текстовые команды по типам (параметры порта cmd_*)
+
+	// Состояние сессий подключенных терминалов, ключ - net.Conn
+	sessions sync.Map
+}
+
+// egtsSession хранит состояние одного подключения терминала
+type egtsSession struct {
+	tid  uint32 // идентификатор терминала (TID или OID)
+	imei string
+	imsi string
+	pid  uint16 // счетчик пакетов транспортного уровня ТП
+	rn   uint16 // счетчик записей уровня поддержки услуг ТП
+
+	// пакеты с данными, принятые при авторизации терминала без EGTS_SR_TERM_IDENTITY
+	pending []*egts.Package
+
+	// подтверждения и команды пишутся в подключение из разных горутин,
+	// writeMu защищает счетчики pid и rn и запись в подключение
+	writeMu  sync.Mutex
+	commands protocol.PendingCommands
+}
+
+// write формирует пакет с очередными номерами пакета и records записей и отправляет его
+func (s *egtsSession) write(conn net.Conn, records int, encode func(pid, rn uint16) ([]byte, error)) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	packet, err := encode(s.nextPID(), s.nextRN(records))
+	if err != nil {
+		return fmt.Errorf("failed to encode packet: %w", err)
+	}
+	_, err = conn.Write(packet)
+	return err
+}
+
+func (s *egtsSession) nextPID() uint16 {
+	pid := s.pid
+	s.pid++
+	return pid
+}
+
+// nextRN резервирует count номеров записей и возвращает первый из них
+func (s *egtsSession) nextRN(count int) uint16 {
+	rn := s.rn
+	s.rn += uint16(count)
+	return rn
+}
+
+func NewEgtsHandler() *EgtsHandler {
+	h := &EgtsHandler{
+		commands: make(map[string]string),
+	}
+	h.Base = connectionmanager.NewBase(h, defaultAuthTimeout)
+	return h
+}
+
+// Start запускает обработчик, делегируя управление соединениями ConnectionManager
+func (h *EgtsHandler) Start(ctx context.Context, publisher protocol.DataPublisher, port int) error {
+	h.publisher = publisher
+	return h.Manager().Start(ctx, port, h.handleConnection)
+}
+
+// GetName возвращает имя протокола
+func (h *EgtsHandler) GetName() string {
+	return "EGTS"
+}
+
+// Stop останавливает ConnectionManager
+func (h *EgtsHandler) Stop() error {
+	logger.Info("Stopping EGTS handler...")
+
+	return h.Manager().Stop()
+}
+
+// GetClientID реализует интерфейс ClientData для авторизации.
+// Читает первый пакет, ищет в нем EGTS_SR_TERM_IDENTITY, подтверждает его
+// и сообщает терминалу результат авторизации (EGTS_SR_RESULT_CODE).
+func (h *EgtsHandler) GetClientID(conn net.Conn) (string, error) {
+	// Устанавливаем таймаут на чтение, чтобы не зависнуть, если клиент ничего не присылает
+	conn.SetReadDeadline(time.Now().Add(h.AuthTimeout()))
+	defer conn.SetReadDeadline(time.Time{})
+
+	sess := &egtsSession{}
+
+	raw, err := egts.ReadPackage(conn)
+	if err != nil {
+		return "", fmt.Errorf("failed to read auth packet: %w", err)
+	}
+
+	stats := protocol.StatsOf(conn)
+	pkg := egts.Package{}
+	if code, err := pkg.Decode(raw); err != nil {
+		stats.DecodeError()
+		h.writeResponse(conn, sess, pkg.PacketIdentifier, code, nil)
+		return "", fmt.Errorf("failed to decode auth packet: %w", err)
+	}
+	stats.PacketDecoded()
+	stats.SetProtocolVersion(strconv.Itoa(int(pkg.ProtocolVersion)))
+
+	sdrs, ok := pkg.ServicesFrameData.(*egts.ServiceDataSet)
+	if pkg.PacketType != egts.EGTS_PT_APPDATA || !ok {
+		return "", fmt.Errorf("unexpected packet type %d before authorization", pkg.PacketType)
+	}
+
+	if ident := egts.FindTermIdentity(sdrs); ident != nil {
+		sess.applyIdentity(ident)
+		if err := h.processPackage(conn, sess, &pkg); err != nil {
+			return "", err
+		}
+	} else {
+		// Часть терминалов не проходит авторизацию и сразу передает данные с OID
+		for _, sdr := range *sdrs {
+			if sdr.ObjectIDFieldExists == "1" && sdr.ObjectIdentifier != 0 {
+				sess.tid = sdr.ObjectIdentifier
+				break
+			}
+		}
+		if sess.tid == 0 {
+			h.writeResponse(conn, sess, pkg.PacketIdentifier, egts.EGTS_PC_AUTH_DENIED, nil)
+			return "", fmt.Errorf("first packet has neither EGTS_SR_TERM_IDENTITY nor OID")
+		}
+		if err := h.authorize(sess); err != nil {
+			h.writeResponse(conn, sess, pkg.PacketIdentifier, egts.EGTS_PC_OK, nil)
+			h.writeResultCode(conn, sess, egts.EGTS_PC_AUTH_DENIED)
+			return "", err
+		}
+		// Данные будут обработаны в handleConnection, когда появится publisher сессии
+		sess.pending = append(sess.pending, &pkg)
+	}
+
+	h.sessions.Store(conn, sess)
+	return strconv.FormatUint(uint64(sess.tid), 10), nil
+}
+
+// handleConnection содержит логику, специфичную для EGTS, после авторизации
+func (h *EgtsHandler) handleConnection(ctx context.Context, conn net.Conn, clientID string) {
+	value, ok := h.sessions.Load(conn)
+	if !ok {
+		logger.Errorf("EGTS session for client ID %s not found", clientID)
+		return
+	}
+	sess := value.(*egtsSession)
+	defer func() {
+		h.sessions.Delete(conn)
+		sess.commands.Fail("connection closed")
+	}()
+
+	logger.Infof("Starting EGTS data processing for client ID: %s", clientID)
+	protocol.NotifyAuthorized(h.publisher, clientID, sess.imei)
+
+	// Блокирующее чтение прерываем закрытием соединения при отмене контекста
+	connCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-connCtx.Done()
+		conn.Close()
+	}()
+
+	for _, pkg := range sess.pending {
+		if err := h.processPackage(conn, sess, pkg); err != nil {
+			logger.Errorf("EGTS client ID %s: %v", clientID, err)
+			return
+		}
+	}
+	sess.pending = nil
+
+	stats := protocol.StatsOf(conn)
+	reader := bufio.NewReader(conn)
+	for {
+		raw, err := egts.ReadPackage(reader)
+		if err != nil {
+			switch {
+			case ctx.Err() != nil:
+				logger.Infof("EGTS processing for client ID %s cancelled", clientID)
+			case errors.Is(err, io.EOF):
+				logger.Infof("EGTS client ID %s closed connection", clientID)
+			default:
+				logger.Errorf("Failed to read EGTS packet from client ID %s: %v", clientID, err)
+			}
+			return
+		}
+
+		pkg := egts.Package{}
+		code, err := pkg.Decode(raw)
+		if err != nil {
+			stats.DecodeError()
+			logger.Warnf("Failed to decode EGTS packet from client ID %s (code %d): %v", clientID, code, err)
+			if err := h.writeResponse(conn, sess, pkg.PacketIdentifier, code, nil); err != nil {
+				logger.Errorf("EGTS client ID %s: %v", clientID, err)
+				return
+			}
+			continue
+		}
+		stats.PacketDecoded()
+
+		if err := h.processPackage(conn, sess, &pkg); err != nil {
+			logger.Errorf("EGTS client ID %s: %v", clientID, err)
+			return
+		}
+	}
+}
+
+// processPackage обрабатывает разобранный пакет: публикует навигационные данные,
+// подтверждает каждую запись и при необходимости отправляет результат авторизации
+func (h *EgtsHandler) processPackage(conn net.Conn, sess *egtsSession, pkg *egts.Package) error {
+	if pkg.PacketType == egts.EGTS_PT_RESPONSE {
+		// Подтверждение на наш пакет (например, на EGTS_SR_RESULT_CODE)
+		logger.Debugf("EGTS client %d confirmed packet", sess.tid)
+		return nil
+	}
+
+	sdrs, ok := pkg.ServicesFrameData.(*egts.ServiceDataSet)
+	if pkg.PacketType != egts.EGTS_PT_APPDATA || !ok {
+		return h.writeResponse(conn, sess, pkg.PacketIdentifier, egts.EGTS_PC_UNS_TYPE, nil)
+	}
+
+	statuses := make([]egts.RecordStatus, 0, len(*sdrs))
+	authorized := false
+	var denied error // терминал не допущен реестром устройств
+	receivedAt := time.Now().UTC()
+
+	for i := range *sdrs {
+		sdr := &(*sdrs)[i]
+		status := egts.RecordStatus{
+			RecordNumber: sdr.RecordNumber,
+			Service:      sdr.SourceServiceType,
+			Status:       egts.EGTS_PC_OK,
+		}
+		if denied != nil {
+			// Данные недопущенного терминала не публикуем
+			status.Status = egts.EGTS_PC_AUTH_DENIED
+			statuses = append(statuses, status)
+			continue
+		}
+
+		switch sdr.SourceServiceType {
+		case egts.SERVICE_AUTH:
+			if ident := egts.FindTermIdentity(&egts.ServiceDataSet{*sdr}); ident != nil {
+				if sess.tid != 0 && ident.TerminalIdentifier != sess.tid {
+					// ConnectionManager учитывает сессию под прежним TID (поиск, команды,
+					// повторные подключения), поэтому сменить его в той же сессии нельзя
+					denied = fmt.Errorf("terminal %d tried to re-authorize as %d", sess.tid, ident.TerminalIdentifier)
+					status.Status = egts.EGTS_PC_AUTH_DENIED
+					break
+				}
+				sess.applyIdentity(ident)
+				if denied = h.authorize(sess); denied != nil {
+					status.Status = egts.EGTS_PC_AUTH_DENIED
+					break
+				}
+				authorized = true
+			}
+		case egts.SERVICE_DATA:
+			rec, found := sdr.ToNavRecord()
+			if !found {
+				break
+			}
+			if rec.Client == 0 {
+				rec.Client = sess.tid
+			}
+			rec.PacketID = uint32(pkg.PacketIdentifier)
+			rec.ReceivedTime = receivedAt
+			rec.Imei = sess.imei
+			rec.Imsi = sess.imsi
+
+			if err := h.publisher.Publish(rec); err != nil {
+				// Не подтверждаем запись, терминал оставит ее в "черном ящике"
+				logger.Errorf("Failed to publish EGTS data for client %d: %v", sess.tid, err)
+				status.Status = egts.EGTS_PC_IO_ERROR
+			} else {
+				protocol.StatsOf(conn).RecordPublished(rec)
+				logger.Debugf("EGTS data for client %d published", sess.tid)
+			}
+		case egts.SERVICE_COMMANDS:
+			for _, confirm := range egts.FindCommandConfirms(&egts.ServiceDataSet{*sdr}) {
+				h.resolveCommand(sess, confirm)
+			}
+		default:
+			status.Status = egts.EGTS_PC_SRVC_NFOUND
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	if err := h.writeResponse(conn, sess, pkg.PacketIdentifier, egts.EGTS_PC_OK, statuses); err != nil {
+		return err
+	}
+
+	if denied != nil {
+		h.writeResultCode(conn, sess, egts.EGTS_PC_AUTH_DENIED)
+		return denied
+	}
+	if authorized {
+		if err := h.writeResultCode(conn, sess, egts.EGTS_PC_OK); err != nil {
+			return err
+		}
+		logger.Debugf("EGTS client %d authorized, result code sent", sess.tid)
+	}
+
+	return nil
+}
+
+// authorize проверяет терминал по реестру устройств (по TID и IMEI)
+func (h *EgtsHandler) authorize(sess *egtsSession) error {
+	return protocol.AuthorizeDevice(h.publisher, strconv.FormatUint(uint64(sess.tid), 10), sess.imei)
+}
+
+// writeResultCode сообщает терминалу результат авторизации (EGTS_SR_RESULT_CODE)
+func (h *EgtsHandler) writeResultCode(conn net.Conn, sess *egtsSession, code uint8) error {
+	err := sess.write(conn, 1, func(pid, rn uint16) ([]byte, error) {
+		return egts.EncodeResultCode(pid, rn, code)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send result code: %w", err)
+	}
+	return nil
+}
+
+// writeResponse отправляет EGTS_PT_RESPONSE на пакет rpid
+func (h *EgtsHandler) writeResponse(conn net.Conn, sess *egtsSession, rpid uint16, result uint8, statuses []egts.RecordStatus) error {
+	err := sess.write(conn, len(statuses), func(pid, rn uint16) ([]byte, error) {
+		return egts.EncodePtResponse(pid, rpid, result, rn, statuses)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send response: %w", err)
+	}
+	return nil
+}
+
+// applyIdentity сохраняет учетные данные терминала из EGTS_SR_TERM_IDENTITY
+func (s *egtsSession) applyIdentity(ident *egts.SrTermIdentity) {
+	s.tid = ident.TerminalIdentifier
+	if ident.IMEIE == "1" {
+		s.imei = ident.IMEI
+	}
+	if ident.IMSIE == "1" {
+		s.imsi = ident.IMSI
+	}
+}
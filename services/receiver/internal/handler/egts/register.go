@@ -0,0 +1,32 @@
+package egts
+
+import (
+	"github.com/rackov/NavControlSystem/services/receiver/internal/protocol"
+)
+
+func init() {
+	protocol.Register(protocol.Descriptor{
+		Name:        "EGTS",
+		Description: "ЕГТС, ГОСТ 33472-2015",
+		Options:     append(append([]protocol.OptionSchema{}, protocol.SessionOptions...), commandOptions...),
+		UDP:         true,
+		Factory:     newEgtsHandlerFromConfig,
+	})
+}
+
+// newEgtsHandlerFromConfig создает обработчик по параметрам порта из реестра протоколов
+func newEgtsHandlerFromConfig(cfg protocol.HandlerConfig) (protocol.ProtocolHandler, error) {
+	h := NewEgtsHandler()
+
+	if err := cfg.ApplySession(h); err != nil {
+		return nil, err
+	}
+
+	for _, opt := range commandOptions {
+		if text := cfg.Options[opt.Name]; text != "" {
+			h.commands[opt.Name] = text
+		}
+	}
+
+	return h, nil
+}
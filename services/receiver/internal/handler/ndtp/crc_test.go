@@ -0,0 +1,11 @@
+package ndtp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrc16(t *testing.T) {
+	assert.Equal(t, uint16(0x4B37), crc16([]byte("123456789")))
+}
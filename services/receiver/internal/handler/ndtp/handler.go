@@ -0,0 +1,231 @@
+package ndtp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/connectionmanager"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/protocol"
+)
+
+// defaultAuthTimeout - время ожидания NPH_SGC_CONN_REQUEST от устройства по умолчанию
+const defaultAuthTimeout = 30 * time.Second
+
+type NdtpHandler struct {
+	connectionmanager.Base
+
+	publisher protocol.DataPublisher // Храним publisher для доступа в handleConnection
+
+	// Состояние сессий между GetClientID и handleConnection, ключ - net.Conn
+	sessions sync.Map
+}
+
+// ndtpSession хранит состояние одного подключения устройства
+type ndtpSession struct {
+	peerAddress uint32 // адрес устройства из NPH_SGC_CONN_REQUEST
+	nplID       uint16 // счетчик исходящих пакетов NPL
+
+	stats *protocol.ConnStats
+}
+
+func (s *ndtpSession) nextID() uint16 {
+	id := s.nplID
+	s.nplID++
+	return id
+}
+
+func NewNdtpHandler() *NdtpHandler {
+	h := &NdtpHandler{}
+	h.Base = connectionmanager.NewBase(h, defaultAuthTimeout)
+	return h
+}
+
+// Start запускает обработчик, делегируя управление соединениями ConnectionManager
+func (h *NdtpHandler) Start(ctx context.Context, publisher protocol.DataPublisher, port int) error {
+	h.publisher = publisher
+	return h.Manager().Start(ctx, port, h.handleConnection)
+}
+
+// GetName возвращает имя протокола
+func (h *NdtpHandler) GetName() string {
+	return "NDTP"
+}
+
+// Stop останавливает ConnectionManager
+func (h *NdtpHandler) Stop() error {
+	logger.Info("Stopping NDTP handler...")
+
+	return h.Manager().Stop()
+}
+
+// GetClientID реализует интерфейс ClientData для авторизации.
+// Первым пакетом устройство обязано прислать NPH_SGC_CONN_REQUEST с адресом устройства.
+func (h *NdtpHandler) GetClientID(conn net.Conn) (string, error) {
+	// Устанавливаем таймаут на чтение, чтобы не зависнуть, если клиент ничего не присылает
+	conn.SetReadDeadline(time.Now().Add(h.AuthTimeout()))
+	defer conn.SetReadDeadline(time.Time{})
+
+	sess := &ndtpSession{stats: protocol.StatsOf(conn)}
+
+	raw, err := ReadPackage(conn)
+	if err != nil {
+		return "", fmt.Errorf("failed to read connection request: %w", err)
+	}
+
+	pkg := Package{}
+	if code, err := pkg.Decode(raw); err != nil {
+		sess.stats.DecodeError()
+		h.writeResult(conn, sess, &pkg.Nph, code)
+		return "", fmt.Errorf("failed to decode connection request: %w", err)
+	}
+
+	if pkg.Nph.ServiceID != NPH_SRV_GENERIC_CONTROLS || pkg.Nph.PacketType != NPH_SGC_CONN_REQUEST {
+		h.writeResult(conn, sess, &pkg.Nph, NPH_RESULT_PACKET_UNEXPECTED)
+		return "", fmt.Errorf("unexpected packet service %d type %d before authorization",
+			pkg.Nph.ServiceID, pkg.Nph.PacketType)
+	}
+
+	req := ConnRequest{}
+	if err := req.Decode(pkg.Data); err != nil {
+		sess.stats.DecodeError()
+		h.writeResult(conn, sess, &pkg.Nph, NPH_RESULT_PACKET_INVALID_SIZE)
+		return "", fmt.Errorf("failed to decode connection request: %w", err)
+	}
+	sess.peerAddress = req.PeerAddress
+	sess.stats.PacketDecoded()
+	sess.stats.SetProtocolVersion(fmt.Sprintf("%d.%d", req.VersionHigh, req.VersionLow))
+
+	clientID := strconv.FormatUint(uint64(sess.peerAddress), 10)
+	if err := protocol.AuthorizeDevice(h.publisher, clientID); err != nil {
+		h.writeResult(conn, sess, &pkg.Nph, NPH_RESULT_CLIENT_NOT_REGISTERED)
+		return "", err
+	}
+
+	if err := h.writeResult(conn, sess, &pkg.Nph, NPH_RESULT_OK); err != nil {
+		return "", err
+	}
+
+	h.sessions.Store(conn, sess)
+	return clientID, nil
+}
+
+// handleConnection содержит логику, специфичную для NDTP, после авторизации
+func (h *NdtpHandler) handleConnection(ctx context.Context, conn net.Conn, clientID string) {
+	value, ok := h.sessions.LoadAndDelete(conn)
+	if !ok {
+		logger.Errorf("NDTP session for client ID %s not found", clientID)
+		return
+	}
+	sess := value.(*ndtpSession)
+
+	logger.Infof("Starting NDTP data processing for client ID: %s", clientID)
+
+	// Блокирующее чтение прерываем закрытием соединения при отмене контекста
+	connCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-connCtx.Done()
+		conn.Close()
+	}()
+
+	reader := bufio.NewReader(conn)
+	for {
+		raw, err := ReadPackage(reader)
+		if err != nil {
+			switch {
+			case ctx.Err() != nil:
+				logger.Infof("NDTP processing for client ID %s cancelled", clientID)
+			case errors.Is(err, io.EOF):
+				logger.Infof("NDTP client ID %s closed connection", clientID)
+			default:
+				logger.Errorf("Failed to read NDTP packet from client ID %s: %v", clientID, err)
+			}
+			return
+		}
+
+		pkg := Package{}
+		if code, err := pkg.Decode(raw); err != nil {
+			sess.stats.DecodeError()
+			logger.Warnf("Failed to decode NDTP packet from client ID %s (code %d): %v", clientID, code, err)
+			if err := h.writeResult(conn, sess, &pkg.Nph, code); err != nil {
+				logger.Errorf("NDTP client ID %s: %v", clientID, err)
+				return
+			}
+			continue
+		}
+
+		sess.stats.PacketDecoded()
+		code := h.processPackage(sess, &pkg)
+		if !pkg.Nph.IsRequest() {
+			continue
+		}
+		if err := h.writeResult(conn, sess, &pkg.Nph, code); err != nil {
+			logger.Errorf("NDTP client ID %s: %v", clientID, err)
+			return
+		}
+	}
+}
+
+// processPackage обрабатывает пакет NPH и возвращает код результата для NPH_RESULT
+func (h *NdtpHandler) processPackage(sess *ndtpSession, pkg *Package) uint32 {
+	switch pkg.Nph.ServiceID {
+	case NPH_SRV_GENERIC_CONTROLS:
+		// Результаты на наши пакеты и служебные запросы просто подтверждаем
+		return NPH_RESULT_OK
+	case NPH_SRV_NAVDATA:
+	default:
+		return NPH_RESULT_SERVICE_NOT_SUPPORTED
+	}
+
+	switch pkg.Nph.PacketType {
+	case NPH_RESULT:
+		return NPH_RESULT_OK
+	case NPH_SND_HISTORY, NPH_SND_REALTIME:
+	default:
+		return NPH_RESULT_PACKET_NOT_SUPPORTED
+	}
+
+	cells, err := DecodeCells(pkg.Data)
+	if err != nil {
+		sess.stats.DecodeError()
+		logger.Warnf("Failed to decode NDTP cells for client %d: %v", sess.peerAddress, err)
+		return NPH_RESULT_PACKET_INVALID_FORMAT
+	}
+
+	receivedAt := time.Now().UTC()
+	for _, rec := range ToNavRecords(cells) {
+		rec.Client = sess.peerAddress
+		rec.PacketID = pkg.Nph.RequestID
+		rec.ReceivedTime = receivedAt
+
+		if err := h.publisher.Publish(rec); err != nil {
+			// Устройство повторит передачу, получив NPH_RESULT_BUSY
+			logger.Errorf("Failed to publish NDTP data for client %d: %v", sess.peerAddress, err)
+			return NPH_RESULT_BUSY
+		}
+		sess.stats.RecordPublished(rec)
+		logger.Debugf("NDTP data for client %d published", sess.peerAddress)
+	}
+
+	return NPH_RESULT_OK
+}
+
+// writeResult отправляет NPH_RESULT на запрос req
+func (h *NdtpHandler) writeResult(conn net.Conn, sess *ndtpSession, req *NphHeader, code uint32) error {
+	answer, err := EncodeResult(sess.nextID(), req, code)
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+	if _, err := conn.Write(answer); err != nil {
+		return fmt.Errorf("failed to send result: %w", err)
+	}
+	return nil
+}
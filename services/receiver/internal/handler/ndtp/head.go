@@ -0,0 +1,67 @@
+package ndtp
+
+// Уровень NPL (Network Protocol Level)
+const (
+	NPL_SIGNATURE  = 0x7E7E // сигнатура начала пакета
+	NPL_HEADER_LEN = 15     // длина заголовка NPL
+
+	NPL_TYPE_NPH = 2 // пакет содержит данные уровня NPH
+
+	NPL_FLAG_ENCRYPTION = 0x01 // данные зашифрованы
+	NPL_FLAG_CRC        = 0x02 // в заголовке передается контрольная сумма данных
+
+	NPL_MAX_DATA_SIZE = 0xFFFF - NPL_HEADER_LEN // максимальная длина данных NPL
+)
+
+// Уровень NPH (Navigation Protocol Header)
+const (
+	NPH_HEADER_LEN = 10 // длина заголовка NPH
+
+	NPH_FLAG_REQUEST = 0x01 // пакет требует ответа NPH_RESULT
+)
+
+/* Сервисы NPH */
+const (
+	NPH_SRV_GENERIC_CONTROLS = 0 // сервис управления соединением
+	NPH_SRV_NAVDATA          = 1 // сервис навигационных данных
+	NPH_SRV_FILE_TRANSFER    = 3 // сервис передачи файлов
+	NPH_SRV_CLIENT_DATA      = 4 // сервис передачи данных клиента
+	NPH_SRV_EXTERNAL_DEVICE  = 5 // сервис внешних устройств
+)
+
+/* Типы пакетов, общие для всех сервисов */
+const (
+	NPH_RESULT = 0 // результат обработки запроса
+)
+
+/* Типы пакетов сервиса NPH_SRV_GENERIC_CONTROLS */
+const (
+	NPH_SGC_CONN_REQUEST = 100 // запрос на подключение (авторизация)
+	NPH_SGC_CONN_AUTH    = 101 // авторизация по паролю
+)
+
+/* Типы пакетов сервиса NPH_SRV_NAVDATA */
+const (
+	NPH_SND_HISTORY  = 100 // данные из "черного ящика"
+	NPH_SND_REALTIME = 101 // данные реального времени
+)
+
+/* Типы ячеек навигационных данных */
+const (
+	NPH_CELL_NAVDATA = 0 // основные навигационные данные
+	NPH_CELL_SENSORS = 2 // состояние дискретных и аналоговых входов
+)
+
+/* Коды результата NPH_RESULT */
+const (
+	NPH_RESULT_OK                      = 0   // Успешно
+	NPH_RESULT_BUSY                    = 1   // Сервер занят, повторить позже
+	NPH_RESULT_SERVICE_NOT_SUPPORTED   = 100 // Сервис не поддерживается
+	NPH_RESULT_SERVICE_NOT_AVAILABLE   = 101 // Сервис недоступен
+	NPH_RESULT_PACKET_NOT_SUPPORTED    = 200 // Тип пакета не поддерживается
+	NPH_RESULT_PACKET_INVALID_SIZE     = 201 // Неверный размер пакета
+	NPH_RESULT_PACKET_INVALID_FORMAT   = 202 // Неверный формат пакета
+	NPH_RESULT_PACKET_UNEXPECTED       = 203 // Пакет не ожидался
+	NPH_RESULT_PROTO_VER_NOT_SUPPORTED = 300 // Версия протокола не поддерживается
+	NPH_RESULT_CLIENT_NOT_REGISTERED   = 301 // Клиент не зарегистрирован
+)
@@ -0,0 +1,44 @@
+package ndtp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// ConnRequest данные пакета NPH_SGC_CONN_REQUEST, которым устройство
+// начинает сессию и сообщает свой адрес (идентификатор)
+type ConnRequest struct {
+	VersionHigh   uint16 `json:"proto_version_high"` // старшая часть версии протокола
+	VersionLow    uint16 `json:"proto_version_low"`  // младшая часть версии протокола
+	ConnFlags     byte   `json:"connection_flags"`   // флаги соединения
+	PeerAddress   uint32 `json:"peer_address"`       // адрес (идентификатор) устройства
+	MaxPacketSize uint16 `json:"max_packet_size"`    // максимальный размер пакета, который принимает устройство
+}
+
+const connRequestLen = 11
+
+// Decode разбирает байты в структуру запроса подключения
+func (c *ConnRequest) Decode(content []byte) error {
+	if len(content) < connRequestLen {
+		return fmt.Errorf("длина запроса подключения %d меньше %d", len(content), connRequestLen)
+	}
+	if err := binary.Read(bytes.NewReader(content), binary.LittleEndian, c); err != nil {
+		return fmt.Errorf("не удалось прочитать запрос подключения: %v", err)
+	}
+	return nil
+}
+
+// Encode кодирует запрос подключения в байты
+func (c *ConnRequest) Encode() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := binary.Write(buf, binary.LittleEndian, c); err != nil {
+		return nil, fmt.Errorf("не удалось записать запрос подключения: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Length получает длину закодированного запроса
+func (c *ConnRequest) Length() uint16 {
+	return connRequestLen
+}
@@ -0,0 +1,7 @@
+package ndtp
+
+type BinaryData interface {
+	Decode([]byte) error
+	Encode() ([]byte, error)
+	Length() uint16
+}
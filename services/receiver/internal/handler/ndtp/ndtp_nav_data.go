@@ -0,0 +1,129 @@
+package ndtp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// флаги ячейки навигационных данных
+const (
+	NavFlagValid  = 0x80 // координаты валидны
+	NavFlagSouth  = 0x40 // южная широта
+	NavFlagWest   = 0x20 // западная долгота
+	NavFlagMoving = 0x10 // признак движения
+)
+
+const (
+	navDataLen = 28
+	sensorsLen = 12
+)
+
+// NavData ячейка NPH_CELL_NAVDATA с основными навигационными данными
+type NavData struct {
+	CellType   byte   `json:"type"`
+	Number     byte   `json:"number"`   // номер ячейки
+	Time       uint32 `json:"time"`     // время навигации (unix time)
+	Longitude  uint32 `json:"lon"`      // долгота по модулю, градусы*10^7
+	Latitude   uint32 `json:"lat"`      // широта по модулю, градусы*10^7
+	Flags      byte   `json:"flags"`    // NavFlag*
+	Satellites byte   `json:"nsat"`     // количество спутников
+	Speed      uint16 `json:"speed"`    // скорость, км/ч
+	Course     uint16 `json:"course"`   // направление движения, градусы
+	Altitude   int16  `json:"altitude"` // высота, м
+	Hdop       uint16 `json:"hdop"`     // HDOP*10
+	Odometer   uint32 `json:"odometer"` // пробег, 0,1 км
+}
+
+// Decode разбирает байты в структуру ячейки
+func (n *NavData) Decode(content []byte) error {
+	if len(content) < navDataLen {
+		return fmt.Errorf("длина ячейки навигационных данных %d меньше %d", len(content), navDataLen)
+	}
+	if err := binary.Read(bytes.NewReader(content), binary.LittleEndian, n); err != nil {
+		return fmt.Errorf("не удалось прочитать ячейку навигационных данных: %v", err)
+	}
+	if n.CellType != NPH_CELL_NAVDATA {
+		return fmt.Errorf("неверный тип ячейки навигационных данных: %d", n.CellType)
+	}
+	return nil
+}
+
+// Encode кодирует ячейку в байты
+func (n *NavData) Encode() ([]byte, error) {
+	n.CellType = NPH_CELL_NAVDATA
+	buf := new(bytes.Buffer)
+	if err := binary.Write(buf, binary.LittleEndian, n); err != nil {
+		return nil, fmt.Errorf("не удалось записать ячейку навигационных данных: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Length получает длину закодированной ячейки
+func (n *NavData) Length() uint16 {
+	return navDataLen
+}
+
+// Sensors ячейка NPH_CELL_SENSORS с состоянием входов и выходов
+type Sensors struct {
+	CellType      byte      `json:"type"`
+	Number        byte      `json:"number"` // номер ячейки
+	DigitalInputs byte      `json:"din"`    // состояние дискретных входов 1..8
+	DigitalOuts   byte      `json:"dout"`   // состояние дискретных выходов 1..8
+	AnalogInputs  [4]uint16 `json:"ain"`    // значения аналоговых входов, мВ
+}
+
+// Decode разбирает байты в структуру ячейки
+func (s *Sensors) Decode(content []byte) error {
+	if len(content) < sensorsLen {
+		return fmt.Errorf("длина ячейки датчиков %d меньше %d", len(content), sensorsLen)
+	}
+	if err := binary.Read(bytes.NewReader(content), binary.LittleEndian, s); err != nil {
+		return fmt.Errorf("не удалось прочитать ячейку датчиков: %v", err)
+	}
+	if s.CellType != NPH_CELL_SENSORS {
+		return fmt.Errorf("неверный тип ячейки датчиков: %d", s.CellType)
+	}
+	return nil
+}
+
+// Encode кодирует ячейку в байты
+func (s *Sensors) Encode() ([]byte, error) {
+	s.CellType = NPH_CELL_SENSORS
+	buf := new(bytes.Buffer)
+	if err := binary.Write(buf, binary.LittleEndian, s); err != nil {
+		return nil, fmt.Errorf("не удалось записать ячейку датчиков: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Length получает длину закодированной ячейки
+func (s *Sensors) Length() uint16 {
+	return sensorsLen
+}
+
+// DecodeCells разбирает данные пакетов NPH_SND_HISTORY/NPH_SND_REALTIME
+// в последовательность ячеек. Длина ячейки определяется ее типом.
+func DecodeCells(content []byte) ([]BinaryData, error) {
+	var cells []BinaryData
+
+	for len(content) > 0 {
+		var cell BinaryData
+		switch content[0] {
+		case NPH_CELL_NAVDATA:
+			cell = &NavData{}
+		case NPH_CELL_SENSORS:
+			cell = &Sensors{}
+		default:
+			return cells, fmt.Errorf("неизвестный тип ячейки: %d", content[0])
+		}
+
+		if err := cell.Decode(content); err != nil {
+			return cells, err
+		}
+		cells = append(cells, cell)
+		content = content[cell.Length():]
+	}
+
+	return cells, nil
+}
@@ -0,0 +1,79 @@
+package ndtp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testNavData = NavData{
+		CellType:   NPH_CELL_NAVDATA,
+		Number:     1,
+		Time:       1533570258,
+		Longitude:  372085530,
+		Latitude:   556363110,
+		Flags:      NavFlagValid | NavFlagMoving,
+		Satellites: 12,
+		Speed:      60,
+		Course:     270,
+		Altitude:   150,
+		Hdop:       8,
+		Odometer:   1234,
+	}
+	testSensors = Sensors{
+		CellType:      NPH_CELL_SENSORS,
+		Number:        2,
+		DigitalInputs: 0x05,
+		DigitalOuts:   0x01,
+		AnalogInputs:  [4]uint16{12000, 0, 3300, 0},
+	}
+)
+
+func TestDecodeCells(t *testing.T) {
+	nav, err := testNavData.Encode()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, navDataLen, len(nav))
+
+	sens, err := testSensors.Encode()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, sensorsLen, len(sens))
+
+	cells, err := DecodeCells(append(nav, sens...))
+	if assert.NoError(t, err) && assert.Len(t, cells, 2) {
+		assert.Equal(t, &testNavData, cells[0])
+		assert.Equal(t, &testSensors, cells[1])
+	}
+}
+
+func TestDecodeCells_Unknown(t *testing.T) {
+	_, err := DecodeCells([]byte{0x55, 0x00})
+	assert.Error(t, err)
+}
+
+func TestToNavRecords(t *testing.T) {
+	nav := testNavData
+	nav.Flags |= NavFlagSouth
+
+	records := ToNavRecords([]BinaryData{&nav, &testSensors})
+	if assert.Len(t, records, 1) {
+		rec := records[0]
+		assert.Equal(t, time.Unix(1533570258, 0).UTC(), rec.NavigationTime)
+		assert.InDelta(t, -55.636311, rec.Latitude, 1e-9)
+		assert.InDelta(t, 37.208553, rec.Longitude, 1e-9)
+		assert.Equal(t, uint16(270), rec.Course)
+		assert.True(t, rec.Valid)
+		assert.True(t, rec.Moving)
+		assert.Equal(t, 0.8, rec.Hdop)
+		assert.Equal(t, 123.4, rec.Odometer)
+		assert.Equal(t, uint32(0x05), rec.DigitalInputs)
+		assert.Equal(t, uint32(1), rec.DigitalOutputs)
+		assert.Len(t, rec.Sensors, 4)
+		assert.Equal(t, 12000.0, rec.Sensors["ain1"])
+	}
+}
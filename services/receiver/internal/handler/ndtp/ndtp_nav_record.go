@@ -0,0 +1,57 @@
+package ndtp
+
+import (
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/models"
+)
+
+// ToNavRecords собирает навигационные записи из ячеек пакета.
+// Каждая ячейка NPH_CELL_NAVDATA начинает новую запись, ячейки датчиков
+// дополняют последнюю запись.
+func ToNavRecords(cells []BinaryData) []*models.NavRecord {
+	var (
+		records []*models.NavRecord
+		current *models.NavRecord
+	)
+
+	for _, cell := range cells {
+		switch c := cell.(type) {
+		case *NavData:
+			current = &models.NavRecord{
+				NavigationTime: time.Unix(int64(c.Time), 0).UTC(),
+				Latitude:       float64(c.Latitude) / 1e7,
+				Longitude:      float64(c.Longitude) / 1e7,
+				Altitude:       float64(c.Altitude),
+				AltitudeValid:  true,
+				Speed:          float64(c.Speed),
+				Course:         c.Course,
+				Satellites:     c.Satellites,
+				// HDOP и пробег передаются с дискретностью 0,1
+				Hdop:     float64(c.Hdop) / 10,
+				Odometer: float64(c.Odometer) / 10,
+				Valid:    c.Flags&NavFlagValid != 0,
+				Moving:   c.Flags&NavFlagMoving != 0,
+			}
+			if c.Flags&NavFlagSouth != 0 {
+				current.Latitude = -current.Latitude
+			}
+			if c.Flags&NavFlagWest != 0 {
+				current.Longitude = -current.Longitude
+			}
+			records = append(records, current)
+		case *Sensors:
+			if current == nil {
+				current = &models.NavRecord{}
+				records = append(records, current)
+			}
+			current.DigitalInputs = uint32(c.DigitalInputs)
+			current.DigitalOutputs = uint32(c.DigitalOuts)
+			for i, v := range c.AnalogInputs {
+				current.SetSensor(models.SensorName(models.SensorAnalogInput, i+1), float64(v))
+			}
+		}
+	}
+
+	return records
+}
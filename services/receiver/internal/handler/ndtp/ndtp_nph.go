@@ -0,0 +1,45 @@
+package ndtp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// NphHeader заголовок уровня NPH
+type NphHeader struct {
+	ServiceID  uint16 `json:"service_id"`  // идентификатор сервиса
+	PacketType uint16 `json:"packet_type"` // тип пакета внутри сервиса
+	Flags      uint16 `json:"flags"`       // флаги, NPH_FLAG_REQUEST - требуется ответ
+	RequestID  uint32 `json:"request_id"`  // идентификатор запроса
+}
+
+// Decode разбирает байты в заголовок NPH
+func (n *NphHeader) Decode(content []byte) error {
+	if len(content) < NPH_HEADER_LEN {
+		return fmt.Errorf("длина заголовка NPH %d меньше %d", len(content), NPH_HEADER_LEN)
+	}
+	if err := binary.Read(bytes.NewReader(content[:NPH_HEADER_LEN]), binary.LittleEndian, n); err != nil {
+		return fmt.Errorf("не удалось прочитать заголовок NPH: %v", err)
+	}
+	return nil
+}
+
+// Encode кодирует заголовок NPH в байты
+func (n *NphHeader) Encode() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := binary.Write(buf, binary.LittleEndian, n); err != nil {
+		return nil, fmt.Errorf("не удалось записать заголовок NPH: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Length получает длину закодированного заголовка
+func (n *NphHeader) Length() uint16 {
+	return NPH_HEADER_LEN
+}
+
+// IsRequest проверяет, ожидает ли отправитель ответ NPH_RESULT
+func (n *NphHeader) IsRequest() bool {
+	return n.Flags&NPH_FLAG_REQUEST != 0
+}
@@ -0,0 +1,121 @@
+package ndtp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Package пакет уровня NPL с вложенным заголовком NPH и данными сервиса
+type Package struct {
+	Signature   uint16    `json:"signature"`    // сигнатура 0x7E7E
+	DataSize    uint16    `json:"data_size"`    // длина данных после заголовка NPL
+	Flags       uint16    `json:"flags"`        // флаги NPL
+	Crc         uint16    `json:"crc"`          // CRC-16/MODBUS данных (big-endian)
+	Type        byte      `json:"type"`         // тип данных, NPL_TYPE_NPH
+	PeerAddress uint32    `json:"peer_address"` // адрес отправителя
+	RequestID   uint16    `json:"request_id"`   // идентификатор пакета NPL
+	Nph         NphHeader `json:"nph"`          // заголовок NPH
+	Data        []byte    `json:"data"`         // данные сервиса
+}
+
+// Decode разбирает пакет NPL целиком (заголовок NPL, заголовок NPH и данные)
+func (p *Package) Decode(content []byte) (uint32, error) {
+	if len(content) < NPL_HEADER_LEN {
+		return NPH_RESULT_PACKET_INVALID_SIZE, fmt.Errorf("длина пакета %d меньше заголовка NPL", len(content))
+	}
+
+	p.Signature = binary.LittleEndian.Uint16(content[0:2])
+	if p.Signature != NPL_SIGNATURE {
+		return NPH_RESULT_PACKET_INVALID_FORMAT, fmt.Errorf("неверная сигнатура NPL: %X", p.Signature)
+	}
+	p.DataSize = binary.LittleEndian.Uint16(content[2:4])
+	p.Flags = binary.LittleEndian.Uint16(content[4:6])
+	p.Crc = binary.BigEndian.Uint16(content[6:8])
+	p.Type = content[8]
+	p.PeerAddress = binary.LittleEndian.Uint32(content[9:13])
+	p.RequestID = binary.LittleEndian.Uint16(content[13:15])
+
+	data := content[NPL_HEADER_LEN:]
+	if len(data) != int(p.DataSize) {
+		return NPH_RESULT_PACKET_INVALID_SIZE, fmt.Errorf("длина данных %d не совпадает с data_size %d", len(data), p.DataSize)
+	}
+
+	if p.Flags&NPL_FLAG_CRC != 0 {
+		if crc := crc16(data); crc != p.Crc {
+			return NPH_RESULT_PACKET_INVALID_FORMAT, fmt.Errorf("неверная контрольная сумма: %X, подсчитано %X", p.Crc, crc)
+		}
+	}
+	if p.Flags&NPL_FLAG_ENCRYPTION != 0 {
+		return NPH_RESULT_PACKET_NOT_SUPPORTED, fmt.Errorf("шифрование данных не поддерживается")
+	}
+	if p.Type != NPL_TYPE_NPH {
+		return NPH_RESULT_PACKET_NOT_SUPPORTED, fmt.Errorf("неизвестный тип данных NPL: %d", p.Type)
+	}
+
+	if err := p.Nph.Decode(data); err != nil {
+		return NPH_RESULT_PACKET_INVALID_SIZE, err
+	}
+	p.Data = data[NPH_HEADER_LEN:]
+
+	return NPH_RESULT_OK, nil
+}
+
+// Encode кодирует пакет, вычисляя длину и контрольную сумму данных
+func (p *Package) Encode() ([]byte, error) {
+	nph, err := p.Nph.Encode()
+	if err != nil {
+		return nil, err
+	}
+	data := append(nph, p.Data...)
+	if len(data) > NPL_MAX_DATA_SIZE {
+		return nil, fmt.Errorf("длина данных %d превышает допустимую", len(data))
+	}
+
+	p.Signature = NPL_SIGNATURE
+	p.DataSize = uint16(len(data))
+	p.Type = NPL_TYPE_NPH
+	p.Flags |= NPL_FLAG_CRC
+	p.Crc = crc16(data)
+
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.LittleEndian, p.Signature)
+	binary.Write(buf, binary.LittleEndian, p.DataSize)
+	binary.Write(buf, binary.LittleEndian, p.Flags)
+	binary.Write(buf, binary.BigEndian, p.Crc)
+	buf.WriteByte(p.Type)
+	binary.Write(buf, binary.LittleEndian, p.PeerAddress)
+	binary.Write(buf, binary.LittleEndian, p.RequestID)
+	buf.Write(data)
+
+	return buf.Bytes(), nil
+}
+
+// Length получает длину закодированного пакета
+func (p *Package) Length() uint16 {
+	return uint16(NPL_HEADER_LEN + NPH_HEADER_LEN + len(p.Data))
+}
+
+// ReadPackage читает из потока ровно один пакет NPL, используя поле data_size
+func ReadPackage(r io.Reader) ([]byte, error) {
+	packet := make([]byte, NPL_HEADER_LEN)
+	if _, err := io.ReadFull(r, packet); err != nil {
+		return nil, err
+	}
+	if sig := binary.LittleEndian.Uint16(packet[0:2]); sig != NPL_SIGNATURE {
+		return nil, fmt.Errorf("неверная сигнатура NPL: %X", sig)
+	}
+
+	dataSize := int(binary.LittleEndian.Uint16(packet[2:4]))
+	if dataSize < NPH_HEADER_LEN {
+		return nil, fmt.Errorf("некорректная длина данных: %d", dataSize)
+	}
+
+	body := make([]byte, dataSize)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+
+	return append(packet, body...), nil
+}
@@ -0,0 +1,105 @@
+package ndtp
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testConnRequest = ConnRequest{
+	VersionHigh:   6,
+	VersionLow:    2,
+	ConnFlags:     0,
+	PeerAddress:   1000123,
+	MaxPacketSize: 1024,
+}
+
+func testConnRequestPackage(t *testing.T) []byte {
+	data, err := testConnRequest.Encode()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	pkg := Package{
+		RequestID: 1,
+		Nph: NphHeader{
+			ServiceID:  NPH_SRV_GENERIC_CONTROLS,
+			PacketType: NPH_SGC_CONN_REQUEST,
+			Flags:      NPH_FLAG_REQUEST,
+			RequestID:  7,
+		},
+		Data: data,
+	}
+	raw, err := pkg.Encode()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return raw
+}
+
+func TestPackage_EncodeDecode(t *testing.T) {
+	raw := testConnRequestPackage(t)
+	assert.Equal(t, NPL_HEADER_LEN+NPH_HEADER_LEN+connRequestLen, len(raw))
+	assert.Equal(t, []byte{0x7E, 0x7E}, raw[:2])
+
+	pkg := Package{}
+	code, err := pkg.Decode(raw)
+	if assert.NoError(t, err) {
+		assert.Equal(t, uint32(NPH_RESULT_OK), code)
+		assert.Equal(t, uint16(1), pkg.RequestID)
+		assert.Equal(t, uint16(NPH_SGC_CONN_REQUEST), pkg.Nph.PacketType)
+		assert.Equal(t, uint32(7), pkg.Nph.RequestID)
+		assert.True(t, pkg.Nph.IsRequest())
+
+		req := ConnRequest{}
+		if assert.NoError(t, req.Decode(pkg.Data)) {
+			assert.Equal(t, testConnRequest, req)
+		}
+	}
+}
+
+func TestPackage_DecodeBadCrc(t *testing.T) {
+	raw := testConnRequestPackage(t)
+	raw[len(raw)-1] ^= 0xFF
+
+	pkg := Package{}
+	code, err := pkg.Decode(raw)
+	assert.Error(t, err)
+	assert.Equal(t, uint32(NPH_RESULT_PACKET_INVALID_FORMAT), code)
+}
+
+func TestReadPackage(t *testing.T) {
+	raw := testConnRequestPackage(t)
+	stream := bytes.NewReader(append(append([]byte{}, raw...), raw...))
+
+	for i := 0; i < 2; i++ {
+		pkt, err := ReadPackage(stream)
+		if assert.NoError(t, err) {
+			assert.Equal(t, raw, pkt)
+		}
+	}
+
+	_, err := ReadPackage(stream)
+	assert.Error(t, err)
+}
+
+func TestEncodeResult(t *testing.T) {
+	req := NphHeader{ServiceID: NPH_SRV_NAVDATA, PacketType: NPH_SND_REALTIME, Flags: NPH_FLAG_REQUEST, RequestID: 42}
+
+	raw, err := EncodeResult(3, &req, NPH_RESULT_BUSY)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	pkg := Package{}
+	_, err = pkg.Decode(raw)
+	if assert.NoError(t, err) {
+		assert.Equal(t, NphHeader{ServiceID: NPH_SRV_NAVDATA, PacketType: NPH_RESULT, RequestID: 42}, pkg.Nph)
+
+		res := Result{}
+		if assert.NoError(t, res.Decode(pkg.Data)) {
+			assert.Equal(t, uint32(NPH_RESULT_BUSY), res.ErrorCode)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package ndtp
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Result данные пакета NPH_RESULT
+type Result struct {
+	ErrorCode uint32 `json:"error_code"`
+}
+
+// Decode разбирает байты в структуру результата
+func (r *Result) Decode(content []byte) error {
+	if len(content) < 4 {
+		return fmt.Errorf("не удалось получить код результата: длина %d", len(content))
+	}
+	r.ErrorCode = binary.LittleEndian.Uint32(content)
+	return nil
+}
+
+// Encode кодирует результат в байты
+func (r *Result) Encode() ([]byte, error) {
+	result := make([]byte, 4)
+	binary.LittleEndian.PutUint32(result, r.ErrorCode)
+	return result, nil
+}
+
+// Length получает длину закодированного результата
+func (r *Result) Length() uint16 {
+	return 4
+}
+
+// EncodeResult формирует пакет NPH_RESULT в ответ на запрос req.
+// nplID - идентификатор исходящего пакета NPL.
+func EncodeResult(nplID uint16, req *NphHeader, code uint32) ([]byte, error) {
+	data, _ := (&Result{ErrorCode: code}).Encode()
+
+	answer := Package{
+		RequestID: nplID,
+		Nph: NphHeader{
+			ServiceID:  req.ServiceID,
+			PacketType: NPH_RESULT,
+			RequestID:  req.RequestID,
+		},
+		Data: data,
+	}
+	return answer.Encode()
+}
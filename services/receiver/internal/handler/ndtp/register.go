@@ -0,0 +1,14 @@
+package ndtp
+
+import (
+	"github.com/rackov/NavControlSystem/services/receiver/internal/protocol"
+)
+
+func init() {
+	protocol.Register(protocol.Descriptor{
+		Name:        "NDTP",
+		Description: "NDTP, протокол Навтелеком",
+		Options:     protocol.SessionOptions,
+		Factory:     protocol.SessionFactory(func() protocol.SessionHandler { return NewNdtpHandler() }),
+	})
+}
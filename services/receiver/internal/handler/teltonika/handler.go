@@ -0,0 +1,140 @@
+package teltonika
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/connectionmanager"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/protocol"
+)
+
+// defaultAuthTimeout - время ожидания IMEI от устройства по умолчанию
+const defaultAuthTimeout = 30 * time.Second
+
+type TeltonikaHandler struct {
+	connectionmanager.Base
+
+	publisher protocol.DataPublisher // Храним publisher для доступа в handleConnection
+}
+
+func NewTeltonikaHandler() *TeltonikaHandler {
+	h := &TeltonikaHandler{}
+	h.Base = connectionmanager.NewBase(h, defaultAuthTimeout)
+	return h
+}
+
+// Start запускает обработчик, делегируя управление соединениями ConnectionManager
+func (h *TeltonikaHandler) Start(ctx context.Context, publisher protocol.DataPublisher, port int) error {
+	h.publisher = publisher
+	return h.Manager().Start(ctx, port, h.handleConnection)
+}
+
+// GetName возвращает имя протокола
+func (h *TeltonikaHandler) GetName() string {
+	return "TELTONIKA"
+}
+
+// Stop останавливает ConnectionManager
+func (h *TeltonikaHandler) Stop() error {
+	logger.Info("Stopping Teltonika handler...")
+
+	return h.Manager().Stop()
+}
+
+// GetClientID реализует интерфейс ClientData для авторизации.
+// Устройство присылает IMEI, сервер подтверждает его байтом 0x01.
+func (h *TeltonikaHandler) GetClientID(conn net.Conn) (string, error) {
+	// Устанавливаем таймаут на чтение, чтобы не зависнуть, если клиент ничего не присылает
+	conn.SetReadDeadline(time.Now().Add(h.AuthTimeout()))
+	defer conn.SetReadDeadline(time.Time{})
+
+	imei, err := ReadImei(conn)
+	if err != nil {
+		conn.Write([]byte{ImeiRejected})
+		return "", fmt.Errorf("failed to read IMEI: %w", err)
+	}
+	if err := protocol.AuthorizeDevice(h.publisher, imei); err != nil {
+		conn.Write([]byte{ImeiRejected})
+		return "", err
+	}
+
+	if _, err := conn.Write([]byte{ImeiAccepted}); err != nil {
+		return "", fmt.Errorf("failed to send IMEI confirmation: %w", err)
+	}
+
+	return imei, nil
+}
+
+// handleConnection содержит логику, специфичную для Teltonika, после авторизации
+func (h *TeltonikaHandler) handleConnection(ctx context.Context, conn net.Conn, clientID string) {
+	logger.Infof("Starting Teltonika data processing for client ID: %s", clientID)
+
+	// Блокирующее чтение прерываем закрытием соединения при отмене контекста
+	connCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-connCtx.Done()
+		conn.Close()
+	}()
+
+	var client uint32
+	if id, err := strconv.ParseUint(clientID, 10, 32); err == nil {
+		client = uint32(id)
+	}
+
+	stats := protocol.StatsOf(conn)
+	reader := bufio.NewReader(conn)
+	for {
+		raw, err := ReadPacket(reader)
+		if err != nil {
+			switch {
+			case ctx.Err() != nil:
+				logger.Infof("Teltonika processing for client ID %s cancelled", clientID)
+			case errors.Is(err, io.EOF):
+				logger.Infof("Teltonika client ID %s closed connection", clientID)
+			default:
+				logger.Errorf("Failed to read Teltonika packet from client ID %s: %v", clientID, err)
+			}
+			return
+		}
+
+		packet := AvlPacket{}
+		if err := packet.Decode(raw); err != nil {
+			stats.DecodeError()
+			// Без подтверждения устройство повторит передачу
+			logger.Warnf("Failed to decode Teltonika packet from client ID %s: %v", clientID, err)
+			continue
+		}
+
+		stats.PacketDecoded()
+		stats.SetProtocolVersion(fmt.Sprintf("codec 0x%02X", packet.CodecID))
+
+		receivedAt := time.Now().UTC()
+		for i := range packet.Records {
+			rec := packet.Records[i].ToNavRecord()
+			rec.Client = client
+			rec.Imei = clientID
+			rec.ReceivedTime = receivedAt
+
+			if err := h.publisher.Publish(rec); err != nil {
+				// Записи не подтверждаем, устройство передаст их повторно после переподключения
+				logger.Errorf("Failed to publish Teltonika data for client ID %s: %v", clientID, err)
+				return
+			}
+			stats.RecordPublished(rec)
+		}
+		logger.Debugf("Teltonika data for client ID %s published: %d records", clientID, len(packet.Records))
+
+		if _, err := conn.Write(EncodeAck(len(packet.Records))); err != nil {
+			logger.Errorf("Failed to send Teltonika ack to client ID %s: %v", clientID, err)
+			return
+		}
+	}
+}
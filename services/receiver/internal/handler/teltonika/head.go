@@ -0,0 +1,43 @@
+package teltonika
+
+// Кодеки AVL данных
+const (
+	Codec8  = 0x08 // Codec 8, идентификаторы IO - 1 байт
+	Codec8E = 0x8E // Codec 8 Extended, идентификаторы IO - 2 байта, элементы переменной длины
+)
+
+// размеры
+const (
+	SizePreamble  = 4         // нулевая преамбула пакета
+	SizeHeader    = 8         // преамбула и длина поля данных
+	SizeCrc       = 4         // CRC-16/IBM передается в 4 байтах
+	SizeGps       = 15        // GPS элемент записи
+	MaxImeiLength = 17        // максимальная длина IMEI в рукопожатии
+	MaxDataLength = 64 * 1024 // ограничение длины поля данных
+)
+
+// Ответы на рукопожатие
+const (
+	ImeiAccepted = 0x01
+	ImeiRejected = 0x00
+)
+
+// Идентификаторы IO элементов устройств FMB, которые раскладываются
+// по отдельным полям навигационной записи
+const (
+	IoDin1      = 1   // дискретный вход 1
+	IoDin2      = 2   // дискретный вход 2
+	IoDin3      = 3   // дискретный вход 3
+	IoDin4      = 4   // дискретный вход 4
+	IoAin1      = 9   // аналоговый вход 1, мВ
+	IoAin2      = 10  // аналоговый вход 2, мВ
+	IoAin3      = 11  // аналоговый вход 3, мВ
+	IoAin4      = 245 // аналоговый вход 4, мВ
+	IoTotalOdom = 16  // общий пробег, м
+	IoDout1     = 179 // дискретный выход 1
+	IoDout2     = 180 // дискретный выход 2
+	IoDout3     = 50  // дискретный выход 3
+	IoDout4     = 51  // дискретный выход 4
+	IoPdop      = 181 // PDOP*10
+	IoHdop      = 182 // HDOP*10
+)
@@ -0,0 +1,14 @@
+package teltonika
+
+import (
+	"github.com/rackov/NavControlSystem/services/receiver/internal/protocol"
+)
+
+func init() {
+	protocol.Register(protocol.Descriptor{
+		Name:        "TELTONIKA",
+		Description: "Teltonika Codec 8/8E",
+		Options:     protocol.SessionOptions,
+		Factory:     protocol.SessionFactory(func() protocol.SessionHandler { return NewTeltonikaHandler() }),
+	})
+}
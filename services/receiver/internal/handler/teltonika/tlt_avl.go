@@ -0,0 +1,322 @@
+package teltonika
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// IoElement значение одного IO элемента записи
+type IoElement struct {
+	ID    uint16 `json:"id"`
+	Size  uint16 `json:"size"`  // длина значения в байтах: 1, 2, 4, 8 или длина элемента NX
+	Value uint64 `json:"value"` // значение элементов фиксированной длины
+	Raw   []byte `json:"raw"`   // значение элементов переменной длины (только Codec 8E)
+}
+
+// AvlRecord одна AVL запись
+type AvlRecord struct {
+	Timestamp  uint64      `json:"timestamp"` // время в миллисекундах от 1970-01-01 UTC
+	Priority   byte        `json:"priority"`
+	Longitude  int32       `json:"lon"` // градусы*10^7, отрицательная - западная
+	Latitude   int32       `json:"lat"` // градусы*10^7, отрицательная - южная
+	Altitude   int16       `json:"altitude"`
+	Angle      uint16      `json:"angle"`
+	Satellites byte        `json:"satellites"`
+	Speed      uint16      `json:"speed"` // км/ч, 0 при невалидных координатах
+	EventIoID  uint16      `json:"event_io_id"`
+	TotalIo    uint16      `json:"total_io"`
+	IoElements []IoElement `json:"io"`
+}
+
+// AvlPacket пакет AVL данных (TCP)
+type AvlPacket struct {
+	DataLength uint32      `json:"data_length"`
+	CodecID    byte        `json:"codec_id"`
+	Records    []AvlRecord `json:"records"`
+	Crc        uint32      `json:"crc"`
+}
+
+// Decode разбирает пакет целиком, начиная с преамбулы
+func (p *AvlPacket) Decode(content []byte) error {
+	if len(content) < SizeHeader+SizeCrc+3 {
+		return fmt.Errorf("длина пакета %d слишком мала", len(content))
+	}
+	if binary.BigEndian.Uint32(content[:SizePreamble]) != 0 {
+		return fmt.Errorf("неверная преамбула пакета: %X", content[:SizePreamble])
+	}
+	p.DataLength = binary.BigEndian.Uint32(content[SizePreamble:SizeHeader])
+	if len(content) != SizeHeader+int(p.DataLength)+SizeCrc {
+		return fmt.Errorf("длина пакета %d не совпадает с полем длины %d", len(content), p.DataLength)
+	}
+
+	data := content[SizeHeader : SizeHeader+p.DataLength]
+	p.Crc = binary.BigEndian.Uint32(content[SizeHeader+p.DataLength:])
+	if crc := uint32(crc16(data)); crc != p.Crc {
+		return fmt.Errorf("неверная контрольная сумма: %X, подсчитано %X", p.Crc, crc)
+	}
+
+	buf := bytes.NewReader(data)
+	p.CodecID, _ = buf.ReadByte()
+	if p.CodecID != Codec8 && p.CodecID != Codec8E {
+		return fmt.Errorf("кодек %X не поддерживается", p.CodecID)
+	}
+
+	count, _ := buf.ReadByte()
+	p.Records = make([]AvlRecord, 0, count)
+	for i := 0; i < int(count); i++ {
+		rec := AvlRecord{}
+		if err := rec.decode(buf, p.CodecID == Codec8E); err != nil {
+			return fmt.Errorf("запись %d: %v", i, err)
+		}
+		p.Records = append(p.Records, rec)
+	}
+
+	count2, err := buf.ReadByte()
+	if err != nil {
+		return fmt.Errorf("не удалось получить количество записей: %v", err)
+	}
+	if count2 != count {
+		return fmt.Errorf("количество записей в начале (%d) и в конце (%d) не совпадает", count, count2)
+	}
+	if buf.Len() != 0 {
+		return fmt.Errorf("лишние данные в пакете: %d байт", buf.Len())
+	}
+
+	return nil
+}
+
+// Encode кодирует пакет, вычисляя длину и контрольную сумму
+func (p *AvlPacket) Encode() ([]byte, error) {
+	if p.CodecID != Codec8 && p.CodecID != Codec8E {
+		return nil, fmt.Errorf("кодек %X не поддерживается", p.CodecID)
+	}
+	if len(p.Records) > 0xFF {
+		return nil, fmt.Errorf("слишком много записей: %d", len(p.Records))
+	}
+
+	data := new(bytes.Buffer)
+	data.WriteByte(p.CodecID)
+	data.WriteByte(byte(len(p.Records)))
+	for i := range p.Records {
+		if err := p.Records[i].encode(data, p.CodecID == Codec8E); err != nil {
+			return nil, fmt.Errorf("запись %d: %v", i, err)
+		}
+	}
+	data.WriteByte(byte(len(p.Records)))
+
+	p.DataLength = uint32(data.Len())
+	p.Crc = uint32(crc16(data.Bytes()))
+
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.BigEndian, uint32(0))
+	binary.Write(buf, binary.BigEndian, p.DataLength)
+	buf.Write(data.Bytes())
+	binary.Write(buf, binary.BigEndian, p.Crc)
+
+	return buf.Bytes(), nil
+}
+
+func (r *AvlRecord) decode(buf *bytes.Reader, extended bool) error {
+	head := struct {
+		Timestamp  uint64
+		Priority   byte
+		Longitude  int32
+		Latitude   int32
+		Altitude   int16
+		Angle      uint16
+		Satellites byte
+		Speed      uint16
+	}{}
+	if err := binary.Read(buf, binary.BigEndian, &head); err != nil {
+		return fmt.Errorf("не удалось получить GPS элемент: %v", err)
+	}
+	r.Timestamp = head.Timestamp
+	r.Priority = head.Priority
+	r.Longitude = head.Longitude
+	r.Latitude = head.Latitude
+	r.Altitude = head.Altitude
+	r.Angle = head.Angle
+	r.Satellites = head.Satellites
+	r.Speed = head.Speed
+
+	var err error
+	if r.EventIoID, err = readCount(buf, extended); err != nil {
+		return fmt.Errorf("не удалось получить event IO ID: %v", err)
+	}
+	if r.TotalIo, err = readCount(buf, extended); err != nil {
+		return fmt.Errorf("не удалось получить количество IO: %v", err)
+	}
+
+	for _, size := range []uint16{1, 2, 4, 8} {
+		n, err := readCount(buf, extended)
+		if err != nil {
+			return fmt.Errorf("не удалось получить количество IO длиной %d: %v", size, err)
+		}
+		for i := 0; i < int(n); i++ {
+			el := IoElement{Size: size}
+			if el.ID, err = readCount(buf, extended); err != nil {
+				return fmt.Errorf("не удалось получить ID IO: %v", err)
+			}
+			value := make([]byte, 8)
+			if _, err = io.ReadFull(buf, value[8-size:]); err != nil {
+				return fmt.Errorf("не удалось получить значение IO %d: %v", el.ID, err)
+			}
+			el.Value = binary.BigEndian.Uint64(value)
+			r.IoElements = append(r.IoElements, el)
+		}
+	}
+
+	if !extended {
+		return nil
+	}
+
+	// элементы переменной длины NX
+	n, err := readCount(buf, true)
+	if err != nil {
+		return fmt.Errorf("не удалось получить количество IO NX: %v", err)
+	}
+	for i := 0; i < int(n); i++ {
+		el := IoElement{}
+		if err = binary.Read(buf, binary.BigEndian, &el.ID); err != nil {
+			return fmt.Errorf("не удалось получить ID IO: %v", err)
+		}
+		if err = binary.Read(buf, binary.BigEndian, &el.Size); err != nil {
+			return fmt.Errorf("не удалось получить длину IO %d: %v", el.ID, err)
+		}
+		el.Raw = make([]byte, el.Size)
+		if _, err = io.ReadFull(buf, el.Raw); err != nil {
+			return fmt.Errorf("не удалось получить значение IO %d: %v", el.ID, err)
+		}
+		r.IoElements = append(r.IoElements, el)
+	}
+
+	return nil
+}
+
+func (r *AvlRecord) encode(buf *bytes.Buffer, extended bool) error {
+	binary.Write(buf, binary.BigEndian, r.Timestamp)
+	buf.WriteByte(r.Priority)
+	binary.Write(buf, binary.BigEndian, r.Longitude)
+	binary.Write(buf, binary.BigEndian, r.Latitude)
+	binary.Write(buf, binary.BigEndian, r.Altitude)
+	binary.Write(buf, binary.BigEndian, r.Angle)
+	buf.WriteByte(r.Satellites)
+	binary.Write(buf, binary.BigEndian, r.Speed)
+
+	writeCount(buf, r.EventIoID, extended)
+	writeCount(buf, uint16(len(r.IoElements)), extended)
+
+	for _, size := range []uint16{1, 2, 4, 8} {
+		var group []IoElement
+		for _, el := range r.IoElements {
+			if el.Raw == nil && el.Size == size {
+				group = append(group, el)
+			}
+		}
+		writeCount(buf, uint16(len(group)), extended)
+		for _, el := range group {
+			if !extended && el.ID > 0xFF {
+				return fmt.Errorf("ID IO %d не помещается в Codec 8", el.ID)
+			}
+			writeCount(buf, el.ID, extended)
+			value := make([]byte, 8)
+			binary.BigEndian.PutUint64(value, el.Value)
+			buf.Write(value[8-size:])
+		}
+	}
+
+	if !extended {
+		return nil
+	}
+
+	var group []IoElement
+	for _, el := range r.IoElements {
+		if el.Raw != nil {
+			group = append(group, el)
+		}
+	}
+	writeCount(buf, uint16(len(group)), true)
+	for _, el := range group {
+		binary.Write(buf, binary.BigEndian, el.ID)
+		binary.Write(buf, binary.BigEndian, uint16(len(el.Raw)))
+		buf.Write(el.Raw)
+	}
+
+	return nil
+}
+
+// readCount читает счетчик или идентификатор: 1 байт в Codec 8, 2 байта в Codec 8E
+func readCount(buf *bytes.Reader, extended bool) (uint16, error) {
+	if !extended {
+		b, err := buf.ReadByte()
+		return uint16(b), err
+	}
+	var v uint16
+	err := binary.Read(buf, binary.BigEndian, &v)
+	return v, err
+}
+
+func writeCount(buf *bytes.Buffer, v uint16, extended bool) {
+	if !extended {
+		buf.WriteByte(byte(v))
+		return
+	}
+	binary.Write(buf, binary.BigEndian, v)
+}
+
+// ReadPacket читает из потока ровно один AVL пакет (преамбула, длина, данные, CRC)
+func ReadPacket(r io.Reader) ([]byte, error) {
+	head := make([]byte, SizeHeader)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return nil, err
+	}
+	if binary.BigEndian.Uint32(head[:SizePreamble]) != 0 {
+		return nil, fmt.Errorf("неверная преамбула пакета: %X", head[:SizePreamble])
+	}
+
+	length := binary.BigEndian.Uint32(head[SizePreamble:])
+	if length == 0 || length > MaxDataLength {
+		return nil, fmt.Errorf("некорректная длина поля данных: %d", length)
+	}
+
+	packet := make([]byte, SizeHeader+int(length)+SizeCrc)
+	copy(packet, head)
+	if _, err := io.ReadFull(r, packet[SizeHeader:]); err != nil {
+		return nil, err
+	}
+
+	return packet, nil
+}
+
+// ReadImei читает рукопожатие: длина (2 байта) и IMEI в ASCII
+func ReadImei(r io.Reader) (string, error) {
+	head := make([]byte, 2)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return "", err
+	}
+	length := binary.BigEndian.Uint16(head)
+	if length == 0 || length > MaxImeiLength {
+		return "", fmt.Errorf("некорректная длина IMEI: %d", length)
+	}
+
+	imei := make([]byte, length)
+	if _, err := io.ReadFull(r, imei); err != nil {
+		return "", err
+	}
+	for _, c := range imei {
+		if c < '0' || c > '9' {
+			return "", fmt.Errorf("IMEI содержит недопустимые символы: %q", imei)
+		}
+	}
+
+	return string(imei), nil
+}
+
+// EncodeAck формирует подтверждение приема: количество принятых записей (4 байта)
+func EncodeAck(count int) []byte {
+	ack := make([]byte, 4)
+	binary.BigEndian.PutUint32(ack, uint32(count))
+	return ack
+}
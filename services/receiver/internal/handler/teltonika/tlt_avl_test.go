@@ -0,0 +1,151 @@
+package teltonika
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// примеры пакетов из документации Teltonika
+const (
+	testCodec8Hex = "000000000000003608010000016B40D8EA30010000000000000000000000000000000105021503010101425E0F01F10000601A014E0000000000000000010000C7CF"
+
+	testCodec8EHex = "000000000000004A8E010000016B412CEE000100000000000000000000000000000000010005000100010100010011001D00010010015E2C880002000B000000003544C87A000E000000001DD7E06A00000100002994"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestAvlPacket_DecodeCodec8(t *testing.T) {
+	raw := mustHex(t, testCodec8Hex)
+
+	packet := AvlPacket{}
+	if !assert.NoError(t, packet.Decode(raw)) {
+		return
+	}
+
+	assert.Equal(t, byte(Codec8), packet.CodecID)
+	if assert.Len(t, packet.Records, 1) {
+		rec := packet.Records[0]
+		assert.Equal(t, uint64(0x16B40D8EA30), rec.Timestamp)
+		assert.Equal(t, byte(1), rec.Priority)
+		assert.Equal(t, uint16(1), rec.EventIoID)
+		assert.Equal(t, uint16(5), rec.TotalIo)
+		assert.Equal(t, []IoElement{
+			{ID: 0x15, Size: 1, Value: 3},
+			{ID: 0x01, Size: 1, Value: 1},
+			{ID: 0x42, Size: 2, Value: 0x5E0F},
+			{ID: 0xF1, Size: 4, Value: 0x601A},
+			{ID: 0x4E, Size: 8, Value: 0},
+		}, rec.IoElements)
+	}
+
+	encoded, err := packet.Encode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, raw, encoded)
+	}
+}
+
+func TestAvlPacket_DecodeCodec8E(t *testing.T) {
+	raw := mustHex(t, testCodec8EHex)
+
+	packet := AvlPacket{}
+	if !assert.NoError(t, packet.Decode(raw)) {
+		return
+	}
+
+	assert.Equal(t, byte(Codec8E), packet.CodecID)
+	if assert.Len(t, packet.Records, 1) {
+		assert.Equal(t, []IoElement{
+			{ID: 0x01, Size: 1, Value: 1},
+			{ID: 0x11, Size: 2, Value: 0x1D},
+			{ID: 0x10, Size: 4, Value: 0x15E2C88},
+			{ID: 0x0B, Size: 8, Value: 0x3544C87A},
+			{ID: 0x0E, Size: 8, Value: 0x1DD7E06A},
+		}, packet.Records[0].IoElements)
+	}
+
+	encoded, err := packet.Encode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, raw, encoded)
+	}
+}
+
+func TestAvlPacket_DecodeBadCrc(t *testing.T) {
+	raw := mustHex(t, testCodec8Hex)
+	raw[len(raw)-1] ^= 0xFF
+
+	packet := AvlPacket{}
+	assert.Error(t, packet.Decode(raw))
+}
+
+func TestReadPacket(t *testing.T) {
+	raw := mustHex(t, testCodec8Hex)
+	stream := bytes.NewReader(append(append([]byte{}, raw...), raw...))
+
+	for i := 0; i < 2; i++ {
+		pkt, err := ReadPacket(stream)
+		if assert.NoError(t, err) {
+			assert.Equal(t, raw, pkt)
+		}
+	}
+}
+
+func TestReadImei(t *testing.T) {
+	imei, err := ReadImei(bytes.NewReader(mustHex(t, "000F333536333037303432343431303133")))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "356307042441013", imei)
+	}
+
+	_, err = ReadImei(bytes.NewReader([]byte{0x00, 0x03, 'a', 'b', 'c'}))
+	assert.Error(t, err)
+}
+
+func TestEncodeAck(t *testing.T) {
+	assert.Equal(t, []byte{0x00, 0x00, 0x00, 0x02}, EncodeAck(2))
+}
+
+func TestAvlRecord_ToNavRecord(t *testing.T) {
+	rec := AvlRecord{
+		Timestamp:  1560166592000,
+		Longitude:  -705000000,
+		Latitude:   556363110,
+		Angle:      270,
+		Satellites: 9,
+		Speed:      60,
+		Priority:   priorityPanic,
+		EventIoID:  IoDin1,
+		IoElements: []IoElement{
+			{ID: IoDin1, Size: 1, Value: 1},
+			{ID: IoDin3, Size: 1, Value: 1},
+			{ID: IoDout2, Size: 1, Value: 1},
+			{ID: IoAin1, Size: 2, Value: 12000},
+			{ID: IoTotalOdom, Size: 4, Value: 123456},
+			{ID: 66, Size: 2, Value: 13800},
+			{ID: 300, Size: 4, Value: 5},
+			{ID: 385, Raw: []byte{0xAB, 0xCD}},
+		},
+	}
+
+	nav := rec.ToNavRecord()
+	assert.Equal(t, time.Unix(1560166592, 0).UTC(), nav.NavigationTime)
+	assert.InDelta(t, 55.636311, nav.Latitude, 1e-9)
+	assert.InDelta(t, -70.5, nav.Longitude, 1e-9)
+	assert.True(t, nav.Valid)
+	assert.Equal(t, uint16(270), nav.Course)
+	assert.Equal(t, uint32(0x05), nav.DigitalInputs)
+	assert.Equal(t, uint32(0x02), nav.DigitalOutputs)
+	assert.Equal(t, 123.456, nav.Odometer)
+	assert.Equal(t, map[string]float64{"ain1": 12000, "io66": 13800, "io300": 5}, nav.Sensors)
+	assert.Equal(t, map[string]string{"io385": "abcd"}, nav.Params)
+	assert.True(t, nav.Alarm)
+	assert.Equal(t, "io1", nav.Event)
+}
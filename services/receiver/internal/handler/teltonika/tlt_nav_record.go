@@ -0,0 +1,90 @@
+package teltonika
+
+import (
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/models"
+)
+
+// приоритет AVL записи "Panic" - запись отправлена по тревоге
+const priorityPanic = 2
+
+// номера дискретных входов и аналоговых входов по ID IO элемента
+var (
+	digitalInputs  = map[uint16]uint{IoDin1: 0, IoDin2: 1, IoDin3: 2, IoDin4: 3}
+	digitalOutputs = map[uint16]uint{IoDout1: 0, IoDout2: 1, IoDout3: 2, IoDout4: 3}
+	analogInputs   = map[uint16]int{IoAin1: 1, IoAin2: 2, IoAin3: 3, IoAin4: 4}
+)
+
+// ToNavRecord переводит AVL запись в навигационную запись.
+// Известные IO элементы раскладываются по полям записи: дискретные входы и выходы -
+// в битовые маски, аналоговые входы - в датчики ain1..ain4 (мВ). Остальные числовые
+// элементы становятся датчиками io<ID>, элементы переменной длины - параметрами io<ID> в hex.
+func (r *AvlRecord) ToNavRecord() *models.NavRecord {
+	rec := &models.NavRecord{
+		NavigationTime: time.UnixMilli(int64(r.Timestamp)).UTC(),
+		// Координаты в градусах*10^7 со знаком
+		Latitude:   float64(r.Latitude) / 1e7,
+		Longitude:  float64(r.Longitude) / 1e7,
+		Speed:      float64(r.Speed),
+		Course:     r.Angle,
+		Satellites: r.Satellites,
+	}
+	if r.Satellites > 0 && (r.Latitude != 0 || r.Longitude != 0) {
+		rec.Valid = true
+		rec.Altitude = float64(r.Altitude)
+		rec.AltitudeValid = true
+	}
+
+	// Приоритет Panic - тревога, запись по изменению IO элемента - событие
+	rec.Alarm = r.Priority == priorityPanic
+	if r.EventIoID != 0 {
+		rec.Event = models.SensorName(models.SensorIO, int(r.EventIoID))
+	}
+
+	for _, el := range r.IoElements {
+		name := models.SensorName(models.SensorIO, int(el.ID))
+		if el.Raw != nil {
+			rec.SetParam(name, hex.EncodeToString(el.Raw))
+			continue
+		}
+
+		if bit, ok := digitalInputs[el.ID]; ok {
+			if el.Value != 0 {
+				rec.DigitalInputs |= 1 << bit
+			}
+			continue
+		}
+		if bit, ok := digitalOutputs[el.ID]; ok {
+			if el.Value != 0 {
+				rec.DigitalOutputs |= 1 << bit
+			}
+			continue
+		}
+		if num, ok := analogInputs[el.ID]; ok {
+			rec.SetSensor(models.SensorName(models.SensorAnalogInput, num), float64(el.Value))
+			continue
+		}
+
+		switch el.ID {
+		case IoTotalOdom:
+			// пробег передается в метрах
+			rec.Odometer = float64(el.Value) / 1000
+		case IoPdop:
+			rec.Pdop = float64(el.Value) / 10
+		case IoHdop:
+			rec.Hdop = float64(el.Value) / 10
+		default:
+			if el.Size <= 4 {
+				rec.SetSensor(name, float64(el.Value))
+			} else {
+				// 8-байтовые значения не всегда точно представимы в float64
+				rec.SetParam(name, strconv.FormatUint(el.Value, 10))
+			}
+		}
+	}
+
+	return rec
+}
@@ -0,0 +1,18 @@
+package wialon
+
+// crc16 контрольная сумма CRC-16/ARC (полином 0xA001, начальное значение 0x0000)
+func crc16(data []byte) uint16 {
+	crc := uint16(0)
+	for _, b := range data {
+		crc ^= uint16(b)
+		for i := 0; i < 8; i++ {
+			if crc&0x0001 != 0 {
+				crc = (crc >> 1) ^ 0xA001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+
+	return crc
+}
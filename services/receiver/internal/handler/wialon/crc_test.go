@@ -0,0 +1,11 @@
+package wialon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrc16(t *testing.T) {
+	assert.Equal(t, uint16(0xBB3D), crc16([]byte("123456789")))
+}
@@ -0,0 +1,289 @@
+package wialon
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/connectionmanager"
+	"github.com/rackov/NavControlSystem/services/receiver/internal/protocol"
+)
+
+const (
+	// defaultAuthTimeout - время ожидания пакета логина от трекера по умолчанию
+	defaultAuthTimeout = 30 * time.Second
+	// maxLineLength - максимальная длина пакета (черный ящик может быть большим)
+	maxLineLength = 64 * 1024
+)
+
+type WialonHandler struct {
+	connectionmanager.Base
+
+	publisher protocol.DataPublisher // Храним publisher для доступа в handleConnection
+
+	// Состояние сессий между GetClientID и handleConnection, ключ - net.Conn
+	sessions sync.Map
+}
+
+// wialonSession хранит состояние одного подключения трекера
+type wialonSession struct {
+	imei     string
+	client   uint32 // IMEI в числовом виде, если помещается в uint32
+	withCrc  bool   // версия 2.0, пакеты содержат CRC16
+	reader   *bufio.Reader
+	sequence uint32 // номер принятого пакета с данными
+	stats    *protocol.ConnStats
+}
+
+func NewWialonHandler() *WialonHandler {
+	h := &WialonHandler{}
+	h.Base = connectionmanager.NewBase(h, defaultAuthTimeout)
+	return h
+}
+
+// Start запускает обработчик, делегируя управление соединениями ConnectionManager
+func (h *WialonHandler) Start(ctx context.Context, publisher protocol.DataPublisher, port int) error {
+	h.publisher = publisher
+	return h.Manager().Start(ctx, port, h.handleConnection)
+}
+
+// GetName возвращает имя протокола
+func (h *WialonHandler) GetName() string {
+	return "WIALON"
+}
+
+// Stop останавливает ConnectionManager
+func (h *WialonHandler) Stop() error {
+	logger.Info("Stopping Wialon IPS handler...")
+
+	return h.Manager().Stop()
+}
+
+// GetClientID реализует интерфейс ClientData для авторизации.
+// Первым пакетом трекер присылает #L# с IMEI, он и становится clientID.
+func (h *WialonHandler) GetClientID(conn net.Conn) (string, error) {
+	// Устанавливаем таймаут на чтение, чтобы не зависнуть, если клиент ничего не присылает
+	conn.SetReadDeadline(time.Now().Add(h.AuthTimeout()))
+	defer conn.SetReadDeadline(time.Time{})
+
+	stats := protocol.StatsOf(conn)
+	reader := bufio.NewReaderSize(conn, maxLineLength)
+	line, err := readLine(reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to read login packet: %w", err)
+	}
+
+	msg, err := ParseMessage(line)
+	if err != nil {
+		stats.DecodeError()
+		conn.Write(EncodeAnswer(AnswerLogin, LoginRejected))
+		return "", fmt.Errorf("failed to parse login packet: %w", err)
+	}
+
+	login, err := ParseLogin(msg)
+	if err != nil {
+		stats.DecodeError()
+		code := LoginRejected
+		if errors.Is(err, ErrCrc) {
+			code = LoginCrc
+		}
+		conn.Write(EncodeAnswer(AnswerLogin, code))
+		return "", fmt.Errorf("failed to parse login packet: %w", err)
+	}
+
+	stats.PacketDecoded()
+	stats.SetProtocolVersion(login.Version)
+	if err := protocol.AuthorizeDevice(h.publisher, login.Imei); err != nil {
+		conn.Write(EncodeAnswer(AnswerLogin, LoginRejected))
+		return "", err
+	}
+	if _, err := conn.Write(EncodeAnswer(AnswerLogin, LoginOK)); err != nil {
+		return "", fmt.Errorf("failed to send login answer: %w", err)
+	}
+
+	sess := &wialonSession{
+		imei:    login.Imei,
+		withCrc: login.Version == ProtocolVersion2,
+		reader:  reader,
+		stats:   stats,
+	}
+	if id, err := strconv.ParseUint(login.Imei, 10, 32); err == nil {
+		sess.client = uint32(id)
+	}
+
+	h.sessions.Store(conn, sess)
+	return login.Imei, nil
+}
+
+// handleConnection содержит логику, специфичную для Wialon IPS, после авторизации
+func (h *WialonHandler) handleConnection(ctx context.Context, conn net.Conn, clientID string) {
+	value, ok := h.sessions.LoadAndDelete(conn)
+	if !ok {
+		logger.Errorf("Wialon session for client ID %s not found", clientID)
+		return
+	}
+	sess := value.(*wialonSession)
+
+	logger.Infof("Starting Wialon IPS data processing for client ID: %s", clientID)
+
+	// Блокирующее чтение прерываем закрытием соединения при отмене контекста
+	connCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-connCtx.Done()
+		conn.Close()
+	}()
+
+	for {
+		line, err := readLine(sess.reader)
+		if err != nil {
+			switch {
+			case ctx.Err() != nil:
+				logger.Infof("Wialon processing for client ID %s cancelled", clientID)
+			case errors.Is(err, io.EOF):
+				logger.Infof("Wialon client ID %s closed connection", clientID)
+			default:
+				logger.Errorf("Failed to read Wialon packet from client ID %s: %v", clientID, err)
+			}
+			return
+		}
+
+		msg, err := ParseMessage(line)
+		if err != nil {
+			sess.stats.DecodeError()
+			logger.Warnf("Wialon client ID %s: %v", clientID, err)
+			continue
+		}
+
+		answer, err := h.processMessage(sess, msg)
+		if err != nil {
+			// Данные не подтверждаем, трекер повторит передачу после переподключения
+			logger.Errorf("Wialon client ID %s: %v", clientID, err)
+			return
+		}
+		if answer == nil {
+			continue
+		}
+		if _, err := conn.Write(answer); err != nil {
+			logger.Errorf("Failed to send Wialon answer to client ID %s: %v", clientID, err)
+			return
+		}
+	}
+}
+
+// processMessage обрабатывает пакет и возвращает ответ сервера.
+// Ошибка возвращается только при невозможности опубликовать данные.
+func (h *WialonHandler) processMessage(sess *wialonSession, msg *Message) ([]byte, error) {
+	switch msg.Type {
+	case TypePing:
+		sess.stats.PacketDecoded()
+		return EncodeAnswer(AnswerPing, ""), nil
+
+	case TypeLogin:
+		// Повторный логин в рамках сессии просто подтверждаем
+		sess.stats.PacketDecoded()
+		return EncodeAnswer(AnswerLogin, LoginOK), nil
+
+	case TypeShortData, TypeData:
+		answerType, crcCode, parse := AnswerShortData, ShortDataCrc, ParseShortData
+		if msg.Type == TypeData {
+			answerType, crcCode, parse = AnswerData, DataCrc, ParseData
+		}
+
+		if sess.withCrc {
+			if err := msg.CheckCrc(";"); err != nil {
+				sess.stats.DecodeError()
+				logger.Warnf("Wialon client %s: %v", sess.imei, err)
+				return EncodeAnswer(answerType, crcCode), nil
+			}
+		}
+		data, err := parse(msg.Body)
+		if err != nil {
+			sess.stats.DecodeError()
+			logger.Warnf("Wialon client %s: %v", sess.imei, err)
+			return EncodeAnswer(answerType, errorCode(err)), nil
+		}
+		sess.stats.PacketDecoded()
+		if err := h.publish(sess, data); err != nil {
+			return nil, err
+		}
+		return EncodeAnswer(answerType, DataOK), nil
+
+	case TypeBlackBox:
+		if sess.withCrc {
+			if err := msg.CheckCrc("|"); err != nil {
+				sess.stats.DecodeError()
+				logger.Warnf("Wialon client %s: %v", sess.imei, err)
+				return EncodeAnswer(AnswerBlackBox, "0"), nil
+			}
+		}
+		items, err := ParseBlackBox(msg.Body)
+		if err != nil {
+			sess.stats.DecodeError()
+			logger.Warnf("Wialon client %s: black box message %d: %v", sess.imei, len(items)+1, err)
+		} else {
+			sess.stats.PacketDecoded()
+		}
+		for _, data := range items {
+			if err := h.publish(sess, data); err != nil {
+				return nil, err
+			}
+		}
+		// Подтверждаем число принятых сообщений
+		return EncodeAnswer(AnswerBlackBox, strconv.Itoa(len(items))), nil
+
+	default:
+		sess.stats.DecodeError()
+		logger.Warnf("Wialon client %s: unsupported packet type %s", sess.imei, msg.Type)
+		return nil, nil
+	}
+}
+
+// publish публикует данные одного сообщения
+func (h *WialonHandler) publish(sess *wialonSession, data *Data) error {
+	rec := data.ToNavRecord()
+	rec.Client = sess.client
+	rec.Imei = sess.imei
+	rec.ReceivedTime = time.Now().UTC()
+	if rec.NavigationTime.IsZero() {
+		// Время не передано (NA), используем время сервера
+		rec.NavigationTime = rec.ReceivedTime
+	}
+	sess.sequence++
+	rec.PacketID = sess.sequence
+
+	if err := h.publisher.Publish(rec); err != nil {
+		return fmt.Errorf("failed to publish data: %w", err)
+	}
+	sess.stats.RecordPublished(rec)
+	logger.Debugf("Wialon data for client %s published", sess.imei)
+	return nil
+}
+
+// errorCode возвращает код ответа для ошибки разбора пакета с данными
+func errorCode(err error) string {
+	var dataErr *DataError
+	if errors.As(err, &dataErr) {
+		return dataErr.Code
+	}
+	return DataStructure
+}
+
+// readLine читает один пакет, завершающийся \n
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadSlice('\n')
+	if err != nil {
+		if errors.Is(err, bufio.ErrBufferFull) {
+			return "", fmt.Errorf("packet exceeds %d bytes", maxLineLength)
+		}
+		return "", err
+	}
+	return string(line), nil
+}
@@ -0,0 +1,57 @@
+package wialon
+
+// Типы пакетов Wialon IPS
+const (
+	TypeLogin     = "L"  // пакет логина
+	TypeShortData = "SD" // сокращенный пакет с данными
+	TypeData      = "D"  // расширенный пакет с данными
+	TypeBlackBox  = "B"  // пакет с данными из "черного ящика"
+	TypePing      = "P"  // пинговый пакет
+)
+
+// Типы ответов сервера
+const (
+	AnswerLogin     = "AL"
+	AnswerShortData = "ASD"
+	AnswerData      = "AD"
+	AnswerBlackBox  = "AB"
+	AnswerPing      = "AP"
+)
+
+// Коды ответа на пакет логина
+const (
+	LoginOK       = "1"  // логин принят
+	LoginRejected = "0"  // соединение отклонено
+	LoginPassword = "01" // ошибка пароля
+	LoginCrc      = "10" // ошибка контрольной суммы
+)
+
+// Коды ответа на пакеты с данными (#SD#, #D#)
+const (
+	DataOK        = "1"  // пакет принят
+	DataStructure = "-1" // ошибка структуры пакета
+	DataTime      = "0"  // некорректное время
+	DataCoords    = "10" // ошибка получения координат
+	DataSpeed     = "11" // ошибка получения скорости, курса или высоты
+	DataSats      = "12" // ошибка получения количества спутников или HDOP
+	DataIO        = "13" // ошибка получения входов или выходов
+	DataADC       = "14" // ошибка получения АЦП
+	DataParams    = "15" // ошибка получения дополнительных параметров
+	ShortDataCrc  = "13" // ошибка контрольной суммы #SD#
+	DataCrc       = "16" // ошибка контрольной суммы #D#
+)
+
+// Типы дополнительных параметров
+const (
+	ParamInt    = "1"
+	ParamDouble = "2"
+	ParamString = "3"
+)
+
+const (
+	ProtocolVersion2 = "2.0"
+	NotAvailable     = "NA" // значение поля отсутствует
+
+	shortDataFields = 10 // число полей #SD# без CRC
+	dataFields      = 16 // число полей #D# без CRC
+)
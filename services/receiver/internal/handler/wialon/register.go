@@ -0,0 +1,14 @@
+package wialon
+
+import (
+	"github.com/rackov/NavControlSystem/services/receiver/internal/protocol"
+)
+
+func init() {
+	protocol.Register(protocol.Descriptor{
+		Name:        "WIALON",
+		Description: "Wialon IPS 1.1/2.0",
+		Options:     protocol.SessionOptions,
+		Factory:     protocol.SessionFactory(func() protocol.SessionHandler { return NewWialonHandler() }),
+	})
+}
@@ -0,0 +1,223 @@
+package wialon
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DataError ошибка разбора пакета с данными с кодом ответа сервера
+type DataError struct {
+	Code string
+	Err  error
+}
+
+func (e *DataError) Error() string {
+	return e.Err.Error()
+}
+
+func dataError(code string, format string, args ...interface{}) error {
+	return &DataError{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// Data навигационные данные пакетов #SD# и #D#
+type Data struct {
+	Time       time.Time         `json:"time"`       // время фиксации (UTC), нулевое при NA
+	Latitude   float64           `json:"lat"`        // широта в градусах, отрицательная для южной
+	Longitude  float64           `json:"lon"`        // долгота в градусах, отрицательная для западной
+	Valid      bool              `json:"valid"`      // координаты переданы
+	Speed      int               `json:"speed"`      // скорость, км/ч
+	Course     int               `json:"course"`     // курс, градусы
+	Altitude   int               `json:"altitude"`   // высота, м
+	Satellites int               `json:"satellites"` // количество спутников
+	Hdop       float64           `json:"hdop"`
+	Inputs     uint32            `json:"inputs"`  // битовая маска дискретных входов
+	Outputs    uint32            `json:"outputs"` // битовая маска дискретных выходов
+	Adc        []float64         `json:"adc"`
+	IButton    string            `json:"ibutton"`
+	Params     map[string]string `json:"params"` // дополнительные параметры name -> value
+}
+
+// ParseShortData разбирает тело пакета #SD# (без CRC):
+// Date;Time;LatDeg;LatSign;LonDeg;LonSign;Speed;Course;Alt;Sats
+func ParseShortData(body string) (*Data, error) {
+	fields := strings.Split(body, ";")
+	if len(fields) != shortDataFields {
+		return nil, dataError(DataStructure, "неверное число полей #SD#: %d", len(fields))
+	}
+
+	d := &Data{}
+	if err := d.decodeShort(fields); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// ParseData разбирает тело пакета #D# (без CRC):
+// Date;Time;LatDeg;LatSign;LonDeg;LonSign;Speed;Course;Alt;Sats;HDOP;Inputs;Outputs;ADC;Ibutton;Params
+func ParseData(body string) (*Data, error) {
+	fields := strings.Split(body, ";")
+	if len(fields) != dataFields {
+		return nil, dataError(DataStructure, "неверное число полей #D#: %d", len(fields))
+	}
+
+	d := &Data{}
+	if err := d.decodeShort(fields[:shortDataFields]); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if d.Hdop, err = parseFloat(fields[10]); err != nil {
+		return nil, dataError(DataSats, "не удалось получить HDOP: %v", err)
+	}
+	if d.Inputs, err = parseUint32(fields[11]); err != nil {
+		return nil, dataError(DataIO, "не удалось получить входы: %v", err)
+	}
+	if d.Outputs, err = parseUint32(fields[12]); err != nil {
+		return nil, dataError(DataIO, "не удалось получить выходы: %v", err)
+	}
+
+	if fields[13] != "" && fields[13] != NotAvailable {
+		for _, v := range strings.Split(fields[13], ",") {
+			adc, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, dataError(DataADC, "не удалось получить АЦП: %v", err)
+			}
+			d.Adc = append(d.Adc, adc)
+		}
+	}
+
+	if fields[14] != NotAvailable {
+		d.IButton = fields[14]
+	}
+
+	if fields[15] != "" && fields[15] != NotAvailable {
+		d.Params = make(map[string]string)
+		for _, p := range strings.Split(fields[15], ",") {
+			parts := strings.SplitN(p, ":", 3)
+			if len(parts) != 3 || parts[0] == "" {
+				return nil, dataError(DataParams, "неверный формат параметра: %q", p)
+			}
+			switch parts[1] {
+			case ParamInt:
+				_, err = strconv.ParseInt(parts[2], 10, 64)
+			case ParamDouble:
+				_, err = strconv.ParseFloat(parts[2], 64)
+			case ParamString:
+				err = nil
+			default:
+				err = fmt.Errorf("неизвестный тип %s", parts[1])
+			}
+			if err != nil {
+				return nil, dataError(DataParams, "неверное значение параметра %s: %v", parts[0], err)
+			}
+			d.Params[parts[0]] = parts[2]
+		}
+	}
+
+	return d, nil
+}
+
+// ParseBlackBox разбирает тело пакета #B# (без CRC) на отдельные сообщения
+// #SD# или #D#, тип каждого определяется числом полей
+func ParseBlackBox(body string) ([]*Data, error) {
+	var result []*Data
+	for _, item := range strings.Split(body, "|") {
+		var (
+			d   *Data
+			err error
+		)
+		switch strings.Count(item, ";") + 1 {
+		case shortDataFields:
+			d, err = ParseShortData(item)
+		case dataFields:
+			d, err = ParseData(item)
+		default:
+			err = dataError(DataStructure, "неверное число полей сообщения черного ящика")
+		}
+		if err != nil {
+			return result, err
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+func (d *Data) decodeShort(fields []string) error {
+	var err error
+
+	if fields[0] != NotAvailable && fields[1] != NotAvailable {
+		if d.Time, err = time.Parse("020106150405", fields[0]+fields[1]); err != nil {
+			return dataError(DataTime, "не удалось получить время: %v", err)
+		}
+	}
+
+	if fields[2] != NotAvailable && fields[4] != NotAvailable {
+		if d.Latitude, err = parseCoord(fields[2], fields[3], "N", "S"); err != nil {
+			return dataError(DataCoords, "не удалось получить широту: %v", err)
+		}
+		if d.Longitude, err = parseCoord(fields[4], fields[5], "E", "W"); err != nil {
+			return dataError(DataCoords, "не удалось получить долготу: %v", err)
+		}
+		d.Valid = true
+	}
+
+	if d.Speed, err = parseInt(fields[6]); err != nil {
+		return dataError(DataSpeed, "не удалось получить скорость: %v", err)
+	}
+	if d.Course, err = parseInt(fields[7]); err != nil {
+		return dataError(DataSpeed, "не удалось получить курс: %v", err)
+	}
+	if d.Altitude, err = parseInt(fields[8]); err != nil {
+		return dataError(DataSpeed, "не удалось получить высоту: %v", err)
+	}
+	if d.Satellites, err = parseInt(fields[9]); err != nil {
+		return dataError(DataSats, "не удалось получить количество спутников: %v", err)
+	}
+
+	return nil
+}
+
+// parseCoord переводит координату формата (D)DDMM.MMMM в градусы
+func parseCoord(value, sign, positive, negative string) (float64, error) {
+	raw, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	deg := math.Floor(raw / 100)
+	coord := deg + (raw-deg*100)/60
+
+	switch sign {
+	case positive:
+	case negative:
+		coord = -coord
+	default:
+		return 0, fmt.Errorf("неизвестное полушарие %q", sign)
+	}
+	return coord, nil
+}
+
+func parseInt(value string) (int, error) {
+	if value == NotAvailable {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	return int(math.Round(v)), err
+}
+
+func parseUint32(value string) (uint32, error) {
+	if value == NotAvailable {
+		return 0, nil
+	}
+	v, err := strconv.ParseUint(value, 10, 32)
+	return uint32(v), err
+}
+
+func parseFloat(value string) (float64, error) {
+	if value == NotAvailable {
+		return 0, nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
@@ -0,0 +1,86 @@
+package wialon
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testShortData = "220925;101530;5544.6025;N;03739.6834;E;60;270;150;12"
+	testData      = testShortData + ";0.9;5;1;12.5,3300;NA;fuel:2:45.5,driver:3:Ivanov"
+)
+
+func TestParseShortData(t *testing.T) {
+	d, err := ParseShortData(testShortData)
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Date(2025, 9, 22, 10, 15, 30, 0, time.UTC), d.Time)
+		assert.InDelta(t, 55.743375, d.Latitude, 1e-6)
+		assert.InDelta(t, 37.66139, d.Longitude, 1e-6)
+		assert.True(t, d.Valid)
+		assert.Equal(t, 60, d.Speed)
+		assert.Equal(t, 270, d.Course)
+		assert.Equal(t, 150, d.Altitude)
+		assert.Equal(t, 12, d.Satellites)
+	}
+}
+
+func TestParseShortData_Errors(t *testing.T) {
+	tests := map[string]string{
+		"NA;NA":                                 DataStructure,
+		"320925;101530;NA;NA;NA;NA;NA;NA;NA;NA": DataTime,
+		"NA;NA;5544.6025;X;03739.6834;E;NA;NA;NA;NA": DataCoords,
+		"NA;NA;NA;NA;NA;NA;fast;NA;NA;NA":            DataSpeed,
+	}
+	for body, code := range tests {
+		_, err := ParseShortData(body)
+		var dataErr *DataError
+		if assert.True(t, errors.As(err, &dataErr), body) {
+			assert.Equal(t, code, dataErr.Code, body)
+		}
+	}
+}
+
+func TestParseData(t *testing.T) {
+	d, err := ParseData(testData)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 0.9, d.Hdop)
+		assert.Equal(t, uint32(5), d.Inputs)
+		assert.Equal(t, uint32(1), d.Outputs)
+		assert.Equal(t, []float64{12.5, 3300}, d.Adc)
+		assert.Equal(t, "", d.IButton)
+		assert.Equal(t, map[string]string{"fuel": "45.5", "driver": "Ivanov"}, d.Params)
+	}
+
+	_, err = ParseData(testShortData + ";NA;NA;NA;NA;NA;fuel:7:1")
+	assert.Equal(t, DataParams, errorCode(err))
+}
+
+func TestParseBlackBox(t *testing.T) {
+	items, err := ParseBlackBox(testShortData + "|" + testData)
+	if assert.NoError(t, err) && assert.Len(t, items, 2) {
+		assert.Nil(t, items[0].Params)
+		assert.Equal(t, "Ivanov", items[1].Params["driver"])
+	}
+
+	items, err = ParseBlackBox(testShortData + "|bad")
+	assert.Error(t, err)
+	assert.Len(t, items, 1)
+}
+
+func TestData_ToNavRecord(t *testing.T) {
+	d, _ := ParseData("220925;101530;3345.0000;S;07030.0000;W;60;270;150;12;0.9;5;1;12.5;NA;fuel:2:45.5")
+
+	rec := d.ToNavRecord()
+	assert.InDelta(t, -33.75, rec.Latitude, 1e-9)
+	assert.InDelta(t, -70.5, rec.Longitude, 1e-9)
+	assert.True(t, rec.Valid)
+	assert.Equal(t, 150.0, rec.Altitude)
+	assert.Equal(t, 0.9, rec.Hdop)
+	assert.Equal(t, uint32(5), rec.DigitalInputs)
+	assert.Equal(t, uint32(1), rec.DigitalOutputs)
+	assert.Equal(t, 12.5, rec.Sensors["ain1"])
+	assert.Equal(t, "45.5", rec.Params["fuel"])
+}
@@ -0,0 +1,100 @@
+package wialon
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrCrc ошибка проверки контрольной суммы пакета
+var ErrCrc = errors.New("неверная контрольная сумма")
+
+// Message пакет Wialon IPS вида #TYPE#BODY без завершающих \r\n
+type Message struct {
+	Type string `json:"type"`
+	Body string `json:"body"`
+}
+
+// ParseMessage разбирает строку пакета на тип и тело
+func ParseMessage(line string) (*Message, error) {
+	line = strings.TrimRight(line, "\r\n")
+	if !strings.HasPrefix(line, "#") {
+		return nil, fmt.Errorf("пакет должен начинаться с #: %q", line)
+	}
+	end := strings.Index(line[1:], "#")
+	if end < 0 {
+		return nil, fmt.Errorf("не найден тип пакета: %q", line)
+	}
+
+	return &Message{
+		Type: line[1 : end+1],
+		Body: line[end+2:],
+	}, nil
+}
+
+// CheckCrc отделяет от тела CRC16 (последнее поле после sep) и проверяет ее.
+// Контрольная сумма считается по телу пакета вместе с последним разделителем.
+func (m *Message) CheckCrc(sep string) error {
+	idx := strings.LastIndex(m.Body, sep)
+	if idx < 0 {
+		return fmt.Errorf("%w: поле не найдено", ErrCrc)
+	}
+
+	crc, err := strconv.ParseUint(m.Body[idx+1:], 16, 16)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCrc, err)
+	}
+	if calc := crc16([]byte(m.Body[:idx+1])); calc != uint16(crc) {
+		return fmt.Errorf("%w: %X, подсчитано %X", ErrCrc, crc, calc)
+	}
+
+	m.Body = m.Body[:idx]
+	return nil
+}
+
+// Encode кодирует пакет в строку протокола, при withCrc добавляя контрольную сумму
+func (m *Message) Encode(sep string, withCrc bool) []byte {
+	body := m.Body
+	if withCrc {
+		body += sep
+		body += fmt.Sprintf("%04X", crc16([]byte(body)))
+	}
+	return []byte("#" + m.Type + "#" + body + "\r\n")
+}
+
+// EncodeAnswer формирует ответ сервера, например #AD#1
+func EncodeAnswer(answerType string, code string) []byte {
+	return []byte("#" + answerType + "#" + code + "\r\n")
+}
+
+// Login данные пакета #L#
+type Login struct {
+	Version  string `json:"version"`
+	Imei     string `json:"imei"`
+	Password string `json:"password"`
+}
+
+// ParseLogin разбирает пакет логина версий 1.1 (IMEI;Password) и 2.0 (2.0;IMEI;Password;CRC16)
+func ParseLogin(m *Message) (*Login, error) {
+	if m.Type != TypeLogin {
+		return nil, fmt.Errorf("ожидался пакет логина, получен %s", m.Type)
+	}
+
+	if strings.HasPrefix(m.Body, ProtocolVersion2+";") {
+		if err := m.CheckCrc(";"); err != nil {
+			return nil, err
+		}
+		fields := strings.Split(m.Body, ";")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("неверное число полей пакета логина: %d", len(fields))
+		}
+		return &Login{Version: fields[0], Imei: fields[1], Password: fields[2]}, nil
+	}
+
+	fields := strings.Split(m.Body, ";")
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("неверное число полей пакета логина: %d", len(fields))
+	}
+	return &Login{Version: "1.1", Imei: fields[0], Password: fields[1]}, nil
+}
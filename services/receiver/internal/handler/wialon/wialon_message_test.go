@@ -0,0 +1,51 @@
+package wialon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMessage(t *testing.T) {
+	msg, err := ParseMessage("#SD#NA;NA;NA;NA;NA;NA;NA;NA;NA;NA\r\n")
+	if assert.NoError(t, err) {
+		assert.Equal(t, TypeShortData, msg.Type)
+		assert.Equal(t, "NA;NA;NA;NA;NA;NA;NA;NA;NA;NA", msg.Body)
+	}
+
+	_, err = ParseMessage("SD#1;2\r\n")
+	assert.Error(t, err)
+}
+
+func TestMessage_CheckCrc(t *testing.T) {
+	raw := (&Message{Type: TypePing, Body: "2.0;861230043907626;NA"}).Encode(";", true)
+
+	msg, err := ParseMessage(string(raw))
+	if assert.NoError(t, err) && assert.NoError(t, msg.CheckCrc(";")) {
+		assert.Equal(t, "2.0;861230043907626;NA", msg.Body)
+	}
+
+	msg, _ = ParseMessage("#L#2.0;861230043907626;NA;0000\r\n")
+	assert.ErrorIs(t, msg.CheckCrc(";"), ErrCrc)
+}
+
+func TestParseLogin(t *testing.T) {
+	raw := (&Message{Type: TypeLogin, Body: "2.0;861230043907626;NA"}).Encode(";", true)
+	msg, _ := ParseMessage(string(raw))
+
+	login, err := ParseLogin(msg)
+	if assert.NoError(t, err) {
+		assert.Equal(t, &Login{Version: "2.0", Imei: "861230043907626", Password: "NA"}, login)
+	}
+
+	msg, _ = ParseMessage("#L#861230043907626;pass\r\n")
+	login, err = ParseLogin(msg)
+	if assert.NoError(t, err) {
+		assert.Equal(t, &Login{Version: "1.1", Imei: "861230043907626", Password: "pass"}, login)
+	}
+}
+
+func TestEncodeAnswer(t *testing.T) {
+	assert.Equal(t, []byte("#AD#1\r\n"), EncodeAnswer(AnswerData, DataOK))
+	assert.Equal(t, []byte("#AP#\r\n"), EncodeAnswer(AnswerPing, ""))
+}
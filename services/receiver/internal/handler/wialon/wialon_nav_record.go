@@ -0,0 +1,44 @@
+package wialon
+
+import (
+	"github.com/rackov/NavControlSystem/pkg/models"
+)
+
+// ToNavRecord переводит данные пакета в навигационную запись.
+// Wialon IPS передает значения в физических единицах, поэтому они переносятся как есть;
+// значения ADC становятся датчиками ain1, ain2, ...
+func (d *Data) ToNavRecord() *models.NavRecord {
+	rec := &models.NavRecord{
+		Speed:          float64(d.Speed),
+		Course:         uint16(d.Course),
+		Satellites:     uint8(d.Satellites),
+		Hdop:           d.Hdop,
+		DigitalInputs:  d.Inputs,
+		DigitalOutputs: d.Outputs,
+	}
+
+	if !d.Time.IsZero() {
+		rec.NavigationTime = d.Time.UTC()
+	}
+
+	if d.Valid {
+		rec.Valid = true
+		rec.Latitude = d.Latitude
+		rec.Longitude = d.Longitude
+		rec.Altitude = float64(d.Altitude)
+		rec.AltitudeValid = true
+	}
+
+	for i, v := range d.Adc {
+		rec.SetSensor(models.SensorName(models.SensorAnalogInput, i+1), v)
+	}
+
+	for k, v := range d.Params {
+		rec.SetParam(k, v)
+	}
+	if d.IButton != "" {
+		rec.SetParam("ibutton", d.IButton)
+	}
+
+	return rec
+}
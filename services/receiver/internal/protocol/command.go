@@ -0,0 +1,134 @@
+package protocol
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/rackov/NavControlSystem/pkg/models"
+)
+
+var (
+	// ErrDeviceNotConnected - устройство не подключено к порту
+	ErrDeviceNotConnected = errors.New("device is not connected")
+	// ErrCommandNotSupported - протокол не умеет передавать команду этого типа
+	ErrCommandNotSupported = errors.New("command is not supported by protocol")
+	// ErrTooManyCommands - все номера команд подключения заняты командами, ждущими ответа
+	ErrTooManyCommands = errors.New("too many commands waiting for reply")
+)
+
+// CommandSender реализуют обработчики протоколов, которые умеют передавать команды устройствам.
+type CommandSender interface {
+	// SendCommand передает команду подключенному устройству и ждет его ответа до отмены ctx.
+	// Возвращает ErrDeviceNotConnected, если устройство не подключено к порту, и
+	// ErrCommandNotSupported, если протокол не поддерживает команду. Отказ устройства
+	// и отсутствие ответа - не ошибки, а статусы результата.
+	SendCommand(ctx context.Context, cmd *models.Command) (*models.CommandResult, error)
+}
+
+// CommandReply - ответ устройства на команду
+type CommandReply struct {
+	Status  string // статус результата (models.CommandDelivered, ...)
+	Code    uint32 // код ответа в терминах протокола
+	Message string // описание ошибки
+}
+
+// PendingCommands связывает команды, отправленные по одному подключению, с ответами устройства.
+// Ключ - номер команды в протоколе (номер посылки Arnavi, CID EGTS).
+type PendingCommands struct {
+	mu      sync.Mutex
+	last    uint32
+	waiters map[uint32]chan CommandReply
+}
+
+// Add резервирует номер команды и возвращает канал, в который придет ответ.
+// Номер выбирает next по предыдущему номеру, занятые номера пропускаются.
+// Ответ нужно дождаться через Wait, который освобождает номер, или освободить его через Remove.
+func (p *PendingCommands) Add(next func(prev uint32) uint32) (uint32, <-chan CommandReply, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.waiters == nil {
+		p.waiters = make(map[uint32]chan CommandReply)
+	}
+	id := next(p.last)
+	for i := 0; i < len(p.waiters); i++ {
+		if _, busy := p.waiters[id]; !busy {
+			break
+		}
+		id = next(id)
+	}
+	if _, busy := p.waiters[id]; busy {
+		return 0, nil, ErrTooManyCommands
+	}
+	p.last = id
+
+	ch := make(chan CommandReply, 1)
+	p.waiters[id] = ch
+	return id, ch, nil
+}
+
+// Resolve передает ответ устройства на команду id. Возвращает false, если такой команды не ждут.
+func (p *PendingCommands) Resolve(id uint32, reply CommandReply) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ch, ok := p.waiters[id]
+	if !ok {
+		return false
+	}
+	delete(p.waiters, id)
+	ch <- reply
+	return true
+}
+
+// Has проверяет, ждет ли подключение ответа на команду id
+func (p *PendingCommands) Has(id uint32) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.waiters[id]
+	return ok
+}
+
+// Wait ждет ответа на команду id до отмены ctx. Если ответа нет, номер освобождается,
+// а результат получает статус models.CommandTimeout.
+func (p *PendingCommands) Wait(ctx context.Context, id uint32, ch <-chan CommandReply) CommandReply {
+	select {
+	case reply := <-ch:
+		return reply
+	case <-ctx.Done():
+		p.Remove(id)
+		// ответ мог прийти одновременно с отменой
+		select {
+		case reply := <-ch:
+			return reply
+		default:
+		}
+		return CommandReply{Status: models.CommandTimeout, Message: "no reply from device"}
+	}
+}
+
+// Remove освобождает номер команды, ответа на которую больше не ждут
+func (p *PendingCommands) Remove(id uint32) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.waiters, id)
+}
+
+// Fail завершает все ожидающие команды ошибкой. Вызывается при закрытии подключения.
+func (p *PendingCommands) Fail(message string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for id, ch := range p.waiters {
+		delete(p.waiters, id)
+		ch <- CommandReply{Status: models.CommandFailed, Message: message}
+	}
+}
+
+// Len возвращает число команд, ожидающих ответа
+func (p *PendingCommands) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.waiters)
+}
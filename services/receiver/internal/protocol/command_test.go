@@ -0,0 +1,68 @@
+package protocol
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// nextByte перебирает номера 1..3
+func nextByte(prev uint32) uint32 {
+	return prev%3 + 1
+}
+
+func TestPendingCommands_Add(t *testing.T) {
+	p := &PendingCommands{}
+
+	id1, _, err := p.Add(nextByte)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1), id1)
+
+	id2, _, _ := p.Add(nextByte)
+	id3, _, _ := p.Add(nextByte)
+	assert.Equal(t, []uint32{2, 3}, []uint32{id2, id3})
+
+	// все номера заняты
+	_, _, err = p.Add(nextByte)
+	assert.ErrorIs(t, err, ErrTooManyCommands)
+
+	// освободившийся номер используется снова, занятые пропускаются
+	assert.True(t, p.Resolve(2, CommandReply{Status: models.CommandDelivered}))
+	id, _, err := p.Add(nextByte)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2), id)
+	assert.Equal(t, 3, p.Len())
+}
+
+func TestPendingCommands_Wait(t *testing.T) {
+	p := &PendingCommands{}
+
+	id, ch, _ := p.Add(nextByte)
+	go p.Resolve(id, CommandReply{Status: models.CommandFailed, Code: 5})
+	reply := p.Wait(context.Background(), id, ch)
+	assert.Equal(t, CommandReply{Status: models.CommandFailed, Code: 5}, reply)
+	assert.False(t, p.Has(id))
+
+	// ответа нет - таймаут, номер освобождается
+	id, ch, _ = p.Add(nextByte)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	reply = p.Wait(ctx, id, ch)
+	assert.Equal(t, models.CommandTimeout, reply.Status)
+	assert.False(t, p.Has(id))
+	assert.False(t, p.Resolve(id, CommandReply{Status: models.CommandDelivered}))
+}
+
+func TestPendingCommands_Fail(t *testing.T) {
+	p := &PendingCommands{}
+
+	id, ch, _ := p.Add(nextByte)
+	p.Fail("connection closed")
+	reply := p.Wait(context.Background(), id, ch)
+	assert.Equal(t, models.CommandFailed, reply.Status)
+	assert.Equal(t, "connection closed", reply.Message)
+	assert.Equal(t, 0, p.Len())
+}
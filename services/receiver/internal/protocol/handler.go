@@ -2,83 +2,95 @@ package protocol
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/models"
 )
 
-type NavRecord struct {
-	Client              uint32       `json:"tid"`
-	PacketID            uint32       `json:"pk_id"`
-	NavigationTimestamp uint32       `json:"nav_time"`
-	ReceivedTimestamp   uint32       `json:"rec_time"`
-	Latitude            uint32       `json:"lat"`
-	Longitude           uint32       `json:"lng"`
-	Speed               uint16       `json:"speed"`
-	FlagPos             byte         `json:"flag"`
-	Pdop                uint16       `json:"pdop"`
-	Hdop                uint16       `json:"hdop"`
-	Vdop                uint16       `json:"vdop"`
-	Nsat                uint8        `json:"nsat"`
-	Ns                  uint16       `json:"ns"`
-	DigInput            byte         `json:"din"`
-	Odometer            uint32       `json:"odm"`
-	Course              uint8        `json:"course"`
-	Imei                string       `json:"imei"`
-	Imsi                string       `json:"imsi"`
-	AnSenAbs            []Sensor     `json:"in_abs_in"`  // одного аналогового входа
-	DigSenAbs           []DiSensor   `json:"in_abs_dig"` // одного дискретного входа
-	AnSensors           []DopAnIn    `json:"in_an"`      // дополнительных аналоговых входов
-	DigSenonrs          []DopDigIn   `json:"in_dig"`     // дополнительных дискретного входа
-	DigSenOuts          []int        `json:"out_dig"`    // дополнительных дискретного выхода
-	LiquidSensors       LiquidSensor `json:"sn_liq"`     // данных о показаниях ДУТ
+// DataPublisher - интерфейс для публикации данных (будет реализован основным сервисом).
+// Каждый порт получает свой экземпляр, который заполняет в записи Protocol и PortID:
+// по ним и по ID устройства выбирается топик NATS.
+type DataPublisher interface {
+	Publish(data *models.NavRecord) error
+	IsConnected() bool
 }
 
-func (eep *NavRecord) ToBytes() ([]byte, error) {
-	return json.Marshal(eep)
+// SessionObserver может реализовать DataPublisher порта, чтобы узнавать об авторизации устройств
+// (например, чтобы передать им команды, ожидавшие подключения).
+type SessionObserver interface {
+	// DeviceAuthorized вызывается после авторизации устройства. ids - идентификаторы устройства
+	// (ID, IMEI), по которым ему адресуют команды. Вызов не должен блокировать обработчик.
+	DeviceAuthorized(ids ...string)
 }
 
-type DopAnIn struct {
-	Asfe byte      `json:"asfe"`
-	Ansi [8]uint32 `json:"ansi"`
+// NotifyAuthorized сообщает publisher об авторизации устройства, если он реализует SessionObserver
+func NotifyAuthorized(publisher DataPublisher, ids ...string) {
+	if observer, ok := publisher.(SessionObserver); ok {
+		observer.DeviceAuthorized(ids...)
+	}
 }
 
-type DopDigIn struct {
-	Dioe byte    `json:"dioe"`
-	Adio [8]byte `json:"adio"`
+// ErrDeviceDenied - устройство не допущено к порту (нет в реестре, отключено, чужой протокол)
+var ErrDeviceDenied = errors.New("device denied")
+
+// DeviceAuthorizer может реализовать DataPublisher порта, чтобы проверять устройства по реестру
+type DeviceAuthorizer interface {
+	// AuthorizeDevice возвращает ошибку, если устройство с идентификаторами ids (ID, IMEI)
+	// не допущено к порту
+	AuthorizeDevice(ids ...string) error
 }
 
-type DiSensor struct {
-	StateNumber byte `json:"st_num"`
-	Number      byte `json:"num"`
+// AuthorizeDevice проверяет устройство, если publisher реализует DeviceAuthorizer.
+// Ошибка оборачивает ErrDeviceDenied: обработчик отказывает устройству в авторизации.
+func AuthorizeDevice(publisher DataPublisher, ids ...string) error {
+	authorizer, ok := publisher.(DeviceAuthorizer)
+	if !ok {
+		return nil
+	}
+	if err := authorizer.AuthorizeDevice(ids...); err != nil {
+		return fmt.Errorf("%w: %v", ErrDeviceDenied, err)
+	}
+	return nil
 }
 
-type LiquidSensor struct {
-	FlagLiqNum uint8     `json:"fl_ln"`
-	Value      [8]uint32 `json:"val_l"`
+// ClientInfo содержит информацию о подключенном клиенте
+type ClientInfo struct {
+	ID    string    // ID устройства (например, из EGTS)
+	Addr  string    // Сетевой адрес клиента (IP:Port)
+	Net   string    // Транспорт подключения: tcp или udp
+	Since time.Time // Время подключения
+	Stats Stats     // Статистика подключения
 }
 
-type Sensor struct {
-	SensorNumber uint8  `json:"sn_n"`
-	Value        uint32 `json:"val"`
+// BanInfo описывает заблокированный IP-адрес
+type BanInfo struct {
+	IP       string    // IP-адрес
+	Until    time.Time // время окончания блокировки
+	Failures int       // неудачных авторизаций, после которых IP заблокирован
 }
 
-type NavRecords struct {
-	PacketType int16       `json:"packet_type"`
-	PacketID   uint32      `json:"packet_id"`
-	RecNav     []NavRecord `json:"record"`
+// CaptureConfig - запись сырого трафика сессий порта (см. internal/capture).
+// Применяется к сессиям, открытым после изменения.
+type CaptureConfig struct {
+	Dir      string            // каталог файлов записи
+	Protocol string            // имя протокола порта, для воспроизведения
+	PortID   string            // ID порта
+	Options  map[string]string // параметры порта, для воспроизведения
+	All      bool              // записывать все сессии порта
+	Devices  map[string]bool   // записывать сессии устройств с этими ID
 }
 
-// DataPublisher - интерфейс для публикации данных (будет реализован основным сервисом)
-type DataPublisher interface {
-	Publish(data *NavRecord) error
-	IsConnected() bool
+// Enabled возвращает true, если запись включена для порта или хотя бы одного устройства
+func (c CaptureConfig) Enabled() bool {
+	return c.Dir != "" && (c.All || len(c.Devices) > 0)
 }
 
-// ClientInfo содержит информацию о подключенном клиенте
-type ClientInfo struct {
-	ID    string    // ID устройства (например, из EGTS)
-	Addr  string    // Сетевой адрес клиента (IP:Port)
-	Since time.Time // Время подключения
+// Captures возвращает true, если сессию устройства deviceID нужно записать.
+// deviceID пустой - устройство не авторизовалось.
+func (c CaptureConfig) Captures(deviceID string) bool {
+	return c.Dir != "" && (c.All || (deviceID != "" && c.Devices[deviceID]))
 }
 
 // ProtocolHandler - интерфейс, который должен реализовать каждый обработчик протокола
@@ -102,4 +114,22 @@ type ProtocolHandler interface {
 
 	// DisconnectClient отключает конкретного клиента по его сетевому адресу
 	DisconnectClient(clientAddr string) error
+
+	// GetClient возвращает информацию о подключении устройства по его ID
+	GetClient(deviceID string) (ClientInfo, bool)
+
+	// DisconnectDevice отключает устройство по его ID
+	DisconnectDevice(deviceID string) error
+
+	// GetPortStats возвращает статистику порта с момента его открытия, включая закрытые подключения
+	GetPortStats() Stats
+
+	// ListBans возвращает IP-адреса, заблокированные на порту
+	ListBans() []BanInfo
+
+	// Unban снимает блокировку IP-адреса, false - IP не был заблокирован
+	Unban(ip string) bool
+
+	// SetCapture включает или выключает запись трафика сессий порта
+	SetCapture(cfg CaptureConfig)
 }
@@ -0,0 +1,229 @@
+package protocol
+
+import (
+	"crypto/tls"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OptAuthTimeout - общий для всех протоколов параметр: время ожидания авторизации устройства
+const OptAuthTimeout = "auth_timeout"
+
+// Транспорт порта
+const (
+	TransportTCP = "tcp" // по умолчанию
+	TransportUDP = "udp" // датаграммы, только для протоколов с Descriptor.UDP
+)
+
+// OptionSchema описывает один параметр конфигурации порта, который понимает протокол
+type OptionSchema struct {
+	Name        string
+	Description string
+	Default     string // значение по умолчанию, пустое - параметр обязателен
+	Optional    bool   // параметр без значения по умолчанию можно не задавать
+	Validate    func(value string) error
+}
+
+// AuthTimeoutOption - описание параметра auth_timeout со значением по умолчанию 30s
+var AuthTimeoutOption = OptionSchema{
+	Name:        OptAuthTimeout,
+	Description: "время ожидания авторизации устройства (например, 30s)",
+	Default:     "30s",
+	Validate:    ValidateDuration,
+}
+
+// ValidateDuration проверяет, что значение - положительная длительность в формате time.ParseDuration
+func ValidateDuration(value string) error {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	if d <= 0 {
+		return fmt.Errorf("duration must be positive: %s", value)
+	}
+	return nil
+}
+
+// ValidateInt проверяет, что значение - целое число
+func ValidateInt(value string) error {
+	_, err := strconv.Atoi(value)
+	return err
+}
+
+// HandlerConfig - параметры порта, передаваемые фабрике обработчика
+type HandlerConfig struct {
+	PortID    string
+	Port      int
+	Options   map[string]string // параметры протокола, дополненные значениями по умолчанию
+	TLS       *tls.Config       // настройки TLS порта, nil - подключения без шифрования
+	Transport string            // TransportTCP или TransportUDP, пусто - TCP
+}
+
+// Duration возвращает параметр name как длительность
+func (c HandlerConfig) Duration(name string) (time.Duration, error) {
+	d, err := time.ParseDuration(c.Options[name])
+	if err != nil {
+		return 0, fmt.Errorf("option %s: %w", name, err)
+	}
+	return d, nil
+}
+
+// Int возвращает параметр name как целое число
+func (c HandlerConfig) Int(name string) (int, error) {
+	v, err := strconv.Atoi(c.Options[name])
+	if err != nil {
+		return 0, fmt.Errorf("option %s: %w", name, err)
+	}
+	return v, nil
+}
+
+// HandlerFactory создает обработчик протокола для одного порта
+type HandlerFactory func(cfg HandlerConfig) (ProtocolHandler, error)
+
+// Descriptor описывает зарегистрированный протокол
+type Descriptor struct {
+	Name        string
+	Description string
+	Options     []OptionSchema
+	UDP         bool // протокол допускает транспорт UDP (пакет целиком в одной датаграмме)
+	Factory     HandlerFactory
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Descriptor)
+)
+
+// Register регистрирует протокол. Вызывается из init() пакета обработчика.
+// Повторная регистрация имени - ошибка программиста, поэтому паникуем.
+func Register(d Descriptor) {
+	name := strings.ToUpper(d.Name)
+	if name == "" || d.Factory == nil {
+		panic("protocol: Register requires name and factory")
+	}
+	d.Name = name
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, dup := registry[name]; dup {
+		panic("protocol: Register called twice for " + name)
+	}
+	registry[name] = d
+}
+
+// Lookup возвращает описание протокола по имени (без учета регистра)
+func Lookup(name string) (Descriptor, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	d, ok := registry[strings.ToUpper(name)]
+	return d, ok
+}
+
+// IsRegistered проверяет, зарегистрирован ли протокол
+func IsRegistered(name string) bool {
+	_, ok := Lookup(name)
+	return ok
+}
+
+// Protocols возвращает описания всех зарегистрированных протоколов, отсортированные по имени
+func Protocols() []Descriptor {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	list := make([]Descriptor, 0, len(registry))
+	for _, d := range registry {
+		list = append(list, d)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// ResolveOptions проверяет параметры порта по схеме протокола и дополняет их значениями по умолчанию
+func (d Descriptor) ResolveOptions(options map[string]string) (map[string]string, error) {
+	known := make(map[string]OptionSchema, len(d.Options))
+	for _, opt := range d.Options {
+		known[opt.Name] = opt
+	}
+	for name := range options {
+		if _, ok := known[name]; !ok {
+			return nil, fmt.Errorf("protocol %s has no option %q", d.Name, name)
+		}
+	}
+
+	resolved := make(map[string]string, len(d.Options))
+	for _, opt := range d.Options {
+		value, ok := options[opt.Name]
+		if !ok {
+			if opt.Optional && opt.Default == "" {
+				continue
+			}
+			if opt.Default == "" {
+				return nil, fmt.Errorf("protocol %s requires option %q", d.Name, opt.Name)
+			}
+			value = opt.Default
+		}
+		if opt.Validate != nil {
+			if err := opt.Validate(value); err != nil {
+				return nil, fmt.Errorf("invalid value of option %q for protocol %s: %w", opt.Name, d.Name, err)
+			}
+		}
+		resolved[opt.Name] = value
+	}
+	return resolved, nil
+}
+
+// ValidatePort проверяет имя протокола и его параметры, не создавая обработчик
+func ValidatePort(name string, options map[string]string) error {
+	d, ok := Lookup(name)
+	if !ok {
+		return fmt.Errorf("unknown protocol: %s", name)
+	}
+	_, err := d.ResolveOptions(options)
+	return err
+}
+
+// ValidateTransport проверяет, что протокол name поддерживает транспорт порта
+func ValidateTransport(name, transport string) error {
+	d, ok := Lookup(name)
+	if !ok {
+		return fmt.Errorf("unknown protocol: %s", name)
+	}
+	return d.checkTransport(transport)
+}
+
+func (d Descriptor) checkTransport(transport string) error {
+	switch transport {
+	case "", TransportTCP:
+		return nil
+	case TransportUDP:
+		if !d.UDP {
+			return fmt.Errorf("protocol %s does not support transport udp", d.Name)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown transport %q (tcp, udp)", transport)
+	}
+}
+
+// NewHandler создает обработчик зарегистрированного протокола name для порта cfg
+func NewHandler(name string, cfg HandlerConfig) (ProtocolHandler, error) {
+	d, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown protocol: %s", name)
+	}
+	if err := d.checkTransport(cfg.Transport); err != nil {
+		return nil, err
+	}
+	options, err := d.ResolveOptions(cfg.Options)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Options = options
+	return d.Factory(cfg)
+}
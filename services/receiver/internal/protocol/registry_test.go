@@ -0,0 +1,97 @@
+package protocol
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubHandler struct {
+	cfg HandlerConfig
+}
+
+func (h *stubHandler) Start(ctx context.Context, publisher DataPublisher, port int) error { return nil }
+func (h *stubHandler) Stop() error                                                        { return nil }
+func (h *stubHandler) GetName() string                                                    { return "STUB" }
+func (h *stubHandler) IsRunning() bool                                                    { return false }
+func (h *stubHandler) GetActiveConnectionsCount() int                                     { return 0 }
+func (h *stubHandler) GetConnectedClients() []ClientInfo                                  { return nil }
+func (h *stubHandler) DisconnectClient(clientAddr string) error                           { return nil }
+func (h *stubHandler) GetClient(deviceID string) (ClientInfo, bool)                       { return ClientInfo{}, false }
+func (h *stubHandler) DisconnectDevice(deviceID string) error                             { return nil }
+func (h *stubHandler) GetPortStats() Stats                                                { return Stats{} }
+func (h *stubHandler) ListBans() []BanInfo                                                { return nil }
+func (h *stubHandler) Unban(ip string) bool                                               { return false }
+func (h *stubHandler) SetCapture(cfg CaptureConfig)                                       {}
+
+func init() {
+	Register(Descriptor{
+		Name: "stub",
+		Options: []OptionSchema{
+			AuthTimeoutOption,
+			{Name: "mode", Description: "обязательный параметр"},
+			{Name: "note", Description: "необязательный параметр", Optional: true},
+		},
+		Factory: func(cfg HandlerConfig) (ProtocolHandler, error) {
+			return &stubHandler{cfg: cfg}, nil
+		},
+	})
+}
+
+func TestRegistry_Lookup(t *testing.T) {
+	assert.True(t, IsRegistered("STUB"))
+	assert.True(t, IsRegistered("Stub"))
+	assert.False(t, IsRegistered("UNKNOWN"))
+
+	names := []string{}
+	for _, d := range Protocols() {
+		names = append(names, d.Name)
+	}
+	assert.Contains(t, names, "STUB")
+
+	assert.Panics(t, func() {
+		Register(Descriptor{Name: "STUB", Factory: func(HandlerConfig) (ProtocolHandler, error) { return nil, nil }})
+	})
+}
+
+func TestRegistry_NewHandler(t *testing.T) {
+	h, err := NewHandler("stub", HandlerConfig{PortID: "id", Port: 9000, Options: map[string]string{"mode": "a"}})
+	if assert.NoError(t, err) {
+		cfg := h.(*stubHandler).cfg
+		assert.Equal(t, "id", cfg.PortID)
+		assert.Equal(t, map[string]string{"mode": "a", OptAuthTimeout: "30s"}, cfg.Options)
+
+		timeout, err := cfg.Duration(OptAuthTimeout)
+		assert.NoError(t, err)
+		assert.Equal(t, 30*time.Second, timeout)
+	}
+
+	_, err = NewHandler("UNKNOWN", HandlerConfig{})
+	assert.Error(t, err)
+}
+
+func TestRegistry_ValidatePort(t *testing.T) {
+	assert.NoError(t, ValidatePort("STUB", map[string]string{"mode": "a", OptAuthTimeout: "5s"}))
+	assert.NoError(t, ValidatePort("STUB", map[string]string{"mode": "a", "note": "text"}))
+	// нет обязательного параметра
+	assert.Error(t, ValidatePort("STUB", nil))
+	// неизвестный параметр
+	assert.Error(t, ValidatePort("STUB", map[string]string{"mode": "a", "foo": "1"}))
+	// некорректное значение
+	assert.Error(t, ValidatePort("STUB", map[string]string{"mode": "a", OptAuthTimeout: "-1s"}))
+	assert.Error(t, ValidatePort("UNKNOWN", nil))
+}
+
+func TestRegistry_ValidateTransport(t *testing.T) {
+	assert.NoError(t, ValidateTransport("STUB", ""))
+	assert.NoError(t, ValidateTransport("STUB", TransportTCP))
+	// stub не объявляет поддержку UDP
+	assert.Error(t, ValidateTransport("STUB", TransportUDP))
+	assert.Error(t, ValidateTransport("STUB", "sctp"))
+	assert.Error(t, ValidateTransport("UNKNOWN", ""))
+
+	_, err := NewHandler("stub", HandlerConfig{Options: map[string]string{"mode": "a"}, Transport: TransportUDP})
+	assert.Error(t, err)
+}
@@ -0,0 +1,204 @@
+package protocol
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Общие для всех протоколов параметры подключения
+const (
+	OptIdleTimeout    = "idle_timeout"        // закрывать подключение, если устройство молчит дольше
+	OptKeepAlive      = "tcp_keepalive"       // период проб TCP keepalive
+	OptKeepAliveCount = "tcp_keepalive_count" // число неотвеченных проб до разрыва
+	OptMaxSession     = "max_session"         // максимальная длительность сессии
+
+	OptMaxConnections = "max_connections"     // максимум одновременных подключений к порту
+	OptMaxPerIP       = "max_sessions_per_ip" // максимум одновременных подключений с одного IP
+	OptRatePerIP      = "conn_rate_per_ip"    // максимум новых подключений с одного IP в минуту
+	OptAuthFailBan    = "auth_fail_ban"       // неудачных авторизаций с IP до блокировки
+	OptBanDuration    = "ban_duration"        // срок блокировки IP
+)
+
+// SessionOptions - описания параметров подключения, общие для всех протоколов
+var SessionOptions = []OptionSchema{
+	AuthTimeoutOption,
+	{
+		Name:        OptIdleTimeout,
+		Description: "закрывать подключение без данных дольше этого времени, 0 - не закрывать",
+		Default:     "10m",
+		Validate:    ValidateNonNegativeDuration,
+	},
+	{
+		Name:        OptKeepAlive,
+		Description: "период проб TCP keepalive, 0 - keepalive выключен",
+		Default:     "60s",
+		Validate:    ValidateNonNegativeDuration,
+	},
+	{
+		Name:        OptKeepAliveCount,
+		Description: "число неотвеченных проб TCP keepalive до разрыва подключения",
+		Default:     "3",
+		Validate:    ValidatePositiveInt,
+	},
+	{
+		Name:        OptMaxSession,
+		Description: "максимальная длительность сессии, 0 - без ограничения",
+		Default:     "0",
+		Validate:    ValidateNonNegativeDuration,
+	},
+	{
+		Name:        OptMaxConnections,
+		Description: "максимум одновременных подключений к порту, 0 - без ограничения",
+		Default:     "0",
+		Validate:    ValidateNonNegativeInt,
+	},
+	{
+		Name:        OptMaxPerIP,
+		Description: "максимум одновременных подключений с одного IP, 0 - без ограничения",
+		Default:     "0",
+		Validate:    ValidateNonNegativeInt,
+	},
+	{
+		Name:        OptRatePerIP,
+		Description: "максимум новых подключений с одного IP в минуту, 0 - без ограничения",
+		Default:     "0",
+		Validate:    ValidateNonNegativeInt,
+	},
+	{
+		Name:        OptAuthFailBan,
+		Description: "число неудачных авторизаций с IP за ban_duration, после которого IP блокируется, 0 - не блокировать",
+		Default:     "0",
+		Validate:    ValidateNonNegativeInt,
+	},
+	{
+		Name:        OptBanDuration,
+		Description: "срок блокировки IP",
+		Default:     "10m",
+		Validate:    ValidateNonNegativeDuration,
+	},
+}
+
+// SessionPolicy - ограничения подключений порта
+type SessionPolicy struct {
+	IdleTimeout    time.Duration // закрывать подключение без данных дольше; 0 - не закрывать
+	KeepAlive      time.Duration // период проб TCP keepalive; 0 - выключен
+	KeepAliveCount int           // число неотвеченных проб до разрыва
+	MaxSession     time.Duration // максимальная длительность сессии; 0 - без ограничения
+
+	MaxConnections int           // одновременных подключений к порту; 0 - без ограничения
+	MaxPerIP       int           // одновременных подключений с одного IP; 0 - без ограничения
+	RatePerIP      int           // новых подключений с одного IP в минуту; 0 - без ограничения
+	AuthFailBan    int           // неудачных авторизаций до блокировки IP; 0 - не блокировать
+	BanDuration    time.Duration // срок блокировки IP
+
+	TLS       *tls.Config // настройки TLS, nil - подключения без шифрования
+	Transport string      // TransportTCP или TransportUDP, пусто - TCP
+}
+
+// SessionHandler - обработчик протокола с общими параметрами порта (SessionOptions)
+type SessionHandler interface {
+	ProtocolHandler
+	SetAuthTimeout(timeout time.Duration)
+	SetPolicy(policy SessionPolicy)
+}
+
+// SessionFactory возвращает фабрику для протокола без собственных параметров:
+// newHandler создает обработчик, общие параметры порта задает ApplySession
+func SessionFactory(newHandler func() SessionHandler) HandlerFactory {
+	return func(cfg HandlerConfig) (ProtocolHandler, error) {
+		h := newHandler()
+		if err := cfg.ApplySession(h); err != nil {
+			return nil, err
+		}
+		return h, nil
+	}
+}
+
+// ApplySession задает обработчику время ожидания авторизации и ограничения подключений порта
+func (c HandlerConfig) ApplySession(h SessionHandler) error {
+	timeout, err := c.Duration(OptAuthTimeout)
+	if err != nil {
+		return err
+	}
+	policy, err := c.SessionPolicy()
+	if err != nil {
+		return err
+	}
+	h.SetAuthTimeout(timeout)
+	h.SetPolicy(policy)
+	return nil
+}
+
+// SessionPolicy возвращает ограничения подключений из параметров порта (см. SessionOptions)
+func (c HandlerConfig) SessionPolicy() (SessionPolicy, error) {
+	var (
+		policy = SessionPolicy{TLS: c.TLS, Transport: c.Transport}
+		err    error
+	)
+	if policy.IdleTimeout, err = c.Duration(OptIdleTimeout); err != nil {
+		return policy, err
+	}
+	if policy.KeepAlive, err = c.Duration(OptKeepAlive); err != nil {
+		return policy, err
+	}
+	if policy.KeepAliveCount, err = c.Int(OptKeepAliveCount); err != nil {
+		return policy, err
+	}
+	if policy.MaxSession, err = c.Duration(OptMaxSession); err != nil {
+		return policy, err
+	}
+	if policy.MaxConnections, err = c.Int(OptMaxConnections); err != nil {
+		return policy, err
+	}
+	if policy.MaxPerIP, err = c.Int(OptMaxPerIP); err != nil {
+		return policy, err
+	}
+	if policy.RatePerIP, err = c.Int(OptRatePerIP); err != nil {
+		return policy, err
+	}
+	if policy.AuthFailBan, err = c.Int(OptAuthFailBan); err != nil {
+		return policy, err
+	}
+	if policy.BanDuration, err = c.Duration(OptBanDuration); err != nil {
+		return policy, err
+	}
+	return policy, nil
+}
+
+// ValidateNonNegativeDuration проверяет, что значение - длительность не меньше нуля
+func ValidateNonNegativeDuration(value string) error {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	if d < 0 {
+		return fmt.Errorf("duration must not be negative: %s", value)
+	}
+	return nil
+}
+
+// ValidatePositiveInt проверяет, что значение - целое число больше нуля
+func ValidatePositiveInt(value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	if n <= 0 {
+		return fmt.Errorf("value must be positive: %s", value)
+	}
+	return nil
+}
+
+// ValidateNonNegativeInt проверяет, что значение - целое число не меньше нуля
+func ValidateNonNegativeInt(value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	if n < 0 {
+		return fmt.Errorf("value must not be negative: %s", value)
+	}
+	return nil
+}
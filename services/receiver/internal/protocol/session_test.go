@@ -0,0 +1,74 @@
+package protocol
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerConfig_SessionPolicy(t *testing.T) {
+	options, err := Descriptor{Name: "session", Options: SessionOptions}.ResolveOptions(nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	cfg := HandlerConfig{Options: options, Transport: TransportTCP}
+
+	// по умолчанию ограничений нет, блокировка IP выключена
+	policy, err := cfg.SessionPolicy()
+	if assert.NoError(t, err) {
+		assert.Equal(t, SessionPolicy{
+			IdleTimeout:    10 * time.Minute,
+			KeepAlive:      60 * time.Second,
+			KeepAliveCount: 3,
+			BanDuration:    10 * time.Minute,
+			Transport:      TransportTCP,
+		}, policy)
+	}
+
+	cfg.Options[OptAuthFailBan] = "5"
+	cfg.Options[OptIdleTimeout] = "5m"
+	policy, err = cfg.SessionPolicy()
+	if assert.NoError(t, err) {
+		assert.Equal(t, 5, policy.AuthFailBan)
+		assert.Equal(t, 5*time.Minute, policy.IdleTimeout)
+	}
+
+	cfg.Options[OptAuthFailBan] = "many"
+	_, err = cfg.SessionPolicy()
+	assert.Error(t, err)
+}
+
+// sessionStub запоминает общие параметры порта, переданные фабрикой
+type sessionStub struct {
+	stubHandler
+	authTimeout time.Duration
+	policy      SessionPolicy
+}
+
+func (h *sessionStub) SetAuthTimeout(timeout time.Duration) { h.authTimeout = timeout }
+func (h *sessionStub) SetPolicy(policy SessionPolicy)       { h.policy = policy }
+
+func TestSessionFactory(t *testing.T) {
+	options, err := Descriptor{Name: "session", Options: SessionOptions}.ResolveOptions(map[string]string{
+		OptAuthTimeout:    "5s",
+		OptMaxConnections: "100",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	factory := SessionFactory(func() SessionHandler { return &sessionStub{} })
+
+	h, err := factory(HandlerConfig{Options: options, Transport: TransportUDP})
+	if assert.NoError(t, err) {
+		stub := h.(*sessionStub)
+		assert.Equal(t, 5*time.Second, stub.authTimeout)
+		assert.Equal(t, 100, stub.policy.MaxConnections)
+		assert.Equal(t, TransportUDP, stub.policy.Transport)
+	}
+
+	// ошибка в параметрах порта не создает обработчик
+	options[OptMaxConnections] = "many"
+	_, err = factory(HandlerConfig{Options: options})
+	assert.Error(t, err)
+}
@@ -0,0 +1,201 @@
+package protocol
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/models"
+)
+
+// Stats - снимок статистики подключения или порта
+type Stats struct {
+	BytesIn         uint64    // принято байт
+	BytesOut        uint64    // отправлено байт
+	Packets         uint64    // разобрано пакетов
+	Records         uint64    // опубликовано навигационных записей
+	DecodeErrors    uint64    // пакетов, которые не удалось разобрать
+	LastPacket      time.Time // время приема последнего пакета, в том числе неразобранного
+	LastFix         time.Time // время навигации последней записи с валидными координатами
+	ProtocolVersion string    // версия протокола, которую сообщило устройство
+
+	Closes map[string]uint64 // закрытых подключений по причинам, только для порта
+	Drops  map[string]uint64 // отброшенных датаграмм UDP по причинам, только для порта
+}
+
+// ConnStats накапливает статистику подключения. Счетчики дублируются в статистику порта,
+// поэтому она учитывает и закрытые подключения. Методы потокобезопасны; вызовы на nil
+// ничего не делают, так что обработчику не нужно проверять, есть ли у подключения статистика.
+type ConnStats struct {
+	bytesIn      atomic.Uint64
+	bytesOut     atomic.Uint64
+	packets      atomic.Uint64
+	records      atomic.Uint64
+	decodeErrors atomic.Uint64
+	lastPacket   atomic.Int64 // UnixNano
+	lastFix      atomic.Int64 // UnixNano
+	version      atomic.Pointer[string]
+
+	closesMu sync.Mutex
+	closes   map[string]uint64 // причина закрытия -> число подключений, только для порта
+	drops    map[string]uint64 // причина -> число отброшенных датаграмм, только для порта (под closesMu)
+
+	port *ConnStats // статистика порта, nil для самого порта
+}
+
+// NewConnStats создает статистику подключения к порту с общей статистикой port
+func NewConnStats(port *ConnStats) *ConnStats {
+	return &ConnStats{port: port}
+}
+
+// StatsOf возвращает статистику подключения, принятого ConnectionManager, или nil
+func StatsOf(conn net.Conn) *ConnStats {
+	if c, ok := conn.(interface{ Stats() *ConnStats }); ok {
+		return c.Stats()
+	}
+	return nil
+}
+
+// AddBytesIn учитывает принятые байты
+func (s *ConnStats) AddBytesIn(n int) {
+	if s == nil || n <= 0 {
+		return
+	}
+	s.bytesIn.Add(uint64(n))
+	s.port.AddBytesIn(n)
+}
+
+// AddBytesOut учитывает отправленные байты
+func (s *ConnStats) AddBytesOut(n int) {
+	if s == nil || n <= 0 {
+		return
+	}
+	s.bytesOut.Add(uint64(n))
+	s.port.AddBytesOut(n)
+}
+
+// PacketDecoded учитывает разобранный пакет
+func (s *ConnStats) PacketDecoded() {
+	if s == nil {
+		return
+	}
+	s.packets.Add(1)
+	s.lastPacket.Store(time.Now().UnixNano())
+	s.port.PacketDecoded()
+}
+
+// DecodeError учитывает пакет, который не удалось разобрать
+func (s *ConnStats) DecodeError() {
+	if s == nil {
+		return
+	}
+	s.decodeErrors.Add(1)
+	s.lastPacket.Store(time.Now().UnixNano())
+	s.port.DecodeError()
+}
+
+// RecordPublished учитывает опубликованную запись и время ее навигации, если координаты валидны
+func (s *ConnStats) RecordPublished(rec *models.NavRecord) {
+	if s == nil {
+		return
+	}
+	s.records.Add(1)
+	if rec.Valid && !rec.NavigationTime.IsZero() {
+		fix := rec.NavigationTime.UnixNano()
+		// записи из "черного ящика" приходят с более ранним временем
+		for {
+			last := s.lastFix.Load()
+			if fix <= last || s.lastFix.CompareAndSwap(last, fix) {
+				break
+			}
+		}
+	}
+	s.port.RecordPublished(rec)
+}
+
+// SetProtocolVersion сохраняет версию протокола устройства
+func (s *ConnStats) SetProtocolVersion(version string) {
+	if s == nil {
+		return
+	}
+	s.version.Store(&version)
+}
+
+// SessionClosed учитывает закрытое подключение в статистике порта
+func (s *ConnStats) SessionClosed(reason string) {
+	if s == nil {
+		return
+	}
+	if s.port != nil {
+		s.port.SessionClosed(reason)
+		return
+	}
+	s.closesMu.Lock()
+	defer s.closesMu.Unlock()
+	if s.closes == nil {
+		s.closes = make(map[string]uint64)
+	}
+	s.closes[reason]++
+}
+
+// DatagramDropped учитывает отброшенную датаграмму UDP в статистике порта.
+// Отдельно от SessionClosed: отказ получает каждая датаграмма, а не подключение.
+func (s *ConnStats) DatagramDropped(reason string) {
+	if s == nil {
+		return
+	}
+	if s.port != nil {
+		s.port.DatagramDropped(reason)
+		return
+	}
+	s.closesMu.Lock()
+	defer s.closesMu.Unlock()
+	if s.drops == nil {
+		s.drops = make(map[string]uint64)
+	}
+	s.drops[reason]++
+}
+
+// Snapshot возвращает текущие значения счетчиков
+func (s *ConnStats) Snapshot() Stats {
+	if s == nil {
+		return Stats{}
+	}
+	st := Stats{
+		BytesIn:      s.bytesIn.Load(),
+		BytesOut:     s.bytesOut.Load(),
+		Packets:      s.packets.Load(),
+		Records:      s.records.Load(),
+		DecodeErrors: s.decodeErrors.Load(),
+		LastPacket:   unixNanoTime(s.lastPacket.Load()),
+		LastFix:      unixNanoTime(s.lastFix.Load()),
+	}
+	if v := s.version.Load(); v != nil {
+		st.ProtocolVersion = *v
+	}
+
+	s.closesMu.Lock()
+	if len(s.closes) > 0 {
+		st.Closes = make(map[string]uint64, len(s.closes))
+		for reason, n := range s.closes {
+			st.Closes[reason] = n
+		}
+	}
+	if len(s.drops) > 0 {
+		st.Drops = make(map[string]uint64, len(s.drops))
+		for reason, n := range s.drops {
+			st.Drops[reason] = n
+		}
+	}
+	s.closesMu.Unlock()
+	return st
+}
+
+// unixNanoTime преобразует UnixNano в время, 0 - нулевое время
+func unixNanoTime(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns).UTC()
+}
@@ -0,0 +1,97 @@
+package protocol
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type statsConn struct {
+	net.Conn
+	stats *ConnStats
+}
+
+func (c *statsConn) Stats() *ConnStats { return c.stats }
+
+func TestConnStats_PortTotals(t *testing.T) {
+	port := NewConnStats(nil)
+	first := NewConnStats(port)
+	second := NewConnStats(port)
+
+	first.AddBytesIn(10)
+	first.AddBytesOut(4)
+	first.PacketDecoded()
+	first.SetProtocolVersion("0x23")
+	second.AddBytesIn(5)
+	second.DecodeError()
+
+	st := first.Snapshot()
+	assert.Equal(t, uint64(10), st.BytesIn)
+	assert.Equal(t, uint64(4), st.BytesOut)
+	assert.Equal(t, uint64(1), st.Packets)
+	assert.Equal(t, "0x23", st.ProtocolVersion)
+	assert.False(t, st.LastPacket.IsZero())
+
+	total := port.Snapshot()
+	assert.Equal(t, uint64(15), total.BytesIn)
+	assert.Equal(t, uint64(1), total.Packets)
+	assert.Equal(t, uint64(1), total.DecodeErrors)
+}
+
+func TestConnStats_SessionClosed(t *testing.T) {
+	port := NewConnStats(nil)
+	conn := NewConnStats(port)
+
+	conn.SessionClosed("idle_timeout")
+	NewConnStats(port).SessionClosed("idle_timeout")
+	NewConnStats(port).SessionClosed("client_closed")
+
+	assert.Nil(t, conn.Snapshot().Closes)
+	assert.Equal(t, map[string]uint64{"idle_timeout": 2, "client_closed": 1}, port.Snapshot().Closes)
+}
+
+func TestConnStats_DatagramDropped(t *testing.T) {
+	port := NewConnStats(nil)
+
+	// отброшенные датаграммы не считаются закрытыми подключениями
+	port.DatagramDropped("rejected_banned")
+	NewConnStats(port).DatagramDropped("queue_full")
+	port.DatagramDropped("rejected_banned")
+
+	st := port.Snapshot()
+	assert.Nil(t, st.Closes)
+	assert.Equal(t, map[string]uint64{"rejected_banned": 2, "queue_full": 1}, st.Drops)
+}
+
+func TestConnStats_LastFix(t *testing.T) {
+	s := NewConnStats(nil)
+	fix := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	s.RecordPublished(&models.NavRecord{Valid: false, NavigationTime: fix.Add(time.Hour)})
+	assert.True(t, s.Snapshot().LastFix.IsZero())
+
+	s.RecordPublished(&models.NavRecord{Valid: true, NavigationTime: fix})
+	// запись из "черного ящика" не сдвигает время назад
+	s.RecordPublished(&models.NavRecord{Valid: true, NavigationTime: fix.Add(-time.Hour)})
+
+	st := s.Snapshot()
+	assert.Equal(t, uint64(3), st.Records)
+	assert.Equal(t, fix, st.LastFix)
+}
+
+func TestStatsOf(t *testing.T) {
+	var nilStats *ConnStats
+	nilStats.PacketDecoded()
+	assert.Equal(t, Stats{}, nilStats.Snapshot())
+
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	assert.Nil(t, StatsOf(server))
+
+	stats := NewConnStats(nil)
+	assert.Same(t, stats, StatsOf(&statsConn{Conn: server, stats: stats}))
+}
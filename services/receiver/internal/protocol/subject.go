@@ -0,0 +1,95 @@
+package protocol
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Подстановки в шаблонах топиков NATS
+const (
+	SubjectVarProtocol = "{protocol}"  // имя протокола в нижнем регистре (egts, arnavi, ...)
+	SubjectVarPortID   = "{port_id}"   // ID порта из конфигурации
+	SubjectVarDeviceID = "{device_id}" // IMEI или ID клиента (NavRecord.DeviceID)
+)
+
+// SubjectTemplate - шаблон топика NATS, например "nav.{protocol}.{port_id}.{device_id}"
+type SubjectTemplate string
+
+// Validate проверяет шаблон: токены между точками не пустые, без пробелов и
+// символов '*' и '>', в фигурных скобках только известные подстановки.
+func (t SubjectTemplate) Validate() error {
+	if t == "" {
+		return fmt.Errorf("пустой шаблон топика")
+	}
+	for _, token := range strings.Split(string(t), ".") {
+		if token == "" {
+			return fmt.Errorf("шаблон %q: пустой токен", t)
+		}
+		rest := token
+		for _, v := range []string{SubjectVarProtocol, SubjectVarPortID, SubjectVarDeviceID} {
+			rest = strings.ReplaceAll(rest, v, "")
+		}
+		if strings.ContainsAny(rest, "{}") {
+			return fmt.Errorf("шаблон %q: неизвестная подстановка в токене %q", t, token)
+		}
+		if strings.ContainsAny(rest, "*> \t\r\n") {
+			return fmt.Errorf("шаблон %q: недопустимый символ в токене %q", t, token)
+		}
+	}
+	return nil
+}
+
+// Render подставляет значения в шаблон. Значения приводятся к допустимому токену NATS.
+func (t SubjectTemplate) Render(protocol, portID, deviceID string) string {
+	return strings.NewReplacer(
+		SubjectVarProtocol, SubjectToken(strings.ToLower(protocol)),
+		SubjectVarPortID, SubjectToken(portID),
+		SubjectVarDeviceID, SubjectToken(deviceID),
+	).Replace(string(t))
+}
+
+// Wildcard возвращает маску, под которую попадают все топики шаблона:
+// токены с подстановками заменяются на '*'. Используется для топиков потока JetStream.
+func (t SubjectTemplate) Wildcard() string {
+	tokens := strings.Split(string(t), ".")
+	for i, token := range tokens {
+		if strings.Contains(token, "{") {
+			tokens[i] = "*"
+		}
+	}
+	return strings.Join(tokens, ".")
+}
+
+// Extract возвращает значение подстановки variable из топика subject, построенного по шаблону.
+// Подстановка должна занимать токен шаблона целиком, например "nav.commands.{device_id}".
+func (t SubjectTemplate) Extract(subject, variable string) (string, bool) {
+	tokens := strings.Split(string(t), ".")
+	values := strings.Split(subject, ".")
+	if len(tokens) != len(values) {
+		return "", false
+	}
+	for i, token := range tokens {
+		if token == variable {
+			return values[i], true
+		}
+	}
+	return "", false
+}
+
+// SubjectToken приводит значение к одному токену NATS: точки, пробелы и символы
+// '*' и '>' заменяются на '_', пустое значение - на "unknown".
+func SubjectToken(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', ' ', '\t', '\r', '\n':
+			return '_'
+		}
+		if r < 0x20 || r == 0x7f {
+			return '_'
+		}
+		return r
+	}, value)
+}
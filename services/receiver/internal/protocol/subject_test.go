@@ -0,0 +1,57 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/rackov/NavControlSystem/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// Тип записи определяет, по какому шаблону строится топик
+func TestNavRecord_Kind(t *testing.T) {
+	assert.Equal(t, models.RecordSensors, (&models.NavRecord{}).Kind())
+	assert.Equal(t, models.RecordPosition, (&models.NavRecord{Valid: true}).Kind())
+	assert.Equal(t, models.RecordPosition, (&models.NavRecord{Latitude: 55.7522}).Kind())
+	assert.Equal(t, models.RecordEvent, (&models.NavRecord{Valid: true, Event: "src4"}).Kind())
+	assert.Equal(t, models.RecordAlarm, (&models.NavRecord{Valid: true, Event: "src13", Alarm: true}).Kind())
+	assert.Equal(t, "alarms", models.RecordAlarm.String())
+}
+
+func TestSubjectTemplate_Validate(t *testing.T) {
+	assert.NoError(t, SubjectTemplate("nav.{protocol}.{port_id}.{device_id}").Validate())
+	assert.NoError(t, SubjectTemplate("nav.data").Validate())
+	assert.NoError(t, SubjectTemplate("nav.dev-{device_id}").Validate())
+
+	assert.Error(t, SubjectTemplate("").Validate())
+	assert.Error(t, SubjectTemplate("nav..{device_id}").Validate())
+	assert.Error(t, SubjectTemplate("nav.{imei}").Validate())
+	assert.Error(t, SubjectTemplate("nav.*").Validate())
+	assert.Error(t, SubjectTemplate("nav.>").Validate())
+}
+
+func TestSubjectTemplate_Render(t *testing.T) {
+	tmpl := SubjectTemplate("nav.{protocol}.{port_id}.{device_id}")
+
+	assert.Equal(t, "nav.egts.p1.866795030000000", tmpl.Render("EGTS", "p1", "866795030000000"))
+	// значения не должны добавлять токены или маски
+	assert.Equal(t, "nav.wialon.p_1.a_b_c", tmpl.Render("WIALON", "p.1", "a*b>c"))
+	assert.Equal(t, "nav.unknown.unknown.0", tmpl.Render("", "", "0"))
+
+	assert.Equal(t, "nav.*.*.*", tmpl.Wildcard())
+	assert.Equal(t, "nav.alarms.*", SubjectTemplate("nav.alarms.dev-{device_id}").Wildcard())
+}
+
+func TestSubjectTemplate_Extract(t *testing.T) {
+	tmpl := SubjectTemplate("nav.commands.{device_id}")
+
+	id, ok := tmpl.Extract("nav.commands.866795030000000", SubjectVarDeviceID)
+	assert.True(t, ok)
+	assert.Equal(t, "866795030000000", id)
+
+	_, ok = tmpl.Extract("nav.commands.a.b", SubjectVarDeviceID)
+	assert.False(t, ok)
+	_, ok = tmpl.Extract("nav.commands.1", SubjectVarPortID)
+	assert.False(t, ok)
+	_, ok = SubjectTemplate("nav.dev-{device_id}").Extract("nav.dev-1", SubjectVarDeviceID)
+	assert.False(t, ok)
+}
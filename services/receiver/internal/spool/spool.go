@@ -0,0 +1,460 @@
+// Package spool реализует журнал упреждающей записи (write-ahead spool) на диске.
+// Пока NATS недоступен, принятые от устройств данные пишутся в сегментные файлы
+// с fsync, а после восстановления связи вычитываются в порядке записи.
+package spool
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	segmentExt = ".seg"
+	cursorFile = "cursor"
+	// recordHeaderLen - длина (4 байта) и CRC32 (4 байта) перед данными записи
+	recordHeaderLen = 8
+
+	defaultSegmentSize = 16 << 20
+	// maxRecord - предельный размер записи (защита от поврежденной длины в сегменте)
+	maxRecord = 16 << 20
+)
+
+var (
+	// ErrFull - превышен максимальный размер спула, запись не принята
+	ErrFull = errors.New("spool is full")
+	// ErrClosed - спул закрыт
+	ErrClosed = errors.New("spool is closed")
+	// ErrCorrupt - сегмент или запись повреждены. Drain переименовывает поврежденный сегмент
+	// в *.corrupt и продолжает со следующего: записи после повреждения остаются в файле
+	// для разбора вручную. Запись, которую send отверг с этой ошибкой, пропускается.
+	ErrCorrupt = errors.New("spool data is corrupt")
+)
+
+// corruptExt - расширение сегмента, отложенного из-за повреждения
+const corruptExt = ".corrupt"
+
+// Options - параметры спула
+type Options struct {
+	SegmentSize int64 // размер сегмента, после которого начинается новый файл
+	MaxSize     int64 // максимальный суммарный размер сегментов, 0 - без ограничения
+}
+
+type segment struct {
+	seq  uint64
+	path string
+	size int64
+}
+
+// Spool - очередь записей в сегментных файлах каталога dir.
+// Append и Drain можно вызывать из разных горутин.
+type Spool struct {
+	dir  string
+	opts Options
+
+	mu      sync.Mutex
+	sealed  []*segment // закрытые сегменты, от старых к новым
+	active  *segment   // сегмент, в который идет запись (nil, пока нечего писать)
+	file    *os.File
+	size    int64 // суммарный размер всех сегментов
+	nextSeq uint64
+	closed  bool
+
+	// позиция чтения в самом старом сегменте
+	cursorSeq    uint64
+	cursorOffset int64
+
+	drainMu sync.Mutex // одновременно работает только один Drain
+}
+
+// Open открывает (или создает) спул в каталоге dir.
+// Сегменты, оставшиеся от прошлого запуска, будут вычитаны первыми.
+func Open(dir string, opts Options) (*Spool, error) {
+	if opts.SegmentSize <= 0 {
+		opts.SegmentSize = defaultSegmentSize
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory %s: %w", dir, err)
+	}
+
+	s := &Spool{dir: dir, opts: opts}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spool directory %s: %w", dir, err)
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		s.sealed = append(s.sealed, &segment{seq: seq, path: filepath.Join(dir, name), size: info.Size()})
+		s.size += info.Size()
+	}
+	sort.Slice(s.sealed, func(i, j int) bool { return s.sealed[i].seq < s.sealed[j].seq })
+	if n := len(s.sealed); n > 0 {
+		s.nextSeq = s.sealed[n-1].seq + 1
+		// Запись в последний сегмент могла оборваться при аварийной остановке
+		if err := s.trimTornTail(s.sealed[n-1]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.loadCursor(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Append дописывает запись в конец спула и дожидается ее сброса на диск.
+func (s *Spool) Append(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.appendLocked(data)
+}
+
+// AppendIfPending дописывает запись, только если в спуле есть невычитанные данные.
+// Позволяет писать напрямую в NATS, не нарушая порядок относительно уже сохраненных записей.
+// build формирует запись и вызывается, только если она действительно пишется в спул.
+func (s *Spool) AppendIfPending(build func() ([]byte, error)) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.emptyLocked() {
+		return false, nil
+	}
+	data, err := build()
+	if err != nil {
+		return true, err
+	}
+	return true, s.appendLocked(data)
+}
+
+func (s *Spool) appendLocked(data []byte) error {
+	if s.closed {
+		return ErrClosed
+	}
+	if len(data) > maxRecord {
+		return fmt.Errorf("spool record too large: %d bytes", len(data))
+	}
+	recLen := int64(recordHeaderLen + len(data))
+	if s.opts.MaxSize > 0 && s.size+recLen > s.opts.MaxSize {
+		return ErrFull
+	}
+
+	if s.active != nil && s.active.size >= s.opts.SegmentSize {
+		if err := s.sealLocked(); err != nil {
+			return err
+		}
+	}
+	if s.active == nil {
+		if err := s.openSegmentLocked(); err != nil {
+			return err
+		}
+	}
+
+	buf := make([]byte, recLen)
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(len(data)))
+	binary.LittleEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(data))
+	copy(buf[recordHeaderLen:], data)
+
+	if err := s.writeLocked(buf); err != nil {
+		// Часть записи могла попасть в файл: обрезаем сегмент до последней целой записи.
+		// Если не удалось, закрываем сегмент - его чтение ограничено известным размером.
+		if terr := s.file.Truncate(s.active.size); terr != nil {
+			s.sealLocked()
+		}
+		return err
+	}
+	s.active.size += recLen
+	s.size += recLen
+	return nil
+}
+
+func (s *Spool) writeLocked(buf []byte) error {
+	if _, err := s.file.Write(buf); err != nil {
+		return fmt.Errorf("failed to write spool record: %w", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync spool segment: %w", err)
+	}
+	return nil
+}
+
+func (s *Spool) openSegmentLocked() error {
+	seg := &segment{seq: s.nextSeq, path: filepath.Join(s.dir, fmt.Sprintf("%020d%s", s.nextSeq, segmentExt))}
+	f, err := os.OpenFile(seg.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create spool segment: %w", err)
+	}
+	s.nextSeq++
+	s.active = seg
+	s.file = f
+	return nil
+}
+
+// sealLocked закрывает текущий сегмент и переводит его в очередь на чтение
+func (s *Spool) sealLocked() error {
+	if s.active == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.sealed = append(s.sealed, s.active)
+	s.active = nil
+	s.file = nil
+	if err != nil {
+		return fmt.Errorf("failed to close spool segment: %w", err)
+	}
+	return nil
+}
+
+func (s *Spool) emptyLocked() bool {
+	return len(s.sealed) == 0 && (s.active == nil || s.active.size == 0)
+}
+
+// Empty сообщает, что все записи спула вычитаны
+func (s *Spool) Empty() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.emptyLocked()
+}
+
+// Size возвращает суммарный размер сегментов в байтах
+func (s *Spool) Size() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// Drain передает записи в send в порядке их записи, пока спул не опустеет.
+// Успешно переданные сегменты удаляются. При ошибке send позиция сохраняется,
+// и следующий Drain начнет с той же записи.
+// Поврежденные сегменты и записи, которые send отверг с ErrCorrupt, пропускаются;
+// в этом случае Drain вычитывает спул до конца и возвращает ошибку ErrCorrupt.
+func (s *Spool) Drain(send func(data []byte) error) (int, error) {
+	s.drainMu.Lock()
+	defer s.drainMu.Unlock()
+
+	sent := 0
+	var corrupt []error
+	// Запись, которую send отверг как поврежденную, передать не удастся никогда:
+	// пропускаем ее, чтобы она не остановила выгрузку остальных
+	deliver := func(data []byte) error {
+		err := send(data)
+		switch {
+		case err == nil:
+			sent++
+		case errors.Is(err, ErrCorrupt):
+			corrupt = append(corrupt, err)
+			return nil
+		}
+		return err
+	}
+	for {
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			return sent, ErrClosed
+		}
+		if len(s.sealed) == 0 {
+			if s.emptyLocked() {
+				s.mu.Unlock()
+				return sent, errors.Join(corrupt...)
+			}
+			// Вычитываем и текущий сегмент: новые записи пойдут в следующий
+			if err := s.sealLocked(); err != nil {
+				s.mu.Unlock()
+				return sent, err
+			}
+		}
+		seg := s.sealed[0]
+		offset := int64(0)
+		if s.cursorSeq == seg.seq {
+			offset = s.cursorOffset
+		}
+		s.mu.Unlock()
+
+		pos, err := replaySegment(seg.path, offset, seg.size, deliver)
+		quarantined := false
+		if errors.Is(err, ErrCorrupt) {
+			// Записи после повреждения не прочитать: откладываем сегмент и идем дальше
+			if rerr := os.Rename(seg.path, seg.path+corruptExt); rerr != nil {
+				return sent, fmt.Errorf("%w; failed to move segment: %v", err, rerr)
+			}
+			corrupt = append(corrupt, fmt.Errorf("%w, moved to %s", err, seg.path+corruptExt))
+			quarantined = true
+		} else if err != nil {
+			s.mu.Lock()
+			s.cursorSeq, s.cursorOffset = seg.seq, pos
+			cerr := s.saveCursorLocked()
+			s.mu.Unlock()
+			if cerr != nil {
+				return sent, cerr
+			}
+			return sent, err
+		}
+
+		s.mu.Lock()
+		s.sealed = s.sealed[1:]
+		s.size -= seg.size
+		s.cursorSeq, s.cursorOffset = 0, 0
+		cerr := s.saveCursorLocked()
+		s.mu.Unlock()
+
+		if !quarantined {
+			if err := os.Remove(seg.path); err != nil && !os.IsNotExist(err) {
+				return sent, fmt.Errorf("failed to remove spool segment: %w", err)
+			}
+		}
+		if cerr != nil {
+			return sent, cerr
+		}
+	}
+}
+
+// replaySegment читает записи сегмента начиная с offset до end (размер сегмента).
+// Возвращает позицию первой непереданной записи.
+// Запись, которую не удалось прочитать до end, означает повреждение сегмента (ErrCorrupt).
+func replaySegment(path string, offset, end int64, send func([]byte) error) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return offset, nil
+		}
+		return offset, fmt.Errorf("failed to open spool segment: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return offset, err
+	}
+
+	r := bufio.NewReader(io.LimitReader(f, end-offset))
+	for offset < end {
+		data, err := readRecord(r)
+		if err != nil {
+			return offset, fmt.Errorf("%w: %s at offset %d: %v", ErrCorrupt, filepath.Base(path), offset, err)
+		}
+		if err := send(data); err != nil {
+			return offset, err
+		}
+		offset += int64(recordHeaderLen + len(data))
+	}
+	return offset, nil
+}
+
+// readRecord читает одну запись и проверяет ее длину и CRC
+func readRecord(r io.Reader) ([]byte, error) {
+	head := make([]byte, recordHeaderLen)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return nil, fmt.Errorf("short record header: %w", err)
+	}
+	size := binary.LittleEndian.Uint32(head[0:4])
+	if size > maxRecord {
+		return nil, fmt.Errorf("record too large: %d bytes", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("short record data: %w", err)
+	}
+	if crc32.ChecksumIEEE(data) != binary.LittleEndian.Uint32(head[4:8]) {
+		return nil, errors.New("crc mismatch")
+	}
+	return data, nil
+}
+
+// trimTornTail обрезает последнюю запись сегмента, если она не дописана до конца файла:
+// так выглядит запись, оборванная аварийной остановкой. Поврежденные записи
+// (неверный CRC, слишком большая длина) не трогает - их найдет Drain.
+func (s *Spool) trimTornTail(seg *segment) error {
+	f, err := os.OpenFile(seg.path, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open spool segment: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	head := make([]byte, recordHeaderLen)
+	offset := int64(0)
+	for offset < seg.size {
+		if _, err := io.ReadFull(r, head); err != nil {
+			break
+		}
+		size := binary.LittleEndian.Uint32(head[0:4])
+		if size > maxRecord {
+			return nil
+		}
+		if _, err := r.Discard(int(size)); err != nil {
+			break
+		}
+		offset += int64(recordHeaderLen) + int64(size)
+	}
+	if offset == seg.size {
+		return nil
+	}
+	if err := f.Truncate(offset); err != nil {
+		return fmt.Errorf("failed to trim spool segment: %w", err)
+	}
+	s.size -= seg.size - offset
+	seg.size = offset
+	return nil
+}
+
+func (s *Spool) loadCursor() error {
+	data, err := os.ReadFile(filepath.Join(s.dir, cursorFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read spool cursor: %w", err)
+	}
+	if _, err := fmt.Sscanf(string(data), "%d %d", &s.cursorSeq, &s.cursorOffset); err != nil {
+		return fmt.Errorf("invalid spool cursor: %w", err)
+	}
+	return nil
+}
+
+// saveCursorLocked атомарно сохраняет позицию чтения (запись во временный файл и rename)
+func (s *Spool) saveCursorLocked() error {
+	path := filepath.Join(s.dir, cursorFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(fmt.Sprintf("%d %d", s.cursorSeq, s.cursorOffset)), 0644); err != nil {
+		return fmt.Errorf("failed to write spool cursor: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to rename spool cursor: %w", err)
+	}
+	return nil
+}
+
+// Close закрывает текущий сегмент. Невычитанные данные останутся на диске до следующего Open.
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.file != nil {
+		err := s.file.Close()
+		s.file = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package spool
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func collect(t *testing.T, s *Spool) []string {
+	var got []string
+	_, err := s.Drain(func(data []byte) error {
+		got = append(got, string(data))
+		return nil
+	})
+	assert.NoError(t, err)
+	return got
+}
+
+func TestSpool_AppendDrainOrder(t *testing.T) {
+	s, err := Open(t.TempDir(), Options{SegmentSize: 32})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer s.Close()
+
+	assert.True(t, s.Empty())
+	want := []string{}
+	for i := 0; i < 10; i++ {
+		rec := fmt.Sprintf("record-%d", i)
+		want = append(want, rec)
+		assert.NoError(t, s.Append([]byte(rec)))
+	}
+	assert.False(t, s.Empty())
+
+	assert.Equal(t, want, collect(t, s))
+	assert.True(t, s.Empty())
+	assert.Equal(t, int64(0), s.Size())
+}
+
+func TestSpool_DrainResumesAfterError(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir, Options{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, s.Append([]byte{byte(i)}))
+	}
+
+	errSend := errors.New("nats is down")
+	n, err := s.Drain(func(data []byte) error {
+		if data[0] == 2 {
+			return errSend
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errSend)
+	assert.Equal(t, 2, n)
+	assert.NoError(t, s.Close())
+
+	// после перезапуска чтение продолжается с непереданной записи
+	s, err = Open(dir, Options{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer s.Close()
+	assert.NoError(t, s.Append([]byte{5}))
+	assert.Equal(t, []string{"\x02", "\x03", "\x04", "\x05"}, collect(t, s))
+}
+
+func TestSpool_AppendIfPending(t *testing.T) {
+	s, err := Open(t.TempDir(), Options{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer s.Close()
+
+	// пока спул пуст, запись не формируется
+	built := 0
+	record := func(data string) func() ([]byte, error) {
+		return func() ([]byte, error) {
+			built++
+			return []byte(data), nil
+		}
+	}
+	ok, err := s.AppendIfPending(record("a"))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 0, built)
+
+	assert.NoError(t, s.Append([]byte("b")))
+	ok, err = s.AppendIfPending(record("c"))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 1, built)
+
+	ok, err = s.AppendIfPending(func() ([]byte, error) { return nil, errors.New("marshal failed") })
+	assert.Error(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"b", "c"}, collect(t, s))
+}
+
+func TestSpool_MaxSize(t *testing.T) {
+	s, err := Open(t.TempDir(), Options{MaxSize: 20})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer s.Close()
+
+	assert.NoError(t, s.Append([]byte("0123456789")))
+	assert.ErrorIs(t, s.Append([]byte("0123456789")), ErrFull)
+}
+
+func TestSpool_TornTail(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir, Options{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, s.Append([]byte("ok")))
+	assert.NoError(t, s.Close())
+
+	// имитируем сбой посреди записи: заголовок без данных
+	f, err := os.OpenFile(filepath.Join(dir, fmt.Sprintf("%020d%s", 0, segmentExt)), os.O_APPEND|os.O_WRONLY, 0644)
+	if !assert.NoError(t, err) {
+		return
+	}
+	f.Write([]byte{10, 0, 0, 0, 1, 2, 3, 4, 'x'})
+	f.Close()
+
+	s, err = Open(dir, Options{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer s.Close()
+	assert.Equal(t, []string{"ok"}, collect(t, s))
+	assert.True(t, s.Empty())
+}
+
+func TestSpool_CorruptRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(seg []byte)
+	}{
+		// записи "a", "b", "c" по 9 байт: вторая начинается со смещения 9
+		{"crc mismatch", func(seg []byte) { seg[9+recordHeaderLen] = 'x' }},
+		{"length too large", func(seg []byte) { copy(seg[9:13], []byte{0xff, 0xff, 0xff, 0xff}) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			s, err := Open(dir, Options{})
+			if !assert.NoError(t, err) {
+				return
+			}
+			for _, rec := range []string{"a", "b", "c"} {
+				assert.NoError(t, s.Append([]byte(rec)))
+			}
+			assert.NoError(t, s.Close())
+
+			path := filepath.Join(dir, fmt.Sprintf("%020d%s", 0, segmentExt))
+			seg, err := os.ReadFile(path)
+			if !assert.NoError(t, err) {
+				return
+			}
+			tt.corrupt(seg)
+			assert.NoError(t, os.WriteFile(path, seg, 0644))
+
+			s, err = Open(dir, Options{})
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer s.Close()
+			assert.NoError(t, s.Append([]byte("d")))
+
+			// поврежденный сегмент откладывается целиком, следующие вычитываются
+			var got []string
+			n, err := s.Drain(func(data []byte) error {
+				got = append(got, string(data))
+				return nil
+			})
+			assert.ErrorIs(t, err, ErrCorrupt)
+			assert.Equal(t, 2, n)
+			assert.Equal(t, []string{"a", "d"}, got)
+			assert.True(t, s.Empty())
+			assert.Equal(t, int64(0), s.Size())
+
+			kept, err := os.ReadFile(path + corruptExt)
+			assert.NoError(t, err)
+			assert.Equal(t, seg, kept)
+		})
+	}
+}
+
+func TestSpool_FailedWrite(t *testing.T) {
+	s, err := Open(t.TempDir(), Options{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer s.Close()
+
+	assert.NoError(t, s.Append([]byte("a")))
+	size := s.Size()
+
+	// файл сегмента недоступен для записи: запись отклоняется, сегмент закрывается,
+	// и следующая запись идет в новый сегмент
+	s.file.Close()
+	assert.Error(t, s.Append([]byte("b")))
+	assert.Equal(t, size, s.Size())
+	assert.NoError(t, s.Append([]byte("c")))
+
+	assert.Equal(t, []string{"a", "c"}, collect(t, s))
+}
+
+func TestSpool_DrainSkipsRejectedRecord(t *testing.T) {
+	s, err := Open(t.TempDir(), Options{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer s.Close()
+
+	for _, rec := range []string{"a", "bad", "c"} {
+		assert.NoError(t, s.Append([]byte(rec)))
+	}
+
+	var got []string
+	n, err := s.Drain(func(data []byte) error {
+		if string(data) == "bad" {
+			return fmt.Errorf("%w: invalid record", ErrCorrupt)
+		}
+		got = append(got, string(data))
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrCorrupt)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"a", "c"}, got)
+	assert.True(t, s.Empty())
+}
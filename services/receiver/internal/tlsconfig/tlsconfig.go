@@ -0,0 +1,192 @@
+// Package tlsconfig загружает сертификаты TLS для портов RECEIVER. Сертификаты
+// перечитываются при изменении файлов без перезапуска слушателя: новые подключения
+// получают новый сертификат, уже установленные сессии не прерываются.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/logger"
+)
+
+// Режимы проверки клиентских сертификатов
+const (
+	ClientAuthNone          = "none"            // клиентский сертификат не запрашивается
+	ClientAuthVerifyIfGiven = "verify_if_given" // сертификат проверяется, если клиент его прислал
+	ClientAuthRequire       = "require"         // без действительного сертификата подключение отклоняется
+)
+
+// checkInterval - как часто при новых подключениях проверяется, не изменились ли файлы
+const checkInterval = 10 * time.Second
+
+// Options - файлы сертификатов порта
+type Options struct {
+	CertFile     string // сертификат сервера (PEM, можно с цепочкой)
+	KeyFile      string // закрытый ключ сервера (PEM)
+	ClientCAFile string // сертификаты УЦ для проверки клиентов (PEM)
+	ClientAuth   string // режим проверки клиентов (ClientAuth*), пусто - ClientAuthNone
+}
+
+// Validate проверяет параметры без чтения файлов
+func (o Options) Validate() error {
+	if o.CertFile == "" || o.KeyFile == "" {
+		return fmt.Errorf("cert_file and key_file must be specified")
+	}
+	switch o.ClientAuth {
+	case "", ClientAuthNone:
+	case ClientAuthVerifyIfGiven, ClientAuthRequire:
+		if o.ClientCAFile == "" {
+			return fmt.Errorf("client_ca_file must be specified for client_auth %s", o.ClientAuth)
+		}
+	default:
+		return fmt.Errorf("unknown client_auth %q (none, verify_if_given, require)", o.ClientAuth)
+	}
+	return nil
+}
+
+// Info - сведения о загруженном сертификате сервера
+type Info struct {
+	Subject  string    // субъект сертификата
+	NotAfter time.Time // срок действия
+	LoadedAt time.Time // время загрузки
+}
+
+// Source хранит текущие сертификаты порта и перечитывает их при изменении файлов
+type Source struct {
+	opts Options
+
+	mu       sync.Mutex
+	config   *tls.Config
+	info     Info
+	modTimes []time.Time // время изменения файлов на момент загрузки
+	checked  time.Time   // время последней проверки файлов
+}
+
+// New загружает сертификаты. Ошибка в файлах не дает открыть порт.
+func New(opts Options) (*Source, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	s := &Source{opts: opts}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Config возвращает настройки для слушателя. Сертификаты выбираются при каждом
+// подключении, поэтому Reload применяется без пересоздания слушателя.
+func (s *Source) Config() *tls.Config {
+	return &tls.Config{
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return s.current(), nil
+		},
+	}
+}
+
+// Info возвращает сведения о загруженном сертификате
+func (s *Source) Info() Info {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.info
+}
+
+// Reload перечитывает файлы сертификатов. При ошибке остаются прежние сертификаты.
+func (s *Source) Reload() error {
+	modTimes := s.fileModTimes()
+
+	cert, err := tls.LoadX509KeyPair(s.opts.CertFile, s.opts.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate %s: %w", s.opts.CertFile, err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse certificate %s: %w", s.opts.CertFile, err)
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	switch s.opts.ClientAuth {
+	case ClientAuthVerifyIfGiven:
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	if s.opts.ClientCAFile != "" {
+		pem, err := os.ReadFile(s.opts.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA %s: %w", s.opts.ClientCAFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in client CA %s", s.opts.ClientCAFile)
+		}
+		config.ClientCAs = pool
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = config
+	s.info = Info{Subject: leaf.Subject.String(), NotAfter: leaf.NotAfter, LoadedAt: time.Now()}
+	s.modTimes = modTimes
+	s.checked = time.Now()
+	return nil
+}
+
+// current возвращает настройки с текущими сертификатами, не чаще раза в checkInterval
+// проверяя, не изменились ли файлы
+func (s *Source) current() *tls.Config {
+	s.mu.Lock()
+	config, changed := s.config, false
+	if time.Since(s.checked) >= checkInterval {
+		s.checked = time.Now()
+		changed = !equalTimes(s.modTimes, s.fileModTimes())
+	}
+	s.mu.Unlock()
+
+	if changed {
+		if err := s.Reload(); err != nil {
+			logger.Errorf("Failed to reload TLS certificate, keeping the previous one: %v", err)
+		} else {
+			logger.Infof("TLS certificate %s reloaded", s.opts.CertFile)
+			s.mu.Lock()
+			config = s.config
+			s.mu.Unlock()
+		}
+	}
+	return config
+}
+
+// fileModTimes возвращает время изменения файлов сертификатов (нулевое, если файла нет)
+func (s *Source) fileModTimes() []time.Time {
+	files := []string{s.opts.CertFile, s.opts.KeyFile, s.opts.ClientCAFile}
+	times := make([]time.Time, len(files))
+	for i, name := range files {
+		if name == "" {
+			continue
+		}
+		if fi, err := os.Stat(name); err == nil {
+			times[i] = fi.ModTime()
+		}
+	}
+	return times
+}
+
+func equalTimes(a, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
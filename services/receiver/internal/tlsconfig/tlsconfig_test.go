@@ -0,0 +1,168 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// testCert - сертификат с ключом, подписанный parent (nil - самоподписанный)
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+func newTestCert(t *testing.T, cn string, isCA bool, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:              []string{"localhost"},
+	}
+	signer, signerKey := tmpl, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+// write сохраняет сертификат и ключ в PEM-файлы каталога dir
+func (c *testCert) write(t *testing.T, dir, name string) (certFile, keyFile string) {
+	keyDer, err := x509.MarshalECPrivateKey(c.key)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0644)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	return certFile, keyFile
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+// handshake выполняет TLS-рукопожатие клиента client с сервером server
+func handshake(server, client *tls.Config) (serverErr, clientErr error) {
+	sc, cc := net.Pipe()
+	defer sc.Close()
+	defer cc.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		err := tls.Server(sc, server).Handshake()
+		sc.Close()
+		done <- err
+	}()
+	clientConn := tls.Client(cc, client)
+	clientErr = clientConn.Handshake()
+	if clientErr == nil {
+		// в TLS 1.3 сервер проверяет сертификат клиента после завершения рукопожатия клиентом
+		clientConn.Read(make([]byte, 1))
+	}
+	cc.Close()
+	return <-done, clientErr
+}
+
+func TestOptions_Validate(t *testing.T) {
+	assert.Error(t, Options{CertFile: "a.crt"}.Validate())
+	assert.NoError(t, Options{CertFile: "a.crt", KeyFile: "a.key"}.Validate())
+	assert.Error(t, Options{CertFile: "a.crt", KeyFile: "a.key", ClientAuth: ClientAuthRequire}.Validate())
+	assert.NoError(t, Options{CertFile: "a.crt", KeyFile: "a.key", ClientAuth: ClientAuthRequire, ClientCAFile: "ca.crt"}.Validate())
+	assert.Error(t, Options{CertFile: "a.crt", KeyFile: "a.key", ClientAuth: "always"}.Validate())
+}
+
+func TestSource_Reload(t *testing.T) {
+	level, _ := logger.ParseLevel("ERROR")
+	logger.Init(level)
+
+	dir := t.TempDir()
+	certFile, keyFile := newTestCert(t, "first", false, nil).write(t, dir, "server")
+
+	_, err := New(Options{CertFile: certFile, KeyFile: filepath.Join(dir, "missing.key")})
+	assert.Error(t, err)
+
+	src, err := New(Options{CertFile: certFile, KeyFile: keyFile})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "CN=first", src.Info().Subject)
+
+	// новый сертификат подхватывается при следующем подключении после checkInterval
+	newTestCert(t, "second", false, nil).write(t, dir, "server")
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, future, future)
+	src.mu.Lock()
+	src.checked = time.Time{}
+	src.mu.Unlock()
+
+	config, err := src.Config().GetConfigForClient(nil)
+	assert.NoError(t, err)
+	leaf, _ := x509.ParseCertificate(config.Certificates[0].Certificate[0])
+	assert.Equal(t, "second", leaf.Subject.CommonName)
+	assert.Equal(t, "CN=second", src.Info().Subject)
+
+	// поврежденный файл не заменяет загруженный сертификат
+	os.WriteFile(certFile, []byte("broken"), 0644)
+	assert.Error(t, src.Reload())
+	assert.Equal(t, "CN=second", src.Info().Subject)
+}
+
+func TestSource_ClientAuth(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", true, nil)
+	caFile, _ := ca.write(t, dir, "ca")
+	certFile, keyFile := newTestCert(t, "localhost", false, ca).write(t, dir, "server")
+	client := newTestCert(t, "866795030000000", false, ca)
+	stranger := newTestCert(t, "stranger", false, nil)
+
+	src, err := New(Options{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile, ClientAuth: ClientAuthRequire})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	clientConfig := func(certs ...tls.Certificate) *tls.Config {
+		return &tls.Config{RootCAs: roots, ServerName: "localhost", Certificates: certs}
+	}
+
+	serverErr, clientErr := handshake(src.Config(), clientConfig(client.tlsCertificate()))
+	assert.NoError(t, serverErr)
+	assert.NoError(t, clientErr)
+
+	serverErr, _ = handshake(src.Config(), clientConfig())
+	assert.Error(t, serverErr)
+
+	serverErr, _ = handshake(src.Config(), clientConfig(stranger.tlsCertificate()))
+	assert.Error(t, serverErr)
+}
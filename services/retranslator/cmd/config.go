@@ -0,0 +1,264 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/google/uuid"
+	"github.com/rackov/NavControlSystem/services/retranslator/internal/nats"
+	"github.com/rackov/NavControlSystem/services/retranslator/internal/sender"
+)
+
+// TargetConfig описывает один сторонний сервер, на который ретранслируются записи.
+type TargetConfig struct {
+	ID           string   `toml:"id"`                // Уникальный идентификатор, входит в имя потребителя NATS
+	Address      string   `toml:"address"`           // Адрес сервера host:port
+	AuthMode     string   `toml:"auth_mode"`         // dispatcher | terminal
+	DispatcherID uint32   `toml:"dispatcher_id"`     // ID диспетчера, выданный владельцем сервера
+	Description  string   `toml:"description"`       // Описание диспетчера
+	Devices      []string `toml:"devices,omitempty"` // Передаваемые устройства (IMEI или ID); пусто - все
+	// TID/OID устройств на сервере (IMEI или ID -> идентификатор); без него передается
+	// числовой ID устройства, а записи устройств, у которых его нет (IMEI), пропускаются
+	DeviceIDs map[string]uint32 `toml:"device_ids,omitempty"`
+}
+
+// targetIDPattern - допустимые символы ID сервера: ID входит в имя durable-потребителя
+var targetIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Config описывает всю конфигурацию для сервиса RETRANSLATOR.
+type Config struct {
+	GrpcPort    int    `toml:"grpc_port"`
+	MetricsPort int    `toml:"metrics_port"`
+	NatsURL     string `toml:"nats_url"`
+	LogLevel    string `toml:"log_level"`
+
+	Logging struct {
+		FilePath string `toml:"file_path"`
+	} `toml:"logging"`
+
+	Nats struct {
+		MaxReconnects  int           `toml:"max_reconnects"`  // Число попыток переподключения, -1 - без ограничения (по умолчанию)
+		ReconnectWait  time.Duration `toml:"reconnect_wait"`  // Пауза между попытками, например "2s"
+		Stream         string        `toml:"stream"`          // Поток JetStream, который создает RECEIVER
+		DurablePrefix  string        `toml:"durable_prefix"`  // Префикс имен durable-потребителей, к нему добавляется ID сервера
+		FilterSubjects []string      `toml:"filter_subjects"` // Топики потока; пусто - все записи
+		BatchSize      int           `toml:"batch_size"`      // Сколько записей читать за раз
+		FetchWait      time.Duration `toml:"fetch_wait"`      // Сколько ждать набора пачки
+		AckWait        time.Duration `toml:"ack_wait"`        // Сколько JetStream ждет подтверждения до повторной доставки
+		MaxDeliver     int           `toml:"max_deliver"`     // Число попыток доставки, 0 - без ограничения
+		RetryDelay     time.Duration `toml:"retry_delay"`     // Пауза перед повтором после ошибки передачи
+	} `toml:"nats"`
+
+	Egts struct {
+		DialTimeout     time.Duration `toml:"dial_timeout"`     // Сколько ждать подключения к серверу
+		ResponseTimeout time.Duration `toml:"response_timeout"` // TL_RESPONSE_TO, по ГОСТ 5 с
+		ResendAttempts  int           `toml:"resend_attempts"`  // TL_RESEND_ATTEMPTS, по ГОСТ 3
+	} `toml:"egts"`
+
+	Targets []TargetConfig `toml:"targets"`
+
+	mu         sync.RWMutex // Защищает Targets
+	configPath string       // Путь к файлу, из которого загружена конфигурация
+}
+
+// subscriberConfig возвращает параметры потребителя JetStream для сервера id
+func (c *Config) subscriberConfig(id string) nats.Config {
+	return nats.Config{
+		Stream:         c.Nats.Stream,
+		Durable:        c.Nats.DurablePrefix + id,
+		FilterSubjects: c.Nats.FilterSubjects,
+		BatchSize:      c.Nats.BatchSize,
+		FetchWait:      c.Nats.FetchWait,
+		AckWait:        c.Nats.AckWait,
+		MaxDeliver:     c.Nats.MaxDeliver,
+		RetryDelay:     c.Nats.RetryDelay,
+	}
+}
+
+// senderConfig возвращает параметры передачи на сервер
+func (c *Config) senderConfig(t TargetConfig) sender.Config {
+	return sender.Config{
+		Address:         t.Address,
+		AuthMode:        t.AuthMode,
+		DispatcherID:    t.DispatcherID,
+		Description:     t.Description,
+		Devices:         t.Devices,
+		DeviceIDs:       t.DeviceIDs,
+		DialTimeout:     c.Egts.DialTimeout,
+		ResponseTimeout: c.Egts.ResponseTimeout,
+		ResendAttempts:  c.Egts.ResendAttempts,
+	}
+}
+
+// LoadConfig загружает и парсит TOML файл.
+func LoadConfig(configPath *string) (*Config, error) {
+	cfgFile := resolveConfigPath(configPath)
+
+	data, err := os.ReadFile(cfgFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", cfgFile, err)
+	}
+
+	var cfg Config
+	meta, err := toml.Decode(string(data), &cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", cfgFile, err)
+	}
+	cfg.configPath = cfgFile
+
+	if cfg.NatsURL == "" {
+		return nil, fmt.Errorf("nats_url is not specified in %s", cfgFile)
+	}
+	if !meta.IsDefined("nats", "max_reconnects") {
+		cfg.Nats.MaxReconnects = -1
+	}
+	if cfg.Nats.ReconnectWait <= 0 {
+		cfg.Nats.ReconnectWait = 2 * time.Second
+	}
+	if cfg.Nats.Stream == "" {
+		cfg.Nats.Stream = "NAV_DATA"
+	}
+	if cfg.Nats.DurablePrefix == "" {
+		cfg.Nats.DurablePrefix = "retranslator_"
+	}
+	if cfg.Nats.BatchSize <= 0 {
+		cfg.Nats.BatchSize = 100
+	}
+	if cfg.Nats.FetchWait <= 0 {
+		cfg.Nats.FetchWait = time.Second
+	}
+	if cfg.Nats.AckWait <= 0 {
+		cfg.Nats.AckWait = time.Minute
+	}
+	if cfg.Nats.RetryDelay <= 0 {
+		cfg.Nats.RetryDelay = 10 * time.Second
+	}
+	if cfg.Egts.DialTimeout <= 0 {
+		cfg.Egts.DialTimeout = 10 * time.Second
+	}
+	if cfg.Egts.ResponseTimeout <= 0 {
+		cfg.Egts.ResponseTimeout = 5 * time.Second
+	}
+	if !meta.IsDefined("egts", "resend_attempts") {
+		cfg.Egts.ResendAttempts = 3
+	}
+
+	seen := make(map[string]bool, len(cfg.Targets))
+	for _, t := range cfg.Targets {
+		if err := cfg.validateTarget(t); err != nil {
+			return nil, fmt.Errorf("invalid target %q in %s: %w", t.ID, cfgFile, err)
+		}
+		if seen[t.ID] {
+			return nil, fmt.Errorf("duplicate target id %q in %s", t.ID, cfgFile)
+		}
+		seen[t.ID] = true
+	}
+
+	if cfg.Logging.FilePath != "" {
+		logDir := filepath.Dir(cfg.Logging.FilePath)
+		if err := os.MkdirAll(logDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create log directory %s: %w", logDir, err)
+		}
+	}
+
+	fmt.Printf("Config loaded from: %s\n", cfgFile)
+	return &cfg, nil
+}
+
+// resolveConfigPath определяет итоговый путь к файлу конфигурации.
+func resolveConfigPath(configPath *string) string {
+	if configPath != nil && *configPath != "" {
+		return *configPath
+	}
+	if envPath := os.Getenv("RETRANSLATOR_CONFIG_PATH"); envPath != "" {
+		return envPath
+	}
+	return "./configs/retranslator.toml" // Путь по умолчанию
+}
+
+// validateTarget проверяет описание сервера ретрансляции
+func (c *Config) validateTarget(t TargetConfig) error {
+	if !targetIDPattern.MatchString(t.ID) {
+		return fmt.Errorf("target id must contain only letters, digits, '_' and '-'")
+	}
+	return c.senderConfig(t).Validate()
+}
+
+// Save перезаписывает конфигурационный файл текущим состоянием.
+func (c *Config) Save() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.configPath == "" {
+		return fmt.Errorf("config path is not set, cannot save")
+	}
+
+	// Создаем временный файл для атомарной записи
+	tmpFile := c.configPath + ".tmp"
+	f, err := os.Create(tmpFile)
+	if err != nil {
+		return fmt.Errorf("failed to create temp config file: %w", err)
+	}
+	defer f.Close()
+
+	if err := toml.NewEncoder(f).Encode(c); err != nil {
+		return fmt.Errorf("failed to encode config to TOML: %w", err)
+	}
+
+	// Атомарно заменяем старый файл новым
+	if err := os.Rename(tmpFile, c.configPath); err != nil {
+		return fmt.Errorf("failed to rename temp config file: %w", err)
+	}
+
+	fmt.Printf("Config successfully saved to: %s\n", c.configPath)
+	return nil
+}
+
+// --- Методы для управления серверами ретрансляции ---
+
+// GetTargets возвращает копию списка серверов.
+func (c *Config) GetTargets() []TargetConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]TargetConfig(nil), c.Targets...)
+}
+
+// AddTarget добавляет сервер в конфигурацию в памяти. Пустой ID генерируется.
+func (c *Config) AddTarget(t TargetConfig) (TargetConfig, error) {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	if err := c.validateTarget(t); err != nil {
+		return t, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, existing := range c.Targets {
+		if existing.ID == t.ID {
+			return t, fmt.Errorf("target with id %s already exists", t.ID)
+		}
+	}
+	c.Targets = append(c.Targets, t)
+	return t, nil
+}
+
+// RemoveTarget удаляет сервер из конфигурации в памяти и возвращает его описание.
+func (c *Config) RemoveTarget(id string) (TargetConfig, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, t := range c.Targets {
+		if t.ID == id {
+			c.Targets = append(c.Targets[:i:i], c.Targets[i+1:]...)
+			return t, nil
+		}
+	}
+	return TargetConfig{}, fmt.Errorf("target with id %s not found", id)
+}
@@ -0,0 +1,19 @@
+package main // Важный момент: этот файл относится к пакету main
+
+import "github.com/rackov/NavControlSystem/pkg/monitoring"
+
+// ServiceMetrics - глобальные метрики сервиса RETRANSLATOR, доступны из любого файла пакета main.
+var ServiceMetrics *monitoring.ServiceMetrics
+
+// InitMetrics инициализирует и регистрирует все метрики для сервиса RETRANSLATOR.
+func InitMetrics(serviceName string) {
+	ServiceMetrics = monitoring.NewServiceMetrics(serviceName)
+
+	ServiceMetrics.RegisterGauge("nats_connected", "NATS connection state (1 - connected).")
+	ServiceMetrics.RegisterGauge("targets", "Configured retranslation targets.")
+	ServiceMetrics.RegisterGauge("egts_sessions", "Open EGTS sessions to all targets.")
+
+	ServiceMetrics.SetGauge("nats_connected", 0) // 0 - отключен
+	ServiceMetrics.SetGauge("targets", 0)
+	ServiceMetrics.SetGauge("egts_sessions", 0)
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/pkg/models"
+	"github.com/rackov/NavControlSystem/pkg/tnats"
+	"github.com/rackov/NavControlSystem/proto"
+	"github.com/rackov/NavControlSystem/services/retranslator/internal/nats"
+	"github.com/rackov/NavControlSystem/services/retranslator/internal/sender"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// target - запущенная ретрансляция на один сервер: свой потребитель NATS и свои сессии ЕГТС
+type target struct {
+	cfg        TargetConfig
+	forwarder  *sender.Forwarder
+	subscriber *nats.Subscriber
+	cancel     context.CancelFunc
+	done       chan struct{}
+}
+
+// HandleBatch передает пачку записей на сервер (nats.Handler).
+func (t *target) HandleBatch(ctx context.Context, recs []*models.NavRecord) []error {
+	start := time.Now()
+	errs := t.forwarder.Forward(ctx, recs)
+
+	sent := 0
+	for _, err := range errs {
+		if err == nil {
+			sent++
+		}
+	}
+	ServiceMetrics.AddOperations("records_forwarded", sent)
+	if sent < len(recs) {
+		ServiceMetrics.IncErrorCounter("forward_failed")
+	}
+	ServiceMetrics.ObserveOperationDuration("forward_batch", time.Since(start))
+	logger.Debugf("Target %s: forwarded %d of %d records in %s", t.cfg.ID, sent, len(recs), time.Since(start))
+	return errs
+}
+
+// HandleInvalid учитывает сообщение, которое не удалось разобрать.
+func (t *target) HandleInvalid(subject string, err error) {
+	ServiceMetrics.IncErrorCounter("record_decode_failed")
+	logger.Warnf("Target %s: dropping undecodable message from %s: %v", t.cfg.ID, subject, err)
+}
+
+// RetranslatorServer реализует gRPC-сервер и управляет жизненным циклом
+// сервиса RETRANSLATOR: читает записи из NATS и передает их на сторонние серверы по ЕГТС.
+type RetranslatorServer struct {
+	proto.UnimplementedRetranslatorControlServer
+	cfg        *Config
+	nats       *tnats.Client
+	grpcServer *grpc.Server
+
+	ctx     context.Context // контекст Start, от него запускаются серверы ретрансляции
+	mu      sync.Mutex
+	targets map[string]*target
+}
+
+// NewRetranslatorServer создает новый экземпляр сервера.
+func NewRetranslatorServer(cfg *Config) *RetranslatorServer {
+	return &RetranslatorServer{cfg: cfg, targets: make(map[string]*target)}
+}
+
+// Start подключается к NATS, запускает ретрансляцию на все серверы из конфигурации и gRPC-сервер.
+func (s *RetranslatorServer) Start(ctx context.Context) error {
+	logger.Info("Starting RETRANSLATOR server...")
+	s.ctx = ctx
+
+	// 1. NATS: подключение в фоне, подписчики ждут соединения сами
+	natsCfg := tnats.DefaultConfig(s.cfg.NatsURL)
+	natsCfg.Name = "retranslator"
+	natsCfg.MaxReconnects = s.cfg.Nats.MaxReconnects
+	natsCfg.ReconnectWait = s.cfg.Nats.ReconnectWait
+	natsCfg.RetryOnFailedConnect = true
+	natsCfg.OnStateChange = func(state tnats.State, err error) {
+		switch state {
+		case tnats.StateConnected, tnats.StateReconnected:
+			ServiceMetrics.SetGauge("nats_connected", 1)
+		default:
+			ServiceMetrics.SetGauge("nats_connected", 0)
+		}
+	}
+	client, err := tnats.NewClient(natsCfg, logger.GetLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create NATS client: %w", err)
+	}
+	s.nats = client
+
+	// 2. Серверы ретрансляции
+	for _, t := range s.cfg.GetTargets() {
+		s.startTarget(t)
+	}
+	go s.sessionsLoop(ctx)
+
+	// 3. gRPC сервер
+	if err := s.startGrpcServer(); err != nil {
+		return fmt.Errorf("failed to start gRPC server: %w", err)
+	}
+	logger.Infof("gRPC server started on port %d", s.cfg.GrpcPort)
+
+	logger.Info("RETRANSLATOR server started successfully")
+	return nil
+}
+
+// Stop останавливает компоненты сервиса. Контекст Start к этому моменту должен быть отменен.
+func (s *RetranslatorServer) Stop() {
+	logger.Info("Shutting down RETRANSLATOR server...")
+
+	if s.grpcServer != nil {
+		logger.Debug("Stopping gRPC server...")
+		s.grpcServer.GracefulStop()
+	}
+
+	s.mu.Lock()
+	for id := range s.targets {
+		s.stopTargetLocked(id)
+	}
+	s.mu.Unlock()
+
+	if s.nats != nil {
+		logger.Debug("Closing NATS connection...")
+		s.nats.Close()
+		ServiceMetrics.SetGauge("nats_connected", 0)
+	}
+	logger.Info("RETRANSLATOR server stopped.")
+}
+
+// --- Управление серверами ретрансляции ---
+
+// startTarget запускает чтение потока и передачу записей на сервер
+func (s *RetranslatorServer) startTarget(cfg TargetConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	t := &target{
+		cfg:       cfg,
+		forwarder: sender.NewForwarder(s.cfg.senderConfig(cfg)),
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+	t.subscriber = nats.NewSubscriber(s.nats, s.cfg.subscriberConfig(cfg.ID), t)
+	s.targets[cfg.ID] = t
+
+	go func() {
+		defer close(t.done)
+		t.subscriber.Run(ctx)
+	}()
+
+	ServiceMetrics.SetGauge("targets", float64(len(s.targets)))
+	logger.Infof("Retranslation to %s (%s, %s) started", cfg.ID, cfg.Address, cfg.AuthMode)
+}
+
+// stopTargetLocked останавливает передачу на сервер и закрывает его сессии.
+// Потребитель NATS остается, непереданные записи дождутся следующего запуска.
+func (s *RetranslatorServer) stopTargetLocked(id string) *target {
+	t, ok := s.targets[id]
+	if !ok {
+		return nil
+	}
+	t.cancel()
+	<-t.done
+	t.forwarder.Close()
+	delete(s.targets, id)
+
+	ServiceMetrics.SetGauge("targets", float64(len(s.targets)))
+	logger.Infof("Retranslation to %s stopped", id)
+	return t
+}
+
+// sessionsLoop раз в 15 секунд обновляет метрику открытых сессий ЕГТС.
+func (s *RetranslatorServer) sessionsLoop(ctx context.Context) {
+	ticker := time.NewTicker(15 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			sessions := 0
+			for _, t := range s.targets {
+				sessions += t.forwarder.Sessions()
+			}
+			s.mu.Unlock()
+			ServiceMetrics.SetGauge("egts_sessions", float64(sessions))
+		}
+	}
+}
+
+// --- gRPC Server Management ---
+
+// startGrpcServer инициализирует и запускает gRPC-сервер в отдельной горутине.
+func (s *RetranslatorServer) startGrpcServer() error {
+	s.grpcServer = grpc.NewServer()
+	proto.RegisterRetranslatorControlServer(s.grpcServer, s)
+	reflection.Register(s.grpcServer)
+
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", s.cfg.GrpcPort))
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := s.grpcServer.Serve(lis); err != nil && err != grpc.ErrServerStopped {
+			logger.Errorf("gRPC server error: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// --- gRPC Service Implementation ---
+
+// SetLogLevel изменяет глобальный уровень логирования.
+func (s *RetranslatorServer) SetLogLevel(ctx context.Context, req *proto.SetLogLevelRequest) (*proto.SetLogLevelResponse, error) {
+	level, err := logger.ParseLevel(req.Level)
+	if err != nil {
+		logger.Errorf("Failed to parse log level '%s': %v", req.Level, err)
+		return nil, status.Errorf(codes.InvalidArgument, "invalid log level: %s", req.Level)
+	}
+
+	logger.SetGlobalLevel(level)
+	logger.Infof("Log level set to %s", req.Level)
+	return &proto.SetLogLevelResponse{Success: true}, nil
+}
+
+// GetRetranslatorStatus возвращает состояние NATS и статистику по каждому серверу.
+func (s *RetranslatorServer) GetRetranslatorStatus(ctx context.Context, req *proto.GetRetranslatorStatusRequest) (*proto.GetRetranslatorStatusResponse, error) {
+	response := &proto.GetRetranslatorStatusResponse{
+		NatsConnected: s.nats.IsConnected(),
+	}
+
+	for _, cfg := range s.cfg.GetTargets() {
+		ts := &proto.TargetStatus{
+			Target:   targetDefinition(cfg),
+			Consumer: s.cfg.subscriberConfig(cfg.ID).Durable,
+		}
+
+		s.mu.Lock()
+		t := s.targets[cfg.ID]
+		s.mu.Unlock()
+		if t != nil {
+			stats := t.forwarder.Stats()
+			ts.Sessions = uint32(t.forwarder.Sessions())
+			ts.RecordsSent = stats.RecordsSent.Load()
+			ts.PacketsSent = stats.PacketsSent.Load()
+			ts.Retransmits = stats.Retransmits.Load()
+			ts.Errors = stats.Errors.Load()
+			ts.Skipped = stats.Skipped.Load()
+			ts.LastSendTime = stats.LastSendTime.Load()
+			ts.LastError = t.forwarder.LastError()
+			if pending, err := t.subscriber.Pending(); err == nil {
+				ts.Pending = pending
+			}
+		}
+		response.Targets = append(response.Targets, ts)
+	}
+
+	logger.Debugf("GRPC call: GetRetranslatorStatus. %d targets.", len(response.Targets))
+	return response, nil
+}
+
+// AddTarget добавляет сервер ретрансляции, запускает передачу на него и сохраняет конфигурацию.
+func (s *RetranslatorServer) AddTarget(ctx context.Context, req *proto.TargetDefinition) (*proto.TargetOperationResponse, error) {
+	logger.Infof("GRPC call: AddTarget %s (%s)", req.Id, req.Address)
+
+	cfg, err := s.cfg.AddTarget(TargetConfig{
+		ID:           req.Id,
+		Address:      req.Address,
+		AuthMode:     req.AuthMode,
+		DispatcherID: req.DispatcherId,
+		Description:  req.Description,
+		Devices:      req.Devices,
+		DeviceIDs:    req.DeviceIds,
+	})
+	if err != nil {
+		logger.Warnf("AddTarget rejected: %v", err)
+		return &proto.TargetOperationResponse{Success: false, Message: err.Error()}, nil
+	}
+
+	s.startTarget(cfg)
+
+	if err := s.cfg.Save(); err != nil {
+		logger.Errorf("Failed to save configuration after adding target: %v", err)
+		return &proto.TargetOperationResponse{
+			Success:       true,
+			Message:       fmt.Sprintf("target added, but configuration is not saved: %v", err),
+			TargetDetails: targetDefinition(cfg),
+		}, nil
+	}
+
+	return &proto.TargetOperationResponse{
+		Success:       true,
+		Message:       "Target added successfully",
+		TargetDetails: targetDefinition(cfg),
+	}, nil
+}
+
+// RemoveTarget останавливает передачу на сервер, удаляет его потребителя NATS и конфигурацию.
+func (s *RetranslatorServer) RemoveTarget(ctx context.Context, req *proto.TargetIdentifier) (*proto.TargetOperationResponse, error) {
+	logger.Infof("GRPC call: RemoveTarget %s", req.Id)
+
+	cfg, err := s.cfg.RemoveTarget(req.Id)
+	if err != nil {
+		return &proto.TargetOperationResponse{Success: false, Message: err.Error()}, nil
+	}
+
+	s.mu.Lock()
+	t := s.stopTargetLocked(req.Id)
+	s.mu.Unlock()
+
+	// непереданные записи удаленному серверу больше не нужны
+	if t != nil {
+		if err := t.subscriber.DeleteConsumer(); err != nil {
+			logger.Warnf("Failed to delete NATS consumer of target %s: %v", req.Id, err)
+		}
+	}
+
+	if err := s.cfg.Save(); err != nil {
+		logger.Errorf("Failed to save configuration after removing target: %v", err)
+		return &proto.TargetOperationResponse{
+			Success:       true,
+			Message:       fmt.Sprintf("target removed, but configuration is not saved: %v", err),
+			TargetDetails: targetDefinition(cfg),
+		}, nil
+	}
+
+	return &proto.TargetOperationResponse{
+		Success:       true,
+		Message:       "Target removed successfully",
+		TargetDetails: targetDefinition(cfg),
+	}, nil
+}
+
+// targetDefinition преобразует описание сервера в сообщение gRPC
+func targetDefinition(cfg TargetConfig) *proto.TargetDefinition {
+	return &proto.TargetDefinition{
+		Id:           cfg.ID,
+		Address:      cfg.Address,
+		AuthMode:     cfg.AuthMode,
+		DispatcherId: cfg.DispatcherID,
+		Description:  cfg.Description,
+		Devices:      cfg.Devices,
+		DeviceIds:    cfg.DeviceIDs,
+	}
+}
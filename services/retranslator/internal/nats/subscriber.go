@@ -0,0 +1,253 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/pkg/models"
+	"github.com/rackov/NavControlSystem/pkg/tnats"
+	"github.com/rackov/NavControlSystem/proto"
+)
+
+// contentTypeHdr - заголовок с кодировкой записи (см. proto.MarshalNavRecord)
+const contentTypeHdr = "Content-Type"
+
+// Config - параметры durable-потребителя JetStream одного сервера ретрансляции
+type Config struct {
+	Stream         string        // поток, в который RECEIVER публикует записи
+	Durable        string        // имя durable-потребителя, у каждого сервера свое
+	FilterSubjects []string      // топики потока, которые читает потребитель; пусто - все
+	BatchSize      int           // сколько сообщений запрашивать за раз
+	FetchWait      time.Duration // сколько ждать набора пачки
+	AckWait        time.Duration // сколько JetStream ждет подтверждения до повторной доставки
+	MaxDeliver     int           // число попыток доставки сообщения; 0 - без ограничения
+	RetryDelay     time.Duration // пауза перед повтором после ошибки передачи
+}
+
+// consumerConfig возвращает конфигурацию потребителя для nats.go
+func (c Config) consumerConfig() *nats.ConsumerConfig {
+	cc := &nats.ConsumerConfig{
+		Durable: c.Durable,
+		// новый сервер получает записи с момента подключения, а не всю историю потока
+		DeliverPolicy: nats.DeliverNewPolicy,
+		AckPolicy:     nats.AckExplicitPolicy,
+		AckWait:       c.AckWait,
+		MaxDeliver:    c.MaxDeliver,
+		MaxAckPending: 2 * c.BatchSize,
+	}
+	if c.MaxDeliver == 0 {
+		cc.MaxDeliver = -1
+	}
+	switch len(c.FilterSubjects) {
+	case 0:
+	case 1:
+		cc.FilterSubject = c.FilterSubjects[0]
+	default:
+		cc.FilterSubjects = c.FilterSubjects
+	}
+	return cc
+}
+
+// Handler получает записи, прочитанные из потока
+type Handler interface {
+	// HandleBatch передает пачку записей и возвращает ошибки по каждой записи.
+	// Записи с ошибкой не подтверждаются и доставляются повторно через Config.RetryDelay.
+	HandleBatch(ctx context.Context, recs []*models.NavRecord) []error
+	// HandleInvalid сообщает о сообщении, которое не удалось разобрать. Такое сообщение
+	// подтверждается как окончательно обработанное (Term) и больше не доставляется.
+	HandleInvalid(subject string, err error)
+}
+
+// Subscriber читает навигационные записи из JetStream пачками и передает их Handler.
+// Сообщение подтверждается только после того, как запись принята сервером.
+type Subscriber struct {
+	client  *tnats.Client
+	cfg     Config
+	handler Handler
+
+	mu  sync.Mutex
+	sub *nats.Subscription
+}
+
+// NewSubscriber создает подписчика. Чтение начинается в Run.
+func NewSubscriber(client *tnats.Client, cfg Config, handler Handler) *Subscriber {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.FetchWait <= 0 {
+		cfg.FetchWait = time.Second
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = 10 * time.Second
+	}
+	return &Subscriber{client: client, cfg: cfg, handler: handler}
+}
+
+// Pending возвращает число сообщений потока, еще не доставленных потребителю
+func (s *Subscriber) Pending() (uint64, error) {
+	s.mu.Lock()
+	sub := s.sub
+	s.mu.Unlock()
+	if sub == nil {
+		return 0, tnats.ErrNotConnected
+	}
+	info, err := sub.ConsumerInfo()
+	if err != nil {
+		return 0, err
+	}
+	return info.NumPending, nil
+}
+
+// Run читает поток до отмены ctx. Если NATS, поток или потребитель недоступны,
+// Run повторяет попытки, а не завершается.
+func (s *Subscriber) Run(ctx context.Context) {
+	defer s.unsubscribe()
+
+	for ctx.Err() == nil {
+		if !s.client.IsConnected() {
+			sleep(ctx, s.cfg.FetchWait)
+			continue
+		}
+
+		sub, err := s.subscription()
+		if err != nil {
+			logger.Errorf("Failed to subscribe to stream %s: %v", s.cfg.Stream, err)
+			sleep(ctx, s.cfg.RetryDelay)
+			continue
+		}
+
+		fetchCtx, cancel := context.WithTimeout(ctx, s.cfg.FetchWait)
+		msgs, err := sub.Fetch(s.cfg.BatchSize, nats.Context(fetchCtx))
+		cancel()
+		switch {
+		case err == nil:
+		case errors.Is(err, context.DeadlineExceeded), errors.Is(err, nats.ErrTimeout), errors.Is(err, context.Canceled):
+			// за FetchWait новых сообщений не было
+			continue
+		case errors.Is(err, nats.ErrConsumerDeleted), errors.Is(err, nats.ErrConsumerNotFound), errors.Is(err, nats.ErrBadSubscription):
+			logger.Warnf("Consumer %s is lost, resubscribing: %v", s.cfg.Durable, err)
+			s.unsubscribe()
+			continue
+		default:
+			logger.Warnf("Failed to fetch messages from stream %s: %v", s.cfg.Stream, err)
+			sleep(ctx, s.cfg.RetryDelay)
+			continue
+		}
+
+		if !s.process(ctx, msgs) {
+			sleep(ctx, s.cfg.RetryDelay)
+		}
+	}
+}
+
+// process разбирает пачку, передает ее Handler и подтверждает переданные записи.
+// Возвращает false, если часть записей передать не удалось.
+func (s *Subscriber) process(ctx context.Context, msgs []*nats.Msg) bool {
+	recs, valid := s.decode(msgs)
+	if len(recs) == 0 {
+		return true
+	}
+
+	ok := true
+	for i, err := range s.handler.HandleBatch(ctx, recs) {
+		if err != nil {
+			ok = false
+			if err := valid[i].NakWithDelay(s.cfg.RetryDelay); err != nil {
+				logger.Debugf("Failed to nak message: %v", err)
+			}
+			continue
+		}
+		if err := valid[i].Ack(); err != nil {
+			// запись будет доставлена и передана на сервер повторно по AckWait
+			logger.Warnf("Failed to ack message %s: %v", valid[i].Subject, err)
+		}
+	}
+	return ok
+}
+
+// decode разбирает записи пачки. Неразборчивые сообщения сразу снимаются с доставки.
+func (s *Subscriber) decode(msgs []*nats.Msg) ([]*models.NavRecord, []*nats.Msg) {
+	recs := make([]*models.NavRecord, 0, len(msgs))
+	valid := make([]*nats.Msg, 0, len(msgs))
+	for _, msg := range msgs {
+		rec, err := proto.UnmarshalNavRecord(msg.Data, msg.Header.Get(contentTypeHdr))
+		if err != nil {
+			s.handler.HandleInvalid(msg.Subject, err)
+			if err := msg.Term(); err != nil {
+				logger.Debugf("Failed to terminate message: %v", err)
+			}
+			continue
+		}
+		recs = append(recs, rec)
+		valid = append(valid, msg)
+	}
+	return recs, valid
+}
+
+// subscription возвращает pull-подписку, при необходимости создавая потребителя
+func (s *Subscriber) subscription() (*nats.Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sub != nil {
+		return s.sub, nil
+	}
+
+	if _, err := s.client.EnsureConsumer(s.cfg.Stream, s.cfg.consumerConfig()); err != nil {
+		return nil, err
+	}
+	js, err := s.client.JetStream()
+	if err != nil {
+		return nil, err
+	}
+	sub, err := js.PullSubscribe("", s.cfg.Durable, nats.Bind(s.cfg.Stream, s.cfg.Durable))
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind to consumer %s: %w", s.cfg.Durable, err)
+	}
+	logger.Infof("Subscribed to stream %s as durable consumer %s", s.cfg.Stream, s.cfg.Durable)
+	s.sub = sub
+	return sub, nil
+}
+
+// unsubscribe снимает локальную подписку. Durable-потребитель на сервере остается.
+func (s *Subscriber) unsubscribe() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sub != nil {
+		// потребитель создан EnsureConsumer, а не библиотекой, поэтому при отписке он не удаляется
+		if err := s.sub.Unsubscribe(); err != nil {
+			logger.Debugf("Failed to unsubscribe: %v", err)
+		}
+		s.sub = nil
+	}
+}
+
+// DeleteConsumer удаляет durable-потребителя из NATS. Вызывается после остановки Run,
+// когда сервер ретрансляции удален и непереданные ему записи больше не нужны.
+func (s *Subscriber) DeleteConsumer() error {
+	js, err := s.client.JetStream()
+	if err != nil {
+		return err
+	}
+	err = js.DeleteConsumer(s.cfg.Stream, s.cfg.Durable)
+	if errors.Is(err, nats.ErrConsumerNotFound) {
+		return nil
+	}
+	return err
+}
+
+// sleep ждет d или отмены ctx
+func sleep(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
@@ -0,0 +1,79 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/pkg/models"
+	"github.com/rackov/NavControlSystem/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+type testHandler struct {
+	batches [][]*models.NavRecord
+	invalid []string
+	failed  map[uint32]bool // записи каких клиентов не передаются
+}
+
+func (h *testHandler) HandleBatch(ctx context.Context, recs []*models.NavRecord) []error {
+	h.batches = append(h.batches, recs)
+	errs := make([]error, len(recs))
+	for i, rec := range recs {
+		if h.failed[rec.Client] {
+			errs[i] = errors.New("no response")
+		}
+	}
+	return errs
+}
+
+func (h *testHandler) HandleInvalid(subject string, err error) {
+	h.invalid = append(h.invalid, subject)
+}
+
+func TestConfig_ConsumerConfig(t *testing.T) {
+	cc := Config{Durable: "retranslator_region", BatchSize: 100, AckWait: time.Minute}.consumerConfig()
+	assert.Equal(t, "retranslator_region", cc.Durable)
+	assert.Equal(t, nats.DeliverNewPolicy, cc.DeliverPolicy)
+	assert.Equal(t, nats.AckExplicitPolicy, cc.AckPolicy)
+	assert.Equal(t, -1, cc.MaxDeliver)
+	assert.Equal(t, 200, cc.MaxAckPending)
+
+	cc = Config{FilterSubjects: []string{"nav.*.*.*"}}.consumerConfig()
+	assert.Equal(t, "nav.*.*.*", cc.FilterSubject)
+}
+
+func TestSubscriber_Process(t *testing.T) {
+	level, _ := logger.ParseLevel("ERROR")
+	logger.Init(level)
+
+	ok1, _, _ := proto.MarshalNavRecord(&models.NavRecord{Client: 1}, proto.EncodingJSON)
+	ok2, _, _ := proto.MarshalNavRecord(&models.NavRecord{Client: 2}, proto.EncodingJSON)
+	msgs := []*nats.Msg{
+		{Subject: "nav.egts.p1.1", Data: ok1},
+		{Subject: "nav.egts.p1.3", Data: []byte("{broken")},
+		{Subject: "nav.egts.p1.2", Data: ok2},
+	}
+
+	// сообщения не привязаны к подписке, поэтому подтверждения лишь логируются
+	h := &testHandler{}
+	s := NewSubscriber(nil, Config{}, h)
+	assert.True(t, s.process(context.Background(), msgs))
+	if assert.Len(t, h.batches, 1) && assert.Len(t, h.batches[0], 2) {
+		assert.Equal(t, uint32(2), h.batches[0][1].Client)
+	}
+	assert.Equal(t, []string{"nav.egts.p1.3"}, h.invalid)
+
+	// запись одного устройства не передана - пачку нужно повторить
+	h = &testHandler{failed: map[uint32]bool{2: true}}
+	s = NewSubscriber(nil, Config{}, h)
+	assert.False(t, s.process(context.Background(), msgs))
+
+	h = &testHandler{}
+	s = NewSubscriber(nil, Config{}, h)
+	assert.True(t, s.process(context.Background(), msgs[1:2]))
+	assert.Empty(t, h.batches)
+}
@@ -0,0 +1,261 @@
+package sender
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/pkg/models"
+)
+
+// Способы авторизации на сервере
+const (
+	// AuthDispatcher - одна сессия от имени ТП (EGTS_SR_DISPATCHER_IDENTITY), записи всех
+	// устройств передаются в ней с OID устройства
+	AuthDispatcher = "dispatcher"
+	// AuthTerminal - отдельная сессия на каждое устройство (EGTS_SR_TERM_IDENTITY),
+	// сервер видит устройства так, будто они подключены к нему напрямую
+	AuthTerminal = "terminal"
+)
+
+// Config - параметры ретрансляции на один сервер
+type Config struct {
+	Address         string            // адрес сервера host:port
+	AuthMode        string            // AuthDispatcher или AuthTerminal
+	DispatcherID    uint32            // ID диспетчера, выданный владельцем сервера
+	Description     string            // краткое описание диспетчера
+	Devices         []string          // устройства (models.NavRecord.DeviceID), которые передаются; пусто - все
+	DeviceIDs       map[string]uint32 // TID/OID устройств на сервере по DeviceID; без него - числовой ID устройства
+	DialTimeout     time.Duration     // сколько ждать подключения
+	ResponseTimeout time.Duration     // TL_RESPONSE_TO - сколько ждать подтверждения пакета
+	ResendAttempts  int               // TL_RESEND_ATTEMPTS - сколько раз повторять пакет без подтверждения
+}
+
+// Validate проверяет обязательные параметры
+func (c Config) Validate() error {
+	if c.Address == "" {
+		return fmt.Errorf("address is not specified")
+	}
+	switch c.AuthMode {
+	case AuthDispatcher, AuthTerminal:
+	default:
+		return fmt.Errorf("unknown auth mode %q, expected %s or %s", c.AuthMode, AuthDispatcher, AuthTerminal)
+	}
+	for device, id := range c.DeviceIDs {
+		if id == 0 {
+			return fmt.Errorf("device %s: TID/OID must not be 0", device)
+		}
+	}
+	return nil
+}
+
+// Stats - статистика ретрансляции на сервер с момента запуска
+type Stats struct {
+	RecordsSent  atomic.Uint64
+	PacketsSent  atomic.Uint64
+	Retransmits  atomic.Uint64 // повторов пакетов без подтверждения
+	Errors       atomic.Uint64 // неудачных попыток передать записи устройства
+	Skipped      atomic.Uint64 // записей, не переданных из-за отсутствия TID/OID устройства
+	LastSendTime atomic.Int64  // Unix timestamp в секундах
+}
+
+// dialFunc открывает авторизованную сессию, подменяется в тестах
+type dialFunc func(ctx context.Context, cfg Config, ident Identity, stats *Stats) (sender, error)
+
+// sender - то, что Forwarder требует от сессии
+type sender interface {
+	Send(recs []*models.NavRecord) error
+	Close() error
+}
+
+// Forwarder передает записи на один сервер, группируя их по устройствам.
+// Сессии открываются при первой записи и переоткрываются после ошибки.
+type Forwarder struct {
+	cfg     Config
+	devices map[string]struct{}
+	dial    dialFunc
+	stats   Stats
+
+	mu       sync.Mutex
+	sessions map[string]sender // ключ - DeviceID в режиме AuthTerminal, "" в режиме AuthDispatcher
+	lastErr  string
+	unmapped map[string]struct{} // устройства без TID/OID, о которых уже предупредили в журнале
+}
+
+// NewForwarder создает Forwarder. Подключение к серверу - при первой передаче.
+func NewForwarder(cfg Config) *Forwarder {
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = 10 * time.Second
+	}
+	if cfg.ResponseTimeout <= 0 {
+		cfg.ResponseTimeout = 5 * time.Second
+	}
+	if cfg.ResendAttempts < 0 {
+		cfg.ResendAttempts = 0
+	}
+
+	f := &Forwarder{
+		cfg:      cfg,
+		sessions: make(map[string]sender),
+		unmapped: make(map[string]struct{}),
+		dial: func(ctx context.Context, cfg Config, ident Identity, stats *Stats) (sender, error) {
+			return Dial(ctx, cfg, ident, stats)
+		},
+	}
+	if len(cfg.Devices) > 0 {
+		f.devices = make(map[string]struct{}, len(cfg.Devices))
+		for _, id := range cfg.Devices {
+			f.devices[id] = struct{}{}
+		}
+	}
+	return f
+}
+
+// Stats возвращает статистику ретрансляции
+func (f *Forwarder) Stats() *Stats {
+	return &f.stats
+}
+
+// Sessions возвращает число открытых сессий
+func (f *Forwarder) Sessions() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.sessions)
+}
+
+// LastError возвращает последнюю ошибку передачи
+func (f *Forwarder) LastError() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lastErr
+}
+
+// Forward передает записи и возвращает ошибки по каждой записи (nil - запись передана
+// или не предназначена этому серверу). Ошибка одного устройства не мешает остальным.
+// Записи устройства без TID/OID пропускаются: повторная доставка их не исправит.
+// Forward вызывается из одной горутины: у каждого сервера один читатель потока.
+func (f *Forwarder) Forward(ctx context.Context, recs []*models.NavRecord) []error {
+	errs := make([]error, len(recs))
+
+	// группируем по устройствам, сохраняя порядок записей внутри устройства
+	var order []string
+	groups := make(map[string][]int)
+	for i, rec := range recs {
+		id := rec.DeviceID()
+		if f.devices != nil {
+			if _, ok := f.devices[id]; !ok {
+				continue
+			}
+		}
+		if _, ok := groups[id]; !ok {
+			order = append(order, id)
+		}
+		groups[id] = append(groups[id], i)
+	}
+
+	for _, id := range order {
+		idx := groups[id]
+		oid := f.objectID(recs[idx[0]])
+		if oid == 0 {
+			f.skip(id, len(idx))
+			continue
+		}
+		// OID передается в поле Client: у устройств с IMEI оно пустое
+		group := make([]*models.NavRecord, len(idx))
+		for i, j := range idx {
+			rec := *recs[j]
+			rec.Client = oid
+			group[i] = &rec
+		}
+
+		if err := f.send(ctx, id, group); err != nil {
+			f.stats.Errors.Add(1)
+			f.mu.Lock()
+			f.lastErr = err.Error()
+			f.mu.Unlock()
+			logger.Warnf("Failed to forward %d records of device %s to %s: %v", len(group), id, f.cfg.Address, err)
+			for _, j := range idx {
+				errs[j] = err
+			}
+		}
+	}
+	return errs
+}
+
+// objectID возвращает TID/OID устройства на сервере: из настроек сервера,
+// иначе - числовой ID устройства. 0 - идентификатора нет.
+func (f *Forwarder) objectID(rec *models.NavRecord) uint32 {
+	if oid, ok := f.cfg.DeviceIDs[rec.DeviceID()]; ok {
+		return oid
+	}
+	return rec.Client
+}
+
+// skip учитывает записи устройства без TID/OID. Предупреждение пишется один раз на устройство.
+func (f *Forwarder) skip(deviceID string, count int) {
+	f.stats.Skipped.Add(uint64(count))
+	if _, ok := f.unmapped[deviceID]; ok {
+		return
+	}
+	f.unmapped[deviceID] = struct{}{}
+	logger.Warnf("Device %s has no TID/OID for %s, its records are skipped (set device_ids for the target)", deviceID, f.cfg.Address)
+}
+
+// send передает записи одного устройства, при необходимости открывая сессию.
+// Сессия с ошибкой закрывается: после сбоя счетчики пакетов на сервере и у нас могут разойтись.
+// Блокировка на время обмена с сервером не удерживается, чтобы не задерживать статистику.
+func (f *Forwarder) send(ctx context.Context, deviceID string, recs []*models.NavRecord) error {
+	key := ""
+	if f.cfg.AuthMode == AuthTerminal {
+		key = deviceID
+	}
+
+	f.mu.Lock()
+	sess, ok := f.sessions[key]
+	f.mu.Unlock()
+
+	if !ok {
+		var err error
+		if sess, err = f.dial(ctx, f.cfg, f.identity(recs[0]), &f.stats); err != nil {
+			return err
+		}
+		logger.Infof("EGTS session to %s opened (%s %s)", f.cfg.Address, f.cfg.AuthMode, key)
+		f.mu.Lock()
+		f.sessions[key] = sess
+		f.mu.Unlock()
+	}
+
+	if err := sess.Send(recs); err != nil {
+		sess.Close()
+		f.mu.Lock()
+		delete(f.sessions, key)
+		f.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// identity возвращает учетные данные сессии для записи rec
+func (f *Forwarder) identity(rec *models.NavRecord) Identity {
+	return Identity{
+		Mode:         f.cfg.AuthMode,
+		DispatcherID: f.cfg.DispatcherID,
+		Description:  f.cfg.Description,
+		TID:          rec.Client,
+		IMEI:         rec.Imei,
+	}
+}
+
+// Close закрывает все сессии
+func (f *Forwarder) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for key, sess := range f.sessions {
+		sess.Close()
+		delete(f.sessions, key)
+	}
+}
@@ -0,0 +1,126 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type testSender struct {
+	sent   [][]*models.NavRecord
+	err    error
+	closed bool
+}
+
+func (s *testSender) Send(recs []*models.NavRecord) error {
+	s.sent = append(s.sent, recs)
+	return s.err
+}
+
+func (s *testSender) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestForwarder_Forward(t *testing.T) {
+	level, _ := logger.ParseLevel("ERROR")
+	logger.Init(level)
+
+	cfg := testConfig()
+	cfg.AuthMode = AuthTerminal
+	cfg.Devices = []string{"1", "2"}
+	f := NewForwarder(cfg)
+
+	sessions := map[uint32]*testSender{1: {}, 2: {err: errors.New("broken pipe")}}
+	var idents []Identity
+	f.dial = func(ctx context.Context, cfg Config, ident Identity, stats *Stats) (sender, error) {
+		idents = append(idents, ident)
+		return sessions[ident.TID], nil
+	}
+
+	recs := []*models.NavRecord{{Client: 1, PacketID: 1}, {Client: 2}, {Client: 3}, {Client: 1, PacketID: 2}}
+	errs := f.Forward(context.Background(), recs)
+
+	// устройство 3 не передается этому серверу, устройство 2 - с ошибкой
+	assert.NoError(t, errs[0])
+	assert.Error(t, errs[1])
+	assert.NoError(t, errs[2])
+	assert.NoError(t, errs[3])
+	assert.Equal(t, []Identity{{Mode: AuthTerminal, DispatcherID: 10, TID: 1}, {Mode: AuthTerminal, DispatcherID: 10, TID: 2}}, idents)
+
+	if assert.Len(t, sessions[1].sent, 1) {
+		assert.Equal(t, []*models.NavRecord{recs[0], recs[3]}, sessions[1].sent[0])
+	}
+	// сессия с ошибкой закрыта и будет открыта заново
+	assert.True(t, sessions[2].closed)
+	assert.Equal(t, 1, f.Sessions())
+	assert.Equal(t, uint64(1), f.Stats().Errors.Load())
+	assert.Equal(t, "broken pipe", f.LastError())
+}
+
+func TestForwarder_Dispatcher(t *testing.T) {
+	level, _ := logger.ParseLevel("ERROR")
+	logger.Init(level)
+
+	f := NewForwarder(testConfig())
+	sess := &testSender{}
+	dials := 0
+	f.dial = func(ctx context.Context, cfg Config, ident Identity, stats *Stats) (sender, error) {
+		dials++
+		return sess, nil
+	}
+
+	errs := f.Forward(context.Background(), []*models.NavRecord{{Client: 1}, {Client: 2}})
+	assert.Equal(t, []error{nil, nil}, errs)
+	// одна сессия ТП, записи разных устройств - разными пакетами
+	assert.Equal(t, 1, dials)
+	assert.Len(t, sess.sent, 2)
+
+	f.Close()
+	assert.True(t, sess.closed)
+	assert.Zero(t, f.Sessions())
+}
+
+func TestForwarder_DeviceIDs(t *testing.T) {
+	level, _ := logger.ParseLevel("ERROR")
+	logger.Init(level)
+
+	cfg := testConfig()
+	cfg.AuthMode = AuthTerminal
+	cfg.DeviceIDs = map[string]uint32{"356307042441013": 501}
+	f := NewForwarder(cfg)
+
+	sess := &testSender{}
+	var idents []Identity
+	f.dial = func(ctx context.Context, cfg Config, ident Identity, stats *Stats) (sender, error) {
+		idents = append(idents, ident)
+		return sess, nil
+	}
+
+	recs := []*models.NavRecord{{Imei: "356307042441013"}, {Imei: "860000000000001"}, {Imei: "860000000000001"}}
+	errs := f.Forward(context.Background(), recs)
+
+	// устройство без TID/OID пропускается без ошибки, чтобы запись не доставлялась повторно
+	assert.Equal(t, []error{nil, nil, nil}, errs)
+	assert.Equal(t, []Identity{{Mode: AuthTerminal, DispatcherID: 10, TID: 501, IMEI: "356307042441013"}}, idents)
+	if assert.Len(t, sess.sent, 1) {
+		assert.Equal(t, []*models.NavRecord{{Imei: "356307042441013", Client: 501}}, sess.sent[0])
+	}
+	// запись из потока не изменена
+	assert.Zero(t, recs[0].Client)
+	assert.Equal(t, uint64(2), f.Stats().Skipped.Load())
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, testConfig().Validate())
+	assert.Error(t, Config{AuthMode: AuthDispatcher}.Validate())
+	assert.Error(t, Config{Address: "host:1", AuthMode: "token"}.Validate())
+
+	cfg := testConfig()
+	cfg.DeviceIDs = map[string]uint32{"356307042441013": 0}
+	assert.Error(t, cfg.Validate())
+}
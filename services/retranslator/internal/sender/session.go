@@ -0,0 +1,250 @@
+package sender
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/egts"
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/pkg/models"
+)
+
+// recordsPerPacket - сколько навигационных записей передавать в одном пакете.
+// Запись занимает 40-80 байт, так что пакет заведомо меньше предельных 65517 байт.
+const recordsPerPacket = 50
+
+// ErrNoResponse - сервер не подтвердил пакет после всех повторов
+var ErrNoResponse = errors.New("no EGTS_PT_RESPONSE from server")
+
+// Identity - учетные данные, с которыми сессия авторизуется на сервере
+type Identity struct {
+	Mode         string // AuthDispatcher или AuthTerminal
+	DispatcherID uint32 // ID диспетчера для EGTS_SR_DISPATCHER_IDENTITY
+	Description  string // краткое описание диспетчера
+	TID          uint32 // идентификатор терминала для EGTS_SR_TERM_IDENTITY
+	IMEI         string // IMEI терминала, если известен
+}
+
+// Session - клиентская сессия ЕГТС с одним сервером. Пакеты передаются по одному:
+// следующий отправляется после EGTS_PT_RESPONSE на предыдущий (ГОСТ 33472-2015, п. 5.8).
+type Session struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	stats  *Stats
+
+	responseTimeout time.Duration // TL_RESPONSE_TO
+	resendAttempts  int           // TL_RESEND_ATTEMPTS
+
+	pid uint16 // счетчик пакетов транспортного уровня
+	rn  uint16 // счетчик записей уровня поддержки услуг
+
+	resultCode *uint8 // результат авторизации из EGTS_SR_RESULT_CODE
+}
+
+// Dial подключается к серверу и проходит авторизацию
+func Dial(ctx context.Context, cfg Config, ident Identity, stats *Stats) (*Session, error) {
+	dialer := net.Dialer{Timeout: cfg.DialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", cfg.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	s := newSession(conn, cfg, stats)
+	if err := s.auth(ident); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("authorization on %s failed: %w", cfg.Address, err)
+	}
+	return s, nil
+}
+
+func newSession(conn net.Conn, cfg Config, stats *Stats) *Session {
+	if stats == nil {
+		stats = &Stats{}
+	}
+	return &Session{
+		conn:            conn,
+		reader:          bufio.NewReader(conn),
+		stats:           stats,
+		responseTimeout: cfg.ResponseTimeout,
+		resendAttempts:  cfg.ResendAttempts,
+	}
+}
+
+// Close закрывает соединение с сервером
+func (s *Session) Close() error {
+	return s.conn.Close()
+}
+
+func (s *Session) nextPID() uint16 {
+	pid := s.pid
+	s.pid++
+	return pid
+}
+
+// nextRN резервирует count номеров записей и возвращает первый из них
+func (s *Session) nextRN(count int) uint16 {
+	rn := s.rn
+	s.rn += uint16(count)
+	return rn
+}
+
+// auth отправляет учетные данные и ждет EGTS_SR_RESULT_CODE
+func (s *Session) auth(ident Identity) error {
+	var (
+		packet []byte
+		err    error
+	)
+	pid := s.nextPID()
+	switch ident.Mode {
+	case AuthDispatcher:
+		packet, err = egts.EncodeDispatcherIdentity(pid, s.nextRN(1), ident.DispatcherID, ident.Description)
+	case AuthTerminal:
+		packet, err = egts.EncodeTermIdentity(pid, s.nextRN(1), ident.TID, ident.IMEI)
+	default:
+		err = fmt.Errorf("unknown auth mode: %s", ident.Mode)
+	}
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.exchange(pid, packet)
+	if err != nil {
+		return err
+	}
+	if resp.ProcessingResult != egts.EGTS_PC_OK {
+		return fmt.Errorf("identity packet rejected with code %d", resp.ProcessingResult)
+	}
+
+	// результат авторизации приходит отдельным пакетом, обычно сразу за подтверждением
+	deadline := time.Now().Add(s.responseTimeout)
+	for s.resultCode == nil {
+		if _, err := s.readPackage(deadline); err != nil {
+			return fmt.Errorf("no EGTS_SR_RESULT_CODE: %w", err)
+		}
+	}
+	if *s.resultCode != egts.EGTS_PC_OK {
+		return fmt.Errorf("access denied with code %d", *s.resultCode)
+	}
+	return nil
+}
+
+// Send передает записи на сервер пакетами по recordsPerPacket записей
+func (s *Session) Send(recs []*models.NavRecord) error {
+	for len(recs) > 0 {
+		n := min(len(recs), recordsPerPacket)
+
+		pid := s.nextPID()
+		packet, err := egts.EncodeNavRecords(pid, s.nextRN(n), recs[:n])
+		if err != nil {
+			return fmt.Errorf("failed to encode records: %w", err)
+		}
+
+		resp, err := s.exchange(pid, packet)
+		if err != nil {
+			return err
+		}
+		if resp.ProcessingResult != egts.EGTS_PC_OK {
+			return fmt.Errorf("packet %d rejected with code %d", pid, resp.ProcessingResult)
+		}
+
+		s.stats.PacketsSent.Add(1)
+		s.stats.RecordsSent.Add(uint64(n))
+		s.stats.LastSendTime.Store(time.Now().Unix())
+		recs = recs[n:]
+	}
+	return nil
+}
+
+// exchange отправляет пакет pid и ждет подтверждения на него. Если за TL_RESPONSE_TO
+// подтверждения нет, пакет отправляется повторно, всего не более TL_RESEND_ATTEMPTS раз.
+func (s *Session) exchange(pid uint16, packet []byte) (*egts.PtResponse, error) {
+	for attempt := 0; attempt <= s.resendAttempts; attempt++ {
+		if attempt > 0 {
+			s.stats.Retransmits.Add(1)
+			logger.Debugf("Resending EGTS packet %d to %s, attempt %d", pid, s.conn.RemoteAddr(), attempt)
+		}
+
+		if err := s.conn.SetWriteDeadline(time.Now().Add(s.responseTimeout)); err != nil {
+			return nil, err
+		}
+		if _, err := s.conn.Write(packet); err != nil {
+			return nil, fmt.Errorf("failed to send packet %d: %w", pid, err)
+		}
+
+		deadline := time.Now().Add(s.responseTimeout)
+		for {
+			resp, err := s.readPackage(deadline)
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if resp != nil && resp.ResponsePacketID == pid {
+				return resp, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("packet %d: %w", pid, ErrNoResponse)
+}
+
+// readPackage читает один пакет сервера до deadline. Возвращает подтверждение, если это
+// EGTS_PT_RESPONSE; на пакеты с данными сервера сразу отправляет подтверждение.
+func (s *Session) readPackage(deadline time.Time) (*egts.PtResponse, error) {
+	if err := s.conn.SetReadDeadline(deadline); err != nil {
+		return nil, err
+	}
+	raw, err := egts.ReadPackage(s.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := egts.Package{}
+	if _, err := pkg.Decode(raw); err != nil {
+		return nil, fmt.Errorf("failed to decode server packet: %w", err)
+	}
+
+	switch pkg.PacketType {
+	case egts.EGTS_PT_RESPONSE:
+		resp, _ := pkg.ServicesFrameData.(*egts.PtResponse)
+		return resp, nil
+	case egts.EGTS_PT_APPDATA:
+		sdrs, ok := pkg.ServicesFrameData.(*egts.ServiceDataSet)
+		if !ok {
+			return nil, nil
+		}
+		if code := egts.FindResultCode(sdrs); code != nil {
+			result := code.ResultCode
+			s.resultCode = &result
+		}
+		return nil, s.confirm(pkg.PacketIdentifier, sdrs)
+	}
+	return nil, nil
+}
+
+// confirm подтверждает пакет сервера с записями sdrs
+func (s *Session) confirm(rpid uint16, sdrs *egts.ServiceDataSet) error {
+	statuses := make([]egts.RecordStatus, 0, len(*sdrs))
+	for _, sdr := range *sdrs {
+		statuses = append(statuses, egts.RecordStatus{
+			RecordNumber: sdr.RecordNumber,
+			Service:      sdr.SourceServiceType,
+			Status:       egts.EGTS_PC_OK,
+		})
+	}
+
+	answer, err := egts.EncodePtResponse(s.nextPID(), rpid, egts.EGTS_PC_OK, s.nextRN(len(statuses)), statuses)
+	if err != nil {
+		return err
+	}
+	if err := s.conn.SetWriteDeadline(time.Now().Add(s.responseTimeout)); err != nil {
+		return err
+	}
+	_, err = s.conn.Write(answer)
+	return err
+}
@@ -0,0 +1,157 @@
+package sender
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/egts"
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// testServer - сервер ЕГТС на другом конце net.Pipe: подтверждает пакеты и на запрос
+// авторизации отвечает кодом authResult. Пакеты с данными из drop не подтверждаются
+// (номер по порядку, начиная с 1), имитируя потерю ответа.
+type testServer struct {
+	conn       net.Conn
+	authResult uint8
+	drop       map[int]bool
+
+	received chan *egts.Package
+}
+
+func newTestServer(conn net.Conn, authResult uint8, drop ...int) *testServer {
+	s := &testServer{conn: conn, authResult: authResult, drop: map[int]bool{}, received: make(chan *egts.Package, 16)}
+	for _, n := range drop {
+		s.drop[n] = true
+	}
+	go s.serve()
+	return s
+}
+
+func (s *testServer) serve() {
+	var pid, dataPackets int
+	for {
+		raw, err := egts.ReadPackage(s.conn)
+		if err != nil {
+			return
+		}
+		pkg := &egts.Package{}
+		if _, err := pkg.Decode(raw); err != nil || pkg.PacketType != egts.EGTS_PT_APPDATA {
+			continue
+		}
+		sdrs := pkg.ServicesFrameData.(*egts.ServiceDataSet)
+
+		if (*sdrs)[0].SourceServiceType == egts.SERVICE_DATA {
+			dataPackets++
+			if s.drop[dataPackets] {
+				continue
+			}
+			s.received <- pkg
+		}
+
+		answer, _ := egts.EncodePtResponse(uint16(pid), pkg.PacketIdentifier, egts.EGTS_PC_OK, 0, nil)
+		pid++
+		s.conn.Write(answer)
+
+		if (*sdrs)[0].SourceServiceType == egts.SERVICE_AUTH {
+			answer, _ = egts.EncodeResultCode(uint16(pid), 0, s.authResult)
+			pid++
+			s.conn.Write(answer)
+		}
+	}
+}
+
+func testConfig() Config {
+	return Config{Address: "pipe", AuthMode: AuthDispatcher, DispatcherID: 10, ResponseTimeout: 100 * time.Millisecond, ResendAttempts: 2}
+}
+
+func TestSession_AuthAndSend(t *testing.T) {
+	level, _ := logger.ParseLevel("ERROR")
+	logger.Init(level)
+
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	srv := newTestServer(server, egts.EGTS_PC_OK)
+
+	stats := &Stats{}
+	s := newSession(client, testConfig(), stats)
+	if !assert.NoError(t, s.auth(Identity{Mode: AuthDispatcher, DispatcherID: 10})) {
+		return
+	}
+
+	recs := make([]*models.NavRecord, recordsPerPacket+1)
+	for i := range recs {
+		recs[i] = &models.NavRecord{Client: 7, NavigationTime: time.Unix(1700000000+int64(i), 0).UTC(), Valid: true}
+	}
+	assert.NoError(t, s.Send(recs))
+
+	first := <-srv.received
+	assert.Len(t, *first.ServicesFrameData.(*egts.ServiceDataSet), recordsPerPacket)
+	second := <-srv.received
+	assert.Len(t, *second.ServicesFrameData.(*egts.ServiceDataSet), 1)
+
+	assert.Equal(t, uint64(2), stats.PacketsSent.Load())
+	assert.Equal(t, uint64(recordsPerPacket+1), stats.RecordsSent.Load())
+	assert.Zero(t, stats.Retransmits.Load())
+}
+
+func TestSession_Retransmit(t *testing.T) {
+	level, _ := logger.ParseLevel("ERROR")
+	logger.Init(level)
+
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	// первый пакет с данными теряется, повтор подтверждается
+	srv := newTestServer(server, egts.EGTS_PC_OK, 1)
+
+	stats := &Stats{}
+	s := newSession(client, testConfig(), stats)
+	if !assert.NoError(t, s.auth(Identity{Mode: AuthTerminal, TID: 7})) {
+		return
+	}
+
+	assert.NoError(t, s.Send([]*models.NavRecord{{Client: 7}}))
+	pkg := <-srv.received
+	// повтор - тот же пакет с тем же PID: 0 - авторизация, 1 - подтверждение EGTS_SR_RESULT_CODE
+	assert.Equal(t, uint16(2), pkg.PacketIdentifier)
+	assert.Equal(t, uint64(1), stats.Retransmits.Load())
+}
+
+func TestSession_NoResponse(t *testing.T) {
+	level, _ := logger.ParseLevel("ERROR")
+	logger.Init(level)
+
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	newTestServer(server, egts.EGTS_PC_OK, 1, 2, 3)
+
+	stats := &Stats{}
+	s := newSession(client, testConfig(), stats)
+	if !assert.NoError(t, s.auth(Identity{Mode: AuthDispatcher})) {
+		return
+	}
+
+	err := s.Send([]*models.NavRecord{{Client: 7}})
+	assert.True(t, errors.Is(err, ErrNoResponse))
+	assert.Equal(t, uint64(2), stats.Retransmits.Load())
+}
+
+func TestSession_AuthDenied(t *testing.T) {
+	level, _ := logger.ParseLevel("ERROR")
+	logger.Init(level)
+
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	newTestServer(server, egts.EGTS_PC_AUTH_DENIED)
+
+	s := newSession(client, testConfig(), nil)
+	assert.Error(t, s.auth(Identity{Mode: AuthDispatcher}))
+}
@@ -0,0 +1,18 @@
+package main // Важный момент: этот файл относится к пакету main
+
+import "github.com/rackov/NavControlSystem/pkg/monitoring"
+
+// ServiceMetrics - глобальные метрики сервиса WRITER, доступны из любого файла пакета main.
+var ServiceMetrics *monitoring.ServiceMetrics
+
+// InitMetrics инициализирует и регистрирует все метрики для сервиса WRITER.
+func InitMetrics(serviceName string) {
+	ServiceMetrics = monitoring.NewServiceMetrics(serviceName)
+
+	ServiceMetrics.RegisterGauge("nats_connected", "NATS connection state (1 - connected).")
+	ServiceMetrics.RegisterGauge("writer_paused", "Reading from NATS is paused (1 - paused).")
+	ServiceMetrics.RegisterGauge("consumer_pending", "Messages in the stream not yet delivered to the writer.")
+
+	ServiceMetrics.SetGauge("nats_connected", 0) // 0 - отключен
+	ServiceMetrics.SetGauge("writer_paused", 0)
+}
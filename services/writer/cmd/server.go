@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/pkg/models"
+	"github.com/rackov/NavControlSystem/pkg/postgres"
+	"github.com/rackov/NavControlSystem/pkg/tnats"
+	"github.com/rackov/NavControlSystem/proto"
+	"github.com/rackov/NavControlSystem/services/writer/internal/nats"
+	pgwriter "github.com/rackov/NavControlSystem/services/writer/internal/postgres"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// WriterServer реализует gRPC-сервер и управляет жизненным циклом
+// сервиса WRITER: читает записи из NATS и сохраняет их в PostgreSQL.
+type WriterServer struct {
+	proto.UnimplementedWriterControlServer
+	cfg        *Config
+	nats       *tnats.Client
+	pool       *pgxpool.Pool
+	writer     *pgwriter.Writer
+	subscriber *nats.Subscriber
+	grpcServer *grpc.Server
+	wg         sync.WaitGroup // горутина чтения из NATS
+
+	// Статистика с момента запуска
+	recordsWritten atomic.Uint64
+	batchesWritten atomic.Uint64
+	writeErrors    atomic.Uint64
+	decodeErrors   atomic.Uint64
+	lastWriteTime  atomic.Int64 // Unix timestamp в секундах
+
+	lastErrMu sync.Mutex
+	lastErr   string
+}
+
+// NewWriterServer создает новый экземпляр сервера.
+func NewWriterServer(cfg *Config) *WriterServer {
+	return &WriterServer{cfg: cfg}
+}
+
+// Start подключается к БД и NATS, запускает чтение потока и gRPC-сервер.
+func (s *WriterServer) Start(ctx context.Context) error {
+	logger.Info("Starting WRITER server...")
+
+	// 1. PostgreSQL: без БД писать некуда, поэтому ошибка подключения фатальна
+	pool, err := postgres.Connect(ctx, s.cfg.postgresConfig())
+	if err != nil {
+		return fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+	}
+	s.pool = pool
+	if s.cfg.Postgres.Migrate {
+		applied, err := postgres.Migrate(ctx, pool)
+		if err != nil {
+			return fmt.Errorf("failed to apply migrations: %w", err)
+		}
+		logger.Infof("Database schema is up to date (%d migrations applied)", applied)
+	}
+	s.writer = pgwriter.NewWriter(pool)
+
+	// 2. NATS: подключение в фоне, подписчик ждет соединения сам
+	natsCfg := tnats.DefaultConfig(s.cfg.NatsURL)
+	natsCfg.Name = "writer"
+	natsCfg.MaxReconnects = s.cfg.Nats.MaxReconnects
+	natsCfg.ReconnectWait = s.cfg.Nats.ReconnectWait
+	natsCfg.RetryOnFailedConnect = true
+	natsCfg.OnStateChange = func(state tnats.State, err error) {
+		switch state {
+		case tnats.StateConnected, tnats.StateReconnected:
+			ServiceMetrics.SetGauge("nats_connected", 1)
+		default:
+			ServiceMetrics.SetGauge("nats_connected", 0)
+		}
+	}
+	client, err := tnats.NewClient(natsCfg, logger.GetLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create NATS client: %w", err)
+	}
+	s.nats = client
+
+	// 3. Чтение потока
+	s.subscriber = nats.NewSubscriber(client, s.cfg.subscriberConfig(), s)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.subscriber.Run(ctx)
+	}()
+	go s.pendingLoop(ctx)
+
+	// 4. gRPC сервер
+	if err := s.startGrpcServer(); err != nil {
+		return fmt.Errorf("failed to start gRPC server: %w", err)
+	}
+	logger.Infof("gRPC server started on port %d", s.cfg.GrpcPort)
+
+	logger.Info("WRITER server started successfully")
+	return nil
+}
+
+// Stop останавливает компоненты сервиса. Контекст Start к этому моменту должен быть отменен.
+func (s *WriterServer) Stop() {
+	logger.Info("Shutting down WRITER server...")
+
+	if s.grpcServer != nil {
+		logger.Debug("Stopping gRPC server...")
+		s.grpcServer.GracefulStop()
+	}
+
+	// Ждем, пока допишется текущая пачка
+	s.wg.Wait()
+
+	if s.nats != nil {
+		logger.Debug("Closing NATS connection...")
+		s.nats.Close()
+		ServiceMetrics.SetGauge("nats_connected", 0)
+	}
+	if s.pool != nil {
+		s.pool.Close()
+	}
+	logger.Info("WRITER server stopped.")
+}
+
+// --- Обработка записей (nats.Handler) ---
+
+// HandleBatch сохраняет пачку записей в БД.
+func (s *WriterServer) HandleBatch(ctx context.Context, recs []*models.NavRecord) error {
+	start := time.Now()
+	if err := s.writer.Write(ctx, recs); err != nil {
+		s.writeErrors.Add(1)
+		s.setLastError(err)
+		ServiceMetrics.IncErrorCounter("db_write_failed")
+		logger.Errorf("Failed to write batch of %d records: %v", len(recs), err)
+		return err
+	}
+
+	s.recordsWritten.Add(uint64(len(recs)))
+	s.batchesWritten.Add(1)
+	s.lastWriteTime.Store(time.Now().Unix())
+	ServiceMetrics.AddOperations("records_written", len(recs))
+	ServiceMetrics.IncOperationCounter("batches_written")
+	ServiceMetrics.ObserveOperationDuration("db_write_batch", time.Since(start))
+	logger.Debugf("Wrote batch of %d records in %s", len(recs), time.Since(start))
+	return nil
+}
+
+// HandleInvalid учитывает сообщение, которое не удалось разобрать.
+func (s *WriterServer) HandleInvalid(subject string, err error) {
+	s.decodeErrors.Add(1)
+	ServiceMetrics.IncErrorCounter("record_decode_failed")
+	logger.Warnf("Dropping undecodable message from %s: %v", subject, err)
+}
+
+func (s *WriterServer) setLastError(err error) {
+	s.lastErrMu.Lock()
+	s.lastErr = err.Error()
+	s.lastErrMu.Unlock()
+}
+
+func (s *WriterServer) lastError() string {
+	s.lastErrMu.Lock()
+	defer s.lastErrMu.Unlock()
+	return s.lastErr
+}
+
+// pendingLoop раз в 15 секунд обновляет метрику отставания от потока.
+func (s *WriterServer) pendingLoop(ctx context.Context) {
+	ticker := time.NewTicker(15 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if pending, err := s.subscriber.Pending(); err == nil {
+				ServiceMetrics.SetGauge("consumer_pending", float64(pending))
+			}
+		}
+	}
+}
+
+// --- gRPC Server Management ---
+
+// startGrpcServer инициализирует и запускает gRPC-сервер в отдельной горутине.
+func (s *WriterServer) startGrpcServer() error {
+	s.grpcServer = grpc.NewServer()
+	proto.RegisterWriterControlServer(s.grpcServer, s)
+	reflection.Register(s.grpcServer)
+
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", s.cfg.GrpcPort))
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := s.grpcServer.Serve(lis); err != nil && err != grpc.ErrServerStopped {
+			logger.Errorf("gRPC server error: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// --- gRPC Service Implementation ---
+
+// SetLogLevel изменяет глобальный уровень логирования.
+func (s *WriterServer) SetLogLevel(ctx context.Context, req *proto.SetLogLevelRequest) (*proto.SetLogLevelResponse, error) {
+	level, err := logger.ParseLevel(req.Level)
+	if err != nil {
+		logger.Errorf("Failed to parse log level '%s': %v", req.Level, err)
+		return nil, status.Errorf(codes.InvalidArgument, "invalid log level: %s", req.Level)
+	}
+
+	logger.SetGlobalLevel(level)
+	logger.Infof("Log level set to %s", req.Level)
+	return &proto.SetLogLevelResponse{Success: true}, nil
+}
+
+// GetWriterStatus возвращает состояние подключений и статистику записи.
+func (s *WriterServer) GetWriterStatus(ctx context.Context, req *proto.GetWriterStatusRequest) (*proto.GetWriterStatusResponse, error) {
+	pingCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	response := &proto.GetWriterStatusResponse{
+		NatsConnected:  s.nats.IsConnected(),
+		DbConnected:    s.writer.Ping(pingCtx) == nil,
+		Paused:         s.subscriber.Paused(),
+		Stream:         s.cfg.Nats.Stream,
+		Consumer:       s.cfg.Nats.Durable,
+		RecordsWritten: s.recordsWritten.Load(),
+		BatchesWritten: s.batchesWritten.Load(),
+		WriteErrors:    s.writeErrors.Load(),
+		DecodeErrors:   s.decodeErrors.Load(),
+		LastWriteTime:  s.lastWriteTime.Load(),
+		LastError:      s.lastError(),
+	}
+	if pending, err := s.subscriber.Pending(); err == nil {
+		response.Pending = pending
+	}
+
+	logger.Debugf("GRPC call: GetWriterStatus. Written %d records.", response.RecordsWritten)
+	return response, nil
+}
+
+// Pause приостанавливает чтение из NATS. Записи копятся в потоке JetStream.
+func (s *WriterServer) Pause(ctx context.Context, req *proto.WriterControlRequest) (*proto.WriterControlResponse, error) {
+	s.subscriber.Pause()
+	ServiceMetrics.SetGauge("writer_paused", 1)
+	logger.Info("GRPC call: Pause. Reading from NATS is paused.")
+	return &proto.WriterControlResponse{Success: true, Message: "writer paused", Paused: true}, nil
+}
+
+// Resume возобновляет чтение из NATS.
+func (s *WriterServer) Resume(ctx context.Context, req *proto.WriterControlRequest) (*proto.WriterControlResponse, error) {
+	s.subscriber.Resume()
+	ServiceMetrics.SetGauge("writer_paused", 0)
+	logger.Info("GRPC call: Resume. Reading from NATS is resumed.")
+	return &proto.WriterControlResponse{Success: true, Message: "writer resumed", Paused: false}, nil
+}
@@ -0,0 +1,258 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/pkg/models"
+	"github.com/rackov/NavControlSystem/pkg/tnats"
+	"github.com/rackov/NavControlSystem/proto"
+)
+
+// contentTypeHdr - заголовок с кодировкой записи (см. proto.MarshalNavRecord)
+const contentTypeHdr = "Content-Type"
+
+// Config - параметры durable-потребителя JetStream
+type Config struct {
+	Stream         string        // поток, в который RECEIVER публикует записи
+	Durable        string        // имя durable-потребителя; позиция чтения сохраняется между перезапусками
+	FilterSubjects []string      // топики потока, которые читает потребитель; пусто - все
+	BatchSize      int           // сколько сообщений запрашивать за раз (размер пачки вставки)
+	FetchWait      time.Duration // сколько ждать набора пачки
+	AckWait        time.Duration // сколько JetStream ждет подтверждения до повторной доставки
+	MaxDeliver     int           // число попыток доставки сообщения; 0 - без ограничения
+	RetryDelay     time.Duration // пауза перед повтором после ошибки записи
+}
+
+// consumerConfig возвращает конфигурацию потребителя для nats.go
+func (c Config) consumerConfig() *nats.ConsumerConfig {
+	cc := &nats.ConsumerConfig{
+		Durable:       c.Durable,
+		DeliverPolicy: nats.DeliverAllPolicy,
+		AckPolicy:     nats.AckExplicitPolicy,
+		AckWait:       c.AckWait,
+		MaxDeliver:    c.MaxDeliver,
+		// неподтвержденных сообщений не больше двух пачек: одна пишется, вторая ждет повтора
+		MaxAckPending: 2 * c.BatchSize,
+	}
+	if c.MaxDeliver == 0 {
+		cc.MaxDeliver = -1
+	}
+	switch len(c.FilterSubjects) {
+	case 0:
+	case 1:
+		cc.FilterSubject = c.FilterSubjects[0]
+	default:
+		cc.FilterSubjects = c.FilterSubjects
+	}
+	return cc
+}
+
+// Handler получает записи, прочитанные из потока
+type Handler interface {
+	// HandleBatch сохраняет пачку записей. Если вернуть ошибку, пачка не подтверждается
+	// и будет доставлена повторно через Config.RetryDelay.
+	HandleBatch(ctx context.Context, recs []*models.NavRecord) error
+	// HandleInvalid сообщает о сообщении, которое не удалось разобрать. Такое сообщение
+	// подтверждается как окончательно обработанное (Term) и больше не доставляется.
+	HandleInvalid(subject string, err error)
+}
+
+// Subscriber читает навигационные записи из JetStream пачками и передает их Handler.
+// Сообщения подтверждаются только после успешной обработки пачки.
+type Subscriber struct {
+	client  *tnats.Client
+	cfg     Config
+	handler Handler
+
+	mu  sync.Mutex
+	sub *nats.Subscription
+
+	paused atomic.Bool
+}
+
+// NewSubscriber создает подписчика. Чтение начинается в Run.
+func NewSubscriber(client *tnats.Client, cfg Config, handler Handler) *Subscriber {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	if cfg.FetchWait <= 0 {
+		cfg.FetchWait = time.Second
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = 5 * time.Second
+	}
+	return &Subscriber{client: client, cfg: cfg, handler: handler}
+}
+
+// Pause приостанавливает чтение. Пачка, которая уже пишется, дописывается.
+func (s *Subscriber) Pause() {
+	s.paused.Store(true)
+}
+
+// Resume возобновляет чтение
+func (s *Subscriber) Resume() {
+	s.paused.Store(false)
+}
+
+// Paused сообщает, приостановлено ли чтение
+func (s *Subscriber) Paused() bool {
+	return s.paused.Load()
+}
+
+// Pending возвращает число сообщений потока, еще не доставленных потребителю
+func (s *Subscriber) Pending() (uint64, error) {
+	s.mu.Lock()
+	sub := s.sub
+	s.mu.Unlock()
+	if sub == nil {
+		return 0, tnats.ErrNotConnected
+	}
+	info, err := sub.ConsumerInfo()
+	if err != nil {
+		return 0, err
+	}
+	return info.NumPending, nil
+}
+
+// Run читает поток до отмены ctx. Если NATS, поток или потребитель недоступны,
+// Run повторяет попытки, а не завершается.
+func (s *Subscriber) Run(ctx context.Context) {
+	defer s.unsubscribe()
+
+	for ctx.Err() == nil {
+		if s.paused.Load() || !s.client.IsConnected() {
+			sleep(ctx, s.cfg.FetchWait)
+			continue
+		}
+
+		sub, err := s.subscription()
+		if err != nil {
+			logger.Errorf("Failed to subscribe to stream %s: %v", s.cfg.Stream, err)
+			sleep(ctx, s.cfg.RetryDelay)
+			continue
+		}
+
+		fetchCtx, cancel := context.WithTimeout(ctx, s.cfg.FetchWait)
+		msgs, err := sub.Fetch(s.cfg.BatchSize, nats.Context(fetchCtx))
+		cancel()
+		switch {
+		case err == nil:
+		case errors.Is(err, context.DeadlineExceeded), errors.Is(err, nats.ErrTimeout), errors.Is(err, context.Canceled):
+			// за FetchWait новых сообщений не было
+			continue
+		case errors.Is(err, nats.ErrConsumerDeleted), errors.Is(err, nats.ErrConsumerNotFound), errors.Is(err, nats.ErrBadSubscription):
+			logger.Warnf("Consumer %s is lost, resubscribing: %v", s.cfg.Durable, err)
+			s.unsubscribe()
+			continue
+		default:
+			logger.Warnf("Failed to fetch messages from stream %s: %v", s.cfg.Stream, err)
+			sleep(ctx, s.cfg.RetryDelay)
+			continue
+		}
+
+		if !s.process(ctx, msgs) {
+			sleep(ctx, s.cfg.RetryDelay)
+		}
+	}
+}
+
+// process разбирает пачку, передает ее Handler и подтверждает сообщения.
+// Возвращает false, если пачку не удалось сохранить.
+func (s *Subscriber) process(ctx context.Context, msgs []*nats.Msg) bool {
+	recs, valid := s.decode(msgs)
+	if len(recs) == 0 {
+		return true
+	}
+
+	if err := s.handler.HandleBatch(ctx, recs); err != nil {
+		for _, msg := range valid {
+			if err := msg.NakWithDelay(s.cfg.RetryDelay); err != nil {
+				logger.Debugf("Failed to nak message: %v", err)
+			}
+		}
+		return false
+	}
+
+	for _, msg := range valid {
+		if err := msg.Ack(); err != nil {
+			// сообщение будет доставлено повторно по AckWait, повтор отбросит уникальный ключ в БД
+			logger.Warnf("Failed to ack message %s: %v", msg.Subject, err)
+		}
+	}
+	return true
+}
+
+// decode разбирает записи пачки. Неразборчивые сообщения сразу снимаются с доставки.
+func (s *Subscriber) decode(msgs []*nats.Msg) ([]*models.NavRecord, []*nats.Msg) {
+	recs := make([]*models.NavRecord, 0, len(msgs))
+	valid := make([]*nats.Msg, 0, len(msgs))
+	for _, msg := range msgs {
+		rec, err := proto.UnmarshalNavRecord(msg.Data, msg.Header.Get(contentTypeHdr))
+		if err != nil {
+			s.handler.HandleInvalid(msg.Subject, err)
+			if err := msg.Term(); err != nil {
+				logger.Debugf("Failed to terminate message: %v", err)
+			}
+			continue
+		}
+		recs = append(recs, rec)
+		valid = append(valid, msg)
+	}
+	return recs, valid
+}
+
+// subscription возвращает pull-подписку, при необходимости создавая потребителя
+func (s *Subscriber) subscription() (*nats.Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sub != nil {
+		return s.sub, nil
+	}
+
+	if _, err := s.client.EnsureConsumer(s.cfg.Stream, s.cfg.consumerConfig()); err != nil {
+		return nil, err
+	}
+	js, err := s.client.JetStream()
+	if err != nil {
+		return nil, err
+	}
+	sub, err := js.PullSubscribe("", s.cfg.Durable, nats.Bind(s.cfg.Stream, s.cfg.Durable))
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind to consumer %s: %w", s.cfg.Durable, err)
+	}
+	logger.Infof("Subscribed to stream %s as durable consumer %s", s.cfg.Stream, s.cfg.Durable)
+	s.sub = sub
+	return sub, nil
+}
+
+// unsubscribe снимает локальную подписку. Durable-потребитель на сервере остается.
+func (s *Subscriber) unsubscribe() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sub != nil {
+		// потребитель создан EnsureConsumer, а не библиотекой, поэтому при отписке он не удаляется
+		if err := s.sub.Unsubscribe(); err != nil {
+			logger.Debugf("Failed to unsubscribe: %v", err)
+		}
+		s.sub = nil
+	}
+}
+
+// sleep ждет d или отмены ctx
+func sleep(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
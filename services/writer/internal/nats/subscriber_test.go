@@ -0,0 +1,96 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/pkg/models"
+	"github.com/rackov/NavControlSystem/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+type testHandler struct {
+	batches [][]*models.NavRecord
+	invalid []string
+	err     error
+}
+
+func (h *testHandler) HandleBatch(ctx context.Context, recs []*models.NavRecord) error {
+	h.batches = append(h.batches, recs)
+	return h.err
+}
+
+func (h *testHandler) HandleInvalid(subject string, err error) {
+	h.invalid = append(h.invalid, subject)
+}
+
+func TestConfig_ConsumerConfig(t *testing.T) {
+	cc := Config{Durable: "writer", BatchSize: 100, AckWait: 30 * time.Second}.consumerConfig()
+	assert.Equal(t, "writer", cc.Durable)
+	assert.Equal(t, nats.AckExplicitPolicy, cc.AckPolicy)
+	assert.Equal(t, -1, cc.MaxDeliver)
+	assert.Equal(t, 200, cc.MaxAckPending)
+	assert.Empty(t, cc.FilterSubject)
+	assert.Empty(t, cc.FilterSubjects)
+
+	cc = Config{Durable: "writer", FilterSubjects: []string{"nav.*.*.*"}, MaxDeliver: 10}.consumerConfig()
+	assert.Equal(t, "nav.*.*.*", cc.FilterSubject)
+	assert.Equal(t, 10, cc.MaxDeliver)
+
+	cc = Config{Durable: "writer", FilterSubjects: []string{"nav.*.*.*", "nav.alarms.*.*.*"}}.consumerConfig()
+	assert.Empty(t, cc.FilterSubject)
+	assert.Equal(t, []string{"nav.*.*.*", "nav.alarms.*.*.*"}, cc.FilterSubjects)
+}
+
+func TestSubscriber_Process(t *testing.T) {
+	level, _ := logger.ParseLevel("ERROR")
+	logger.Init(level)
+
+	rec := &models.NavRecord{Client: 1, PacketID: 5, Valid: true, Latitude: 55.75}
+	jsonData, _, _ := proto.MarshalNavRecord(rec, proto.EncodingJSON)
+	pbData, contentType, _ := proto.MarshalNavRecord(rec, proto.EncodingProtobuf)
+
+	pbMsg := nats.NewMsg("nav.egts.p1.1")
+	pbMsg.Header.Set(contentTypeHdr, contentType)
+	pbMsg.Data = pbData
+
+	msgs := []*nats.Msg{
+		{Subject: "nav.egts.p1.1", Data: jsonData}, // без заголовка - JSON
+		pbMsg,
+		{Subject: "nav.egts.p1.2", Data: []byte("{broken")},
+	}
+
+	h := &testHandler{}
+	s := NewSubscriber(nil, Config{}, h)
+	// сообщения не привязаны к подписке, поэтому подтверждения лишь логируются
+	assert.True(t, s.process(context.Background(), msgs))
+	if assert.Len(t, h.batches, 1) && assert.Len(t, h.batches[0], 2) {
+		assert.Equal(t, uint32(5), h.batches[0][1].PacketID)
+		assert.Equal(t, 55.75, h.batches[0][1].Latitude)
+	}
+	assert.Equal(t, []string{"nav.egts.p1.2"}, h.invalid)
+
+	// ошибка записи - пачка не подтверждается
+	h = &testHandler{err: errors.New("db is down")}
+	s = NewSubscriber(nil, Config{}, h)
+	assert.False(t, s.process(context.Background(), msgs[:1]))
+
+	// пачка только из неразборчивых сообщений в Handler не передается
+	h = &testHandler{}
+	s = NewSubscriber(nil, Config{}, h)
+	assert.True(t, s.process(context.Background(), msgs[2:]))
+	assert.Empty(t, h.batches)
+}
+
+func TestSubscriber_Pause(t *testing.T) {
+	s := NewSubscriber(nil, Config{}, &testHandler{})
+	assert.False(t, s.Paused())
+	s.Pause()
+	assert.True(t, s.Paused())
+	s.Resume()
+	assert.False(t, s.Paused())
+}
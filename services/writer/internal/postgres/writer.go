@@ -0,0 +1,273 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rackov/NavControlSystem/pkg/logger"
+	"github.com/rackov/NavControlSystem/pkg/models"
+	pg "github.com/rackov/NavControlSystem/pkg/postgres"
+)
+
+const upsertDeviceSQL = `INSERT INTO devices (device_id, client, imei, imsi, protocol, port_id, last_seen, last_nav_time)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (device_id) DO UPDATE SET
+	client = EXCLUDED.client,
+	imei = COALESCE(NULLIF(EXCLUDED.imei, ''), devices.imei),
+	imsi = COALESCE(NULLIF(EXCLUDED.imsi, ''), devices.imsi),
+	protocol = EXCLUDED.protocol,
+	port_id = EXCLUDED.port_id,
+	last_seen = GREATEST(devices.last_seen, EXCLUDED.last_seen),
+	last_nav_time = GREATEST(devices.last_nav_time, EXCLUDED.last_nav_time)
+RETURNING id`
+
+const insertPositionSQL = `INSERT INTO positions (device_id, nav_time, packet_id, received_time, kind,
+	latitude, longitude, altitude, speed, course, satellites, pdop, hdop, vdop, nav_system, odometer,
+	valid, moving, black_box, alarm, event, digital_inputs, digital_outputs, params)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)
+ON CONFLICT DO NOTHING`
+
+const insertSensorSQL = `INSERT INTO sensor_values (device_id, nav_time, packet_id, name, value)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT DO NOTHING`
+
+// Writer сохраняет пачки навигационных записей в PostgreSQL
+type Writer struct {
+	pool *pgxpool.Pool
+
+	mu         sync.Mutex
+	partitions map[time.Time]bool // сутки, секции которых уже созданы
+}
+
+// NewWriter создает Writer поверх пула соединений
+func NewWriter(pool *pgxpool.Pool) *Writer {
+	return &Writer{
+		pool:       pool,
+		partitions: make(map[time.Time]bool),
+	}
+}
+
+// Ping проверяет доступность БД
+func (w *Writer) Ping(ctx context.Context) error {
+	return w.pool.Ping(ctx)
+}
+
+// Write сохраняет пачку записей в одной транзакции: обновляет устройства,
+// добавляет навигационные записи и показания датчиков. Повторно доставленные
+// записи (то же устройство, время и номер пакета) пропускаются.
+func (w *Writer) Write(ctx context.Context, recs []*models.NavRecord) error {
+	if len(recs) == 0 {
+		return nil
+	}
+	if err := w.ensurePartitions(ctx, recs); err != nil {
+		return err
+	}
+
+	tx, err := w.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	ids, err := upsertDevices(ctx, tx, deviceRows(recs))
+	if err != nil {
+		return err
+	}
+
+	batch := &pgx.Batch{}
+	for _, rec := range recs {
+		id := ids[rec.DeviceID()]
+		args, err := positionArgs(id, rec)
+		if err != nil {
+			return err
+		}
+		batch.Queue(insertPositionSQL, args...)
+
+		navTime := recordTime(rec)
+		for name, value := range rec.Sensors {
+			batch.Queue(insertSensorSQL, id, navTime, int64(rec.PacketID), name, value)
+		}
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to insert records: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit batch: %w", err)
+	}
+	return nil
+}
+
+// ensurePartitions создает секции на сутки записей пачки, которых еще нет
+func (w *Writer) ensurePartitions(ctx context.Context, recs []*models.NavRecord) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, rec := range recs {
+		day := pg.PartitionDay(recordTime(rec))
+		if w.partitions[day] {
+			continue
+		}
+		if err := pg.EnsurePartitions(ctx, w.pool, day); err != nil {
+			var pgErr *pgconn.PgError
+			if !errors.As(err, &pgErr) {
+				return err
+			}
+			// БД отклонила создание секции (например, в секции по умолчанию уже есть строки
+			// за эти сутки) - записи за эти сутки остаются в секции по умолчанию
+			logger.Warnf("Partition for %s is not created, records go to the default partition: %v",
+				day.Format("2006-01-02"), err)
+		}
+		w.partitions[day] = true
+	}
+	return nil
+}
+
+// device - строка таблицы devices, собранная по записям пачки
+type device struct {
+	DeviceID    string
+	Client      uint32
+	Imei        string
+	Imsi        string
+	Protocol    string
+	PortID      string
+	LastSeen    time.Time
+	LastNavTime time.Time
+}
+
+// deviceRows собирает устройства пачки, по одному на DeviceID, с самыми поздними временами.
+// Устройства упорядочены по DeviceID, чтобы параллельные транзакции блокировали строки
+// в одном порядке.
+func deviceRows(recs []*models.NavRecord) []device {
+	byID := make(map[string]*device)
+	for _, rec := range recs {
+		id := rec.DeviceID()
+		d, ok := byID[id]
+		if !ok {
+			d = &device{DeviceID: id}
+			byID[id] = d
+		}
+		d.Client = rec.Client
+		d.Protocol = rec.Protocol
+		d.PortID = rec.PortID
+		if rec.Imei != "" {
+			d.Imei = rec.Imei
+		}
+		if rec.Imsi != "" {
+			d.Imsi = rec.Imsi
+		}
+		if rec.ReceivedTime.After(d.LastSeen) {
+			d.LastSeen = rec.ReceivedTime
+		}
+		if rec.NavigationTime.After(d.LastNavTime) {
+			d.LastNavTime = rec.NavigationTime
+		}
+	}
+
+	result := make([]device, 0, len(byID))
+	for _, d := range byID {
+		result = append(result, *d)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].DeviceID < result[j].DeviceID })
+	return result
+}
+
+// upsertDevices добавляет или обновляет устройства и возвращает их id по DeviceID
+func upsertDevices(ctx context.Context, tx pgx.Tx, devices []device) (map[string]int64, error) {
+	batch := &pgx.Batch{}
+	for _, d := range devices {
+		lastSeen := d.LastSeen
+		if lastSeen.IsZero() {
+			lastSeen = time.Now().UTC()
+		}
+		batch.Queue(upsertDeviceSQL, d.DeviceID, int64(d.Client), d.Imei, d.Imsi, d.Protocol, d.PortID,
+			lastSeen, nullTime(d.LastNavTime))
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	defer results.Close()
+
+	ids := make(map[string]int64, len(devices))
+	for _, d := range devices {
+		var id int64
+		if err := results.QueryRow().Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to upsert device %s: %w", d.DeviceID, err)
+		}
+		ids[d.DeviceID] = id
+	}
+	return ids, nil
+}
+
+// recordTime возвращает время, по которому запись попадает в секцию:
+// время навигации, а если устройство его не передало - время приема
+func recordTime(rec *models.NavRecord) time.Time {
+	switch {
+	case !rec.NavigationTime.IsZero():
+		return rec.NavigationTime.UTC()
+	case !rec.ReceivedTime.IsZero():
+		return rec.ReceivedTime.UTC()
+	default:
+		return time.Now().UTC()
+	}
+}
+
+// positionArgs возвращает параметры insertPositionSQL.
+// Отсутствующие в записи значения передаются как NULL, а не нули.
+func positionArgs(deviceID int64, rec *models.NavRecord) ([]any, error) {
+	var params []byte
+	if len(rec.Params) > 0 {
+		var err error
+		if params, err = json.Marshal(rec.Params); err != nil {
+			return nil, err
+		}
+	}
+
+	var lat, lon, speed any
+	var course, sats any
+	if rec.HasPosition() {
+		lat, lon, speed = rec.Latitude, rec.Longitude, rec.Speed
+		course, sats = int16(rec.Course), int16(rec.Satellites)
+	}
+	var alt any
+	if rec.AltitudeValid {
+		alt = rec.Altitude
+	}
+	var navSystem any
+	if rec.NavSystem != 0 {
+		navSystem = int32(rec.NavSystem)
+	}
+	receivedTime := rec.ReceivedTime
+	if receivedTime.IsZero() {
+		receivedTime = recordTime(rec)
+	}
+
+	return []any{
+		deviceID, recordTime(rec), int64(rec.PacketID), receivedTime.UTC(), rec.Kind().String(),
+		lat, lon, alt, speed, course, sats,
+		nullFloat(rec.Pdop), nullFloat(rec.Hdop), nullFloat(rec.Vdop), navSystem, nullFloat(rec.Odometer),
+		rec.Valid, rec.Moving, rec.BlackBox, rec.Alarm, rec.Event,
+		int64(rec.DigitalInputs), int64(rec.DigitalOutputs), params,
+	}, nil
+}
+
+func nullFloat(v float64) any {
+	if v == 0 {
+		return nil
+	}
+	return v
+}
+
+func nullTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC()
+}
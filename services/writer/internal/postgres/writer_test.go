@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rackov/NavControlSystem/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceRows(t *testing.T) {
+	t1 := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Minute)
+
+	recs := []*models.NavRecord{
+		{Client: 2, Imei: "866795030000000", Protocol: "EGTS", PortID: "p1", NavigationTime: t2, ReceivedTime: t2},
+		{Client: 2, Imei: "866795030000000", Imsi: "250011234567890", Protocol: "EGTS", PortID: "p1", NavigationTime: t1, ReceivedTime: t2},
+		{Client: 100, Protocol: "ARNAVI", PortID: "p2", NavigationTime: t1, ReceivedTime: t1},
+	}
+
+	rows := deviceRows(recs)
+	if assert.Len(t, rows, 2) {
+		// упорядочены по DeviceID
+		assert.Equal(t, "100", rows[0].DeviceID)
+		assert.Equal(t, "ARNAVI", rows[0].Protocol)
+
+		assert.Equal(t, "866795030000000", rows[1].DeviceID)
+		assert.Equal(t, "250011234567890", rows[1].Imsi)
+		assert.Equal(t, t2, rows[1].LastSeen)
+		assert.Equal(t, t2, rows[1].LastNavTime)
+	}
+}
+
+func TestRecordTime(t *testing.T) {
+	nav := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	rec := time.Date(2024, 1, 15, 10, 0, 5, 0, time.UTC)
+
+	assert.Equal(t, nav, recordTime(&models.NavRecord{NavigationTime: nav, ReceivedTime: rec}))
+	assert.Equal(t, rec, recordTime(&models.NavRecord{ReceivedTime: rec}))
+	assert.False(t, recordTime(&models.NavRecord{}).IsZero())
+}
+
+func TestPositionArgs(t *testing.T) {
+	nav := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	args, err := positionArgs(7, &models.NavRecord{
+		PacketID:       12,
+		NavigationTime: nav,
+		ReceivedTime:   nav.Add(time.Second),
+		Latitude:       55.7522,
+		Longitude:      37.6156,
+		Speed:          34.5,
+		Course:         300,
+		Satellites:     12,
+		Hdop:           0.5,
+		Valid:          true,
+		DigitalInputs:  0x05,
+		Params:         map[string]string{"ibutton": "01AB"},
+	})
+	if !assert.NoError(t, err) || !assert.Len(t, args, 24) {
+		return
+	}
+	assert.Equal(t, int64(7), args[0])
+	assert.Equal(t, nav, args[1])
+	assert.Equal(t, int64(12), args[2])
+	assert.Equal(t, "positions", args[4])
+	assert.Equal(t, 55.7522, args[5])
+	assert.Nil(t, args[7]) // высота не передана
+	assert.Equal(t, int16(300), args[9])
+	assert.Nil(t, args[11]) // pdop
+	assert.Equal(t, 0.5, args[12])
+	assert.Equal(t, int64(5), args[21])
+	assert.JSONEq(t, `{"ibutton":"01AB"}`, string(args[23].([]byte)))
+
+	// запись без координат: координаты и скорость - NULL, а не нули
+	args, err = positionArgs(7, &models.NavRecord{ReceivedTime: nav, DigitalInputs: 1})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "sensors", args[4])
+		assert.Nil(t, args[5])
+		assert.Nil(t, args[6])
+		assert.Nil(t, args[8])
+		assert.Nil(t, args[23])
+	}
+}